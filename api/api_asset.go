@@ -28,4 +28,8 @@ type Asset interface {
 	AddAssetView(ctx context.Context, assetCIDs []string) error //perm:admin
 	// GetAssetsInBucket get assets in bucket
 	GetAssetsInBucket(ctx context.Context, bucketID int) ([]string, error) //perm:admin
+	// CreateAssetShards splits the asset into erasure coded shards, the shards are kept until the asset is deleted
+	CreateAssetShards(ctx context.Context, assetCID string, info *types.AssetErasureInfo) (*types.AssetErasureInfo, error) //perm:admin
+	// PullAssetShard pull the shard with given index of an erasure coded asset from specified sources
+	PullAssetShard(ctx context.Context, assetCID string, shardIndex int64, info *types.AssetErasureInfo, sources []*types.CandidateDownloadInfo) error //perm:admin
 }
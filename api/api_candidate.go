@@ -42,4 +42,6 @@ type ValidationResult struct {
 	Cids []string
 	// The number of random for validator
 	RandomCount int
+	// Proofs are the storage proofs verified by the validator
+	Proofs []*types.StorageProofResult
 }
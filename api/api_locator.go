@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
 )
@@ -24,6 +25,10 @@ type Locator interface {
 	GetSchedulerWithNode(ctx context.Context, nodeID string) (string, error) //perm:default
 	// GetSchedulerWithAPIKey get the scheduler that the user create the api key
 	GetSchedulerWithAPIKey(ctx context.Context, apiKey string) (string, error) //perm:default
+	// ResolveName retrieves the record of the mutable name from the schedulers
+	ResolveName(ctx context.Context, name string) (*types.NameRecord, error) //perm:default
+	// GetSchedulerHealth retrieves the health of the schedulers probed by the locator
+	GetSchedulerHealth(ctx context.Context) ([]*SchedulerHealth, error) //perm:admin
 }
 
 // AccessPoint represents an access point within an area, containing scheduler information.
@@ -31,3 +36,19 @@ type AccessPoint struct {
 	AreaID        string
 	SchedulerURLs []string
 }
+
+// SchedulerHealth is the health of a scheduler probed by the locator
+type SchedulerHealth struct {
+	SchedulerURL string
+	AreaID       string
+	Weight       int
+	// Healthy is false after the scheduler fails the probes in a row, the unhealthy scheduler is not selected
+	Healthy bool
+	// Latency is the moving average of the latency of the probes
+	Latency time.Duration
+	// ErrorRate is the moving average of the failed probes, 0-1
+	ErrorRate   float64
+	OnlineNodes int
+	LastProbe   time.Time
+	LastError   string
+}
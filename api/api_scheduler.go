@@ -51,6 +51,24 @@ type AssetAPI interface {
 	DeleteAsset(ctx context.Context, userID, assetCID string) error //perm:web,admin,user
 	// ShareAssets shares the assets of the user.
 	ShareAssets(ctx context.Context, userID string, assetCID []string) (map[string]string, error) //perm:web,admin,user
+	// PresignAssetURL creates a revocable url to download the asset of the user, the url is limited by the expiration,
+	// max downloads, allowed client ips and password of the options
+	PresignAssetURL(ctx context.Context, userID, assetCID string, opts *types.PresignOptions) (*types.PresignedURL, error) //perm:web,admin,user
+	// ListPresignedURLs lists the presigned urls of the asset of the user
+	ListPresignedURLs(ctx context.Context, userID, assetCID string) ([]*types.PresignedLink, error) //perm:web,admin,user
+	// RevokePresignedURL revokes the presigned url of the user
+	RevokePresignedURL(ctx context.Context, userID, id string) error //perm:web,admin,user
+	// GetRevokedPresignedURLs retrieves the ids of the revoked presigned urls which are not expired
+	GetRevokedPresignedURLs(ctx context.Context) ([]string, error) //perm:edge,candidate
+	// SetUserPublicKey sets the X25519 public key of the user, the data keys of the encrypted assets are wrapped for it
+	SetUserPublicKey(ctx context.Context, userID string, publicKey []byte) error //perm:web,admin,user
+	// GetUserPublicKey retrieves the X25519 public key of the user
+	GetUserPublicKey(ctx context.Context, userID string) ([]byte, error) //perm:web,admin,user
+	// SaveAssetKeyEnvelopes saves the data key of the encrypted asset wrapped for the users,
+	// the envelopes of other users are saved by the owner of the asset or the users holding its key
+	SaveAssetKeyEnvelopes(ctx context.Context, userID, assetCID string, envelopes []*types.AssetKeyEnvelope) error //perm:web,admin,user
+	// GetAssetKeyEnvelope retrieves the data key of the encrypted asset wrapped for the user
+	GetAssetKeyEnvelope(ctx context.Context, userID, assetCID string) (*types.AssetKeyEnvelope, error) //perm:web,admin,user
 	// UpdateShareStatus update share status of the user asset
 	UpdateShareStatus(ctx context.Context, userID, assetCID string) error //perm:web,admin
 	// GetAssetStatus retrieves a asset status
@@ -63,6 +81,28 @@ type AssetAPI interface {
 	SwitchFillDiskTimer(ctx context.Context, open bool) error //perm:web,admin
 	// LoadAWSData load data
 	LoadAWSData(ctx context.Context, limit, offset int, isDistribute bool) ([]*types.AWSDataInfo, error) //perm:web,admin
+	// GetAssetShardSources retrieves the erasure info of an asset and the nodes holding its shards
+	GetAssetShardSources(ctx context.Context, assetCID string) (*types.AssetShardSources, error) //perm:edge,candidate
+	// ReportPartialAssets replaces the assets the edge partially holds in its read-through cache
+	ReportPartialAssets(ctx context.Context, assets []*types.PartialAsset) error //perm:edge
+	// GetPartialReplicas retrieves the edges partially holding the asset with the specified CID
+	GetPartialReplicas(ctx context.Context, cid string) ([]*types.PartialReplica, error) //perm:web,admin
+	// PublishNameRecord publishes the signed record of a mutable name, the record replaces the record with a lower sequence
+	PublishNameRecord(ctx context.Context, record *types.NameRecord) error //perm:web,admin,user
+	// ResolveName retrieves the record of the mutable name
+	ResolveName(ctx context.Context, name string) (*types.NameRecord, error) //perm:edge,candidate,web,locator,admin,user
+	// ListNameRecords lists the records of the names published by the user
+	ListNameRecords(ctx context.Context, userID string) ([]*types.NameRecord, error) //perm:web,admin,user
+	// CreateLifecyclePolicy creates an asset lifecycle policy and returns its id
+	CreateLifecyclePolicy(ctx context.Context, policy *types.LifecyclePolicy) (int64, error) //perm:admin
+	// ListLifecyclePolicies lists all asset lifecycle policies
+	ListLifecyclePolicies(ctx context.Context) ([]*types.LifecyclePolicy, error) //perm:web,admin
+	// DeleteLifecyclePolicy deletes an asset lifecycle policy and detaches it from assets and groups
+	DeleteLifecyclePolicy(ctx context.Context, policyID int64) error //perm:admin
+	// SetAssetLifecyclePolicy attaches a lifecycle policy to an asset, policyID 0 detaches the policy
+	SetAssetLifecyclePolicy(ctx context.Context, cid string, policyID int64) error //perm:admin
+	// SetGroupLifecyclePolicy attaches a lifecycle policy to a user asset group, policyID 0 detaches the policy
+	SetGroupLifecyclePolicy(ctx context.Context, userID string, groupID int, policyID int64) error //perm:web,admin
 }
 
 // NodeAPI is an interface for node
@@ -118,6 +158,8 @@ type NodeAPI interface {
 	RequestActivationCodes(ctx context.Context, nodeType types.NodeType, count int) ([]*types.NodeActivation, error) //perm:web,admin
 	// VerifyTokenWithLimitCount verify token in limit count
 	VerifyTokenWithLimitCount(ctx context.Context, token string) (*types.JWTPayload, error) //perm:edge,candidate
+	// VerifyPresignedToken verify the token of presigned url with the client ip and password of the download, and count the download
+	VerifyPresignedToken(ctx context.Context, token, clientIP, password string) (*types.JWTPayload, error) //perm:edge,candidate
 	// UpdateBandwidths update node bandwidthDown and bandwidthUp
 	UpdateBandwidths(ctx context.Context, bandwidthDown, bandwidthUp int64) error //perm:edge,candidate
 	// GetCandidateNodeIP get candidate ip for locator
@@ -164,6 +206,12 @@ type UserAPI interface {
 	UserAssetDownloadResult(ctx context.Context, userID, cid string, totalTraffic, peakBandwidth int64) error //perm:candidate
 	// SetUserVIP set user vip state
 	SetUserVIP(ctx context.Context, userID string, enableVIP bool) error //perm:admin
+	// GetUserEgress get the egress quota of user and the traffic used in this month
+	GetUserEgress(ctx context.Context, userID string) (*types.UserEgress, error) //perm:web,admin,user
+	// SetUserEgressPlan sets the egress plan of user, it overrides the tier given by the vip state
+	SetUserEgressPlan(ctx context.Context, plan *types.UserEgressPlan) error //perm:admin
+	// RemoveUserEgressPlan removes the egress plan of user, the tier given by the vip state is used again
+	RemoveUserEgressPlan(ctx context.Context, userID string) error //perm:admin
 	// GetUserAccessToken get access token for user
 	GetUserAccessToken(ctx context.Context, userID string) (string, error) //perm:web,admin
 	// GetUserStorageStats
@@ -187,6 +235,25 @@ type UserAPI interface {
 	MoveAssetGroup(ctx context.Context, userID string, groupID, targetGroupID int) error //perm:user,web,admin
 	// GetAPPKeyPermissions get the permissions of user app key
 	GetAPPKeyPermissions(ctx context.Context, userID, keyName string) ([]string, error) //perm:user,web,admin
+
+	// S3 gateway related methods, buckets are the root asset groups of user and objects are the user assets in them
+	// AuthS3Request checks the signature of s3 request with the api key of user,
+	// the token of the returned identity is required by the other s3 methods
+	AuthS3Request(ctx context.Context, req *types.S3AuthReq) (*types.S3Identity, error) //perm:candidate
+	// ListS3Buckets lists the buckets of user
+	ListS3Buckets(ctx context.Context, token string) ([]*types.AssetGroup, error) //perm:candidate
+	// CreateS3Bucket creates a bucket for user
+	CreateS3Bucket(ctx context.Context, token, bucket string) error //perm:candidate
+	// DeleteS3Bucket deletes an empty bucket of user
+	DeleteS3Bucket(ctx context.Context, token, bucket string) error //perm:candidate
+	// ListS3Objects lists the objects whose key starts with prefix and is greater than startAfter
+	ListS3Objects(ctx context.Context, token, bucket, prefix, startAfter string, limit int) (*types.ListS3ObjectsRsp, error) //perm:candidate
+	// GetS3Object retrieves the object with the key
+	GetS3Object(ctx context.Context, token, bucket, key string) (*types.S3Object, error) //perm:candidate
+	// PutS3Object saves an object stored in the candidate, the object with the same key is replaced
+	PutS3Object(ctx context.Context, token, bucket string, object *types.S3Object) error //perm:candidate
+	// DeleteS3Object deletes the object with the key
+	DeleteS3Object(ctx context.Context, token, bucket, key string) error //perm:candidate
 }
 
 // Scheduler is an interface for scheduler
@@ -226,8 +293,19 @@ type Scheduler interface {
 	SetEdgeUpdateConfig(ctx context.Context, info *EdgeUpdateConfig) error //perm:admin
 	// DeleteEdgeUpdateConfig deletes the edge update configuration for the specified node type
 	DeleteEdgeUpdateConfig(ctx context.Context, nodeType int) error //perm:admin
+	// GetRewardModels retrieves the reward models, the first one is the model of the current epoch
+	GetRewardModels(ctx context.Context) ([]*types.RewardModel, error) //perm:admin
+	// SaveRewardModel saves a reward model, the model is used from its start time
+	SaveRewardModel(ctx context.Context, model *types.RewardModel) error //perm:admin
+	// SimulateRewardModel replays the history in the time range through the candidate model and the model of the base version,
+	// the base version 0 is the model of the current epoch
+	SimulateRewardModel(ctx context.Context, candidate *types.RewardModel, baseVersion int, start, end time.Time) (*types.RewardSimulation, error) //perm:admin
 	// GetValidationInfo get information related to validation and election
 	GetValidationInfo(ctx context.Context) (*types.ValidationInfo, error) //perm:web,admin
 	// ElectValidators
 	ElectValidators(ctx context.Context, nodeIDs []string) error //perm:admin
+	// SubscribeEvents streams the asset, replica, node and validation events matching the filter until the context is done
+	SubscribeEvents(ctx context.Context, filter *types.EventFilter) (<-chan *types.SchedulerEvent, error) //perm:web,admin
+	// GetAuditLog lists the audit logs of the privileged calls matching the query
+	GetAuditLog(ctx context.Context, query *types.AuditLogQuery) (*types.ListAuditLogRsp, error) //perm:admin
 }
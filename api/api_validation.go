@@ -1,6 +1,10 @@
 package api
 
-import "context"
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
 
 // Validation is an interface for validate-related operations
 type Validation interface {
@@ -14,6 +18,8 @@ type ValidateReq struct {
 	TCPSrvAddr string
 	RandomSeed int64
 	Duration   int
+	// Challenges replace the random blocks with the storage proofs of the challenged leaves
+	Challenges []*types.StorageChallenge
 }
 
 // TODO: new tcp package, add these to tcp package
@@ -24,4 +30,5 @@ const (
 	TCPMsgTypeNodeID TCPMsgType = iota + 1
 	TCPMsgTypeBlock
 	TCPMsgTypeCancel
+	TCPMsgTypeProof
 )
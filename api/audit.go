@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+)
+
+// AuditFunc records a call of the audited method
+type AuditFunc func(ctx context.Context, method string, args []interface{}, err error)
+
+// auditReadPrefixes are the prefixes of the read only methods, which are not audited
+var auditReadPrefixes = []string{"Get", "List", "Load", "Check", "Verify", "Simulate", "Subscribe"}
+
+// IsAuditedMethod returns whether the call of the method by the caller is audited, the write methods
+// are audited if only the admin can call them, or the caller holds the admin permission
+func IsAuditedMethod(method string, requiredPerms auth.Permission, callerPerms []auth.Permission) bool {
+	for _, prefix := range auditReadPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+
+	isAdmin, adminOnly := false, true
+	for _, p := range split(requiredPerms) {
+		switch p {
+		case RoleAdmin:
+			isAdmin = true
+		case RoleWeb:
+			// the web console manages the scheduler on behalf of the admin
+		default:
+			adminOnly = false
+		}
+	}
+
+	if !isAdmin {
+		return false
+	}
+
+	if adminOnly {
+		return true
+	}
+
+	for _, p := range callerPerms {
+		if p == RoleAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AuditedProxy forwards the calls of out to in, the calls of the audited methods are recorded by audit after they return
+func AuditedProxy(audit AuditFunc, in interface{}, out interface{}) {
+	rint := reflect.ValueOf(out).Elem()
+	ra := reflect.ValueOf(in)
+
+	for f := 0; f < rint.NumField(); f++ {
+		field := rint.Type().Field(f)
+		fn := ra.MethodByName(field.Name)
+
+		requiredPerms := auth.Permission(field.Tag.Get("perm"))
+		if !IsAuditedMethod(field.Name, requiredPerms, []auth.Permission{RoleAdmin}) {
+			rint.Field(f).Set(fn)
+			continue
+		}
+
+		rint.Field(f).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) (results []reflect.Value) {
+			results = fn.Call(args)
+
+			ctx := args[0].Interface().(context.Context)
+			if !IsAuditedMethod(field.Name, requiredPerms, GetPerms(ctx)) {
+				return results
+			}
+
+			params := make([]interface{}, 0, len(args)-1)
+			for _, arg := range args[1:] {
+				params = append(params, arg.Interface())
+			}
+
+			err, _ := results[len(results)-1].Interface().(error)
+			audit(ctx, field.Name, params, err)
+
+			return results
+		}))
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+)
+
+type auditScheduler struct {
+	Scheduler
+}
+
+func (s *auditScheduler) SetUserVIP(ctx context.Context, userID string, enableVIP bool) error {
+	return errors.New("vip failed")
+}
+
+func (s *auditScheduler) DeleteAsset(ctx context.Context, userID, assetCID string) error {
+	return nil
+}
+
+func (s *auditScheduler) GetSchedulerPublicKey(ctx context.Context) (string, error) {
+	return "key", nil
+}
+
+func TestAuditedSchedulerAPI(t *testing.T) {
+	var methods []string
+	var calls [][]interface{}
+	a := AuditedSchedulerAPI(&auditScheduler{}, func(ctx context.Context, method string, args []interface{}, err error) {
+		methods = append(methods, method)
+		calls = append(calls, append(args, err))
+	})
+
+	if err := a.SetUserVIP(context.Background(), "user", true); err == nil {
+		t.Fatal("expected the error of SetUserVIP")
+	}
+
+	if key, err := a.GetSchedulerPublicKey(context.Background()); err != nil || key != "key" {
+		t.Fatalf("unexpected key %s, err %v", key, err)
+	}
+
+	if len(methods) != 1 || methods[0] != "SetUserVIP" {
+		t.Fatalf("unexpected audited methods %v", methods)
+	}
+
+	if calls[0][0] != "user" || calls[0][1] != true || calls[0][2].(error).Error() != "vip failed" {
+		t.Fatalf("unexpected audited call %v", calls[0])
+	}
+
+	// the methods shared with the users are audited only if the caller holds the admin permission
+	userCtx := WithPerm(context.Background(), []auth.Permission{RoleUser})
+	adminCtx := WithPerm(context.Background(), []auth.Permission{RoleAdmin})
+	if err := a.DeleteAsset(userCtx, "user", "cid"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteAsset(adminCtx, "user", "cid"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(methods) != 2 || methods[1] != "DeleteAsset" {
+		t.Fatalf("unexpected audited methods %v", methods)
+	}
+
+	if IsAuditedMethod("GetAssetRecord", "web,admin", nil) || !IsAuditedMethod("DeactivateNode", "web,admin", nil) ||
+		IsAuditedMethod("ShareAssets", "web,admin,user", []auth.Permission{RoleUser}) || !IsAuditedMethod("ShareAssets", "web,admin,user", []auth.Permission{RoleAdmin}) {
+		t.Fatal("unexpected audited methods")
+	}
+}
@@ -25,6 +25,13 @@ func permissionedProxies(in, out interface{}) {
 	}
 }
 
+func auditedProxies(audit AuditFunc, in, out interface{}) {
+	outs := GetInternalStructs(out)
+	for _, o := range outs {
+		AuditedProxy(audit, in, o)
+	}
+}
+
 func PermissionedCandidateAPI(a Candidate) Candidate {
 	var out CandidateStruct
 	permissionedProxies(a, &out)
@@ -37,6 +44,13 @@ func PermissionedSchedulerAPI(a Scheduler) Scheduler {
 	return &out
 }
 
+// AuditedSchedulerAPI records the privileged calls of the scheduler api by audit
+func AuditedSchedulerAPI(a Scheduler, audit AuditFunc) Scheduler {
+	var out SchedulerStruct
+	auditedProxies(audit, a, &out)
+	return &out
+}
+
 func PermissionedEdgeAPI(a Edge) Edge {
 	var out EdgeStruct
 	permissionedProxies(a, &out)
@@ -26,6 +26,8 @@ type AssetStruct struct {
 
 		CreateAsset func(p0 context.Context, p1 *types.AuthUserUploadDownloadAsset) (string, error) `perm:"admin"`
 
+		CreateAssetShards func(p0 context.Context, p1 string, p2 *types.AssetErasureInfo) (*types.AssetErasureInfo, error) `perm:"admin"`
+
 		DeleteAsset func(p0 context.Context, p1 string) (error) `perm:"admin"`
 
 		GetAssetProgresses func(p0 context.Context, p1 []string) (*types.PullResult, error) `perm:"admin"`
@@ -42,6 +44,8 @@ type AssetStruct struct {
 
 		PullAssetFromAWS func(p0 context.Context, p1 string, p2 string) (error) `perm:"admin"`
 
+		PullAssetShard func(p0 context.Context, p1 string, p2 int64, p3 *types.AssetErasureInfo, p4 []*types.CandidateDownloadInfo) (error) `perm:"admin"`
+
 	}
 }
 
@@ -57,34 +61,58 @@ type AssetAPIStruct struct {
 
 		CreateAsset func(p0 context.Context, p1 *types.CreateAssetReq) (*types.CreateAssetRsp, error) `perm:"web,admin,user"`
 
+		CreateLifecyclePolicy func(p0 context.Context, p1 *types.LifecyclePolicy) (int64, error) `perm:"admin"`
+
 		DeleteAsset func(p0 context.Context, p1 string, p2 string) (error) `perm:"web,admin,user"`
 
+		DeleteLifecyclePolicy func(p0 context.Context, p1 int64) (error) `perm:"admin"`
+
 		GetAssetCount func(p0 context.Context) (int, error) `perm:"web,admin"`
 
+		GetAssetKeyEnvelope func(p0 context.Context, p1 string, p2 string) (*types.AssetKeyEnvelope, error) `perm:"web,admin,user"`
+
 		GetAssetListForBucket func(p0 context.Context, p1 uint32) ([]string, error) `perm:"edge,candidate"`
 
 		GetAssetRecord func(p0 context.Context, p1 string) (*types.AssetRecord, error) `perm:"web,admin"`
 
 		GetAssetRecords func(p0 context.Context, p1 int, p2 int, p3 []string, p4 dtypes.ServerID) ([]*types.AssetRecord, error) `perm:"web,admin"`
 
+		GetAssetShardSources func(p0 context.Context, p1 string) (*types.AssetShardSources, error) `perm:"edge,candidate"`
+
 		GetAssetStatus func(p0 context.Context, p1 string, p2 string) (*types.AssetStatus, error) `perm:"web,admin"`
 
 		GetAssetsForNode func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListNodeAssetRsp, error) `perm:"web,admin"`
 
+		GetPartialReplicas func(p0 context.Context, p1 string) ([]*types.PartialReplica, error) `perm:"web,admin"`
+
 		GetReplicaEvents func(p0 context.Context, p1 time.Time, p2 time.Time, p3 int, p4 int) (*types.ListReplicaEventRsp, error) `perm:"web,admin"`
 
 		GetReplicaEventsForNode func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListReplicaEventRsp, error) `perm:"web,admin"`
 
 		GetReplicas func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListReplicaRsp, error) `perm:"web,admin"`
 
+		GetRevokedPresignedURLs func(p0 context.Context) ([]string, error) `perm:"edge,candidate"`
+
+		GetUserPublicKey func(p0 context.Context, p1 string) ([]byte, error) `perm:"web,admin,user"`
+
 		ListAssets func(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) `perm:"web,admin,user"`
 
+		ListLifecyclePolicies func(p0 context.Context) ([]*types.LifecyclePolicy, error) `perm:"web,admin"`
+
+		ListNameRecords func(p0 context.Context, p1 string) ([]*types.NameRecord, error) `perm:"web,admin,user"`
+
+		ListPresignedURLs func(p0 context.Context, p1 string, p2 string) ([]*types.PresignedLink, error) `perm:"web,admin,user"`
+
 		LoadAWSData func(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) `perm:"web,admin"`
 
 		MinioUploadFileEvent func(p0 context.Context, p1 *types.MinioUploadFileEvent) (error) `perm:"candidate"`
 
 		NodeRemoveAssetResult func(p0 context.Context, p1 types.RemoveAssetResult) (error) `perm:"edge,candidate"`
 
+		PresignAssetURL func(p0 context.Context, p1 string, p2 string, p3 *types.PresignOptions) (*types.PresignedURL, error) `perm:"web,admin,user"`
+
+		PublishNameRecord func(p0 context.Context, p1 *types.NameRecord) (error) `perm:"web,admin,user"`
+
 		PullAsset func(p0 context.Context, p1 *types.PullAssetReq) (error) `perm:"web,admin"`
 
 		RePullFailedAssets func(p0 context.Context, p1 []types.AssetHash) (error) `perm:"admin"`
@@ -93,6 +121,20 @@ type AssetAPIStruct struct {
 
 		RemoveAssetReplica func(p0 context.Context, p1 string, p2 string) (error) `perm:"admin"`
 
+		ReportPartialAssets func(p0 context.Context, p1 []*types.PartialAsset) (error) `perm:"edge"`
+
+		ResolveName func(p0 context.Context, p1 string) (*types.NameRecord, error) `perm:"edge,candidate,web,locator,admin,user"`
+
+		RevokePresignedURL func(p0 context.Context, p1 string, p2 string) (error) `perm:"web,admin,user"`
+
+		SaveAssetKeyEnvelopes func(p0 context.Context, p1 string, p2 string, p3 []*types.AssetKeyEnvelope) (error) `perm:"web,admin,user"`
+
+		SetAssetLifecyclePolicy func(p0 context.Context, p1 string, p2 int64) (error) `perm:"admin"`
+
+		SetGroupLifecyclePolicy func(p0 context.Context, p1 string, p2 int, p3 int64) (error) `perm:"web,admin"`
+
+		SetUserPublicKey func(p0 context.Context, p1 string, p2 []byte) (error) `perm:"web,admin,user"`
+
 		ShareAssets func(p0 context.Context, p1 string, p2 []string) (map[string]string, error) `perm:"web,admin,user"`
 
 		StopAssetRecord func(p0 context.Context, p1 []string) (error) `perm:"admin"`
@@ -265,12 +307,16 @@ type LocatorStruct struct {
 
 		GetCandidateIP func(p0 context.Context, p1 string) (string, error) `perm:"admin"`
 
+		GetSchedulerHealth func(p0 context.Context) ([]*SchedulerHealth, error) `perm:"admin"`
+
 		GetSchedulerWithAPIKey func(p0 context.Context, p1 string) (string, error) `perm:"default"`
 
 		GetSchedulerWithNode func(p0 context.Context, p1 string) (string, error) `perm:"default"`
 
 		GetUserAccessPoint func(p0 context.Context, p1 string) (*AccessPoint, error) `perm:"default"`
 
+		ResolveName func(p0 context.Context, p1 string) (*types.NameRecord, error) `perm:"default"`
+
 	}
 }
 
@@ -348,6 +394,8 @@ type NodeAPIStruct struct {
 
 		UpdateNodePort func(p0 context.Context, p1 string, p2 string) (error) `perm:"web,admin"`
 
+		VerifyPresignedToken func(p0 context.Context, p1 string, p2 string, p3 string) (*types.JWTPayload, error) `perm:"edge,candidate"`
+
 		VerifyTokenWithLimitCount func(p0 context.Context, p1 string) (*types.JWTPayload, error) `perm:"edge,candidate"`
 
 	}
@@ -373,12 +421,16 @@ type SchedulerStruct struct {
 
 		ElectValidators func(p0 context.Context, p1 []string) (error) `perm:"admin"`
 
+		GetAuditLog func(p0 context.Context, p1 *types.AuditLogQuery) (*types.ListAuditLogRsp, error) `perm:"admin"`
+
 		GetEdgeUpdateConfigs func(p0 context.Context) (map[int]*EdgeUpdateConfig, error) `perm:"edge"`
 
 		GetNodePublicKey func(p0 context.Context, p1 string) (string, error) `perm:"web,admin"`
 
 		GetRetrieveEventRecords func(p0 context.Context, p1 string, p2 int, p3 int) (*types.ListRetrieveEventRsp, error) `perm:"web,admin"`
 
+		GetRewardModels func(p0 context.Context) ([]*types.RewardModel, error) `perm:"admin"`
+
 		GetSchedulerPublicKey func(p0 context.Context) (string, error) `perm:"edge,candidate"`
 
 		GetValidationInfo func(p0 context.Context) (*types.ValidationInfo, error) `perm:"web,admin"`
@@ -391,12 +443,18 @@ type SchedulerStruct struct {
 
 		NodeValidationResult func(p0 context.Context, p1 io.Reader, p2 string) (error) `perm:"candidate"`
 
+		SaveRewardModel func(p0 context.Context, p1 *types.RewardModel) (error) `perm:"admin"`
+
 		SetEdgeUpdateConfig func(p0 context.Context, p1 *EdgeUpdateConfig) (error) `perm:"admin"`
 
+		SimulateRewardModel func(p0 context.Context, p1 *types.RewardModel, p2 int, p3 time.Time, p4 time.Time) (*types.RewardSimulation, error) `perm:"admin"`
+
 		SubmitNodeWorkloadReport func(p0 context.Context, p1 io.Reader) (error) `perm:"edge,candidate"`
 
 		SubmitUserWorkloadReport func(p0 context.Context, p1 io.Reader) (error) `perm:"default"`
 
+		SubscribeEvents func(p0 context.Context, p1 *types.EventFilter) (<-chan *types.SchedulerEvent, error) `perm:"web,admin"`
+
 		TriggerElection func(p0 context.Context) (error) `perm:"admin"`
 
 	}
@@ -420,20 +478,32 @@ type UserAPIStruct struct {
 
 		AllocateStorage func(p0 context.Context, p1 string) (*types.UserInfo, error) `perm:"web,admin"`
 
+		AuthS3Request func(p0 context.Context, p1 *types.S3AuthReq) (*types.S3Identity, error) `perm:"candidate"`
+
 		CreateAPIKey func(p0 context.Context, p1 string, p2 string, p3 []types.UserAccessControl) (string, error) `perm:"web,admin"`
 
 		CreateAssetGroup func(p0 context.Context, p1 string, p2 string, p3 int) (*types.AssetGroup, error) `perm:"user,web,admin"`
 
+		CreateS3Bucket func(p0 context.Context, p1 string, p2 string) (error) `perm:"candidate"`
+
 		DeleteAPIKey func(p0 context.Context, p1 string, p2 string) (error) `perm:"web,admin"`
 
 		DeleteAssetGroup func(p0 context.Context, p1 string, p2 int) (error) `perm:"user,web,admin"`
 
+		DeleteS3Bucket func(p0 context.Context, p1 string, p2 string) (error) `perm:"candidate"`
+
+		DeleteS3Object func(p0 context.Context, p1 string, p2 string, p3 string) (error) `perm:"candidate"`
+
 		GetAPIKeys func(p0 context.Context, p1 string) (map[string]types.UserAPIKeysInfo, error) `perm:"web,admin"`
 
 		GetAPPKeyPermissions func(p0 context.Context, p1 string, p2 string) ([]string, error) `perm:"user,web,admin"`
 
+		GetS3Object func(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) `perm:"candidate"`
+
 		GetUserAccessToken func(p0 context.Context, p1 string) (string, error) `perm:"web,admin"`
 
+		GetUserEgress func(p0 context.Context, p1 string) (*types.UserEgress, error) `perm:"web,admin,user"`
+
 		GetUserInfo func(p0 context.Context, p1 string) (*types.UserInfo, error) `perm:"web,admin"`
 
 		GetUserInfos func(p0 context.Context, p1 []string) (map[string]*types.UserInfo, error) `perm:"web,admin"`
@@ -444,14 +514,24 @@ type UserAPIStruct struct {
 
 		ListAssetSummary func(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetSummaryRsp, error) `perm:"user,web,admin"`
 
+		ListS3Buckets func(p0 context.Context, p1 string) ([]*types.AssetGroup, error) `perm:"candidate"`
+
+		ListS3Objects func(p0 context.Context, p1 string, p2 string, p3 string, p4 string, p5 int) (*types.ListS3ObjectsRsp, error) `perm:"candidate"`
+
 		ListUserStorageStats func(p0 context.Context, p1 int, p2 int) (*types.ListStorageStatsRsp, error) `perm:"web,admin"`
 
 		MoveAssetGroup func(p0 context.Context, p1 string, p2 int, p3 int) (error) `perm:"user,web,admin"`
 
 		MoveAssetToGroup func(p0 context.Context, p1 string, p2 string, p3 int) (error) `perm:"user,web,admin"`
 
+		PutS3Object func(p0 context.Context, p1 string, p2 string, p3 *types.S3Object) (error) `perm:"candidate"`
+
+		RemoveUserEgressPlan func(p0 context.Context, p1 string) (error) `perm:"admin"`
+
 		RenameAssetGroup func(p0 context.Context, p1 string, p2 string, p3 int) (error) `perm:"user,web,admin"`
 
+		SetUserEgressPlan func(p0 context.Context, p1 *types.UserEgressPlan) (error) `perm:"admin"`
+
 		SetUserVIP func(p0 context.Context, p1 string, p2 bool) (error) `perm:"admin"`
 
 		UserAPIKeysExists func(p0 context.Context, p1 string) (error) `perm:"web"`
@@ -504,6 +584,17 @@ func (s *AssetStub) CreateAsset(p0 context.Context, p1 *types.AuthUserUploadDown
 	return "", ErrNotSupported
 }
 
+func (s *AssetStruct) CreateAssetShards(p0 context.Context, p1 string, p2 *types.AssetErasureInfo) (*types.AssetErasureInfo, error) {
+	if s.Internal.CreateAssetShards == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.CreateAssetShards(p0, p1, p2)
+}
+
+func (s *AssetStub) CreateAssetShards(p0 context.Context, p1 string, p2 *types.AssetErasureInfo) (*types.AssetErasureInfo, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetStruct) DeleteAsset(p0 context.Context, p1 string) (error) {
 	if s.Internal.DeleteAsset == nil {
 		return ErrNotSupported
@@ -592,6 +683,17 @@ func (s *AssetStub) PullAssetFromAWS(p0 context.Context, p1 string, p2 string) (
 	return ErrNotSupported
 }
 
+func (s *AssetStruct) PullAssetShard(p0 context.Context, p1 string, p2 int64, p3 *types.AssetErasureInfo, p4 []*types.CandidateDownloadInfo) (error) {
+	if s.Internal.PullAssetShard == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PullAssetShard(p0, p1, p2, p3, p4)
+}
+
+func (s *AssetStub) PullAssetShard(p0 context.Context, p1 string, p2 int64, p3 *types.AssetErasureInfo, p4 []*types.CandidateDownloadInfo) (error) {
+	return ErrNotSupported
+}
+
 
 
 
@@ -617,6 +719,17 @@ func (s *AssetAPIStub) CreateAsset(p0 context.Context, p1 *types.CreateAssetReq)
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) CreateLifecyclePolicy(p0 context.Context, p1 *types.LifecyclePolicy) (int64, error) {
+	if s.Internal.CreateLifecyclePolicy == nil {
+		return 0, ErrNotSupported
+	}
+	return s.Internal.CreateLifecyclePolicy(p0, p1)
+}
+
+func (s *AssetAPIStub) CreateLifecyclePolicy(p0 context.Context, p1 *types.LifecyclePolicy) (int64, error) {
+	return 0, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) DeleteAsset(p0 context.Context, p1 string, p2 string) (error) {
 	if s.Internal.DeleteAsset == nil {
 		return ErrNotSupported
@@ -628,6 +741,17 @@ func (s *AssetAPIStub) DeleteAsset(p0 context.Context, p1 string, p2 string) (er
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) DeleteLifecyclePolicy(p0 context.Context, p1 int64) (error) {
+	if s.Internal.DeleteLifecyclePolicy == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeleteLifecyclePolicy(p0, p1)
+}
+
+func (s *AssetAPIStub) DeleteLifecyclePolicy(p0 context.Context, p1 int64) (error) {
+	return ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetAssetCount(p0 context.Context) (int, error) {
 	if s.Internal.GetAssetCount == nil {
 		return 0, ErrNotSupported
@@ -639,6 +763,17 @@ func (s *AssetAPIStub) GetAssetCount(p0 context.Context) (int, error) {
 	return 0, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetAssetKeyEnvelope(p0 context.Context, p1 string, p2 string) (*types.AssetKeyEnvelope, error) {
+	if s.Internal.GetAssetKeyEnvelope == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAssetKeyEnvelope(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) GetAssetKeyEnvelope(p0 context.Context, p1 string, p2 string) (*types.AssetKeyEnvelope, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetAssetListForBucket(p0 context.Context, p1 uint32) ([]string, error) {
 	if s.Internal.GetAssetListForBucket == nil {
 		return *new([]string), ErrNotSupported
@@ -672,6 +807,17 @@ func (s *AssetAPIStub) GetAssetRecords(p0 context.Context, p1 int, p2 int, p3 []
 	return *new([]*types.AssetRecord), ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetAssetShardSources(p0 context.Context, p1 string) (*types.AssetShardSources, error) {
+	if s.Internal.GetAssetShardSources == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAssetShardSources(p0, p1)
+}
+
+func (s *AssetAPIStub) GetAssetShardSources(p0 context.Context, p1 string) (*types.AssetShardSources, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetAssetStatus(p0 context.Context, p1 string, p2 string) (*types.AssetStatus, error) {
 	if s.Internal.GetAssetStatus == nil {
 		return nil, ErrNotSupported
@@ -694,6 +840,17 @@ func (s *AssetAPIStub) GetAssetsForNode(p0 context.Context, p1 string, p2 int, p
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetPartialReplicas(p0 context.Context, p1 string) ([]*types.PartialReplica, error) {
+	if s.Internal.GetPartialReplicas == nil {
+		return *new([]*types.PartialReplica), ErrNotSupported
+	}
+	return s.Internal.GetPartialReplicas(p0, p1)
+}
+
+func (s *AssetAPIStub) GetPartialReplicas(p0 context.Context, p1 string) ([]*types.PartialReplica, error) {
+	return *new([]*types.PartialReplica), ErrNotSupported
+}
+
 func (s *AssetAPIStruct) GetReplicaEvents(p0 context.Context, p1 time.Time, p2 time.Time, p3 int, p4 int) (*types.ListReplicaEventRsp, error) {
 	if s.Internal.GetReplicaEvents == nil {
 		return nil, ErrNotSupported
@@ -727,6 +884,28 @@ func (s *AssetAPIStub) GetReplicas(p0 context.Context, p1 string, p2 int, p3 int
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) GetRevokedPresignedURLs(p0 context.Context) ([]string, error) {
+	if s.Internal.GetRevokedPresignedURLs == nil {
+		return *new([]string), ErrNotSupported
+	}
+	return s.Internal.GetRevokedPresignedURLs(p0)
+}
+
+func (s *AssetAPIStub) GetRevokedPresignedURLs(p0 context.Context) ([]string, error) {
+	return *new([]string), ErrNotSupported
+}
+
+func (s *AssetAPIStruct) GetUserPublicKey(p0 context.Context, p1 string) ([]byte, error) {
+	if s.Internal.GetUserPublicKey == nil {
+		return *new([]byte), ErrNotSupported
+	}
+	return s.Internal.GetUserPublicKey(p0, p1)
+}
+
+func (s *AssetAPIStub) GetUserPublicKey(p0 context.Context, p1 string) ([]byte, error) {
+	return *new([]byte), ErrNotSupported
+}
+
 func (s *AssetAPIStruct) ListAssets(p0 context.Context, p1 string, p2 int, p3 int, p4 int) (*types.ListAssetRecordRsp, error) {
 	if s.Internal.ListAssets == nil {
 		return nil, ErrNotSupported
@@ -738,6 +917,39 @@ func (s *AssetAPIStub) ListAssets(p0 context.Context, p1 string, p2 int, p3 int,
 	return nil, ErrNotSupported
 }
 
+func (s *AssetAPIStruct) ListLifecyclePolicies(p0 context.Context) ([]*types.LifecyclePolicy, error) {
+	if s.Internal.ListLifecyclePolicies == nil {
+		return *new([]*types.LifecyclePolicy), ErrNotSupported
+	}
+	return s.Internal.ListLifecyclePolicies(p0)
+}
+
+func (s *AssetAPIStub) ListLifecyclePolicies(p0 context.Context) ([]*types.LifecyclePolicy, error) {
+	return *new([]*types.LifecyclePolicy), ErrNotSupported
+}
+
+func (s *AssetAPIStruct) ListNameRecords(p0 context.Context, p1 string) ([]*types.NameRecord, error) {
+	if s.Internal.ListNameRecords == nil {
+		return *new([]*types.NameRecord), ErrNotSupported
+	}
+	return s.Internal.ListNameRecords(p0, p1)
+}
+
+func (s *AssetAPIStub) ListNameRecords(p0 context.Context, p1 string) ([]*types.NameRecord, error) {
+	return *new([]*types.NameRecord), ErrNotSupported
+}
+
+func (s *AssetAPIStruct) ListPresignedURLs(p0 context.Context, p1 string, p2 string) ([]*types.PresignedLink, error) {
+	if s.Internal.ListPresignedURLs == nil {
+		return *new([]*types.PresignedLink), ErrNotSupported
+	}
+	return s.Internal.ListPresignedURLs(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) ListPresignedURLs(p0 context.Context, p1 string, p2 string) ([]*types.PresignedLink, error) {
+	return *new([]*types.PresignedLink), ErrNotSupported
+}
+
 func (s *AssetAPIStruct) LoadAWSData(p0 context.Context, p1 int, p2 int, p3 bool) ([]*types.AWSDataInfo, error) {
 	if s.Internal.LoadAWSData == nil {
 		return *new([]*types.AWSDataInfo), ErrNotSupported
@@ -771,6 +983,28 @@ func (s *AssetAPIStub) NodeRemoveAssetResult(p0 context.Context, p1 types.Remove
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) PresignAssetURL(p0 context.Context, p1 string, p2 string, p3 *types.PresignOptions) (*types.PresignedURL, error) {
+	if s.Internal.PresignAssetURL == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.PresignAssetURL(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) PresignAssetURL(p0 context.Context, p1 string, p2 string, p3 *types.PresignOptions) (*types.PresignedURL, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) PublishNameRecord(p0 context.Context, p1 *types.NameRecord) (error) {
+	if s.Internal.PublishNameRecord == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PublishNameRecord(p0, p1)
+}
+
+func (s *AssetAPIStub) PublishNameRecord(p0 context.Context, p1 *types.NameRecord) (error) {
+	return ErrNotSupported
+}
+
 func (s *AssetAPIStruct) PullAsset(p0 context.Context, p1 *types.PullAssetReq) (error) {
 	if s.Internal.PullAsset == nil {
 		return ErrNotSupported
@@ -815,6 +1049,83 @@ func (s *AssetAPIStub) RemoveAssetReplica(p0 context.Context, p1 string, p2 stri
 	return ErrNotSupported
 }
 
+func (s *AssetAPIStruct) ReportPartialAssets(p0 context.Context, p1 []*types.PartialAsset) (error) {
+	if s.Internal.ReportPartialAssets == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ReportPartialAssets(p0, p1)
+}
+
+func (s *AssetAPIStub) ReportPartialAssets(p0 context.Context, p1 []*types.PartialAsset) (error) {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) ResolveName(p0 context.Context, p1 string) (*types.NameRecord, error) {
+	if s.Internal.ResolveName == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ResolveName(p0, p1)
+}
+
+func (s *AssetAPIStub) ResolveName(p0 context.Context, p1 string) (*types.NameRecord, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *AssetAPIStruct) RevokePresignedURL(p0 context.Context, p1 string, p2 string) (error) {
+	if s.Internal.RevokePresignedURL == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RevokePresignedURL(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) RevokePresignedURL(p0 context.Context, p1 string, p2 string) (error) {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SaveAssetKeyEnvelopes(p0 context.Context, p1 string, p2 string, p3 []*types.AssetKeyEnvelope) (error) {
+	if s.Internal.SaveAssetKeyEnvelopes == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SaveAssetKeyEnvelopes(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) SaveAssetKeyEnvelopes(p0 context.Context, p1 string, p2 string, p3 []*types.AssetKeyEnvelope) (error) {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SetAssetLifecyclePolicy(p0 context.Context, p1 string, p2 int64) (error) {
+	if s.Internal.SetAssetLifecyclePolicy == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetAssetLifecyclePolicy(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) SetAssetLifecyclePolicy(p0 context.Context, p1 string, p2 int64) (error) {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SetGroupLifecyclePolicy(p0 context.Context, p1 string, p2 int, p3 int64) (error) {
+	if s.Internal.SetGroupLifecyclePolicy == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetGroupLifecyclePolicy(p0, p1, p2, p3)
+}
+
+func (s *AssetAPIStub) SetGroupLifecyclePolicy(p0 context.Context, p1 string, p2 int, p3 int64) (error) {
+	return ErrNotSupported
+}
+
+func (s *AssetAPIStruct) SetUserPublicKey(p0 context.Context, p1 string, p2 []byte) (error) {
+	if s.Internal.SetUserPublicKey == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetUserPublicKey(p0, p1, p2)
+}
+
+func (s *AssetAPIStub) SetUserPublicKey(p0 context.Context, p1 string, p2 []byte) (error) {
+	return ErrNotSupported
+}
+
 func (s *AssetAPIStruct) ShareAssets(p0 context.Context, p1 string, p2 []string) (map[string]string, error) {
 	if s.Internal.ShareAssets == nil {
 		return *new(map[string]string), ErrNotSupported
@@ -1185,6 +1496,17 @@ func (s *LocatorStub) GetCandidateIP(p0 context.Context, p1 string) (string, err
 	return "", ErrNotSupported
 }
 
+func (s *LocatorStruct) GetSchedulerHealth(p0 context.Context) ([]*SchedulerHealth, error) {
+	if s.Internal.GetSchedulerHealth == nil {
+		return *new([]*SchedulerHealth), ErrNotSupported
+	}
+	return s.Internal.GetSchedulerHealth(p0)
+}
+
+func (s *LocatorStub) GetSchedulerHealth(p0 context.Context) ([]*SchedulerHealth, error) {
+	return *new([]*SchedulerHealth), ErrNotSupported
+}
+
 func (s *LocatorStruct) GetSchedulerWithAPIKey(p0 context.Context, p1 string) (string, error) {
 	if s.Internal.GetSchedulerWithAPIKey == nil {
 		return "", ErrNotSupported
@@ -1218,6 +1540,17 @@ func (s *LocatorStub) GetUserAccessPoint(p0 context.Context, p1 string) (*Access
 	return nil, ErrNotSupported
 }
 
+func (s *LocatorStruct) ResolveName(p0 context.Context, p1 string) (*types.NameRecord, error) {
+	if s.Internal.ResolveName == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ResolveName(p0, p1)
+}
+
+func (s *LocatorStub) ResolveName(p0 context.Context, p1 string) (*types.NameRecord, error) {
+	return nil, ErrNotSupported
+}
+
 
 
 
@@ -1573,6 +1906,17 @@ func (s *NodeAPIStub) UpdateNodePort(p0 context.Context, p1 string, p2 string) (
 	return ErrNotSupported
 }
 
+func (s *NodeAPIStruct) VerifyPresignedToken(p0 context.Context, p1 string, p2 string, p3 string) (*types.JWTPayload, error) {
+	if s.Internal.VerifyPresignedToken == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.VerifyPresignedToken(p0, p1, p2, p3)
+}
+
+func (s *NodeAPIStub) VerifyPresignedToken(p0 context.Context, p1 string, p2 string, p3 string) (*types.JWTPayload, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *NodeAPIStruct) VerifyTokenWithLimitCount(p0 context.Context, p1 string) (*types.JWTPayload, error) {
 	if s.Internal.VerifyTokenWithLimitCount == nil {
 		return nil, ErrNotSupported
@@ -1609,6 +1953,17 @@ func (s *SchedulerStub) ElectValidators(p0 context.Context, p1 []string) (error)
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetAuditLog(p0 context.Context, p1 *types.AuditLogQuery) (*types.ListAuditLogRsp, error) {
+	if s.Internal.GetAuditLog == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAuditLog(p0, p1)
+}
+
+func (s *SchedulerStub) GetAuditLog(p0 context.Context, p1 *types.AuditLogQuery) (*types.ListAuditLogRsp, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetEdgeUpdateConfigs(p0 context.Context) (map[int]*EdgeUpdateConfig, error) {
 	if s.Internal.GetEdgeUpdateConfigs == nil {
 		return *new(map[int]*EdgeUpdateConfig), ErrNotSupported
@@ -1642,6 +1997,17 @@ func (s *SchedulerStub) GetRetrieveEventRecords(p0 context.Context, p1 string, p
 	return nil, ErrNotSupported
 }
 
+func (s *SchedulerStruct) GetRewardModels(p0 context.Context) ([]*types.RewardModel, error) {
+	if s.Internal.GetRewardModels == nil {
+		return *new([]*types.RewardModel), ErrNotSupported
+	}
+	return s.Internal.GetRewardModels(p0)
+}
+
+func (s *SchedulerStub) GetRewardModels(p0 context.Context) ([]*types.RewardModel, error) {
+	return *new([]*types.RewardModel), ErrNotSupported
+}
+
 func (s *SchedulerStruct) GetSchedulerPublicKey(p0 context.Context) (string, error) {
 	if s.Internal.GetSchedulerPublicKey == nil {
 		return "", ErrNotSupported
@@ -1708,6 +2074,17 @@ func (s *SchedulerStub) NodeValidationResult(p0 context.Context, p1 io.Reader, p
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) SaveRewardModel(p0 context.Context, p1 *types.RewardModel) (error) {
+	if s.Internal.SaveRewardModel == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SaveRewardModel(p0, p1)
+}
+
+func (s *SchedulerStub) SaveRewardModel(p0 context.Context, p1 *types.RewardModel) (error) {
+	return ErrNotSupported
+}
+
 func (s *SchedulerStruct) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateConfig) (error) {
 	if s.Internal.SetEdgeUpdateConfig == nil {
 		return ErrNotSupported
@@ -1719,6 +2096,17 @@ func (s *SchedulerStub) SetEdgeUpdateConfig(p0 context.Context, p1 *EdgeUpdateCo
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) SimulateRewardModel(p0 context.Context, p1 *types.RewardModel, p2 int, p3 time.Time, p4 time.Time) (*types.RewardSimulation, error) {
+	if s.Internal.SimulateRewardModel == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SimulateRewardModel(p0, p1, p2, p3, p4)
+}
+
+func (s *SchedulerStub) SimulateRewardModel(p0 context.Context, p1 *types.RewardModel, p2 int, p3 time.Time, p4 time.Time) (*types.RewardSimulation, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) SubmitNodeWorkloadReport(p0 context.Context, p1 io.Reader) (error) {
 	if s.Internal.SubmitNodeWorkloadReport == nil {
 		return ErrNotSupported
@@ -1741,6 +2129,17 @@ func (s *SchedulerStub) SubmitUserWorkloadReport(p0 context.Context, p1 io.Reade
 	return ErrNotSupported
 }
 
+func (s *SchedulerStruct) SubscribeEvents(p0 context.Context, p1 *types.EventFilter) (<-chan *types.SchedulerEvent, error) {
+	if s.Internal.SubscribeEvents == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SubscribeEvents(p0, p1)
+}
+
+func (s *SchedulerStub) SubscribeEvents(p0 context.Context, p1 *types.EventFilter) (<-chan *types.SchedulerEvent, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *SchedulerStruct) TriggerElection(p0 context.Context) (error) {
 	if s.Internal.TriggerElection == nil {
 		return ErrNotSupported
@@ -1766,6 +2165,17 @@ func (s *UserAPIStub) AllocateStorage(p0 context.Context, p1 string) (*types.Use
 	return nil, ErrNotSupported
 }
 
+func (s *UserAPIStruct) AuthS3Request(p0 context.Context, p1 *types.S3AuthReq) (*types.S3Identity, error) {
+	if s.Internal.AuthS3Request == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.AuthS3Request(p0, p1)
+}
+
+func (s *UserAPIStub) AuthS3Request(p0 context.Context, p1 *types.S3AuthReq) (*types.S3Identity, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *UserAPIStruct) CreateAPIKey(p0 context.Context, p1 string, p2 string, p3 []types.UserAccessControl) (string, error) {
 	if s.Internal.CreateAPIKey == nil {
 		return "", ErrNotSupported
@@ -1788,6 +2198,17 @@ func (s *UserAPIStub) CreateAssetGroup(p0 context.Context, p1 string, p2 string,
 	return nil, ErrNotSupported
 }
 
+func (s *UserAPIStruct) CreateS3Bucket(p0 context.Context, p1 string, p2 string) (error) {
+	if s.Internal.CreateS3Bucket == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.CreateS3Bucket(p0, p1, p2)
+}
+
+func (s *UserAPIStub) CreateS3Bucket(p0 context.Context, p1 string, p2 string) (error) {
+	return ErrNotSupported
+}
+
 func (s *UserAPIStruct) DeleteAPIKey(p0 context.Context, p1 string, p2 string) (error) {
 	if s.Internal.DeleteAPIKey == nil {
 		return ErrNotSupported
@@ -1810,6 +2231,28 @@ func (s *UserAPIStub) DeleteAssetGroup(p0 context.Context, p1 string, p2 int) (e
 	return ErrNotSupported
 }
 
+func (s *UserAPIStruct) DeleteS3Bucket(p0 context.Context, p1 string, p2 string) (error) {
+	if s.Internal.DeleteS3Bucket == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeleteS3Bucket(p0, p1, p2)
+}
+
+func (s *UserAPIStub) DeleteS3Bucket(p0 context.Context, p1 string, p2 string) (error) {
+	return ErrNotSupported
+}
+
+func (s *UserAPIStruct) DeleteS3Object(p0 context.Context, p1 string, p2 string, p3 string) (error) {
+	if s.Internal.DeleteS3Object == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.DeleteS3Object(p0, p1, p2, p3)
+}
+
+func (s *UserAPIStub) DeleteS3Object(p0 context.Context, p1 string, p2 string, p3 string) (error) {
+	return ErrNotSupported
+}
+
 func (s *UserAPIStruct) GetAPIKeys(p0 context.Context, p1 string) (map[string]types.UserAPIKeysInfo, error) {
 	if s.Internal.GetAPIKeys == nil {
 		return *new(map[string]types.UserAPIKeysInfo), ErrNotSupported
@@ -1832,6 +2275,17 @@ func (s *UserAPIStub) GetAPPKeyPermissions(p0 context.Context, p1 string, p2 str
 	return *new([]string), ErrNotSupported
 }
 
+func (s *UserAPIStruct) GetS3Object(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) {
+	if s.Internal.GetS3Object == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetS3Object(p0, p1, p2, p3)
+}
+
+func (s *UserAPIStub) GetS3Object(p0 context.Context, p1 string, p2 string, p3 string) (*types.S3Object, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *UserAPIStruct) GetUserAccessToken(p0 context.Context, p1 string) (string, error) {
 	if s.Internal.GetUserAccessToken == nil {
 		return "", ErrNotSupported
@@ -1843,6 +2297,17 @@ func (s *UserAPIStub) GetUserAccessToken(p0 context.Context, p1 string) (string,
 	return "", ErrNotSupported
 }
 
+func (s *UserAPIStruct) GetUserEgress(p0 context.Context, p1 string) (*types.UserEgress, error) {
+	if s.Internal.GetUserEgress == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetUserEgress(p0, p1)
+}
+
+func (s *UserAPIStub) GetUserEgress(p0 context.Context, p1 string) (*types.UserEgress, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *UserAPIStruct) GetUserInfo(p0 context.Context, p1 string) (*types.UserInfo, error) {
 	if s.Internal.GetUserInfo == nil {
 		return nil, ErrNotSupported
@@ -1898,6 +2363,28 @@ func (s *UserAPIStub) ListAssetSummary(p0 context.Context, p1 string, p2 int, p3
 	return nil, ErrNotSupported
 }
 
+func (s *UserAPIStruct) ListS3Buckets(p0 context.Context, p1 string) ([]*types.AssetGroup, error) {
+	if s.Internal.ListS3Buckets == nil {
+		return *new([]*types.AssetGroup), ErrNotSupported
+	}
+	return s.Internal.ListS3Buckets(p0, p1)
+}
+
+func (s *UserAPIStub) ListS3Buckets(p0 context.Context, p1 string) ([]*types.AssetGroup, error) {
+	return *new([]*types.AssetGroup), ErrNotSupported
+}
+
+func (s *UserAPIStruct) ListS3Objects(p0 context.Context, p1 string, p2 string, p3 string, p4 string, p5 int) (*types.ListS3ObjectsRsp, error) {
+	if s.Internal.ListS3Objects == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ListS3Objects(p0, p1, p2, p3, p4, p5)
+}
+
+func (s *UserAPIStub) ListS3Objects(p0 context.Context, p1 string, p2 string, p3 string, p4 string, p5 int) (*types.ListS3ObjectsRsp, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *UserAPIStruct) ListUserStorageStats(p0 context.Context, p1 int, p2 int) (*types.ListStorageStatsRsp, error) {
 	if s.Internal.ListUserStorageStats == nil {
 		return nil, ErrNotSupported
@@ -1931,6 +2418,28 @@ func (s *UserAPIStub) MoveAssetToGroup(p0 context.Context, p1 string, p2 string,
 	return ErrNotSupported
 }
 
+func (s *UserAPIStruct) PutS3Object(p0 context.Context, p1 string, p2 string, p3 *types.S3Object) (error) {
+	if s.Internal.PutS3Object == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.PutS3Object(p0, p1, p2, p3)
+}
+
+func (s *UserAPIStub) PutS3Object(p0 context.Context, p1 string, p2 string, p3 *types.S3Object) (error) {
+	return ErrNotSupported
+}
+
+func (s *UserAPIStruct) RemoveUserEgressPlan(p0 context.Context, p1 string) (error) {
+	if s.Internal.RemoveUserEgressPlan == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.RemoveUserEgressPlan(p0, p1)
+}
+
+func (s *UserAPIStub) RemoveUserEgressPlan(p0 context.Context, p1 string) (error) {
+	return ErrNotSupported
+}
+
 func (s *UserAPIStruct) RenameAssetGroup(p0 context.Context, p1 string, p2 string, p3 int) (error) {
 	if s.Internal.RenameAssetGroup == nil {
 		return ErrNotSupported
@@ -1942,6 +2451,17 @@ func (s *UserAPIStub) RenameAssetGroup(p0 context.Context, p1 string, p2 string,
 	return ErrNotSupported
 }
 
+func (s *UserAPIStruct) SetUserEgressPlan(p0 context.Context, p1 *types.UserEgressPlan) (error) {
+	if s.Internal.SetUserEgressPlan == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.SetUserEgressPlan(p0, p1)
+}
+
+func (s *UserAPIStub) SetUserEgressPlan(p0 context.Context, p1 *types.UserEgressPlan) (error) {
+	return ErrNotSupported
+}
+
 func (s *UserAPIStruct) SetUserVIP(p0 context.Context, p1 string, p2 bool) (error) {
 	if s.Internal.SetUserVIP == nil {
 		return ErrNotSupported
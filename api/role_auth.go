@@ -31,6 +31,12 @@ func WithPerm(ctx context.Context, perms []auth.Permission) context.Context {
 	return context.WithValue(ctx, permCtxKey, perms)
 }
 
+// GetPerms returns the permissions of the caller
+func GetPerms(ctx context.Context) []auth.Permission {
+	perms, _ := ctx.Value(permCtxKey).([]auth.Permission)
+	return perms
+}
+
 func WithUserAccessControl(ctx context.Context, acl []types.UserAccessControl) context.Context {
 	return context.WithValue(ctx, aclCtxKey, acl)
 }
@@ -32,6 +32,15 @@ const (
 	NodeDeactivate     // node deactivate
 	NodeOffline        // node offline
 
+	GroupAlreadyExist // group already exist
+
+	UserEgressOutOfQuota // the monthly egress quota of user is exhausted
+
+	PresignedURLRevoked           // the presigned url is revoked
+	PresignedURLOutOfMaxDownloads // the presigned url is out of max downloads
+	PresignedURLClientNotAllowed  // the client ip is not allowed by the presigned url
+	PresignedURLPasswordIncorrect // the password of the presigned url is incorrect
+
 	Success = 0
 	Unknown = -1
 )
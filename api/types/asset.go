@@ -15,6 +15,8 @@ type AssetPullProgress struct {
 	DoneBlocksCount int
 	Size            int64
 	DoneSize        int64
+	// Commitment of the stored asset, only for the succeeded asset
+	Commitment *AssetCommitment
 }
 
 // PullResult contains information about the result of a data pull
@@ -113,6 +115,66 @@ type PullAssetReq struct {
 
 	CandidateNodeList []string
 	EdgeNodeList      []string
+
+	// If DataShards is greater than 0, edges store erasure coded shards instead of full replicas,
+	// the asset is split into DataShards data shards and ParityShards parity shards
+	DataShards   int64
+	ParityShards int64
+
+	// Forwarded is set when the request is forwarded to the scheduler owning the asset, it is not forwarded again
+	Forwarded bool
+}
+
+// AssetErasureInfo describes how an erasure coded asset is split into shards
+type AssetErasureInfo struct {
+	Hash         string `db:"hash"`
+	DataShards   int64  `db:"data_shards"`
+	ParityShards int64  `db:"parity_shards"`
+	StripeSize   int64  `db:"stripe_size"`
+	// size of each shard
+	ShardSize int64 `db:"shard_size"`
+	// size of the car file before encoding
+	CarSize int64 `db:"car_size"`
+}
+
+// TotalShards returns the number of data and parity shards
+func (info *AssetErasureInfo) TotalShards() int64 {
+	return info.DataShards + info.ParityShards
+}
+
+// ReplicaShard represents the shard of an erasure coded asset held by a node
+type ReplicaShard struct {
+	Hash       string `db:"hash"`
+	NodeID     string `db:"node_id"`
+	ShardIndex int64  `db:"shard_index"`
+}
+
+// ShardSource represents a node which can provide the shard with ShardIndex
+type ShardSource struct {
+	ShardIndex int64
+	*CandidateDownloadInfo
+}
+
+// AssetShardSources contains the erasure info of an asset and the nodes holding its shards
+type AssetShardSources struct {
+	ErasureInfo *AssetErasureInfo
+	Sources     []*ShardSource
+}
+
+// PartialAsset is an asset an edge holds part of the blocks in its read-through cache
+type PartialAsset struct {
+	CID    string
+	Blocks int
+	Size   int64
+}
+
+// PartialReplica is a partial asset reported by the edge
+type PartialReplica struct {
+	NodeID      string    `db:"node_id"`
+	Hash        string    `db:"hash"`
+	Blocks      int       `db:"blocks"`
+	Size        int64     `db:"size"`
+	UpdatedTime time.Time `db:"updated_time"`
 }
 
 // AssetType represents the type of a asset
@@ -260,3 +322,76 @@ type AssetView struct {
 	// key bucketID, value bucketHash
 	BucketHashes map[uint32]string
 }
+
+// LifecycleRule is a rule of asset lifecycle policy, it applies once the asset is older than AfterDays
+// and has been retrieved less than MaxRetrievals times in the last AfterDays days (0 means no retrieval condition).
+type LifecycleRule struct {
+	PolicyID      int64 `db:"policy_id"`
+	AfterDays     int64 `db:"after_days"`
+	MaxRetrievals int64 `db:"max_retrievals"`
+	// The number of edge replicas to keep, 0 keeps the candidate replicas only
+	EdgeReplicas int64 `db:"edge_replicas"`
+	// Delete the asset
+	Delete bool `db:"delete_asset"`
+}
+
+// LifecyclePolicy is a set of lifecycle rules attached to assets or user asset groups,
+// the rule with the largest AfterDays of all applicable rules takes effect
+type LifecyclePolicy struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	// Delete the asset at expiration
+	DeleteExpired bool      `db:"delete_expired"`
+	CreatedTime   time.Time `db:"created_time"`
+
+	Rules []*LifecycleRule
+}
+
+// AssetScaling records the edge replicas added to an asset by popularity scaling
+type AssetScaling struct {
+	Hash string `db:"hash"`
+	// The number of edge replicas before scaling
+	BaseReplicas int64 `db:"base_replicas"`
+	// The number of edge replicas added by scaling
+	ScaledReplicas int64     `db:"scaled_replicas"`
+	UpdatedTime    time.Time `db:"updated_time"`
+}
+
+// AssetCommitment is the merkle root committed by a node over the sorted multihashes of the blocks of an asset
+type AssetCommitment struct {
+	Hash   string `db:"hash"`
+	NodeID string `db:"node_id"`
+	// Root is the hex encoded merkle root
+	Root        string    `db:"root"`
+	Leaves      int       `db:"leaves"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// StorageChallenge asks a node to prove the storage of the leaves of an asset commitment
+type StorageChallenge struct {
+	AssetCID string
+	// Leaves are the indexes of the challenged leaves
+	Leaves []int
+}
+
+// StorageProof is the answer of a challenged leaf, the block data with the inclusion proof of its multihash
+type StorageProof struct {
+	AssetCID string
+	Leaf     int
+	// Root and Leaves are the commitment of the asset
+	Root      []byte
+	Leaves    int
+	Multihash []byte
+	Data      []byte
+	Proof     [][]byte
+}
+
+// StorageProofResult is the result of a storage proof verified by the validator
+type StorageProofResult struct {
+	AssetCID string
+	Leaf     int
+	// Root is the hex encoded merkle root which the proof is verified with
+	Root     string
+	Leaves   int
+	Verified bool
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// AuditLog is the record of a privileged call of the scheduler api
+type AuditLog struct {
+	ID int64 `db:"id"`
+	// Caller is the id in the token of the caller, empty if the call has no token
+	Caller string `db:"caller"`
+	// Role is the permissions of the caller separated by comma
+	Role       string `db:"role"`
+	RemoteAddr string `db:"remote_addr"`
+	Method     string `db:"method"`
+	// Args is the json of the arguments, the secrets in it are redacted
+	Args    string `db:"args"`
+	Success bool   `db:"success"`
+	// Result is the error message of the failed call
+	Result      string    `db:"result"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// AuditLogQuery filters the audit logs, the empty fields match any log
+type AuditLogQuery struct {
+	Caller string
+	Method string
+	Start  time.Time
+	End    time.Time
+	Limit  int
+	Offset int
+}
+
+// ListAuditLogRsp list audit logs
+type ListAuditLogRsp struct {
+	Total int         `json:"total"`
+	Logs  []*AuditLog `json:"logs"`
+}
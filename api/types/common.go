@@ -16,12 +16,115 @@ const (
 	EventNodeOnline EventTopics = "node_online"
 	// EventNodeOffline node offline event
 	EventNodeOffline EventTopics = "node_offline"
+	// EventAssetState asset state transition event
+	EventAssetState EventTopics = "asset_state"
+	// EventReplica replica add or remove event
+	EventReplica EventTopics = "replica"
+	// EventValidationResult validation result event
+	EventValidationResult EventTopics = "validation_result"
 )
 
+// SchedulerEventTopics are the topics streamed to the event subscribers
+var SchedulerEventTopics = []EventTopics{EventNodeOnline, EventNodeOffline, EventAssetState, EventReplica, EventValidationResult}
+
 func (t EventTopics) String() string {
 	return string(t)
 }
 
+// SchedulerEvent is a typed event streamed to the subscribers, the field of the topic is set
+type SchedulerEvent struct {
+	Topic      EventTopics
+	Time       time.Time
+	Asset      *AssetStateEvent      `json:",omitempty"`
+	Replica    *ReplicaChangedEvent  `json:",omitempty"`
+	Node       *NodeStateEvent       `json:",omitempty"`
+	Validation *ValidationResultInfo `json:",omitempty"`
+}
+
+// AssetStateEvent is the state transition of an asset state machine
+type AssetStateEvent struct {
+	Hash      string
+	CID       string
+	PrevState string
+	State     string
+}
+
+// ReplicaChangedEvent is a replica added to or removed from a node
+type ReplicaChangedEvent struct {
+	Hash   string
+	CID    string
+	NodeID string
+	Event  ReplicaEvent
+	Size   int64
+}
+
+// NodeStateEvent is a node coming online or going offline
+type NodeStateEvent struct {
+	NodeID string
+	Type   NodeType
+	Online bool
+}
+
+// EventFilter filters the events streamed to a subscriber, the empty fields match all events
+type EventFilter struct {
+	Topics []EventTopics
+	// NodeID matches the replica, node and validation events of the node
+	NodeID string
+	// Hash matches the asset and replica events of the asset
+	Hash string
+}
+
+// Match checks if the event passes the filter
+func (f *EventFilter) Match(event *SchedulerEvent) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Topics) > 0 {
+		matched := false
+		for _, topic := range f.Topics {
+			if topic == event.Topic {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.Hash) > 0 {
+		switch {
+		case event.Asset != nil:
+			if event.Asset.Hash != f.Hash {
+				return false
+			}
+		case event.Replica != nil:
+			if event.Replica.Hash != f.Hash {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	if len(f.NodeID) > 0 {
+		switch {
+		case event.Replica != nil:
+			return event.Replica.NodeID == f.NodeID
+		case event.Node != nil:
+			return event.Node.NodeID == f.NodeID
+		case event.Validation != nil:
+			return event.Validation.NodeID == f.NodeID
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 // ValidationInfo Validation, election related information
 type ValidationInfo struct {
 	NextElectionTime time.Time
@@ -0,0 +1,39 @@
+package types
+
+import "testing"
+
+func TestEventFilterMatch(t *testing.T) {
+	assetEvent := &SchedulerEvent{Topic: EventAssetState, Asset: &AssetStateEvent{Hash: "h1"}}
+	replicaEvent := &SchedulerEvent{Topic: EventReplica, Replica: &ReplicaChangedEvent{Hash: "h1", NodeID: "n1", Event: ReplicaEventRemove}}
+	nodeEvent := &SchedulerEvent{Topic: EventNodeOnline, Node: &NodeStateEvent{NodeID: "n1", Online: true}}
+	validationEvent := &SchedulerEvent{Topic: EventValidationResult, Validation: &ValidationResultInfo{NodeID: "n2"}}
+
+	cases := []struct {
+		name   string
+		filter *EventFilter
+		event  *SchedulerEvent
+		expect bool
+	}{
+		{name: "nil filter", filter: nil, event: assetEvent, expect: true},
+		{name: "empty filter", filter: &EventFilter{}, event: validationEvent, expect: true},
+		{name: "topic", filter: &EventFilter{Topics: []EventTopics{EventReplica, EventNodeOnline}}, event: nodeEvent, expect: true},
+		{name: "other topic", filter: &EventFilter{Topics: []EventTopics{EventReplica}}, event: assetEvent},
+		{name: "asset hash", filter: &EventFilter{Hash: "h1"}, event: assetEvent, expect: true},
+		{name: "other asset hash", filter: &EventFilter{Hash: "h2"}, event: assetEvent},
+		{name: "replica hash", filter: &EventFilter{Hash: "h1"}, event: replicaEvent, expect: true},
+		{name: "hash of node event", filter: &EventFilter{Hash: "h1"}, event: nodeEvent},
+		{name: "replica node", filter: &EventFilter{NodeID: "n1"}, event: replicaEvent, expect: true},
+		{name: "node", filter: &EventFilter{NodeID: "n1"}, event: nodeEvent, expect: true},
+		{name: "validation node", filter: &EventFilter{NodeID: "n1"}, event: validationEvent},
+		{name: "node of asset event", filter: &EventFilter{NodeID: "n1"}, event: assetEvent},
+		{name: "replica hash and node", filter: &EventFilter{Hash: "h1", NodeID: "n1"}, event: replicaEvent, expect: true},
+		{name: "replica hash and other node", filter: &EventFilter{Hash: "h1", NodeID: "n2"}, event: replicaEvent},
+		{name: "asset hash and node", filter: &EventFilter{Hash: "h1", NodeID: "n1"}, event: assetEvent},
+	}
+
+	for _, c := range cases {
+		if matched := c.filter.Match(c.event); matched != c.expect {
+			t.Errorf("%s: matched %v, expected %v", c.name, matched, c.expect)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// NameRecord points a mutable name to an immutable path, the name is derived from the ed25519 key signing the record
+type NameRecord struct {
+	Name string `db:"name"`
+	// Value is the path the name points to, /ipfs/<cid>[/<path>]
+	Value string `db:"value"`
+	// Sequence increases with every publish of the name, the record with the highest sequence wins
+	Sequence uint64 `db:"sequence"`
+	// Validity is the expiration of the record, only the seconds are signed because the database drops the sub-seconds
+	Validity time.Time `db:"validity"`
+	// TTL is how long the resolvers cache the record
+	TTL       time.Duration `db:"ttl"`
+	PublicKey []byte        `db:"public_key"`
+	Signature []byte        `db:"signature"`
+	// UserID is the user publishing the name, only the user can publish the name again
+	UserID      string    `db:"user_id"`
+	UpdatedTime time.Time `db:"updated_time"`
+}
+
+// SignedMessage returns the message signed by the key of the name
+func (r *NameRecord) SignedMessage() []byte {
+	return []byte(fmt.Sprintf("titan-name\n%s\n%s\n%d\n%d\n%d", r.Name, r.Value, r.Sequence, r.Validity.Unix(), int64(r.TTL)))
+}
@@ -170,6 +170,8 @@ type CandidateDownloadInfo struct {
 	AWSBucket string
 	// download from aws
 	AWSKey string
+	// NatType of the node, the downloader asks the scheduler to punch the nat before downloading if it is behind nat
+	NatType string
 }
 
 // NodeIPInfo
@@ -324,6 +326,33 @@ const (
 	ValidationStatusOther
 )
 
+func (s ValidationStatus) String() string {
+	switch s {
+	case ValidationStatusCreate:
+		return "create"
+	case ValidationStatusSuccess:
+		return "success"
+	case ValidationStatusCancel:
+		return "cancel"
+	case ValidationStatusNodeTimeOut:
+		return "node_timeout"
+	case ValidationStatusValidateFail:
+		return "validate_fail"
+	case ValidationStatusValidatorTimeOut:
+		return "validator_timeout"
+	case ValidationStatusGetValidatorBlockErr:
+		return "get_validator_block_err"
+	case ValidationStatusValidatorMismatch:
+		return "validator_mismatch"
+	case ValidationStatusLoadDBErr:
+		return "load_db_err"
+	case ValidationStatusCIDToHashErr:
+		return "cid_to_hash_err"
+	default:
+		return "other"
+	}
+}
+
 // TokenPayload payload of token
 type TokenPayload struct {
 	ID          string    `db:"token_id"`
@@ -333,6 +362,8 @@ type TokenPayload struct {
 	LimitRate   int64     `db:"limit_rate"`
 	CreatedTime time.Time `db:"created_time"`
 	Expiration  time.Time `db:"expiration"`
+	// TrafficLimit is the max bytes served with the token, 0 means unlimited
+	TrafficLimit int64 `db:"-"`
 }
 
 // Token access download asset
@@ -350,6 +381,8 @@ type Workload struct {
 	StartTime     time.Time
 	EndTime       time.Time
 	BlockCount    int64
+	// ClientIPs are the addresses the token is used from
+	ClientIPs []string
 }
 
 // WorkloadStatus Workload Status
@@ -366,6 +399,62 @@ const (
 	WorkloadStatusInvalid
 )
 
+func (s WorkloadStatus) String() string {
+	switch s {
+	case WorkloadStatusCreate:
+		return "create"
+	case WorkloadStatusSucceeded:
+		return "succeeded"
+	case WorkloadStatusFailed:
+		return "failed"
+	case WorkloadStatusInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// WorkloadInvalidReason is the reason code of an invalid workload
+type WorkloadInvalidReason int
+
+const (
+	// WorkloadReasonNone the workload is not invalid
+	WorkloadReasonNone WorkloadInvalidReason = iota
+	// WorkloadReasonNoReport neither the client nor the node reports the workload
+	WorkloadReasonNoReport
+	// WorkloadReasonSpeed the download speed exceeds the upload bandwidth of the node
+	WorkloadReasonSpeed
+	// WorkloadReasonTimeWindow the time windows of the reports do not overlap or are out of the token lifetime
+	WorkloadReasonTimeWindow
+	// WorkloadReasonTokenReuse the token is used from too many addresses
+	WorkloadReasonTokenReuse
+	// WorkloadReasonClientVolume the client downloads abnormal volume in a day
+	WorkloadReasonClientVolume
+	// WorkloadReasonCollusion the client is paired with the node too many times in a day
+	WorkloadReasonCollusion
+)
+
+func (r WorkloadInvalidReason) String() string {
+	switch r {
+	case WorkloadReasonNone:
+		return "none"
+	case WorkloadReasonNoReport:
+		return "no_report"
+	case WorkloadReasonSpeed:
+		return "speed"
+	case WorkloadReasonTimeWindow:
+		return "time_window"
+	case WorkloadReasonTokenReuse:
+		return "token_reuse"
+	case WorkloadReasonClientVolume:
+		return "client_volume"
+	case WorkloadReasonCollusion:
+		return "collusion"
+	default:
+		return "unknown"
+	}
+}
+
 type WorkloadReport struct {
 	TokenID  string
 	ClientID string
@@ -380,6 +469,8 @@ type WorkloadRecord struct {
 	ClientEndTime  int64          `db:"client_end_time"`
 	ClientWorkload []byte         `db:"client_workload"`
 	NodeWorkload   []byte         `db:"node_workload"`
+	// InvalidReason is set if the status is invalid
+	InvalidReason WorkloadInvalidReason `db:"-"`
 }
 
 type NodeWorkloadReport struct {
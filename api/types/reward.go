@@ -0,0 +1,67 @@
+package types
+
+import "time"
+
+// RewardModel is a versioned reward formula of the nodes, the model with the latest start time
+// which is not in the future is used in the epoch
+type RewardModel struct {
+	Version int
+	// StartTime is the beginning of the epoch of the model, zero if the model is not scheduled
+	StartTime time.Time
+	// BandwidthCurve maps the upload bandwidth (MiB/s) to points, linear between the points and flat after the last point
+	BandwidthCurve []RewardCurvePoint
+	// NATMultipliers are the multipliers of the NAT types, the key is NatType.String(), missing types get 0
+	NATMultipliers map[string]float64
+	// NetworkWeights weight the points by the count of edges in the network, the first tier covering the count is used
+	NetworkWeights []RewardNetworkTier
+	// NetworkWeightDefault is the weight of a network larger than all tiers
+	NetworkWeightDefault float64
+	Storage              RewardStorageTerm
+	// OnlineIncrement is the points of an online node per keepalive, multiplied by the network weight
+	OnlineIncrement float64
+	// ValidationProfit is the flat profit of a validation round, the points of a validated node are computed by the formula
+	ValidationProfit float64
+	// WorkloadProfit is the profit of a succeeded workload
+	WorkloadProfit float64
+}
+
+// RewardCurvePoint is a point of the bandwidth curve
+type RewardCurvePoint struct {
+	MiB    float64
+	Points float64
+}
+
+// RewardNetworkTier is the weight of the network with at most MaxNodes edges
+type RewardNetworkTier struct {
+	MaxNodes int
+	Weight   float64
+}
+
+// RewardStorageTerm is the points of the used storage:
+// s = GiB(TitanDiskUsage * Factor), points = weight * min(s, CapGiB) * (Base + 1 / max(min(s, CapGiB), MinGiB))
+type RewardStorageTerm struct {
+	Factor float64
+	CapGiB float64
+	Base   float64
+	MinGiB float64
+}
+
+// RewardNodeDelta is the reward of a node in the simulation with the base and the candidate models
+type RewardNodeDelta struct {
+	NodeID      string
+	Validations int
+	Workloads   int
+	Base        float64
+	Candidate   float64
+	Delta       float64
+}
+
+// RewardSimulation is the result of replaying the history through a candidate model
+type RewardSimulation struct {
+	BaseVersion      int
+	CandidateVersion int
+	StartTime        time.Time
+	EndTime          time.Time
+	NetworkSize      int
+	Nodes            []*RewardNodeDelta
+}
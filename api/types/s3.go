@@ -0,0 +1,94 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// S3AuthReq is an s3 request signed with aws signature version 4,
+// the access key id is "<user-id>:<api-key-name>" and the secret access key is the api key
+type S3AuthReq struct {
+	AccessKeyID string
+	// Date is the date of credential scope, in the format yyyymmdd
+	Date         string
+	Region       string
+	Service      string
+	StringToSign string
+	Signature    string
+}
+
+// S3Identity is the user and the access control list of the api key which signed the s3 request
+type S3Identity struct {
+	UserID            string
+	AccessControlList []UserAccessControl
+	// Token is the short-lived token of the user, the scheduler requires it in the follow-up s3 calls
+	Token string
+}
+
+// S3Session is the extend of the s3 token, it is bound to the user and the candidate which authenticated the request
+type S3Session struct {
+	UserID     string
+	NodeID     string
+	Expiration time.Time
+}
+
+// Allowed checks if the api key has the permission
+func (id *S3Identity) Allowed(acl UserAccessControl) bool {
+	for _, v := range id.AccessControlList {
+		if v == acl {
+			return true
+		}
+	}
+	return false
+}
+
+// S3Object is a user asset addressed by the bucket and the key of s3 gateway,
+// Size is the size of the object content and CarSize is the size of the car of the asset
+type S3Object struct {
+	Key          string
+	CID          string
+	Size         int64
+	CarSize      int64
+	LastModified time.Time
+}
+
+// ListS3ObjectsRsp is the objects in a bucket sorted by key
+type ListS3ObjectsRsp struct {
+	Objects     []*S3Object
+	IsTruncated bool
+}
+
+// FileAsset is an asset built from the content of a file
+type FileAsset struct {
+	CID     string
+	Size    int64
+	CarSize int64
+	// Existed is true if the asset was stored in the node before it is built
+	Existed bool
+}
+
+// MultipartUpload is an s3 multipart upload, the parts are saved in the candidate until it is completed or aborted
+type MultipartUpload struct {
+	UploadID    string
+	UserID      string
+	Bucket      string
+	Key         string
+	CreatedTime time.Time
+}
+
+// MultipartPart is a part of multipart upload, ETag is the hex encoded md5 of the part
+type MultipartPart struct {
+	PartNumber   int
+	ETag         string
+	Size         int64
+	LastModified time.Time
+}
+
+var (
+	// ErrNoSuchUpload the multipart upload does not exist, or has been completed or aborted
+	ErrNoSuchUpload = errors.New("multipart upload not found")
+	// ErrInvalidPart the part of complete request is not uploaded or its etag mismatches
+	ErrInvalidPart = errors.New("invalid part")
+	// ErrInvalidPartOrder the parts of complete request are not in ascending order
+	ErrInvalidPartOrder = errors.New("invalid part order")
+)
@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type AssetProperty struct {
 	AssetCID  string
@@ -28,6 +31,12 @@ type AuthUserUploadDownloadAsset struct {
 	AssetCID   string
 	AssetSize  int64
 	Expiration time.Time
+	// TrafficLimit is the remaining egress allowance of user for the download, 0 means unlimited
+	TrafficLimit int64
+	// LimitRate is the download bandwidth of user in byte/s, 0 means unlimited
+	LimitRate int64
+	// LinkID is the id of the presigned url, the url is revoked by it
+	LinkID string
 }
 
 type UploadProgress struct {
@@ -41,6 +50,26 @@ type UploadingAsset struct {
 	Progress        *UploadProgress
 }
 
+// UploadSession is the state of a resumable upload, Offset is the number of bytes received and persisted
+type UploadSession struct {
+	AssetCID   string
+	UserID     string
+	Size       int64
+	Offset     int64
+	Expiration time.Time
+}
+
+var (
+	// ErrUploadSessionNotFound the resumable upload session does not exist or has expired
+	ErrUploadSessionNotFound = errors.New("upload session not found")
+	// ErrUploadOffsetMismatch the offset of the upload request differs from the offset of the session
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+	// ErrUploadSessionBusy another request is writing to the upload session
+	ErrUploadSessionBusy = errors.New("upload session is busy")
+	// ErrUploadSessionNotOwned the upload session belongs to another user
+	ErrUploadSessionNotOwned = errors.New("upload session belongs to another user")
+)
+
 type UserInfo struct {
 	TotalSize     int64 `db:"total_storage_size"`
 	UsedSize      int64 `db:"used_storage_size"`
@@ -95,4 +124,78 @@ var FuncAccessControlMap = map[string]UserAccessControl{
 	"ListAssetGroup":   UserAPIKeyReadFolder,
 	"DeleteAssetGroup": UserAPIKeyDeleteFolder,
 	"RenameAssetGroup": UserAPIKeyCreateFolder,
+
+	// sharing the key of the encrypted asset is reading it
+	"SaveAssetKeyEnvelopes": UserAPIKeyReadFile,
+	"GetAssetKeyEnvelope":   UserAPIKeyReadFile,
+
+	// the presigned urls are shares of the asset
+	"PresignAssetURL":    UserAPIKeyReadFile,
+	"ListPresignedURLs":  UserAPIKeyReadFile,
+	"RevokePresignedURL": UserAPIKeyReadFile,
+}
+
+// AssetKeyEnvelope is the data key of a client-side encrypted asset wrapped for the public key of a user
+type AssetKeyEnvelope struct {
+	Hash   string `db:"hash"`
+	UserID string `db:"user_id"`
+	// SharedBy is the user who wrapped the key
+	SharedBy    string    `db:"shared_by"`
+	Envelope    []byte    `db:"envelope"`
+	CreatedTime time.Time `db:"created_time"`
+}
+
+// UserEgress is the egress quota of user and the traffic used in the month
+type UserEgress struct {
+	UserID string
+	// Month is formatted as 2006-01
+	Month string
+	// MonthlyQuota 0 means unlimited, it is a soft limit overrun by the downloads started before it is exhausted
+	MonthlyQuota int64
+	UsedTraffic  int64
+	// LimitRate is the download bandwidth in byte/s, 0 means unlimited
+	LimitRate int64
+}
+
+// UserEgressPlan overrides the egress tier given by the vip state of user
+type UserEgressPlan struct {
+	UserID       string    `db:"user_id"`
+	MonthlyQuota int64     `db:"monthly_quota"`
+	LimitRate    int64     `db:"limit_rate"`
+	UpdatedTime  time.Time `db:"updated_time"`
+}
+
+// PresignOptions are the constraints of the presigned url of an asset
+type PresignOptions struct {
+	Expiration time.Time
+	// MaxDownloads 0 means unlimited
+	MaxDownloads int
+	// AllowedIPs are the client ips or cidrs allowed to download, empty means any client
+	AllowedIPs []string
+	// Password is required to download if it is not empty
+	Password string
+}
+
+// PresignedURL is a revocable url to download an asset
+type PresignedURL struct {
+	ID         string
+	URL        string
+	Expiration time.Time
+}
+
+// PresignedLink is the record of a presigned url
+type PresignedLink struct {
+	ID           string    `db:"id"`
+	UserID       string    `db:"user_id"`
+	Hash         string    `db:"hash"`
+	Expiration   time.Time `db:"expiration"`
+	MaxDownloads int       `db:"max_downloads"`
+	Downloads    int       `db:"downloads"`
+	// AllowedIPs is separated by comma
+	AllowedIPs  string    `db:"allowed_ips"`
+	HasPassword bool      `db:"has_password"`
+	Revoked     bool      `db:"revoked"`
+	CreatedTime time.Time `db:"created_time"`
+	// PasswordHash is the bcrypt hash of the password, it never leaves the scheduler
+	PasswordHash string `db:"password_hash" json:"-"`
 }
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
@@ -74,4 +79,91 @@ type EdgeUpdateConfig struct {
 	DownloadURL string    `db:"download_url"`
 	Hash        string    `db:"hash"`
 	UpdateTime  time.Time `db:"update_time"`
+
+	// Signature is the hex ed25519 signature of SignedMessage by the offline release key
+	Signature string `db:"-"`
+	// RolloutPercent is the percentage of nodes taking the release, 100 rolls out to all nodes
+	RolloutPercent int `db:"-"`
+	// RolloutAreas are the areas taking the release, empty is all areas
+	RolloutAreas []string `db:"-"`
+	// RolloutNodes are the nodes taking the release whatever the percentage
+	RolloutNodes []string `db:"-"`
+	// Patches turn the previous versions into the release, the patched app is checked with Hash
+	Patches []*EdgeUpdatePatch `db:"-"`
+}
+
+// EdgeUpdatePatch is a binary patch from a previous version to the release
+type EdgeUpdatePatch struct {
+	NodeType    int     `db:"node_type"`
+	FromVersion Version `db:"from_version"`
+	DownloadURL string  `db:"download_url"`
+	// Hash is the sha256 of the patch file
+	Hash string `db:"hash"`
+}
+
+// PatchFrom returns the patch from the version, nil if there is none
+func (c *EdgeUpdateConfig) PatchFrom(version Version) *EdgeUpdatePatch {
+	for _, patch := range c.Patches {
+		if patch.FromVersion == version {
+			return patch
+		}
+	}
+	return nil
+}
+
+// SignedMessage returns the message of the release signed by the release key
+func (c *EdgeUpdateConfig) SignedMessage() []byte {
+	return []byte(fmt.Sprintf("titan-release\n%d\n%s\n%s\n%s", c.NodeType, c.AppName, c.Version.String(), c.Hash))
+}
+
+// VerifySignature checks the release is signed by the release key
+func (c *EdgeUpdateConfig) VerifySignature(releaseKey ed25519.PublicKey) error {
+	if len(releaseKey) != ed25519.PublicKeySize {
+		return xerrors.New("release key is not set")
+	}
+
+	sig, err := hex.DecodeString(c.Signature)
+	if err != nil {
+		return xerrors.Errorf("decode signature error %w", err)
+	}
+
+	if !ed25519.Verify(releaseKey, c.SignedMessage(), sig) {
+		return xerrors.Errorf("release %s %s is not signed by the release key", c.AppName, c.Version.String())
+	}
+
+	return nil
+}
+
+// ParseReleaseKey parses the hex ed25519 public key of the offline release key
+func ParseReleaseKey(keyHex string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, xerrors.Errorf("decode release key error %w", err)
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, xerrors.Errorf("invalid release key size %d", len(key))
+	}
+
+	return ed25519.PublicKey(key), nil
+}
+
+// InArea checks if the release is rolled out to the area
+func (c *EdgeUpdateConfig) InArea(areaID string) bool {
+	return len(c.RolloutAreas) == 0 || slices.Contains(c.RolloutAreas, areaID)
+}
+
+// InRollout checks if the node takes the release, the nodes are picked by the hash of the node and the release,
+// so the nodes taking a smaller percentage still take the release when the percentage grows
+func (c *EdgeUpdateConfig) InRollout(nodeID string) bool {
+	if slices.Contains(c.RolloutNodes, nodeID) || c.RolloutPercent >= 100 {
+		return true
+	}
+
+	if c.RolloutPercent <= 0 || nodeID == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s@%s:%s", c.AppName, c.Version.String(), nodeID)))
+	return binary.BigEndian.Uint64(sum[:8])%100 < uint64(c.RolloutPercent)
 }
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEdgeUpdateConfigInRollout(t *testing.T) {
+	c := &EdgeUpdateConfig{AppName: "titan-edge", Version: Version(0x000201), RolloutNodes: []string{"e_pinned"}}
+
+	if !c.InRollout("e_pinned") {
+		t.Fatal("pinned node is not in the rollout")
+	}
+	if c.InRollout("e_other") {
+		t.Fatal("node is in the rollout of 0 percent")
+	}
+
+	c.RolloutPercent = 100
+	if !c.InRollout("") {
+		t.Fatal("node is not in the rollout of 100 percent")
+	}
+
+	// the nodes taking a smaller percentage still take the release when the percentage grows
+	c.RolloutPercent = 20
+	taken := make([]string, 0)
+	for i := 0; i < 1000; i++ {
+		if nodeID := fmt.Sprintf("e_%d", i); c.InRollout(nodeID) {
+			taken = append(taken, nodeID)
+		}
+	}
+	if len(taken) < 100 || len(taken) > 300 {
+		t.Fatalf("%d of 1000 nodes in the rollout of 20 percent", len(taken))
+	}
+
+	c.RolloutPercent = 50
+	for _, nodeID := range taken {
+		if !c.InRollout(nodeID) {
+			t.Fatalf("node %s left the rollout when the percentage grows", nodeID)
+		}
+	}
+}
+
+func TestEdgeUpdateConfigSignedMessage(t *testing.T) {
+	c := &EdgeUpdateConfig{NodeType: 1, AppName: "titan-edge", Version: Version(0x000201), Hash: "abc", DownloadURL: "https://a"}
+	expect := "titan-release\n1\ntitan-edge\n0.2.1\nabc"
+	if string(c.SignedMessage()) != expect {
+		t.Fatalf("signed message %q, expected %q", c.SignedMessage(), expect)
+	}
+
+	// the download url is not signed, the app is checked with the signed hash
+	c.DownloadURL = "https://b"
+	if string(c.SignedMessage()) != expect {
+		t.Fatal("signed message changes with the download url")
+	}
+}
@@ -5,6 +5,9 @@ import "os"
 var (
 	CurrentCommit string
 	BuildType     int
+	// ReleaseKey is the hex ed25519 public key of the offline release key, the edge releases are verified by it.
+	// It is set at build time: -ldflags "-X github.com/Filecoin-Titan/titan/build.ReleaseKey=<hex key>"
+	ReleaseKey string
 )
 
 const (
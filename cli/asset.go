@@ -39,6 +39,7 @@ var assetCmds = &cli.Command{
 		switchFillDiskTimerCmd,
 		listAWSDataCmd,
 		assetViewCmd,
+		lifecycleCmds,
 	},
 }
 
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var auditCmd = &cli.Command{
+	Name:  "audit",
+	Usage: "list the audit logs of the privileged calls",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "caller",
+			Usage: "the id of the caller",
+		},
+		&cli.StringFlag{
+			Name:  "method",
+			Usage: "the method of the api, example: SetUserVIP",
+		},
+		&cli.StringFlag{
+			Name:  "start",
+			Usage: "the start time, example: 2024-01-02 15:04:05",
+		},
+		&cli.StringFlag{
+			Name:  "end",
+			Usage: "the end time, example: 2024-01-02 15:04:05",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "count of list",
+			Value: 50,
+		},
+		&cli.IntFlag{
+			Name:  "offset",
+			Usage: "offset of list",
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		query := &types.AuditLogQuery{
+			Caller: cctx.String("caller"),
+			Method: cctx.String("method"),
+			Limit:  cctx.Int("limit"),
+			Offset: cctx.Int("offset"),
+		}
+
+		if start := cctx.String("start"); start != "" {
+			if query.Start, err = time.ParseInLocation(defaultDateTimeLayout, start, time.Local); err != nil {
+				return err
+			}
+		}
+
+		if end := cctx.String("end"); end != "" {
+			if query.End, err = time.ParseInLocation(defaultDateTimeLayout, end, time.Local); err != nil {
+				return err
+			}
+		}
+
+		rsp, err := schedulerAPI.GetAuditLog(ReqContext(cctx), query)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("Caller"),
+			tablewriter.Col("Role"),
+			tablewriter.Col("RemoteAddr"),
+			tablewriter.Col("Method"),
+			tablewriter.Col("Success"),
+			tablewriter.Col("Args"),
+			tablewriter.NewLineCol("Result"),
+		)
+
+		for _, l := range rsp.Logs {
+			m := map[string]interface{}{
+				"Time":       l.CreatedTime.Local().Format(defaultDateTimeLayout),
+				"Caller":     l.Caller,
+				"Role":       l.Role,
+				"RemoteAddr": l.RemoteAddr,
+				"Method":     l.Method,
+				"Success":    l.Success,
+				"Args":       l.Args,
+				"Result":     l.Result,
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
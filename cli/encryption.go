@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/carutil"
+	"github.com/Filecoin-Titan/titan/lib/encryption"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var keygenAssetCmd = &cli.Command{
+	Name:  "keygen",
+	Usage: "generate the key pair of user for the encrypted assets",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "key",
+			Usage:    "the file to save the hex private key",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if _, err := os.Stat(cctx.String("key")); err == nil {
+			return xerrors.Errorf("key file %s already exists", cctx.String("key"))
+		}
+
+		privateKey, publicKey, err := encryption.GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(cctx.String("key"), []byte(hex.EncodeToString(privateKey)), 0o600); err != nil {
+			return err
+		}
+
+		return schedulerAPI.SetUserPublicKey(ReqContext(cctx), cctx.String("user"), publicKey)
+	},
+}
+
+var encryptAssetCmd = &cli.Command{
+	Name:      "encrypt",
+	Usage:     "encrypt the file and build the car to upload, the data key is wrapped for the public key of user",
+	ArgsUsage: "[file] [car]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 2 {
+			return xerrors.New("need the file and the car path")
+		}
+
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		userID := cctx.String("user")
+		publicKey, err := schedulerAPI.GetUserPublicKey(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		dataKey, err := encryption.NewDataKey()
+		if err != nil {
+			return err
+		}
+
+		envelope, err := encryption.WrapKey(publicKey, dataKey)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(cctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		defer src.Close() //nolint:errcheck // ignore error
+
+		r, err := encryption.NewEncryptReader(dataKey, src)
+		if err != nil {
+			return err
+		}
+
+		// the car refers to the encrypted file while it is built
+		carPath := cctx.Args().Get(1)
+		encrypted, err := os.CreateTemp(filepath.Dir(carPath), "encrypted-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(encrypted.Name()) //nolint:errcheck // ignore error
+
+		if _, err := encrypted.ReadFrom(r); err != nil {
+			encrypted.Close() //nolint:errcheck // ignore error
+			return err
+		}
+
+		if err := encrypted.Close(); err != nil {
+			return err
+		}
+
+		car, err := os.Create(carPath)
+		if err != nil {
+			return err
+		}
+		defer car.Close() //nolint:errcheck // ignore error
+
+		root, err := carutil.GenerateFileCar(ctx, encrypted.Name(), car)
+		if err != nil {
+			return err
+		}
+
+		err = schedulerAPI.SaveAssetKeyEnvelopes(ctx, userID, root.String(), []*types.AssetKeyEnvelope{{UserID: userID, Envelope: envelope}})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(root.String())
+		return nil
+	},
+}
+
+var downloadAssetCmd = &cli.Command{
+	Name:      "download",
+	Usage:     "download the encrypted asset from the share link and decrypt it",
+	ArgsUsage: "[link] [output]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "user",
+			Usage: "Specify the user id, the key of user is used if the link has no key",
+		},
+		&cli.StringFlag{
+			Name:  "key",
+			Usage: "the file of the hex private key of user",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 2 {
+			return xerrors.New("need the link and the output path")
+		}
+
+		link := cctx.Args().Get(0)
+
+		var dataKey []byte
+		if !strings.Contains(link, "#") {
+			schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+			if err != nil {
+				return err
+			}
+			defer closer()
+
+			u, err := url.Parse(link)
+			if err != nil {
+				return err
+			}
+
+			assetCID, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/ipfs/"), "/")
+			dataKey, err = unwrapAssetKey(cctx, schedulerAPI, assetCID)
+			if err != nil {
+				return err
+			}
+		}
+
+		out, err := os.Create(cctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+		defer out.Close() //nolint:errcheck // ignore error
+
+		size, err := encryption.Download(ReqContext(cctx), http.DefaultClient, link, dataKey, out)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("download %d bytes to %s\n", size, out.Name())
+		return nil
+	},
+}
+
+// unwrapAssetKey returns the data key of the asset wrapped for the user
+func unwrapAssetKey(cctx *cli.Context, schedulerAPI api.Scheduler, assetCID string) ([]byte, error) {
+	if !cctx.IsSet("user") || !cctx.IsSet("key") {
+		return nil, xerrors.New("user and key are required to unwrap the key of the asset")
+	}
+
+	data, err := os.ReadFile(cctx.String("key"))
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid key file %s", cctx.String("key"))
+	}
+
+	envelope, err := schedulerAPI.GetAssetKeyEnvelope(ReqContext(cctx), cctx.String("user"), assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryption.UnwrapKey(privateKey, envelope.Envelope)
+}
+
+// shareAssetKey wraps the key of the encrypted asset for the recipients, returns the data key
+func shareAssetKey(cctx *cli.Context, schedulerAPI api.Scheduler, userID, assetCID string) ([]byte, error) {
+	dataKey, err := unwrapAssetKey(cctx, schedulerAPI, assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	envelopes := make([]*types.AssetKeyEnvelope, 0)
+	for _, recipient := range cctx.StringSlice("recipient") {
+		publicKey, err := schedulerAPI.GetUserPublicKey(ReqContext(cctx), recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		envelope, err := encryption.WrapKey(publicKey, dataKey)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, &types.AssetKeyEnvelope{UserID: recipient, Envelope: envelope})
+	}
+
+	if len(envelopes) > 0 {
+		if err := schedulerAPI.SaveAssetKeyEnvelopes(ReqContext(cctx), userID, assetCID, envelopes); err != nil {
+			return nil, err
+		}
+	}
+
+	return dataKey, nil
+}
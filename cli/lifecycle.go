@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var lifecycleCmds = &cli.Command{
+	Name:  "lifecycle",
+	Usage: "Manage asset lifecycle policies",
+	Subcommands: []*cli.Command{
+		createLifecyclePolicyCmd,
+		listLifecyclePoliciesCmd,
+		deleteLifecyclePolicyCmd,
+		setAssetLifecyclePolicyCmd,
+		setGroupLifecyclePolicyCmd,
+	},
+}
+
+var policyIDFlag = &cli.Int64Flag{
+	Name:  "policy-id",
+	Usage: "specify the id of a lifecycle policy, 0 means none",
+	Value: 0,
+}
+
+var createLifecyclePolicyCmd = &cli.Command{
+	Name:  "create",
+	Usage: "create a lifecycle policy",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "the name of policy",
+		},
+		&cli.BoolFlag{
+			Name:  "delete-expired",
+			Usage: "delete the assets at expiration",
+		},
+		&cli.StringSliceFlag{
+			Name: "rule",
+			Usage: "the rule in the format <after-days>:<max-retrievals>:<edge-replicas|delete>, " +
+				"e.g. 30:10:2 keeps 2 edge replicas for assets older than 30 days and retrieved less than 10 times in 30 days",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		policy := &types.LifecyclePolicy{
+			Name:          cctx.String("name"),
+			DeleteExpired: cctx.Bool("delete-expired"),
+		}
+
+		for _, str := range cctx.StringSlice("rule") {
+			rule, err := parseLifecycleRule(str)
+			if err != nil {
+				return err
+			}
+			policy.Rules = append(policy.Rules, rule)
+		}
+
+		id, err := schedulerAPI.CreateLifecyclePolicy(ctx, policy)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("policy id:", id)
+		return nil
+	},
+}
+
+func parseLifecycleRule(str string) (*types.LifecycleRule, error) {
+	fields := strings.Split(str, ":")
+	if len(fields) != 3 {
+		return nil, xerrors.Errorf("invalid rule %s", str)
+	}
+
+	afterDays, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid after days %s", fields[0])
+	}
+
+	maxRetrievals, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid max retrievals %s", fields[1])
+	}
+
+	rule := &types.LifecycleRule{AfterDays: afterDays, MaxRetrievals: maxRetrievals}
+	if fields[2] == "delete" {
+		rule.Delete = true
+		return rule, nil
+	}
+
+	rule.EdgeReplicas, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid edge replicas %s", fields[2])
+	}
+
+	return rule, nil
+}
+
+var listLifecyclePoliciesCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list lifecycle policies",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		policies, err := schedulerAPI.ListLifecyclePolicies(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Name"),
+			tablewriter.Col("DeleteExpired"),
+			tablewriter.Col("Rules"),
+		)
+
+		for _, policy := range policies {
+			rules := make([]string, 0, len(policy.Rules))
+			for _, rule := range policy.Rules {
+				action := strconv.FormatInt(rule.EdgeReplicas, 10)
+				if rule.Delete {
+					action = "delete"
+				}
+				rules = append(rules, fmt.Sprintf("%d:%d:%s", rule.AfterDays, rule.MaxRetrievals, action))
+			}
+
+			m := map[string]interface{}{
+				"ID":            policy.ID,
+				"Name":          policy.Name,
+				"DeleteExpired": policy.DeleteExpired,
+				"Rules":         strings.Join(rules, " "),
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var deleteLifecyclePolicyCmd = &cli.Command{
+	Name:  "delete",
+	Usage: "delete a lifecycle policy",
+	Flags: []cli.Flag{
+		policyIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.DeleteLifecyclePolicy(ctx, cctx.Int64("policy-id"))
+	},
+}
+
+var setAssetLifecyclePolicyCmd = &cli.Command{
+	Name:  "set-asset",
+	Usage: "attach a lifecycle policy to an asset",
+	Flags: []cli.Flag{
+		cidFlag,
+		policyIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetAssetLifecyclePolicy(ctx, cctx.String("cid"), cctx.Int64("policy-id"))
+	},
+}
+
+var setGroupLifecyclePolicyCmd = &cli.Command{
+	Name:  "set-group",
+	Usage: "attach a lifecycle policy to a user asset group",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "user-id",
+			Usage: "the id of user",
+		},
+		&cli.IntFlag{
+			Name:  "group-id",
+			Usage: "the id of asset group",
+		},
+		policyIDFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.SetGroupLifecyclePolicy(ctx, cctx.String("user-id"), cctx.Int("group-id"), cctx.Int64("policy-id"))
+	},
+}
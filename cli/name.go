@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var nameCmds = &cli.Command{
+	Name:  "name",
+	Usage: "Manage the mutable names of assets",
+	Subcommands: []*cli.Command{
+		keygenNameCmd,
+		publishNameCmd,
+		resolveNameCmd,
+		listNamesCmd,
+	},
+}
+
+var keygenNameCmd = &cli.Command{
+	Name:  "keygen",
+	Usage: "generate the key of a name and print the name",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "key",
+			Usage:    "the file to save the hex private key",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		pub, key, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Stat(cctx.String("key")); err == nil {
+			return xerrors.Errorf("key file %s already exists", cctx.String("key"))
+		}
+
+		if err := os.WriteFile(cctx.String("key"), []byte(hex.EncodeToString(key)), 0o600); err != nil {
+			return err
+		}
+
+		name, err := ipns.Name(pub)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(name)
+		return nil
+	},
+}
+
+var publishNameCmd = &cli.Command{
+	Name:      "publish",
+	Usage:     "point the name of the key to a path",
+	ArgsUsage: "[/ipfs/<cid>[/<path>]]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "key",
+			Usage:    "the file of the hex private key",
+			Required: true,
+		},
+		&cli.DurationFlag{
+			Name:  "ttl",
+			Usage: "how long the resolvers cache the record",
+			Value: 5 * time.Minute,
+		},
+		&cli.DurationFlag{
+			Name:  "lifetime",
+			Usage: "how long the record is valid",
+			Value: 30 * 24 * time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		value := cctx.Args().First()
+		if !strings.HasPrefix(value, ipns.IPFSPrefix) {
+			value = ipns.IPFSPrefix + value
+		}
+
+		data, err := os.ReadFile(cctx.String("key"))
+		if err != nil {
+			return err
+		}
+
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != ed25519.PrivateKeySize {
+			return xerrors.Errorf("invalid key file %s", cctx.String("key"))
+		}
+
+		name, err := ipns.Name(ed25519.PrivateKey(key).Public().(ed25519.PublicKey))
+		if err != nil {
+			return err
+		}
+
+		// the sequence follows the published record
+		sequence := uint64(1)
+		if old, err := schedulerAPI.ResolveName(ctx, name); err == nil {
+			sequence = old.Sequence + 1
+		}
+
+		record := &types.NameRecord{
+			Value:    value,
+			Sequence: sequence,
+			Validity: time.Now().Add(cctx.Duration("lifetime")).Truncate(time.Second),
+			TTL:      cctx.Duration("ttl"),
+		}
+		if err := ipns.Sign(key, record); err != nil {
+			return err
+		}
+
+		if err := schedulerAPI.PublishNameRecord(ctx, record); err != nil {
+			return err
+		}
+
+		fmt.Printf("published %s to %s, sequence %d\n", record.Name, record.Value, record.Sequence)
+		return nil
+	},
+}
+
+var resolveNameCmd = &cli.Command{
+	Name:      "resolve",
+	Usage:     "print the record of the name",
+	ArgsUsage: "[name]",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		record, err := schedulerAPI.ResolveName(ctx, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		buf, err := json.MarshalIndent(record, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(buf))
+		return nil
+	},
+}
+
+var listNamesCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the names published by the user",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "the user id",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		records, err := schedulerAPI.ListNameRecords(ctx, cctx.String("user"))
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			fmt.Printf("%s\t%s\t%d\t%s\n", record.Name, record.Value, record.Sequence, record.Validity.Format(defaultDateTimeLayout))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var presignAssetCmd = &cli.Command{
+	Name:  "presign",
+	Usage: "create a revocable url to download the asset of user",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Usage:    "special a id for asset",
+			Required: true,
+		},
+		&cli.DurationFlag{
+			Name:  "expire",
+			Usage: "the lifetime of the url",
+			Value: 24 * time.Hour,
+		},
+		&cli.IntFlag{
+			Name:  "max-downloads",
+			Usage: "the max number of downloads, 0 means unlimited",
+		},
+		&cli.StringSliceFlag{
+			Name:  "allow-ip",
+			Usage: "the client ip or cidr allowed to download, any client is allowed if it is not set",
+		},
+		&cli.StringFlag{
+			Name:  "password",
+			Usage: "the password required to download",
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		opts := &types.PresignOptions{
+			Expiration:   time.Now().Add(cctx.Duration("expire")),
+			MaxDownloads: cctx.Int("max-downloads"),
+			AllowedIPs:   cctx.StringSlice("allow-ip"),
+			Password:     cctx.String("password"),
+		}
+
+		presigned, err := schedulerAPI.PresignAssetURL(ReqContext(cctx), cctx.String("user"), cctx.String("cid"), opts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("id: %s, expiration: %s\n", presigned.ID, presigned.Expiration.Format(defaultDateTimeLayout))
+		fmt.Println(presigned.URL)
+		return nil
+	},
+}
+
+var listPresignedCmd = &cli.Command{
+	Name:  "presigned",
+	Usage: "list the presigned urls of the asset of user",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Usage:    "special a id for asset",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		links, err := schedulerAPI.ListPresignedURLs(ReqContext(cctx), cctx.String("user"), cctx.String("cid"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Expiration"),
+			tablewriter.Col("Downloads"),
+			tablewriter.Col("AllowedIPs"),
+			tablewriter.Col("Password"),
+			tablewriter.Col("Revoked"),
+		)
+
+		for _, link := range links {
+			m := map[string]interface{}{
+				"ID":         link.ID,
+				"Expiration": link.Expiration.Format(defaultDateTimeLayout),
+				"Downloads":  fmt.Sprintf("%d/%d", link.Downloads, link.MaxDownloads),
+				"AllowedIPs": link.AllowedIPs,
+				"Password":   link.HasPassword,
+				"Revoked":    link.Revoked,
+			}
+			tw.Write(m)
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var revokePresignedCmd = &cli.Command{
+	Name:      "revoke",
+	Usage:     "revoke the presigned url of user",
+	ArgsUsage: "[id]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return fmt.Errorf("need the id of the presigned url")
+		}
+
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RevokePresignedURL(ReqContext(cctx), cctx.String("user"), cctx.Args().First())
+	},
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var rewardCmds = &cli.Command{
+	Name:  "reward",
+	Usage: "Manage reward models",
+	Subcommands: []*cli.Command{
+		listRewardModelsCmd,
+		setRewardModelCmd,
+		simulateRewardModelCmd,
+	},
+}
+
+var listRewardModelsCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the reward models, the first one is the model of the current epoch",
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		models, err := schedulerAPI.GetRewardModels(ctx)
+		if err != nil {
+			return err
+		}
+
+		buf, err := json.MarshalIndent(models, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(buf))
+		return nil
+	},
+}
+
+var setRewardModelCmd = &cli.Command{
+	Name:  "set",
+	Usage: "save a reward model from a json file",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "model",
+			Usage:    "the json file of the model",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "start-time",
+			Usage: "the beginning of the epoch of the model, overrides the start time in the file, example: 2024-01-02 15:04:05",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		model, err := loadRewardModel(cctx.String("model"))
+		if err != nil {
+			return err
+		}
+
+		if startTime := cctx.String("start-time"); startTime != "" {
+			model.StartTime, err = time.ParseInLocation(defaultDateTimeLayout, startTime, time.Local)
+			if err != nil {
+				return xerrors.Errorf("parse start time err: %s", err.Error())
+			}
+		}
+
+		return schedulerAPI.SaveRewardModel(ctx, model)
+	},
+}
+
+var simulateRewardModelCmd = &cli.Command{
+	Name:  "simulate",
+	Usage: "replay the history through a candidate model and show the reward deltas of the nodes",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "model",
+			Usage:    "the json file of the candidate model",
+			Required: true,
+		},
+		&cli.IntFlag{
+			Name:  "base",
+			Usage: "the version of the base model, 0 is the model of the current epoch",
+		},
+		&cli.IntFlag{
+			Name:  "days",
+			Usage: "the days of the history to replay",
+			Value: 7,
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "the count of the nodes to show, ordered by the delta, 0 shows all",
+			Value: 50,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := ReqContext(cctx)
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		candidate, err := loadRewardModel(cctx.String("model"))
+		if err != nil {
+			return err
+		}
+
+		end := time.Now()
+		start := end.AddDate(0, 0, -cctx.Int("days"))
+
+		result, err := schedulerAPI.SimulateRewardModel(ctx, candidate, cctx.Int("base"), start, end)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Base:\t%d\n", result.BaseVersion)
+		fmt.Printf("Candidate:\t%d\n", result.CandidateVersion)
+		fmt.Printf("Range:\t%s - %s\n", result.StartTime.Format(defaultDateTimeLayout), result.EndTime.Format(defaultDateTimeLayout))
+		fmt.Printf("NetworkSize:\t%d\n", result.NetworkSize)
+
+		var baseTotal, candidateTotal float64
+		for _, node := range result.Nodes {
+			baseTotal += node.Base
+			candidateTotal += node.Candidate
+		}
+		fmt.Printf("Total:\t%.4f -> %.4f (%+.4f)\n\n", baseTotal, candidateTotal, candidateTotal-baseTotal)
+
+		tw := tablewriter.New(
+			tablewriter.Col("NodeID"),
+			tablewriter.Col("Validations"),
+			tablewriter.Col("Workloads"),
+			tablewriter.Col("Base"),
+			tablewriter.Col("Candidate"),
+			tablewriter.Col("Delta"),
+		)
+
+		limit := cctx.Int("limit")
+		for i, node := range result.Nodes {
+			if limit > 0 && i >= limit {
+				break
+			}
+
+			tw.Write(map[string]interface{}{
+				"NodeID":      node.NodeID,
+				"Validations": node.Validations,
+				"Workloads":   node.Workloads,
+				"Base":        fmt.Sprintf("%.4f", node.Base),
+				"Candidate":   fmt.Sprintf("%.4f", node.Candidate),
+				"Delta":       fmt.Sprintf("%+.4f", node.Delta),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
+
+func loadRewardModel(path string) (*types.RewardModel, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &types.RewardModel{}
+	if err := json.Unmarshal(buf, model); err != nil {
+		return nil, xerrors.Errorf("parse model %s err: %s", path, err.Error())
+	}
+
+	return model, nil
+}
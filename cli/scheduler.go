@@ -1,11 +1,18 @@
 package cli
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/lib/bsdiff"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/urfave/cli/v2"
 )
@@ -16,6 +23,9 @@ var SchedulerCMDs = []*cli.Command{
 	WithCategory("asset", assetCmds),
 	WithCategory("config", sConfigCmds),
 	WithCategory("user", userCmds),
+	WithCategory("reward", rewardCmds),
+	WithCategory("name", nameCmds),
+	WithCategory("audit", auditCmd),
 	startElectionCmd,
 	// other
 	edgeUpdaterCmd,
@@ -173,6 +183,9 @@ var edgeUpdaterCmd = &cli.Command{
 		edgeUpdateInfoCmd,
 		setEdgeUpdateInfoCmd,
 		deleteEdgeUpdateInfoCmd,
+		releaseKeygenCmd,
+		signReleaseCmd,
+		diffReleaseCmd,
 	},
 }
 
@@ -230,6 +243,13 @@ var edgeUpdateInfoCmd = &cli.Command{
 			fmt.Printf("Hash:%s\n", updateInfo.Hash)
 			fmt.Printf("Version:%s\n", updateInfo.Version)
 			fmt.Printf("DownloadURL:%s\n", updateInfo.DownloadURL)
+			fmt.Printf("Signature:%s\n", updateInfo.Signature)
+			fmt.Printf("RolloutPercent:%d\n", updateInfo.RolloutPercent)
+			fmt.Printf("RolloutAreas:%s\n", strings.Join(updateInfo.RolloutAreas, ","))
+			fmt.Printf("RolloutNodes:%s\n", strings.Join(updateInfo.RolloutNodes, ","))
+			for _, patch := range updateInfo.Patches {
+				fmt.Printf("Patch:%s %s %s\n", patch.FromVersion.String(), patch.Hash, patch.DownloadURL)
+			}
 			fmt.Println()
 		}
 
@@ -266,6 +286,28 @@ var setEdgeUpdateInfoCmd = &cli.Command{
 			Usage: "node type: 1 is edge, 6 is update",
 			Value: 1,
 		},
+		&cli.StringFlag{
+			Name:  "signature",
+			Usage: "release signature, see 'edge-updater sign'",
+			Value: "",
+		},
+		&cli.IntFlag{
+			Name:  "rollout-percent",
+			Usage: "percentage of nodes taking the release",
+			Value: 100,
+		},
+		&cli.StringSliceFlag{
+			Name:  "rollout-areas",
+			Usage: "areas taking the release, all areas if not set",
+		},
+		&cli.StringSliceFlag{
+			Name:  "rollout-nodes",
+			Usage: "nodes taking the release whatever the rollout percent",
+		},
+		&cli.StringSliceFlag{
+			Name:  "patch",
+			Usage: "patch from a previous version, format: <from-version>:<patch hash>:<download url>, see 'edge-updater diff'",
+		},
 	},
 
 	Action: func(cctx *cli.Context) error {
@@ -287,7 +329,25 @@ var setEdgeUpdateInfoCmd = &cli.Command{
 			return err
 		}
 
-		updateInfo := &api.EdgeUpdateConfig{AppName: appName, NodeType: nodeType, Version: version, Hash: hash, DownloadURL: downloadURL}
+		updateInfo := &api.EdgeUpdateConfig{
+			AppName:        appName,
+			NodeType:       nodeType,
+			Version:        version,
+			Hash:           hash,
+			DownloadURL:    downloadURL,
+			Signature:      cctx.String("signature"),
+			RolloutPercent: cctx.Int("rollout-percent"),
+			RolloutAreas:   cctx.StringSlice("rollout-areas"),
+			RolloutNodes:   cctx.StringSlice("rollout-nodes"),
+		}
+
+		for _, p := range cctx.StringSlice("patch") {
+			patch, err := parsePatch(p)
+			if err != nil {
+				return err
+			}
+			updateInfo.Patches = append(updateInfo.Patches, patch)
+		}
 		err = schedulerAPI.SetEdgeUpdateConfig(ctx, updateInfo)
 		if err != nil {
 			return err
@@ -296,6 +356,171 @@ var setEdgeUpdateInfoCmd = &cli.Command{
 	},
 }
 
+var releaseKeygenCmd = &cli.Command{
+	Name:  "keygen",
+	Usage: "generate the offline release key, <output>.key signs the releases and <output>.pub is given to edge-updater",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "path prefix of the key files",
+			Value: "release",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+
+		output := cctx.String("output")
+		if err = os.WriteFile(output+".key", []byte(hex.EncodeToString(priv)), 0o600); err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(output+".pub", []byte(hex.EncodeToString(pub)), 0o644); err != nil {
+			return err
+		}
+
+		fmt.Printf("release public key: %s\n", hex.EncodeToString(pub))
+		return nil
+	},
+}
+
+var signReleaseCmd = &cli.Command{
+	Name:  "sign",
+	Usage: "sign the release with the offline release key",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "key",
+			Usage:    "release key file generated by 'edge-updater keygen'",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "file",
+			Usage:    "release binary",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "version",
+			Usage:    "release version",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "app-name",
+			Usage:    "app name",
+			Required: true,
+		},
+		&cli.IntFlag{
+			Name:  "node-type",
+			Usage: "node type: 1 is edge, 6 is update",
+			Value: 1,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		keyHex, err := os.ReadFile(cctx.String("key"))
+		if err != nil {
+			return err
+		}
+
+		key, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+		if err != nil {
+			return err
+		}
+
+		if len(key) != ed25519.PrivateKeySize {
+			return fmt.Errorf("invalid release key size %d", len(key))
+		}
+
+		data, err := os.ReadFile(cctx.String("file"))
+		if err != nil {
+			return err
+		}
+
+		version, err := newVersion(cctx.String("version"))
+		if err != nil {
+			return err
+		}
+
+		hash := sha256.Sum256(data)
+		release := &api.EdgeUpdateConfig{
+			AppName:  cctx.String("app-name"),
+			NodeType: cctx.Int("node-type"),
+			Version:  version,
+			Hash:     hex.EncodeToString(hash[:]),
+		}
+
+		fmt.Printf("hash: %s\n", release.Hash)
+		fmt.Printf("signature: %s\n", hex.EncodeToString(ed25519.Sign(key, release.SignedMessage())))
+		return nil
+	},
+}
+
+var diffReleaseCmd = &cli.Command{
+	Name:  "diff",
+	Usage: "create the binary patch from a previous version to the release",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "old",
+			Usage:    "app of the previous version",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "new",
+			Usage:    "app of the release",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "patch file",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		oldApp, err := os.ReadFile(cctx.String("old"))
+		if err != nil {
+			return err
+		}
+
+		newApp, err := os.ReadFile(cctx.String("new"))
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(cctx.String("output"))
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // closed after sync
+
+		h := sha256.New()
+		if err = bsdiff.Diff(oldApp, newApp, io.MultiWriter(f, h)); err != nil {
+			return err
+		}
+
+		if err = f.Sync(); err != nil {
+			return err
+		}
+
+		fmt.Printf("patch hash: %s\n", hex.EncodeToString(h.Sum(nil)))
+		return nil
+	},
+}
+
+// parsePatch parses the patch flag <from-version>:<patch hash>:<download url>
+func parsePatch(s string) (*api.EdgeUpdatePatch, error) {
+	fields := strings.SplitN(s, ":", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid patch %s", s)
+	}
+
+	fromVersion, err := newVersion(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.EdgeUpdatePatch{FromVersion: fromVersion, Hash: fields[1], DownloadURL: fields[2]}, nil
+}
+
 func newVersion(version string) (api.Version, error) {
 	stringSplit := strings.Split(version, ".")
 	if len(stringSplit) != 3 {
@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/encryption"
 	"github.com/Filecoin-Titan/titan/lib/tablewriter"
 	"github.com/docker/go-units"
 	"github.com/urfave/cli/v2"
@@ -19,6 +20,17 @@ var userCmds = &cli.Command{
 		userStorageCmds,
 		userAssetCmds,
 		changeVIP,
+		userEgressCmds,
+	},
+}
+
+var userEgressCmds = &cli.Command{
+	Name:  "egress",
+	Usage: "Manage user egress quota",
+	Subcommands: []*cli.Command{
+		getEgress,
+		setEgressPlan,
+		removeEgressPlan,
 	},
 }
 
@@ -48,6 +60,12 @@ var userAssetCmds = &cli.Command{
 		listAssets,
 		removeAsset,
 		shareLink,
+		keygenAssetCmd,
+		encryptAssetCmd,
+		downloadAssetCmd,
+		presignAssetCmd,
+		listPresignedCmd,
+		revokePresignedCmd,
 	},
 }
 
@@ -350,6 +368,18 @@ var shareLink = &cli.Command{
 			Usage:    "special a id for asset",
 			Required: true,
 		},
+		&cli.StringSliceFlag{
+			Name:  "recipient",
+			Usage: "wrap the key of the encrypted asset for the users",
+		},
+		&cli.BoolFlag{
+			Name:  "embed-key",
+			Usage: "embed the key of the encrypted asset in the fragment of the link",
+		},
+		&cli.StringFlag{
+			Name:  "key",
+			Usage: "the file of the hex private key of user, required to share the key of the encrypted asset",
+		},
 	},
 
 	Action: func(cctx *cli.Context) error {
@@ -362,12 +392,26 @@ var shareLink = &cli.Command{
 		userID := cctx.String("user")
 		assetCID := cctx.String("cid")
 
+		var dataKey []byte
+		if cctx.IsSet("recipient") || cctx.Bool("embed-key") {
+			dataKey, err = shareAssetKey(cctx, schedulerAPI, userID, assetCID)
+			if err != nil {
+				return err
+			}
+		}
+
 		ctx := ReqContext(cctx)
 		links, err := schedulerAPI.ShareAssets(ctx, userID, []string{assetCID})
 		if err != nil {
 			return err
 		}
 
+		if cctx.Bool("embed-key") {
+			for k, v := range links {
+				links[k] = encryption.LinkWithKey(v, dataKey)
+			}
+		}
+
 		if len(links) == 0 {
 			fmt.Printf("User %s not exist asset %s\n", userID, assetCID)
 			return nil
@@ -409,3 +453,104 @@ var changeVIP = &cli.Command{
 		return schedulerAPI.SetUserVIP(ctx, userID, isVIP)
 	},
 }
+
+var getEgress = &cli.Command{
+	Name:  "get",
+	Usage: "get egress quota and traffic of this month for user",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		egress, err := schedulerAPI.GetUserEgress(ReqContext(cctx), cctx.String("user"))
+		if err != nil {
+			return err
+		}
+
+		quota, limitRate := "unlimited", "unlimited"
+		if egress.MonthlyQuota > 0 {
+			quota = units.BytesSize(float64(egress.MonthlyQuota))
+		}
+		if egress.LimitRate > 0 {
+			limitRate = units.BytesSize(float64(egress.LimitRate)) + "/s"
+		}
+
+		fmt.Printf("month: %s, quota: %s, used: %s, limit rate: %s\n", egress.Month, quota, units.BytesSize(float64(egress.UsedTraffic)), limitRate)
+		return nil
+	},
+}
+
+var setEgressPlan = &cli.Command{
+	Name:  "set",
+	Usage: "set egress plan for user, it overrides the tier of vip state",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "quota",
+			Usage: "monthly egress quota, 0 means unlimited, example: 10GiB",
+			Value: "0",
+		},
+		&cli.StringFlag{
+			Name:  "rate",
+			Usage: "download bandwidth per second, 0 means unlimited, example: 2MiB",
+			Value: "0",
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		quota, err := units.RAMInBytes(cctx.String("quota"))
+		if err != nil {
+			return err
+		}
+
+		limitRate, err := units.RAMInBytes(cctx.String("rate"))
+		if err != nil {
+			return err
+		}
+
+		plan := &types.UserEgressPlan{UserID: cctx.String("user"), MonthlyQuota: quota, LimitRate: limitRate}
+		return schedulerAPI.SetUserEgressPlan(ReqContext(cctx), plan)
+	},
+}
+
+var removeEgressPlan = &cli.Command{
+	Name:  "remove",
+	Usage: "remove egress plan of user, the tier of vip state is used",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "user",
+			Usage:    "Specify the user id",
+			Required: true,
+		},
+	},
+
+	Action: func(cctx *cli.Context) error {
+		schedulerAPI, closer, err := GetSchedulerAPI(cctx, "")
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return schedulerAPI.RemoveUserEgressPlan(ReqContext(cctx), cctx.String("user"))
+	},
+}
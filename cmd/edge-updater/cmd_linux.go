@@ -3,7 +3,13 @@
 
 package main
 
-import "os/exec"
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+)
 
 func killApp(appName string) error {
 	cmd := exec.Command("pkill", appName)
@@ -15,3 +21,14 @@ func killApp(appName string) error {
 	return nil
 
 }
+
+// runningAppVersion returns the version of the newest running process of the app,
+// the executable of the process is run even if the file of it has been replaced
+func runningAppVersion(appPath, appName string) (api.Version, error) {
+	out, err := exec.Command("pgrep", "-n", "-x", appName).Output()
+	if err != nil {
+		return api.Version(0), fmt.Errorf("app is not running")
+	}
+
+	return getLocalEdgeVersion(fmt.Sprintf("/proc/%s/exe", strings.TrimSpace(string(out))))
+}
@@ -1,5 +1,12 @@
 package main
 
+import "github.com/Filecoin-Titan/titan/api"
+
 func killApp(appName string) error {
 	return nil
 }
+
+// runningAppVersion returns the version of the app file, the running process is not inspected on windows
+func runningAppVersion(appPath, appName string) (api.Version, error) {
+	return getLocalEdgeVersion(appPath)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/bsdiff"
+	"github.com/urfave/cli/v2"
+)
+
+// downloadTimeout is long enough for the full app on a slow link
+const downloadTimeout = 30 * time.Minute
+
+// fetchApp writes the release to the tmp file, patching the local app if the release has a patch from its version,
+// the full app is downloaded if there is no patch or the patch fails
+func fetchApp(cctx *cli.Context, appPath, tmpFilePath string, updateInfo *api.EdgeUpdateConfig) error {
+	if version, err := localVersion(cctx, appPath, updateInfo); err == nil {
+		if patch := updateInfo.PatchFrom(version); patch != nil {
+			err = patchApp(appPath, tmpFilePath, patch, updateInfo.Hash)
+			if err == nil {
+				log.Infof("%s patched from %s to %s", updateInfo.AppName, version.String(), updateInfo.Version.String())
+				return nil
+			}
+			log.Warnf("patch %s from %s error:%s, download the full app", updateInfo.AppName, version.String(), err)
+		}
+	}
+
+	return downloadApp(updateInfo.DownloadURL, tmpFilePath, updateInfo.Hash)
+}
+
+func localVersion(cctx *cli.Context, appPath string, updateInfo *api.EdgeUpdateConfig) (api.Version, error) {
+	if updateInfo.NodeType == int(types.NodeEdge) {
+		return getLocalEdgeVersion(appPath)
+	}
+	return newVersion(cctx.App.Version)
+}
+
+// patchApp streams the patch onto the local app, the patch and the patched app are checked with their hashes
+func patchApp(appPath, tmpFilePath string, patch *api.EdgeUpdatePatch, hash string) error {
+	old, err := os.Open(appPath)
+	if err != nil {
+		return err
+	}
+	defer old.Close() //nolint:errcheck // read only
+
+	stat, err := old.Stat()
+	if err != nil {
+		return err
+	}
+
+	body, err := get(patch.DownloadURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close() //nolint:errcheck // ignore error
+
+	patchHash := sha256.New()
+	patchReader := io.TeeReader(body, patchHash)
+
+	err = writeVerified(tmpFilePath, hash, func(w io.Writer) error {
+		if err := bsdiff.Patch(old, stat.Size(), patchReader, w); err != nil {
+			return err
+		}
+
+		// the rest of the patch is read for its hash
+		_, err := io.Copy(io.Discard, patchReader)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if h := hex.EncodeToString(patchHash.Sum(nil)); h != patch.Hash {
+		return fmt.Errorf("patch hash %s != %s", h, patch.Hash)
+	}
+
+	return nil
+}
+
+// downloadApp streams the full app to the file and checks its hash
+func downloadApp(downloadURL, filePath, hash string) error {
+	body, err := get(downloadURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close() //nolint:errcheck // ignore error
+
+	return writeVerified(filePath, hash, func(w io.Writer) error {
+		_, err := io.Copy(w, body)
+		return err
+	})
+}
+
+// writeVerified writes the file with the write function, and checks the hash of the written bytes
+func writeVerified(filePath, hash string, write func(w io.Writer) error) error {
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // closed after sync
+
+	h := sha256.New()
+	if err = write(io.MultiWriter(f, h)); err != nil {
+		return err
+	}
+
+	if err = f.Sync(); err != nil {
+		return err
+	}
+
+	if fileHash := hex.EncodeToString(h.Sum(nil)); fileHash != hash {
+		return fmt.Errorf("download file incomplete, file hash %s != %s", fileHash, hash)
+	}
+
+	return nil
+}
+
+func get(url string) (io.ReadCloser, error) {
+	client := &http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close() //nolint:errcheck // ignore error
+		return nil, fmt.Errorf("get %s status %d", url, resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
@@ -2,11 +2,7 @@ package main
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path"
@@ -61,12 +57,37 @@ var runCmd = &cli.Command{
 			Usage: "install path",
 			Value: "/root/edge",
 		},
+		&cli.StringFlag{
+			Name:    "release-key",
+			Usage:   "hex public key of the offline release key, the releases not signed by it are refused, the key built in the binary is used if it is not set",
+			EnvVars: []string{"TITAN_RELEASE_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "edge-repo",
+			Usage:   "edge repo path, the node id in it decides whether the node is in the rollout",
+			EnvVars: []string{"TITAN_EDGE_PATH", "EDGE_PATH"},
+			Value:   "~/.titanedge",
+		},
+		&cli.DurationFlag{
+			Name:  "rollback-window",
+			Usage: "the updated edge is rolled back if it is not healthy in the window",
+			Value: 5 * time.Minute,
+		},
+		&cli.StringFlag{
+			Name:  "health-url",
+			Usage: "url checked for the health of the updated edge, example: --health-url=http://localhost:1234/health",
+			Value: "",
+		},
 	},
 	Before: func(cctx *cli.Context) error {
 		return nil
 	},
 	Action: func(cctx *cli.Context) error {
 		log.Info("Starting titan update app")
+		if _, err := loadReleaseKey(cctx); err != nil {
+			return err
+		}
+
 		schedulerURL := cctx.String("update-server")
 		schedulerAPI, close, err := newSchedulerAPI(cctx, schedulerURL)
 		if err != nil {
@@ -99,15 +120,32 @@ func checkUpdate(cctx *cli.Context, schedulerAPI api.Scheduler) {
 		log.Errorf("EdgeUpdateInfo error:%s", err.Error())
 	}
 
+	releaseKey, err := loadReleaseKey(cctx)
+	if err != nil {
+		log.Errorf("loadReleaseKey error:%s", err.Error())
+		return
+	}
+
+	nodeID := loadNodeID(cctx)
+	installPath := cctx.String("install-path")
+
 	// log.Infof("checkUpdate, updateInfos:%v", updateInfos)
 	mySelfUpdateInfo, ok := updateInfos[int(types.NodeUpdater)]
-	if ok && isNeedToUpdateMySelf(cctx, mySelfUpdateInfo) {
-		updateApp(cctx, mySelfUpdateInfo)
+	if ok && mySelfUpdateInfo.InRollout(nodeID) && isNeedToUpdateMySelf(cctx, mySelfUpdateInfo) {
+		if err := verifyRelease(releaseKey, mySelfUpdateInfo); err != nil {
+			log.Errorf("verifyRelease error:%s", err.Error())
+		} else {
+			updateApp(cctx, mySelfUpdateInfo)
+		}
 	}
 
 	edgeUpdateInfo, ok := updateInfos[int(types.NodeEdge)]
-	if ok && isNeedToUpdateEdge(cctx, edgeUpdateInfo) {
-		updateApp(cctx, edgeUpdateInfo)
+	if ok && edgeUpdateInfo.InRollout(nodeID) && !isRejected(installPath, edgeUpdateInfo) && isNeedToUpdateEdge(cctx, edgeUpdateInfo) {
+		if err := verifyRelease(releaseKey, edgeUpdateInfo); err != nil {
+			log.Errorf("verifyRelease error:%s", err.Error())
+		} else {
+			updateApp(cctx, edgeUpdateInfo)
+		}
 	}
 }
 
@@ -170,41 +208,33 @@ func isNeedToUpdateEdge(cctx *cli.Context, updateInfo *api.EdgeUpdateConfig) boo
 func updateApp(cctx *cli.Context, updateInfo *api.EdgeUpdateConfig) {
 	log.Debugf("updateApp, AppName:%s, Hash:%s, newVersion:%s, downloadURL:%s", updateInfo.AppName, updateInfo.Hash, updateInfo.Version.String(), updateInfo.DownloadURL)
 	installPath := cctx.String("install-path")
-	data, err := downloadApp(updateInfo.DownloadURL)
-	if err != nil {
-		log.Errorf("download app error:%s", err)
-		return
-	}
-
-	// check file hash
-	h := sha256.New()
-	h.Write(data)
-	hash := hex.EncodeToString(h.Sum(nil))
-
-	if updateInfo.Hash != hash {
-		log.Errorf("download file incomplete, file hash %s != %s", hash, updateInfo.Hash)
-		return
-	}
+	filePath := filepath.Join(installPath, updateInfo.AppName)
 
 	fileName := updateInfo.AppName + "_tmp"
 	tmpFilePath := filepath.Join(installPath, fileName)
 
-	err = os.WriteFile(tmpFilePath, data, 0o644)
+	err := fetchApp(cctx, filePath, tmpFilePath, updateInfo)
 	if err != nil {
-		log.Errorf("save app error:%s", err)
+		log.Errorf("download app error:%s", err)
+		os.Remove(tmpFilePath) //nolint:errcheck // ignore error
 		return
 	}
 
-	filePath := filepath.Join(installPath, updateInfo.AppName)
-	err = os.Remove(filePath)
+	// the old app is kept to roll back
+	backupPath := filePath + "_bak"
+	err = os.Rename(filePath, backupPath)
 	if err != nil {
-		log.Errorf("remove old app error:%s", err)
+		log.Errorf("backup old app error:%s", err)
 		return
 	}
 
 	err = os.Rename(tmpFilePath, filePath)
 	if err != nil {
-		log.Errorf("remove old app error:%s", err)
+		log.Errorf("install new app error:%s", err)
+		if err = os.Rename(backupPath, filePath); err != nil {
+			log.Errorf("restore old app error:%s", err)
+		}
+		os.Remove(tmpFilePath) //nolint:errcheck // ignore error
 		return
 	}
 
@@ -221,38 +251,32 @@ func updateApp(cctx *cli.Context, updateInfo *api.EdgeUpdateConfig) {
 		log.Errorf("killApp failed:%s", err.Error())
 		return
 	}
-}
 
-func changePermission(appPath string) error {
-	cmd := exec.Command("chmod", "+x", appPath)
-	_, err := cmd.Output()
-	if err != nil {
-		return err
+	// the updater is restarted by itself
+	if updateInfo.NodeType != int(types.NodeEdge) {
+		return
 	}
 
-	return nil
-}
-
-func downloadApp(downloadURL string) ([]byte, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	req, err := http.NewRequest("GET", downloadURL, nil)
-	if err != nil {
-		return nil, err
+	if waitHealthy(cctx, filePath, updateInfo) {
+		log.Infof("%s updated to %s", updateInfo.AppName, updateInfo.Version.String())
+		return
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	log.Errorf("%s %s is not healthy in %s, roll back", updateInfo.AppName, updateInfo.Version.String(), cctx.Duration("rollback-window"))
+	reject(installPath, updateInfo)
+	if err = rollback(filePath, backupPath, updateInfo.AppName); err != nil {
+		log.Errorf("rollback failed:%s", err.Error())
 	}
+}
 
-	defer resp.Body.Close() //nolint:errcheck // ignore error
-
-	data, err := io.ReadAll(resp.Body)
+func changePermission(appPath string) error {
+	cmd := exec.Command("chmod", "+x", appPath)
+	_, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return data, nil
+	return nil
 }
 
 func newVersion(version string) (api.Version, error) {
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/build"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// interval of checking the health of the updated app
+	healthCheckInterval = 10 * time.Second
+	// the updated app is healthy after passing the count of health checks in a row
+	healthyChecks = 3
+)
+
+// loadReleaseKey parses the hex ed25519 public key of the offline release key,
+// the key built in the binary is used if the flag is not set, and the updater refuses to run without a key
+func loadReleaseKey(cctx *cli.Context) (ed25519.PublicKey, error) {
+	keyHex := strings.TrimSpace(cctx.String("release-key"))
+	if keyHex == "" {
+		keyHex = build.ReleaseKey
+	}
+
+	if keyHex == "" {
+		return nil, fmt.Errorf("release key is not set, set it by --release-key or build it in the binary")
+	}
+
+	return api.ParseReleaseKey(keyHex)
+}
+
+// loadNodeID reads the node id from the edge repo, empty if the edge is not registered yet
+func loadNodeID(cctx *cli.Context) string {
+	repoPath, err := homedir.Expand(cctx.String("edge-repo"))
+	if err != nil {
+		log.Errorf("expand edge repo error:%s", err)
+		return ""
+	}
+
+	nodeID, err := os.ReadFile(filepath.Join(repoPath, "node_id"))
+	if err != nil {
+		log.Warnf("read node id error:%s", err)
+		return ""
+	}
+
+	return strings.TrimSpace(string(nodeID))
+}
+
+// verifyRelease checks the release is signed by the release key, the release is refused without the key
+func verifyRelease(releaseKey ed25519.PublicKey, updateInfo *api.EdgeUpdateConfig) error {
+	return updateInfo.VerifySignature(releaseKey)
+}
+
+// rejectedFile records the release rolled back, so it is not installed again
+func rejectedFile(installPath, appName string) string {
+	return filepath.Join(installPath, appName+"_rejected")
+}
+
+func isRejected(installPath string, updateInfo *api.EdgeUpdateConfig) bool {
+	data, err := os.ReadFile(rejectedFile(installPath, updateInfo.AppName))
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(string(data)) == updateInfo.Version.String()
+}
+
+func reject(installPath string, updateInfo *api.EdgeUpdateConfig) {
+	err := os.WriteFile(rejectedFile(installPath, updateInfo.AppName), []byte(updateInfo.Version.String()), 0o644)
+	if err != nil {
+		log.Errorf("record rejected release error:%s", err)
+	}
+}
+
+// waitHealthy waits for the updated app to pass the health checks in the window
+func waitHealthy(cctx *cli.Context, appPath string, updateInfo *api.EdgeUpdateConfig) bool {
+	deadline := time.Now().Add(cctx.Duration("rollback-window"))
+	passed := 0
+
+	for time.Now().Before(deadline) {
+		time.Sleep(healthCheckInterval)
+
+		if err := checkHealth(cctx, appPath, updateInfo); err != nil {
+			log.Warnf("%s %s health check error:%s", updateInfo.AppName, updateInfo.Version.String(), err)
+			passed = 0
+			continue
+		}
+
+		passed++
+		if passed >= healthyChecks {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkHealth checks the running app is the updated version, and the health url responds ok if it is set
+func checkHealth(cctx *cli.Context, appPath string, updateInfo *api.EdgeUpdateConfig) error {
+	version, err := runningAppVersion(appPath, updateInfo.AppName)
+	if err != nil {
+		return err
+	}
+
+	if version != updateInfo.Version {
+		return fmt.Errorf("running version %s", version.String())
+	}
+
+	healthURL := cctx.String("health-url")
+	if healthURL == "" {
+		return nil
+	}
+
+	client := &http.Client{Timeout: healthCheckInterval}
+	resp, err := client.Get(healthURL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close() //nolint:errcheck // ignore error
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health url status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// rollback restores the previous app and restarts it
+func rollback(filePath, backupPath, appName string) error {
+	if err := os.Rename(backupPath, filePath); err != nil {
+		return err
+	}
+
+	return killApp(appName)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+)
+
+func TestVerifyRelease(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := &api.EdgeUpdateConfig{NodeType: 1, AppName: "titan-edge", Version: api.Version(0x000201), Hash: "abc"}
+	release.Signature = hex.EncodeToString(ed25519.Sign(priv, release.SignedMessage()))
+
+	if err = verifyRelease(pub, release); err != nil {
+		t.Fatal(err)
+	}
+
+	// the release is refused without the key
+	if err = verifyRelease(nil, release); err == nil {
+		t.Fatal("release is verified without the key")
+	}
+
+	tampered := *release
+	tampered.Hash = "def"
+	if err = verifyRelease(pub, &tampered); err == nil {
+		t.Fatal("tampered release is verified")
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyRelease(otherPub, release); err == nil {
+		t.Fatal("release is verified with another key")
+	}
+
+	release.Signature = "not hex"
+	if err = verifyRelease(pub, release); err == nil {
+		t.Fatal("invalid signature is verified")
+	}
+}
@@ -164,7 +164,7 @@ var daemonStartCmd = &cli.Command{
 
 		// Register all metric views
 		if err := view.Register(
-			metrics.DefaultViews...,
+			metrics.NodeViews...,
 		); err != nil {
 			log.Fatalf("Cannot register the view: %v", err)
 		}
@@ -327,6 +327,8 @@ var daemonStartCmd = &cli.Command{
 					APISecret:           apiSecret,
 					MaxSizeOfUploadFile: candidateCfg.MaxSizeOfUploadFile,
 					WebRedirect:         candidateCfg.WebRedirect,
+					EnableS3Gateway:     candidateCfg.EnableS3Gateway,
+					DNSLinkDomains:      candidateCfg.DNSLinkDomains,
 				}
 				httpServer = httpserver.NewHttpServer(opts)
 				return nil
@@ -9,6 +9,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/handler"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/metrics/proxy"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -29,6 +30,8 @@ func CandidateHandler(verify func(ctx context.Context, token string) (*types.JWT
 
 	mux.Handle("/rpc/v0", rpcServer)
 	mux.Handle("/rpc/streams/v0/push/{uuid}", readerHandler)
+	// debugging
+	mux.Handle("/debug/metrics", metrics.Exporter())
 	mux.PathPrefix("/").Handler(http.DefaultServeMux) // pprof
 
 	if !permissioned {
@@ -36,6 +36,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/repo"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"github.com/docker/go-units"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/quic-go/quic-go"
@@ -56,6 +57,7 @@ const (
 	FlagEdgeRepo            = "edge-repo"
 	FlagEdgeRepoDeprecation = "edgerepo"
 	DefaultStorageDir       = "storage"
+	DefaultCacheDir         = "read-through-cache"
 	HeartbeatInterval       = 10 * time.Second
 )
 
@@ -157,7 +159,7 @@ var daemonStartCmd = &cli.Command{
 
 		// Register all metric views
 		if err := view.Register(
-			metrics.DefaultViews...,
+			metrics.NodeViews...,
 		); err != nil {
 			log.Fatalf("Cannot register the view: %v", err)
 		}
@@ -311,13 +313,22 @@ var daemonStartCmd = &cli.Command{
 				return dtypes.InternalIP(strings.Split(localAddr.IP.String(), ":")[0]), nil
 			}),
 
-			node.Override(node.RunGateway, func(assetMgr *asset.Manager, validation *validation.Validation, apiSecret *jwt.HMACSHA) error {
+			node.Override(node.RunGateway, func(assetMgr *asset.Manager, validation *validation.Validation, apiSecret *jwt.HMACSHA, metadataPath dtypes.NodeMetadataPath) error {
 				opts := &httpserver.HttpServerOptions{
 					Asset: assetMgr, Scheduler: schedulerAPI,
 					PrivateKey:          privateKey,
 					Validation:          validation,
 					APISecret:           apiSecret,
 					MaxSizeOfUploadFile: edgeCfg.MaxSizeOfUploadFile,
+					DNSLinkDomains:      edgeCfg.DNSLinkDomains,
+				}
+
+				if edgeCfg.ReadThroughCacheGB > 0 {
+					opts.ReadThroughCache = &httpserver.ReadThroughCacheOptions{
+						Path:       path.Join(string(metadataPath), DefaultCacheDir),
+						Budget:     edgeCfg.ReadThroughCacheGB * units.GiB,
+						HTTPClient: client.NewHTTP3Client(),
+					}
 				}
 				httpServer = httpserver.NewHttpServer(opts)
 
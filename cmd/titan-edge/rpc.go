@@ -8,6 +8,7 @@ import (
 	"github.com/Filecoin-Titan/titan/lib/rpcenc"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/metrics/proxy"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -30,6 +31,8 @@ func EdgeHandler(verify func(ctx context.Context, token string) (*types.JWTPaylo
 
 	mux.Handle("/rpc/v0", rpcServer)
 	mux.Handle("/rpc/streams/v0/push/{uuid}", readerHandler)
+	// debugging
+	mux.Handle("/debug/metrics", metrics.Exporter())
 	mux.PathPrefix("/").Handler(http.DefaultServeMux) // pprof
 
 	if !permissioned {
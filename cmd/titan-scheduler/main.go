@@ -20,6 +20,7 @@ import (
 	"github.com/Filecoin-Titan/titan/build"
 	lcli "github.com/Filecoin-Titan/titan/cli"
 	"github.com/Filecoin-Titan/titan/lib/titanlog"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/repo"
@@ -34,6 +35,7 @@ import (
 	logging "github.com/ipfs/go-log/v2"
 	manet "github.com/multiformats/go-multiaddr/net"
 	"github.com/urfave/cli/v2"
+	"go.opencensus.io/stats/view"
 )
 
 var log = logging.Logger("main")
@@ -181,6 +183,13 @@ var runCmd = &cli.Command{
 	Action: func(cctx *cli.Context) error {
 		log.Info("Starting titan scheduler node")
 
+		// Register all metric views
+		if err := view.Register(
+			metrics.SchedulerViews...,
+		); err != nil {
+			log.Fatalf("Cannot register the view: %v", err)
+		}
+
 		repoPath := cctx.String(FlagSchedulerRepo)
 		r, err := repo.NewFS(repoPath)
 		if err != nil {
@@ -0,0 +1,67 @@
+package bsdiff
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDiffPatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	old := make([]byte, 200<<10)
+	r.Read(old)
+
+	// the new file shares most of the old file with some edits, insertions and deletions
+	new := append([]byte{}, old[:50<<10]...)
+	new = append(new, []byte("inserted bytes of the new version")...)
+	new = append(new, old[60<<10:120<<10]...)
+	for i := 0; i < 100; i++ {
+		new[r.Intn(len(new))]++
+	}
+	tail := make([]byte, 10<<10)
+	r.Read(tail)
+	new = append(new, tail...)
+	new = append(new, old[150<<10:]...)
+
+	cases := map[string][2][]byte{
+		"edited":    {old, new},
+		"empty old": {nil, new},
+		"empty new": {old, nil},
+		"same":      {old, old},
+	}
+
+	for name, c := range cases {
+		patch := &bytes.Buffer{}
+		if err := Diff(c[0], c[1], patch); err != nil {
+			t.Fatalf("%s: diff: %v", name, err)
+		}
+
+		out := &bytes.Buffer{}
+		if err := Patch(bytes.NewReader(c[0]), int64(len(c[0])), bytes.NewReader(patch.Bytes()), out); err != nil {
+			t.Fatalf("%s: patch: %v", name, err)
+		}
+
+		if !bytes.Equal(out.Bytes(), c[1]) {
+			t.Fatalf("%s: patched file is different from the new file", name)
+		}
+
+		if name == "edited" && patch.Len() > len(new)/4 {
+			t.Fatalf("patch of %d bytes is too large for %d bytes", patch.Len(), len(new))
+		}
+	}
+}
+
+func TestCheckSize(t *testing.T) {
+	if err := checkSize("new", math.MaxInt32-1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the offsets of the files from 2GiB overflow int32
+	for _, size := range []int{math.MaxInt32, 2 << 30, 5 << 30} {
+		if err := checkSize("new", size); err == nil {
+			t.Fatalf("file of %d bytes is accepted", size)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+// Package bsdiff creates and applies binary patches with the bsdiff algorithm.
+//
+// The patch is the magic, the size of the new file and a gzip stream of records.
+// A record is the control triple (add length, extra length, seek of old) followed by
+// the add bytes, which are added to the old bytes, and the extra bytes, which are copied.
+// The records are interleaved so the patch is applied in one pass while streaming.
+package bsdiff
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// magic is the header of the patch
+var magic = []byte("TBSDIFF1")
+
+// Diff writes the patch which turns old into new, the files must be smaller than 2GiB
+func Diff(old, new []byte, patch io.Writer) error {
+	if err := checkSize("old", len(old)); err != nil {
+		return err
+	}
+
+	if err := checkSize("new", len(new)); err != nil {
+		return err
+	}
+
+	header := make([]byte, len(magic)+8)
+	copy(header, magic)
+	binary.BigEndian.PutUint64(header[len(magic):], uint64(len(new)))
+	if _, err := patch.Write(header); err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(patch)
+	w := bufio.NewWriter(zw)
+
+	I := qsufsort(old)
+	oldSize, newSize := int32(len(old)), int32(len(new))
+
+	var scan, pos, length int32
+	var lastScan, lastPos, lastOffset int32
+	ctrl := make([]byte, 24)
+
+	for scan < newSize {
+		var oldScore int32
+
+		scan += length
+		for scsc := scan; scan < newSize; scan++ {
+			length = search(I, old, new[scan:], 0, oldSize, &pos)
+
+			for ; scsc < scan+length; scsc++ {
+				if scsc+lastOffset < oldSize && old[scsc+lastOffset] == new[scsc] {
+					oldScore++
+				}
+			}
+
+			if (length == oldScore && length != 0) || length > oldScore+8 {
+				break
+			}
+
+			if scan+lastOffset < oldSize && old[scan+lastOffset] == new[scan] {
+				oldScore--
+			}
+		}
+
+		if length == oldScore && scan != newSize {
+			continue
+		}
+
+		// extend the match forward from the last match
+		var s, sf, lenf int32
+		for i := int32(0); lastScan+i < scan && lastPos+i < oldSize; {
+			if old[lastPos+i] == new[lastScan+i] {
+				s++
+			}
+			i++
+			if s*2-i > sf*2-lenf {
+				sf = s
+				lenf = i
+			}
+		}
+
+		// extend the match backward from the current match
+		var lenb int32
+		if scan < newSize {
+			var sb int32
+			s = 0
+			for i := int32(1); scan >= lastScan+i && pos >= i; i++ {
+				if old[pos-i] == new[scan-i] {
+					s++
+				}
+				if s*2-i > sb*2-lenb {
+					sb = s
+					lenb = i
+				}
+			}
+		}
+
+		// split the overlap of the extensions
+		if lastScan+lenf > scan-lenb {
+			overlap := (lastScan + lenf) - (scan - lenb)
+			var ss, lens int32
+			s = 0
+			for i := int32(0); i < overlap; i++ {
+				if new[lastScan+lenf-overlap+i] == old[lastPos+lenf-overlap+i] {
+					s++
+				}
+				if new[scan-lenb+i] == old[pos-lenb+i] {
+					s--
+				}
+				if s > ss {
+					ss = s
+					lens = i + 1
+				}
+			}
+
+			lenf += lens - overlap
+			lenb -= lens
+		}
+
+		extraLen := (scan - lenb) - (lastScan + lenf)
+		seek := (pos - lenb) - (lastPos + lenf)
+
+		binary.BigEndian.PutUint64(ctrl[0:], uint64(lenf))
+		binary.BigEndian.PutUint64(ctrl[8:], uint64(extraLen))
+		binary.BigEndian.PutUint64(ctrl[16:], uint64(int64(seek)))
+		if _, err := w.Write(ctrl); err != nil {
+			return err
+		}
+
+		for i := int32(0); i < lenf; i++ {
+			if err := w.WriteByte(new[lastScan+i] - old[lastPos+i]); err != nil {
+				return err
+			}
+		}
+
+		if _, err := w.Write(new[lastScan+lenf : scan-lenb]); err != nil {
+			return err
+		}
+
+		lastScan = scan - lenb
+		lastPos = pos - lenb
+		lastOffset = pos - scan
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// checkSize checks the file size fits in the int32 offsets of the suffix array
+func checkSize(name string, size int) error {
+	if size >= math.MaxInt32 {
+		return fmt.Errorf("%s file is too large, %d bytes", name, size)
+	}
+	return nil
+}
+
+// search finds the longest match of new in old with the suffix array, returns the length and sets the position
+func search(I []int32, old, new []byte, st, en int32, pos *int32) int32 {
+	for en-st >= 2 {
+		x := st + (en-st)/2
+		n := min(int(int32(len(old))-I[x]), len(new))
+		if bytes.Compare(old[I[x]:I[x]+int32(n)], new[:n]) < 0 {
+			st = x
+		} else {
+			en = x
+		}
+	}
+
+	x := matchLen(old[I[st]:], new)
+	y := matchLen(old[I[en]:], new)
+	if x > y {
+		*pos = I[st]
+		return x
+	}
+
+	*pos = I[en]
+	return y
+}
+
+func matchLen(a, b []byte) int32 {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return int32(i)
+}
+
+// qsufsort builds the suffix array of buf with the Larsson-Sadakane algorithm
+func qsufsort(buf []byte) []int32 {
+	size := int32(len(buf))
+	I := make([]int32, size+1)
+	V := make([]int32, size+1)
+
+	var buckets [256]int32
+	for _, c := range buf {
+		buckets[c]++
+	}
+	for i := 1; i < 256; i++ {
+		buckets[i] += buckets[i-1]
+	}
+	for i := 255; i > 0; i-- {
+		buckets[i] = buckets[i-1]
+	}
+	buckets[0] = 0
+
+	for i, c := range buf {
+		buckets[c]++
+		I[buckets[c]] = int32(i)
+	}
+	I[0] = size
+	for i, c := range buf {
+		V[i] = buckets[c]
+	}
+	V[size] = 0
+	for i := 1; i < 256; i++ {
+		if buckets[i] == buckets[i-1]+1 {
+			I[buckets[i]] = -1
+		}
+	}
+	I[0] = -1
+
+	for h := int32(1); I[0] != -(size + 1); h += h {
+		var length int32
+		i := int32(0)
+		for i < size+1 {
+			if I[i] < 0 {
+				length -= I[i]
+				i -= I[i]
+				continue
+			}
+
+			if length != 0 {
+				I[i-length] = -length
+			}
+			length = V[I[i]] + 1 - i
+			split(I, V, i, length, h)
+			i += length
+			length = 0
+		}
+		if length != 0 {
+			I[i-length] = -length
+		}
+	}
+
+	for i := int32(0); i < size+1; i++ {
+		I[V[i]] = i
+	}
+
+	return I
+}
+
+func split(I, V []int32, start, length, h int32) {
+	if length < 16 {
+		var j int32
+		for k := start; k < start+length; k += j {
+			j = 1
+			x := V[I[k]+h]
+			for i := int32(1); k+i < start+length; i++ {
+				if V[I[k+i]+h] < x {
+					x = V[I[k+i]+h]
+					j = 0
+				}
+				if V[I[k+i]+h] == x {
+					I[k+j], I[k+i] = I[k+i], I[k+j]
+					j++
+				}
+			}
+			for i := int32(0); i < j; i++ {
+				V[I[k+i]] = k + j - 1
+			}
+			if j == 1 {
+				I[k] = -1
+			}
+		}
+		return
+	}
+
+	x := V[I[start+length/2]+h]
+	var jj, kk int32
+	for i := start; i < start+length; i++ {
+		if V[I[i]+h] < x {
+			jj++
+		}
+		if V[I[i]+h] == x {
+			kk++
+		}
+	}
+	jj += start
+	kk += jj
+
+	i, j, k := start, int32(0), int32(0)
+	for i < jj {
+		switch {
+		case V[I[i]+h] < x:
+			i++
+		case V[I[i]+h] == x:
+			I[i], I[jj+j] = I[jj+j], I[i]
+			j++
+		default:
+			I[i], I[kk+k] = I[kk+k], I[i]
+			k++
+		}
+	}
+
+	for jj+j < kk {
+		if V[I[jj+j]+h] == x {
+			j++
+		} else {
+			I[jj+j], I[kk+k] = I[kk+k], I[jj+j]
+			k++
+		}
+	}
+
+	if jj > start {
+		split(I, V, start, jj-start, h)
+	}
+
+	for i := int32(0); i < kk-jj; i++ {
+		V[I[jj+i]] = kk - 1
+	}
+	if jj == kk-1 {
+		I[jj] = -1
+	}
+
+	if start+length > kk {
+		split(I, V, kk, start+length-kk, h)
+	}
+}
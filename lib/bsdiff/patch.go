@@ -0,0 +1,108 @@
+package bsdiff
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// chunkSize is the size of the old bytes read at a time while patching
+const chunkSize = 32 << 10
+
+// Patch applies the patch to old and streams the new file to out
+func Patch(old io.ReaderAt, oldSize int64, patch io.Reader, out io.Writer) error {
+	header := make([]byte, len(magic)+8)
+	if _, err := io.ReadFull(patch, header); err != nil {
+		return fmt.Errorf("read patch header: %w", err)
+	}
+
+	if !bytes.Equal(header[:len(magic)], magic) {
+		return fmt.Errorf("invalid patch magic %q", header[:len(magic)])
+	}
+	newSize := int64(binary.BigEndian.Uint64(header[len(magic):]))
+
+	zr, err := gzip.NewReader(patch)
+	if err != nil {
+		return err
+	}
+	defer zr.Close() //nolint:errcheck // read only
+
+	r := bufio.NewReader(zr)
+	w := bufio.NewWriter(out)
+
+	ctrl := make([]byte, 24)
+	oldBuf := make([]byte, chunkSize)
+	addBuf := make([]byte, chunkSize)
+
+	var oldPos, newPos int64
+	for newPos < newSize {
+		if _, err = io.ReadFull(r, ctrl); err != nil {
+			return fmt.Errorf("read patch control: %w", err)
+		}
+
+		addLen := int64(binary.BigEndian.Uint64(ctrl[0:]))
+		extraLen := int64(binary.BigEndian.Uint64(ctrl[8:]))
+		seek := int64(binary.BigEndian.Uint64(ctrl[16:]))
+
+		if addLen < 0 || extraLen < 0 || newPos+addLen+extraLen > newSize {
+			return fmt.Errorf("corrupt patch control at %d", newPos)
+		}
+
+		// the add bytes are added to the old bytes, the old bytes out of range are zero
+		for done := int64(0); done < addLen; {
+			n := min(addLen-done, chunkSize)
+			if _, err = io.ReadFull(r, addBuf[:n]); err != nil {
+				return fmt.Errorf("read patch add bytes: %w", err)
+			}
+
+			if err = readOld(old, oldSize, oldPos+done, oldBuf[:n]); err != nil {
+				return err
+			}
+
+			for i := int64(0); i < n; i++ {
+				addBuf[i] += oldBuf[i]
+			}
+
+			if _, err = w.Write(addBuf[:n]); err != nil {
+				return err
+			}
+			done += n
+		}
+
+		if _, err = io.CopyN(w, r, extraLen); err != nil {
+			return fmt.Errorf("read patch extra bytes: %w", err)
+		}
+
+		newPos += addLen + extraLen
+		oldPos += addLen + seek
+	}
+
+	return w.Flush()
+}
+
+// readOld fills buf with the old bytes at offset, the bytes out of the old file are zero
+func readOld(old io.ReaderAt, oldSize, offset int64, buf []byte) error {
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	start, end := offset, offset+int64(len(buf))
+	if start < 0 {
+		start = 0
+	}
+	if end > oldSize {
+		end = oldSize
+	}
+	if start >= end {
+		return nil
+	}
+
+	_, err := old.ReadAt(buf[start-offset:end-offset], start)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("read old file: %w", err)
+	}
+	return nil
+}
@@ -326,6 +326,76 @@ func GenerateCar(ctx context.Context, fileList []Finfo, parentPath string, tmpDi
 	return
 }
 
+// GenerateFileCar builds the unixfs dag of the file and writes the car to output, the root is the file node
+func GenerateFileCar(ctx context.Context, path string, output io.Writer) (cid.Cid, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	stat, err := os.Stat(absPath)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	batching := dss.MutexWrap(datastore.NewMapDatastore())
+	fm := filestore.NewFileManager(batching, filepath.Dir(absPath))
+	fm.AllowFiles = true
+	bs := filestore.NewFilestore(bstore.NewBlockstore(batching), fm)
+	dagServ := merkledag.NewDAGService(blockservice.New(bs, offline.Exchange(bs)))
+
+	cidBuilder, err := merkledag.PrefixForCidVersion(1)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return cid.Undef, err
+	}
+	defer f.Close() //nolint:errcheck // ignore error
+
+	r, err := files.NewReaderPathFile(absPath, f, stat)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	params := ihelper.DagBuilderParams{
+		Maxlinks:   UnixfsLinksPerLevel,
+		RawLeaves:  true,
+		CidBuilder: cidBuilder,
+		Dagserv:    dagServ,
+		NoCopy:     true,
+	}
+
+	db, err := params.New(chunker.NewSizeSplitter(r, int64(UnixfsChunkSize)))
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	node, err := balanced.Layout(db)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	if err = dagServ.Add(ctx, node); err != nil {
+		return cid.Undef, err
+	}
+
+	sc := car.NewSelectiveCar(ctx, bs, []car.Dag{{Root: node.Cid(), Selector: allSelector()}})
+	if err = sc.Write(output); err != nil {
+		return cid.Undef, err
+	}
+
+	return node.Cid(), nil
+}
+
+// WriteCar writes the car v1 of the whole dag of the root, the blocks are read from the store
+func WriteCar(ctx context.Context, store car.ReadStore, root cid.Cid, w io.Writer) error {
+	sc := car.NewSelectiveCar(ctx, store, []car.Dag{{Root: root, Selector: allSelector()}})
+	return sc.Write(w)
+}
+
 func allSelector() ipldprime.Node {
 	ssb := builder.NewSelectorSpecBuilder(basicnode.Prototype.Any)
 	return ssb.ExploreRecursive(selector.RecursionLimitNone(),
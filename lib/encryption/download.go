@@ -0,0 +1,42 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Download downloads the encrypted asset from the link and writes the data decrypted with the key to w,
+// the key embedded in the fragment of the link is used if key is nil
+func Download(ctx context.Context, client *http.Client, link string, key []byte, w io.Writer) (int64, error) {
+	if key == nil {
+		var err error
+		if link, key, err = KeyFromLink(link); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close() //nolint:errcheck // ignore error
+
+	if rsp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return 0, fmt.Errorf("download status code %d, %s", rsp.StatusCode, string(body))
+	}
+
+	r, err := NewDecryptReader(key, rsp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return io.Copy(w, r)
+}
@@ -0,0 +1,139 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, ChunkSize - 1, ChunkSize, 3*ChunkSize + 7} {
+		data := make([]byte, size)
+		rand.Read(data) //nolint:errcheck // ignore error
+
+		r, err := NewEncryptReader(key, bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encrypted, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err = NewDecryptReader(key, bytes.NewReader(encrypted))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decrypted, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("size %d: %s", size, err)
+		}
+
+		if !bytes.Equal(data, decrypted) {
+			t.Fatalf("size %d: decrypted data is not equal", size)
+		}
+
+		// the stream truncated at a chunk boundary is rejected
+		if size > ChunkSize {
+			r, _ = NewDecryptReader(key, bytes.NewReader(encrypted[:headerSize+ChunkSize+16]))
+			if _, err := io.ReadAll(r); err == nil {
+				t.Fatalf("size %d: truncated stream is decrypted", size)
+			}
+		}
+	}
+
+	if _, err := NewDecryptReader(key, bytes.NewReader([]byte("plain data of the asset"))); err != ErrNotEncrypted {
+		t.Fatalf("expected ErrNotEncrypted, got %v", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	priv, pub, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := WrapKey(pub, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unwrapped, err := UnwrapKey(priv, envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(key, unwrapped) {
+		t.Fatal("unwrapped key is not equal")
+	}
+
+	other, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := UnwrapKey(other, envelope); err == nil {
+		t.Fatal("key is unwrapped by other private key")
+	}
+
+	link := LinkWithKey("https://example.com/ipfs/bafy?token=t&filename=a.txt", key)
+	plain, linkKey, err := KeyFromLink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plain != "https://example.com/ipfs/bafy?token=t&filename=a.txt" || !bytes.Equal(key, linkKey) {
+		t.Fatalf("unexpected link %s", plain)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("encrypted asset"), ChunkSize/4)
+	r, err := NewEncryptReader(key, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Fragment != "" {
+			t.Error("fragment is sent to the server")
+		}
+		w.Write(encrypted) //nolint:errcheck // ignore error
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	if _, err := Download(context.Background(), srv.Client(), LinkWithKey(srv.URL+"/ipfs/bafy?token=t", key), nil, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, out.Bytes()) {
+		t.Fatal("downloaded data is not equal")
+	}
+}
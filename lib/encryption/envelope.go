@@ -0,0 +1,147 @@
+package encryption
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	envelopeVersion = 1
+	// keyFragment is the fragment of the share link with the data key, the fragment is not sent to the servers
+	keyFragment = "key="
+)
+
+// GenerateKeyPair returns a X25519 key pair of a user, the data keys are wrapped for the public key
+func GenerateKeyPair() (privateKey, publicKey []byte, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key.Bytes(), key.PublicKey().Bytes(), nil
+}
+
+// PublicKey returns the public key of the private key
+func PublicKey(privateKey []byte) ([]byte, error) {
+	key, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.PublicKey().Bytes(), nil
+}
+
+// kek derives the key encrypting the data key from the shared secret and the public keys
+func kek(shared, ephemeral, recipient []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("titan-key-envelope"))
+	h.Write(shared)
+	h.Write(ephemeral)
+	h.Write(recipient)
+	return h.Sum(nil)
+}
+
+// WrapKey encrypts the data key for the public key with an ephemeral X25519 key,
+// the envelope is version | ephemeral public key | nonce | sealed data key
+func WrapKey(publicKey, dataKey []byte) ([]byte, error) {
+	recipient, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(kek(shared, ephemeral.PublicKey().Bytes(), publicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	envelope := append([]byte{envelopeVersion}, ephemeral.PublicKey().Bytes()...)
+	envelope = append(envelope, nonce...)
+	return aead.Seal(envelope, nonce, dataKey, envelope[:1]), nil
+}
+
+// UnwrapKey decrypts the data key in the envelope with the private key
+func UnwrapKey(privateKey, envelope []byte) ([]byte, error) {
+	key, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	const pubSize, nonceSize = 32, 12
+	if len(envelope) < 1+pubSize+nonceSize || envelope[0] != envelopeVersion {
+		return nil, fmt.Errorf("invalid key envelope")
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(envelope[1 : 1+pubSize])
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := key.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(kek(shared, ephemeral.Bytes(), key.PublicKey().Bytes()))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := envelope[1+pubSize : 1+pubSize+nonceSize]
+	dataKey, err := aead.Open(nil, nonce, envelope[1+pubSize+nonceSize:], envelope[:1])
+	if err != nil {
+		return nil, fmt.Errorf("unwrap key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+// LinkWithKey embeds the data key in the fragment of the share link
+func LinkWithKey(link string, dataKey []byte) string {
+	link, _, _ = strings.Cut(link, "#")
+	return link + "#" + keyFragment + base64.RawURLEncoding.EncodeToString(dataKey)
+}
+
+// KeyFromLink returns the link without the fragment and the data key embedded in the fragment
+func KeyFromLink(link string) (string, []byte, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, ok := strings.CutPrefix(u.Fragment, keyFragment)
+	if !ok {
+		return "", nil, fmt.Errorf("link has no key")
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(key) != KeySize {
+		return "", nil, fmt.Errorf("invalid key size %d", len(key))
+	}
+
+	u.Fragment = ""
+	return u.String(), key, nil
+}
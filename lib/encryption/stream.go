@@ -0,0 +1,209 @@
+// Package encryption encrypts the assets on the client before the car is built,
+// the data key of an asset is wrapped for the public keys of the users who can decrypt it
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// KeySize is the size of the data key of an asset
+	KeySize = 32
+	// ChunkSize is the size of the plaintext chunks which are sealed one by one
+	ChunkSize = 64 << 10
+
+	version    = 1
+	prefixSize = 7
+	headerSize = 4 + 1 + 4 + prefixSize
+)
+
+var magic = []byte("TENC")
+
+// ErrNotEncrypted is returned if the data does not start with the header of the encrypted asset
+var ErrNotEncrypted = errors.New("data is not an encrypted asset")
+
+// NewDataKey returns a random data key for an asset
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// header is magic | version | chunk size | nonce prefix, it is the additional data of all chunks
+type header []byte
+
+func newHeader(chunkSize int) (header, error) {
+	h := make([]byte, headerSize)
+	copy(h, magic)
+	h[4] = version
+	binary.BigEndian.PutUint32(h[5:9], uint32(chunkSize))
+	if _, err := rand.Read(h[9:]); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func readHeader(r io.Reader) (header, int, error) {
+	h := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, h); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, 0, ErrNotEncrypted
+		}
+		return nil, 0, err
+	}
+
+	if !bytes.Equal(h[:4], magic) {
+		return nil, 0, ErrNotEncrypted
+	}
+
+	if h[4] != version {
+		return nil, 0, fmt.Errorf("unsupported version %d of encrypted asset", h[4])
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(h[5:9]))
+	if chunkSize <= 0 || chunkSize > 16<<20 {
+		return nil, 0, fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+
+	return h, chunkSize, nil
+}
+
+// nonce is the nonce prefix | chunk index | last chunk flag, the flag prevents truncating the stream
+func (h header) nonce(index uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, h[9:])
+	binary.BigEndian.PutUint32(nonce[prefixSize:], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// streamReader seals or opens the chunks read from the source
+type streamReader struct {
+	src       *bufio.Reader
+	aead      cipher.AEAD
+	header    header
+	chunkSize int
+	seal      bool
+
+	index uint32
+	buf   []byte
+	out   []byte
+	done  bool
+}
+
+// NewEncryptReader returns a reader of the data of r encrypted with the key
+func NewEncryptReader(key []byte, r io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := newHeader(ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		src:       bufio.NewReader(r),
+		aead:      aead,
+		header:    h,
+		chunkSize: ChunkSize,
+		seal:      true,
+		buf:       make([]byte, ChunkSize),
+		out:       append([]byte{}, h...),
+	}, nil
+}
+
+// NewDecryptReader returns a reader of the data of r decrypted with the key,
+// Read returns an error if the data is modified or truncated
+func NewDecryptReader(key []byte, r io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	h, chunkSize, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		src:       bufio.NewReader(r),
+		aead:      aead,
+		header:    h,
+		chunkSize: chunkSize + aead.Overhead(),
+		buf:       make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	return n, nil
+}
+
+// next seals or opens the next chunk, the chunk is the last one if the source ends after it
+func (s *streamReader) next() error {
+	n, err := io.ReadFull(s.src, s.buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	last := n < s.chunkSize
+	if !last {
+		if _, err := s.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	nonce := s.header.nonce(s.index, last)
+	if s.seal {
+		s.out = s.aead.Seal(s.buf[:0:0], nonce, s.buf[:n], s.header)
+	} else {
+		s.out, err = s.aead.Open(s.buf[:0:0], nonce, s.buf[:n], s.header)
+		if err != nil {
+			return fmt.Errorf("decrypt chunk %d: %w", s.index, err)
+		}
+	}
+
+	s.index++
+	s.done = last
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package erasure implements a systematic Reed-Solomon code over GF(2^8).
+//
+// Data is processed in stripes: each stripe reads dataShards*stripeSize bytes
+// from the source, splits them into dataShards pieces and computes parityShards
+// parity pieces, every piece is appended to its own shard stream. Any
+// dataShards of the dataShards+parityShards shards are enough to rebuild the data.
+package erasure
+
+import (
+	"fmt"
+	"io"
+)
+
+// MaxTotalShards is the largest number of shards supported by GF(2^8)
+const MaxTotalShards = 256
+
+// Codec encodes and decodes shard streams
+type Codec struct {
+	dataShards   int
+	parityShards int
+	// encoding matrix, the top dataShards rows form an identity matrix
+	matrix matrix
+}
+
+// New creates a codec with the given number of data and parity shards
+func New(dataShards, parityShards int) (*Codec, error) {
+	if dataShards <= 0 || parityShards <= 0 {
+		return nil, fmt.Errorf("data shards %d and parity shards %d must be greater than 0", dataShards, parityShards)
+	}
+
+	if dataShards+parityShards > MaxTotalShards {
+		return nil, fmt.Errorf("total shards %d exceeds the limit %d", dataShards+parityShards, MaxTotalShards)
+	}
+
+	total := dataShards + parityShards
+	vm := vandermonde(total, dataShards)
+
+	top, err := vm.subMatrix(0, 0, dataShards, dataShards).invert()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := vm.multiply(top)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Codec{dataShards: dataShards, parityShards: parityShards, matrix: m}, nil
+}
+
+// DataShards returns the number of data shards
+func (c *Codec) DataShards() int {
+	return c.dataShards
+}
+
+// ParityShards returns the number of parity shards
+func (c *Codec) ParityShards() int {
+	return c.parityShards
+}
+
+// TotalShards returns the number of data and parity shards
+func (c *Codec) TotalShards() int {
+	return c.dataShards + c.parityShards
+}
+
+// ShardSize returns the size of every shard for the data of the given size
+func (c *Codec) ShardSize(size int64, stripeSize int) int64 {
+	stripeDataSize := int64(c.dataShards * stripeSize)
+	stripes := (size + stripeDataSize - 1) / stripeDataSize
+	return stripes * int64(stripeSize)
+}
+
+// Encode reads all data from r and writes the shards to writers, which must contain TotalShards writers.
+// It returns the number of bytes read from r.
+func (c *Codec) Encode(r io.Reader, writers []io.Writer, stripeSize int) (int64, error) {
+	if len(writers) != c.TotalShards() {
+		return 0, fmt.Errorf("require %d shard writers, got %d", c.TotalShards(), len(writers))
+	}
+
+	if stripeSize <= 0 {
+		return 0, fmt.Errorf("invalid stripe size %d", stripeSize)
+	}
+
+	buf := make([]byte, stripeSize*c.TotalShards())
+	shards := make([][]byte, c.TotalShards())
+	for i := range shards {
+		shards[i] = buf[i*stripeSize : (i+1)*stripeSize]
+	}
+
+	dataBuf := buf[:stripeSize*c.dataShards]
+	total := int64(0)
+
+	for {
+		n, err := io.ReadFull(r, dataBuf)
+		if n == 0 && err == io.EOF {
+			break
+		}
+
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return total, err
+		}
+
+		total += int64(n)
+
+		// pad the last stripe with zero
+		for i := n; i < len(dataBuf); i++ {
+			dataBuf[i] = 0
+		}
+
+		c.encodeStripe(shards)
+
+		for i, w := range writers {
+			if _, err := w.Write(shards[i]); err != nil {
+				return total, fmt.Errorf("write shard %d: %w", i, err)
+			}
+		}
+
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+// encodeStripe computes the parity pieces of a stripe from its data pieces
+func (c *Codec) encodeStripe(shards [][]byte) {
+	for p := 0; p < c.parityShards; p++ {
+		out := shards[c.dataShards+p]
+		for i := range out {
+			out[i] = 0
+		}
+
+		row := c.matrix[c.dataShards+p]
+		for d := 0; d < c.dataShards; d++ {
+			mulSliceXor(row[d], shards[d], out)
+		}
+	}
+}
+
+// Decode rebuilds the original data of the given size from the shard readers and writes it to w.
+// readers is indexed by shard index, missing shards must be nil, at least DataShards readers are required.
+func (c *Codec) Decode(readers []io.Reader, w io.Writer, size int64, stripeSize int) error {
+	if len(readers) != c.TotalShards() {
+		return fmt.Errorf("require %d shard readers, got %d", c.TotalShards(), len(readers))
+	}
+
+	if stripeSize <= 0 {
+		return fmt.Errorf("invalid stripe size %d", stripeSize)
+	}
+
+	indexes := make([]int, 0, c.dataShards)
+	for i, r := range readers {
+		if r == nil {
+			continue
+		}
+
+		indexes = append(indexes, i)
+		if len(indexes) == c.dataShards {
+			break
+		}
+	}
+
+	if len(indexes) < c.dataShards {
+		return fmt.Errorf("not enough shards, require %d, got %d", c.dataShards, len(indexes))
+	}
+
+	decodeMatrix, err := c.decodeMatrix(indexes)
+	if err != nil {
+		return err
+	}
+
+	in := make([][]byte, c.dataShards)
+	for i := range in {
+		in[i] = make([]byte, stripeSize)
+	}
+
+	out := make([]byte, stripeSize*c.dataShards)
+
+	remaining := size
+	for remaining > 0 {
+		for i, index := range indexes {
+			if _, err := io.ReadFull(readers[index], in[i]); err != nil {
+				return fmt.Errorf("read shard %d: %w", index, err)
+			}
+		}
+
+		for d := 0; d < c.dataShards; d++ {
+			piece := out[d*stripeSize : (d+1)*stripeSize]
+			if decodeMatrix == nil {
+				copy(piece, in[d])
+				continue
+			}
+
+			for i := range piece {
+				piece[i] = 0
+			}
+
+			for i := range indexes {
+				mulSliceXor(decodeMatrix[d][i], in[i], piece)
+			}
+		}
+
+		n := int64(len(out))
+		if remaining < n {
+			n = remaining
+		}
+
+		if _, err := w.Write(out[:n]); err != nil {
+			return err
+		}
+
+		remaining -= n
+	}
+
+	return nil
+}
+
+// decodeMatrix returns the matrix that maps the pieces of the given shards to the data pieces,
+// nil means the given shards are the data shards themselves
+func (c *Codec) decodeMatrix(indexes []int) (matrix, error) {
+	isDataShards := true
+	for i, index := range indexes {
+		if i != index {
+			isDataShards = false
+			break
+		}
+	}
+
+	if isDataShards {
+		return nil, nil
+	}
+
+	sub := newMatrix(c.dataShards, c.dataShards)
+	for i, index := range indexes {
+		copy(sub[i], c.matrix[index])
+	}
+
+	return sub.invert()
+}
@@ -0,0 +1,83 @@
+package erasure
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	const (
+		dataShards   = 4
+		parityShards = 2
+		stripeSize   = 1024
+	)
+
+	codec, err := New(dataShards, parityShards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := []int{0, 1, stripeSize*dataShards - 1, stripeSize * dataShards, 10*stripeSize*dataShards + 123}
+	for _, size := range sizes {
+		data := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(data)
+
+		bufs := make([]*bytes.Buffer, codec.TotalShards())
+		writers := make([]io.Writer, codec.TotalShards())
+		for i := range bufs {
+			bufs[i] = &bytes.Buffer{}
+			writers[i] = bufs[i]
+		}
+
+		n, err := codec.Encode(bytes.NewReader(data), writers, stripeSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != int64(size) {
+			t.Fatalf("encode read %d bytes, expect %d", n, size)
+		}
+
+		for i, buf := range bufs {
+			if int64(buf.Len()) != codec.ShardSize(int64(size), stripeSize) {
+				t.Fatalf("shard %d size %d, expect %d", i, buf.Len(), codec.ShardSize(int64(size), stripeSize))
+			}
+		}
+
+		// lose every combination of parityShards shards
+		for a := 0; a < codec.TotalShards(); a++ {
+			for b := a + 1; b < codec.TotalShards(); b++ {
+				readers := make([]io.Reader, codec.TotalShards())
+				for i := range readers {
+					if i == a || i == b {
+						continue
+					}
+					readers[i] = bytes.NewReader(bufs[i].Bytes())
+				}
+
+				out := &bytes.Buffer{}
+				if err := codec.Decode(readers, out, int64(size), stripeSize); err != nil {
+					t.Fatalf("size %d, lost shard %d,%d: %s", size, a, b, err.Error())
+				}
+
+				if !bytes.Equal(out.Bytes(), data) {
+					t.Fatalf("size %d, lost shard %d,%d: decoded data mismatch", size, a, b)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeNotEnoughShards(t *testing.T) {
+	codec, err := New(3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readers := []io.Reader{bytes.NewReader(nil), nil, nil, bytes.NewReader(nil)}
+	if err := codec.Decode(readers, io.Discard, 10, 16); err == nil {
+		t.Fatal("expect error when shards are not enough")
+	}
+}
@@ -0,0 +1,77 @@
+package erasure
+
+// galois field GF(2^8) arithmetic with the generating polynomial x^8+x^4+x^3+x^2+1 (0x11d)
+
+const (
+	fieldSize      = 256
+	generatingPoly = 0x11d
+)
+
+var (
+	expTable [fieldSize * 2]byte
+	logTable [fieldSize]byte
+	// mulTable[a][b] = a*b
+	mulTable [fieldSize][fieldSize]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < fieldSize-1; i++ {
+		expTable[i] = byte(x)
+		logTable[x] = byte(i)
+		x <<= 1
+		if x >= fieldSize {
+			x ^= generatingPoly
+		}
+	}
+
+	// duplicate the exp table so that galMul does not need a modulo
+	for i := fieldSize - 1; i < len(expTable); i++ {
+		expTable[i] = expTable[i-(fieldSize-1)]
+	}
+
+	for a := 0; a < fieldSize; a++ {
+		for b := 0; b < fieldSize; b++ {
+			mulTable[a][b] = galMul(byte(a), byte(b))
+		}
+	}
+}
+
+// galMul multiplies two elements of the field
+func galMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// galDiv divides a by b, b must not be zero
+func galDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+(fieldSize-1)-int(logTable[b])]
+}
+
+// galExp returns a raised to the power n
+func galExp(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])*n)%(fieldSize-1)]
+}
+
+// mulSliceXor computes out ^= c*in for every byte
+func mulSliceXor(c byte, in, out []byte) {
+	if c == 0 {
+		return
+	}
+
+	mt := &mulTable[c]
+	for i, v := range in {
+		out[i] ^= mt[v]
+	}
+}
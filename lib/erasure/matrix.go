@@ -0,0 +1,104 @@
+package erasure
+
+import (
+	"fmt"
+)
+
+type matrix [][]byte
+
+func newMatrix(rows, cols int) matrix {
+	m := make(matrix, rows)
+	for r := range m {
+		m[r] = make([]byte, cols)
+	}
+	return m
+}
+
+// vandermonde returns a rows x cols vandermonde matrix, any cols rows of it are linearly independent
+func vandermonde(rows, cols int) matrix {
+	m := newMatrix(rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			m[r][c] = galExp(byte(r), c)
+		}
+	}
+	return m
+}
+
+// multiply returns m * right
+func (m matrix) multiply(right matrix) (matrix, error) {
+	if len(m[0]) != len(right) {
+		return nil, fmt.Errorf("columns on left (%d) is different than rows on right (%d)", len(m[0]), len(right))
+	}
+
+	result := newMatrix(len(m), len(right[0]))
+	for r := range result {
+		for c := range result[r] {
+			var value byte
+			for i := range m[r] {
+				value ^= galMul(m[r][i], right[i][c])
+			}
+			result[r][c] = value
+		}
+	}
+	return result, nil
+}
+
+// subMatrix returns the rows [rmin, rmax) and columns [cmin, cmax) of m
+func (m matrix) subMatrix(rmin, cmin, rmax, cmax int) matrix {
+	result := newMatrix(rmax-rmin, cmax-cmin)
+	for r := rmin; r < rmax; r++ {
+		copy(result[r-rmin], m[r][cmin:cmax])
+	}
+	return result
+}
+
+// invert returns the inverse of a square matrix using gauss-jordan elimination
+func (m matrix) invert() (matrix, error) {
+	size := len(m)
+	if size == 0 || size != len(m[0]) {
+		return nil, fmt.Errorf("only square matrices can be inverted")
+	}
+
+	// work on [m | I]
+	work := newMatrix(size, size*2)
+	for r := 0; r < size; r++ {
+		copy(work[r], m[r])
+		work[r][size+r] = 1
+	}
+
+	for r := 0; r < size; r++ {
+		if work[r][r] == 0 {
+			for below := r + 1; below < size; below++ {
+				if work[below][r] != 0 {
+					work[r], work[below] = work[below], work[r]
+					break
+				}
+			}
+		}
+
+		if work[r][r] == 0 {
+			return nil, fmt.Errorf("matrix is singular")
+		}
+
+		if work[r][r] != 1 {
+			scale := galDiv(1, work[r][r])
+			for c := range work[r] {
+				work[r][c] = galMul(work[r][c], scale)
+			}
+		}
+
+		for other := 0; other < size; other++ {
+			if other == r || work[other][r] == 0 {
+				continue
+			}
+
+			scale := work[other][r]
+			for c := range work[other] {
+				work[other][c] ^= galMul(scale, work[r][c])
+			}
+		}
+	}
+
+	return work.subMatrix(0, size, size, size*2), nil
+}
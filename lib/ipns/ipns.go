@@ -0,0 +1,130 @@
+// Package ipns signs and verifies the records of the mutable names.
+//
+// A name is the CIDv1 of the protobuf encoded ed25519 public key with the libp2p-key codec,
+// encoded in base36 like the IPNS names, so it is a valid lower case DNS label.
+// DNSLink TXT records point a domain to an /ipfs/ path or an /ipns/ name.
+package ipns
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multibase"
+	mh "github.com/multiformats/go-multihash"
+)
+
+const (
+	// IPFSPrefix is the prefix of the immutable paths
+	IPFSPrefix = "/ipfs/"
+	// IPNSPrefix is the prefix of the mutable paths
+	IPNSPrefix = "/ipns/"
+	// DNSLinkPrefix is the prefix of the value of a DNSLink TXT record
+	DNSLinkPrefix = "dnslink="
+	// DNSLinkLabel is the label of the DNSLink TXT record of a domain
+	DNSLinkLabel = "_dnslink"
+)
+
+// header of the protobuf encoded libp2p ed25519 public key, type 1 and 32 bytes of data
+var keyHeader = []byte{0x08, 0x01, 0x12, ed25519.PublicKeySize}
+
+// Name returns the name of the public key
+func Name(pub ed25519.PublicKey) (string, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid public key size %d", len(pub))
+	}
+
+	hash, err := mh.Sum(append(append([]byte{}, keyHeader...), pub...), mh.IDENTITY, -1)
+	if err != nil {
+		return "", err
+	}
+
+	return cid.NewCidV1(cid.Libp2pKey, hash).Encode(multibase.MustNewEncoder(multibase.Base36)), nil
+}
+
+// IsName checks if s is a name instead of a domain
+func IsName(s string) bool {
+	c, err := cid.Decode(s)
+	return err == nil && c.Type() == cid.Libp2pKey
+}
+
+// Sign sets the name, public key and signature of the record
+func Sign(key ed25519.PrivateKey, record *types.NameRecord) error {
+	pub := key.Public().(ed25519.PublicKey)
+	name, err := Name(pub)
+	if err != nil {
+		return err
+	}
+
+	record.Name = name
+	record.PublicKey = pub
+	record.Signature = ed25519.Sign(key, record.SignedMessage())
+	return nil
+}
+
+// Verify checks the record is signed by the key of the name and points to an /ipfs/ path
+func Verify(record *types.NameRecord) error {
+	name, err := Name(record.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	if name != record.Name {
+		return fmt.Errorf("public key of name %s does not match", record.Name)
+	}
+
+	if !ed25519.Verify(record.PublicKey, record.SignedMessage(), record.Signature) {
+		return fmt.Errorf("invalid signature of name %s", record.Name)
+	}
+
+	if _, _, err := ParsePath(record.Value); err != nil {
+		return err
+	}
+
+	if record.TTL < 0 {
+		return fmt.Errorf("invalid ttl %s", record.TTL)
+	}
+
+	return nil
+}
+
+// Expired checks if the record is expired at t
+func Expired(record *types.NameRecord, t time.Time) bool {
+	return !record.Validity.After(t)
+}
+
+// ParsePath splits an /ipfs/<cid>[/<path>] path to the root cid and the rest path
+func ParsePath(p string) (cid.Cid, string, error) {
+	if !strings.HasPrefix(p, IPFSPrefix) {
+		return cid.Undef, "", fmt.Errorf("path %s is not an /ipfs/ path", p)
+	}
+
+	root, rest, _ := strings.Cut(strings.TrimPrefix(p, IPFSPrefix), "/")
+	c, err := cid.Decode(root)
+	if err != nil {
+		return cid.Undef, "", fmt.Errorf("path %s decode cid error %s", p, err.Error())
+	}
+
+	if len(rest) > 0 {
+		rest = "/" + rest
+	}
+
+	return c, rest, nil
+}
+
+// ParseDNSLink returns the path of the value of a DNSLink TXT record, an /ipfs/ path or an /ipns/ name
+func ParseDNSLink(txt string) (string, error) {
+	if !strings.HasPrefix(txt, DNSLinkPrefix) {
+		return "", fmt.Errorf("%s is not a dnslink", txt)
+	}
+
+	value := strings.TrimSpace(strings.TrimPrefix(txt, DNSLinkPrefix))
+	if !strings.HasPrefix(value, IPFSPrefix) && !strings.HasPrefix(value, IPNSPrefix) {
+		return "", fmt.Errorf("unsupported dnslink %s", value)
+	}
+
+	return value, nil
+}
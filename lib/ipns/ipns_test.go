@@ -0,0 +1,76 @@
+package ipns
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestSignVerify(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &types.NameRecord{
+		Value:    "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku/index.html",
+		Sequence: 1,
+		Validity: time.Now().Add(time.Hour),
+		TTL:      time.Minute,
+	}
+	if err := Sign(key, record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the names are the same as the ipns names of the ed25519 keys
+	if !strings.HasPrefix(record.Name, "k51") || !IsName(record.Name) || IsName("example.com") {
+		t.Fatalf("unexpected name %s", record.Name)
+	}
+
+	if err := Verify(record); err != nil {
+		t.Fatal(err)
+	}
+
+	root, rest, err := ParsePath(record.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.String() != "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" || rest != "/index.html" {
+		t.Fatalf("unexpected root %s, rest %s", root.String(), rest)
+	}
+
+	// the record is valid after the database drops the sub-seconds of the validity
+	stored := *record
+	stored.Validity = time.Unix(record.Validity.Unix(), 0)
+	if err := Verify(&stored); err != nil {
+		t.Fatal(err)
+	}
+
+	record.Sequence++
+	if err := Verify(record); err == nil {
+		t.Fatal("verify modified record without error")
+	}
+	record.Sequence--
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	record.PublicKey = other.Public().(ed25519.PublicKey)
+	if err := Verify(record); err == nil {
+		t.Fatal("verify record of other key without error")
+	}
+}
+
+func TestParseDNSLink(t *testing.T) {
+	for txt, ok := range map[string]bool{
+		"dnslink=/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku": true,
+		"dnslink=/ipns/example.com": true,
+		"dnslink=/http/example.com": false,
+		"v=spf1 -all":               false,
+	} {
+		if _, err := ParseDNSLink(txt); (err == nil) != ok {
+			t.Errorf("ParseDNSLink %s error %v", txt, err)
+		}
+	}
+}
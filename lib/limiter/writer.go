@@ -0,0 +1,50 @@
+package limiter
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type writer struct {
+	w       io.Writer
+	limiter *rate.Limiter
+}
+
+// NewWriter returns a writer that is rate limited by
+// the given token bucket. Each token in the bucket
+// represents one byte, writes larger than the burst are split.
+func NewWriter(w io.Writer, l *rate.Limiter) io.Writer {
+	return &writer{
+		w:       w,
+		limiter: l,
+	}
+}
+
+func (w *writer) Write(buf []byte) (int, error) {
+	written := 0
+	for len(buf) > 0 {
+		n := len(buf)
+		if burst := w.limiter.Burst(); n > burst {
+			n = burst
+		}
+
+		now := time.Now()
+		rv := w.limiter.ReserveN(now, n)
+		if !rv.OK() {
+			return written, fmt.Errorf("exceeds limiter's burst")
+		}
+		time.Sleep(rv.DelayFrom(now))
+
+		n, err := w.w.Write(buf[:n])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		buf = buf[n:]
+	}
+
+	return written, nil
+}
@@ -0,0 +1,119 @@
+// Package merkle implements a binary sha256 merkle tree with inclusion proofs.
+//
+// The leaves are hashed as H(0x00||data) and the inner nodes as H(0x01||left||right),
+// so a leaf can not be forged as an inner node. The last node of an odd level
+// is promoted to the next level without hashing.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Tree is a merkle tree, levels[0] are the leaf hashes and the last level is the root
+type Tree struct {
+	levels [][][]byte
+}
+
+// NewTree builds the tree of the leaves
+func NewTree(leaves [][]byte) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("merkle tree can not be empty")
+	}
+
+	level := make([][]byte, 0, len(leaves))
+	for _, leaf := range leaves {
+		level = append(level, hashLeaf(leaf))
+	}
+
+	t := &Tree{levels: [][][]byte{level}}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+
+	return t, nil
+}
+
+// Root returns the root hash of the tree
+func (t *Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Leaves returns the count of leaves
+func (t *Tree) Leaves() int {
+	return len(t.levels[0])
+}
+
+// Proof returns the sibling hashes from the leaf to the root, the promoted levels have no sibling
+func (t *Tree) Proof(index int) ([][]byte, error) {
+	if index < 0 || index >= t.Leaves() {
+		return nil, fmt.Errorf("leaf index %d out of range %d", index, t.Leaves())
+	}
+
+	proof := make([][]byte, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// Verify checks the leaf is the index-th of count leaves in the tree with the root
+func Verify(root, leaf []byte, index, count int, proof [][]byte) bool {
+	if index < 0 || index >= count {
+		return false
+	}
+
+	hash := hashLeaf(leaf)
+	for width := count; width > 1; width = (width + 1) / 2 {
+		sibling := index ^ 1
+		if sibling < width {
+			if len(proof) == 0 {
+				return false
+			}
+
+			if index%2 == 0 {
+				hash = hashNode(hash, proof[0])
+			} else {
+				hash = hashNode(proof[0], hash)
+			}
+			proof = proof[1:]
+		}
+		index /= 2
+	}
+
+	return len(proof) == 0 && bytes.Equal(hash, root)
+}
+
+func hashLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func hashNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProof(t *testing.T) {
+	for count := 1; count <= 9; count++ {
+		leaves := make([][]byte, 0, count)
+		for i := 0; i < count; i++ {
+			leaves = append(leaves, []byte(fmt.Sprintf("leaf %d", i)))
+		}
+
+		tree, err := NewTree(leaves)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, leaf := range leaves {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !Verify(tree.Root(), leaf, i, count, proof) {
+				t.Fatalf("verify leaf %d of %d failed", i, count)
+			}
+
+			if Verify(tree.Root(), []byte("other"), i, count, proof) {
+				t.Fatalf("verify tampered leaf %d of %d succeeded", i, count)
+			}
+
+			if count > 1 && Verify(tree.Root(), leaf, (i+1)%count, count, proof) {
+				t.Fatalf("verify leaf %d of %d at wrong index succeeded", i, count)
+			}
+		}
+	}
+
+	if _, err := NewTree(nil); err == nil {
+		t.Fatal("expect error for empty tree")
+	}
+}
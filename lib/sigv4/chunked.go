@@ -0,0 +1,92 @@
+package sigv4
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// chunkedReader decodes the payload in aws-chunked encoding,
+// every chunk is "<hex-size>;chunk-signature=<signature>\r\n<data>\r\n" and the last chunk is empty.
+// The chunk signatures are not verified, the seed signature covers the headers of the request.
+type chunkedReader struct {
+	r         *bufio.Reader
+	remaining int64
+	done      bool
+}
+
+// NewChunkedReader returns a reader of the data of an aws-chunked payload
+func NewChunkedReader(r io.Reader) io.Reader {
+	return &chunkedReader{r: bufio.NewReader(r)}
+}
+
+func (cr *chunkedReader) Read(p []byte) (int, error) {
+	if cr.done {
+		return 0, io.EOF
+	}
+
+	if cr.remaining == 0 {
+		if err := cr.nextChunk(); err != nil {
+			return 0, err
+		}
+
+		if cr.done {
+			return 0, io.EOF
+		}
+	}
+
+	if int64(len(p)) > cr.remaining {
+		p = p[:cr.remaining]
+	}
+
+	n, err := cr.r.Read(p)
+	cr.remaining -= int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err == nil && cr.remaining == 0 {
+		err = cr.readCRLF()
+	}
+	return n, err
+}
+
+func (cr *chunkedReader) nextChunk() error {
+	line, err := cr.r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	header := strings.TrimRight(line, "\r\n")
+	sizeHex := strings.SplitN(header, ";", 2)[0]
+
+	size, err := strconv.ParseInt(sizeHex, 16, 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("invalid chunk header %s", header)
+	}
+
+	if size == 0 {
+		cr.done = true
+		return nil
+	}
+
+	cr.remaining = size
+	return nil
+}
+
+func (cr *chunkedReader) readCRLF() error {
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(cr.r, buf); err != nil {
+		return err
+	}
+
+	if string(buf) != "\r\n" {
+		return fmt.Errorf("invalid chunk terminator")
+	}
+	return nil
+}
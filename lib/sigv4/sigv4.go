@@ -0,0 +1,267 @@
+// Package sigv4 parses the AWS signature version 4 of http requests.
+//
+// The string to sign is built from the request by Parse, the signature is
+// computed by the holder of the secret key with Sign and compared with the
+// signature carried by the request.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Algorithm is the only supported signing algorithm
+	Algorithm = "AWS4-HMAC-SHA256"
+	// UnsignedPayload means the payload is not included in the signature
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// StreamingPayload means the payload is sent in aws-chunked encoding
+	StreamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+	timeFormat    = "20060102T150405Z"
+	dateFormat    = "20060102"
+	scopeTerm     = "aws4_request"
+	maxClockSkew  = 15 * time.Minute
+	maxPresignAge = 7 * 24 * time.Hour
+)
+
+// Request is the signature of a request
+type Request struct {
+	AccessKeyID string
+	// Date is the date of credential scope, in the format yyyymmdd
+	Date          string
+	Region        string
+	Service       string
+	SignedHeaders []string
+	Signature     string
+	// PayloadHash is the hex encoded sha256 of payload, UnsignedPayload or StreamingPayload
+	PayloadHash  string
+	StringToSign string
+}
+
+// IsSigned checks if the request carries a signature version 4 in the header or the query
+func IsSigned(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Authorization"), Algorithm) || r.URL.Query().Get("X-Amz-Algorithm") == Algorithm
+}
+
+// Parse parses the signature of the request and builds the string to sign,
+// now is used to check the time of the request
+func Parse(r *http.Request, now time.Time) (*Request, error) {
+	if r.URL.Query().Get("X-Amz-Algorithm") == Algorithm {
+		return parsePresigned(r, now)
+	}
+	return parseHeader(r, now)
+}
+
+// Sign returns the hex encoded signature of stringToSign with the secret key
+func Sign(secret, date, region, service, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, scopeTerm)
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func parseHeader(r *http.Request, now time.Time) (*Request, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, Algorithm+" ") {
+		return nil, fmt.Errorf("unsupported authorization %s", auth)
+	}
+
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(auth, Algorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid authorization field %s", field)
+		}
+		fields[kv[0]] = kv[1]
+	}
+
+	req := &Request{Signature: fields["Signature"], PayloadHash: r.Header.Get("X-Amz-Content-Sha256")}
+	if err := req.parseCredential(fields["Credential"]); err != nil {
+		return nil, err
+	}
+
+	if err := req.parseSignedHeaders(fields["SignedHeaders"]); err != nil {
+		return nil, err
+	}
+
+	if len(req.PayloadHash) == 0 {
+		return nil, fmt.Errorf("missing header X-Amz-Content-Sha256")
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	t, err := time.Parse(timeFormat, amzDate)
+	if err != nil {
+		if t, err = http.ParseTime(r.Header.Get("Date")); err != nil {
+			return nil, fmt.Errorf("missing or invalid request date")
+		}
+		amzDate = t.UTC().Format(timeFormat)
+	}
+
+	if now.Sub(t) > maxClockSkew || t.Sub(now) > maxClockSkew {
+		return nil, fmt.Errorf("request time %s is too skewed", amzDate)
+	}
+
+	req.StringToSign = req.stringToSign(r, amzDate, r.URL.Query())
+	return req, nil
+}
+
+func parsePresigned(r *http.Request, now time.Time) (*Request, error) {
+	query := r.URL.Query()
+
+	req := &Request{Signature: query.Get("X-Amz-Signature"), PayloadHash: UnsignedPayload}
+	if err := req.parseCredential(query.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+
+	if err := req.parseSignedHeaders(query.Get("X-Amz-SignedHeaders")); err != nil {
+		return nil, err
+	}
+
+	amzDate := query.Get("X-Amz-Date")
+	t, err := time.Parse(timeFormat, amzDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X-Amz-Date %s", amzDate)
+	}
+
+	expires, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxPresignAge {
+		return nil, fmt.Errorf("invalid X-Amz-Expires %s", query.Get("X-Amz-Expires"))
+	}
+
+	if t.Sub(now) > maxClockSkew {
+		return nil, fmt.Errorf("request time %s is too skewed", amzDate)
+	}
+
+	if now.After(t.Add(time.Duration(expires) * time.Second)) {
+		return nil, fmt.Errorf("request has expired")
+	}
+
+	query.Del("X-Amz-Signature")
+	req.StringToSign = req.stringToSign(r, amzDate, query)
+	return req, nil
+}
+
+// parseCredential parses the credential in the format <access-key-id>/<date>/<region>/<service>/aws4_request
+func (req *Request) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) < 5 || parts[len(parts)-1] != scopeTerm {
+		return fmt.Errorf("invalid credential %s", credential)
+	}
+
+	n := len(parts)
+	req.AccessKeyID = strings.Join(parts[:n-4], "/")
+	req.Date, req.Region, req.Service = parts[n-4], parts[n-3], parts[n-2]
+
+	if _, err := time.Parse(dateFormat, req.Date); err != nil {
+		return fmt.Errorf("invalid credential date %s", req.Date)
+	}
+	return nil
+}
+
+func (req *Request) parseSignedHeaders(signedHeaders string) error {
+	if len(signedHeaders) == 0 {
+		return fmt.Errorf("missing signed headers")
+	}
+
+	req.SignedHeaders = strings.Split(signedHeaders, ";")
+	for _, h := range req.SignedHeaders {
+		if h == "host" {
+			return nil
+		}
+	}
+	return fmt.Errorf("host must be signed")
+}
+
+func (req *Request) stringToSign(r *http.Request, amzDate string, query url.Values) string {
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r),
+		canonicalQuery(query),
+		req.canonicalHeaders(r),
+		strings.Join(req.SignedHeaders, ";"),
+		req.PayloadHash,
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join([]string{req.Date, req.Region, req.Service, scopeTerm}, "/")
+	return strings.Join([]string{Algorithm, amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+}
+
+// canonicalURI returns the path as sent by client, s3 does not normalize the path
+func canonicalURI(r *http.Request) string {
+	if p := r.URL.EscapedPath(); len(p) > 0 {
+		return p
+	}
+	return "/"
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func (req *Request) canonicalHeaders(r *http.Request) string {
+	var b strings.Builder
+	for _, h := range req.SignedHeaders {
+		var value string
+		switch h {
+		case "host":
+			value = r.Host
+			if len(value) == 0 {
+				value = r.URL.Host
+			}
+		case "content-length":
+			// the server removes content-length from the header
+			value = strconv.FormatInt(r.ContentLength, 10)
+		default:
+			values := make([]string, 0, len(r.Header.Values(h)))
+			for _, v := range r.Header.Values(h) {
+				values = append(values, strings.Join(strings.Fields(v), " "))
+			}
+			value = strings.Join(values, ",")
+		}
+
+		b.WriteString(h)
+		b.WriteString(":")
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// escape encodes all characters except the unreserved characters of RFC 3986
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
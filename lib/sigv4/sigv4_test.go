@@ -0,0 +1,115 @@
+package sigv4
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	testAccessKey = "user:key"
+	testSecret    = "secret"
+)
+
+func newSigner() *v4.Signer {
+	signer := v4.NewSigner(credentials.NewStaticCredentials(testAccessKey, testSecret, ""))
+	// s3 does not escape the path twice
+	signer.DisableURIPathEscaping = true
+	return signer
+}
+
+// serverRequest converts a signed client request to the request received by server
+func serverRequest(req *http.Request) *http.Request {
+	r := httptest.NewRequest(req.Method, req.URL.RequestURI(), req.Body)
+	r.Host = req.URL.Host
+	r.Header = req.Header.Clone()
+	r.ContentLength = req.ContentLength
+	return r
+}
+
+func TestParseHeader(t *testing.T) {
+	now := time.Now()
+	body := []byte("hello titan")
+
+	req, err := http.NewRequest(http.MethodPut, "https://candidate.titan:9000/bucket/dir/a%20b+c.txt?x-id=PutObject&uploadId=a%2Fb", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Amz-Meta-Note", "  multiple   spaces ")
+
+	if _, err := newSigner().Sign(req, bytes.NewReader(body), "s3", "us-east-1", now); err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := Parse(serverRequest(req), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sr.AccessKeyID != testAccessKey || sr.Region != "us-east-1" || sr.Service != "s3" {
+		t.Fatalf("unexpected credential %s/%s/%s", sr.AccessKeyID, sr.Region, sr.Service)
+	}
+
+	if Sign(testSecret, sr.Date, sr.Region, sr.Service, sr.StringToSign) != sr.Signature {
+		t.Fatal("signature mismatch")
+	}
+
+	if Sign("wrong", sr.Date, sr.Region, sr.Service, sr.StringToSign) == sr.Signature {
+		t.Fatal("signature of wrong secret should mismatch")
+	}
+
+	if _, err := Parse(serverRequest(req), now.Add(time.Hour)); err == nil {
+		t.Fatal("expect error for skewed request")
+	}
+}
+
+func TestParsePresigned(t *testing.T) {
+	now := time.Now()
+
+	req, err := http.NewRequest(http.MethodGet, "https://candidate.titan/bucket/object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newSigner().Presign(req, nil, "s3", "us-east-1", 10*time.Minute, now); err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := Parse(serverRequest(req), now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if Sign(testSecret, sr.Date, sr.Region, sr.Service, sr.StringToSign) != sr.Signature {
+		t.Fatal("signature mismatch")
+	}
+
+	if _, err := Parse(serverRequest(req), now.Add(11*time.Minute)); err == nil {
+		t.Fatal("expect error for expired request")
+	}
+}
+
+func TestChunkedReader(t *testing.T) {
+	payload := "5;chunk-signature=aaaa\r\nhello\r\n6;chunk-signature=bbbb\r\n titan\r\n0;chunk-signature=cccc\r\n\r\n"
+
+	data, err := io.ReadAll(NewChunkedReader(strings.NewReader(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello titan" {
+		t.Fatalf("decoded %q", data)
+	}
+
+	if _, err := io.ReadAll(NewChunkedReader(strings.NewReader("5;chunk-signature=aaaa\r\nhel"))); err == nil {
+		t.Fatal("expect error for truncated payload")
+	}
+}
@@ -14,6 +14,9 @@ import (
 // Distribution
 var defaultMillisecondsDistribution = view.Distribution(0.01, 0.05, 0.1, 0.3, 0.6, 0.8, 1, 2, 3, 4, 5, 6, 8, 10, 13, 16, 20, 25, 30, 40, 50, 65, 80, 100, 130, 160, 200, 250, 300, 400, 500, 650, 800, 1000, 2000, 3000, 4000, 5000, 7500, 10000, 20000, 50000, 100000)
 
+// the assets stay in a state from seconds to days
+var defaultSecondsDistribution = view.Distribution(1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400, 28800, 86400, 172800)
+
 // Global Tags
 var (
 	// common
@@ -24,6 +27,20 @@ var (
 
 	Endpoint, _     = tag.NewKey("endpoint")
 	APIInterface, _ = tag.NewKey("api") // to distinguish between gateway api and full node api endpoint calls
+
+	// scheduler
+	AssetState, _ = tag.NewKey("asset_state")
+	Status, _     = tag.NewKey("status")
+	Reason, _     = tag.NewKey("reason")
+	NATType, _    = tag.NewKey("nat_type")
+	Area, _       = tag.NewKey("area")
+
+	// edge and candidate
+	Format, _      = tag.NewKey("format")
+	Source, _      = tag.NewKey("source")
+	ErrorKind, _   = tag.NewKey("error_kind")
+	CacheResult, _ = tag.NewKey("cache_result")
+	AssetsPath, _  = tag.NewKey("assets_path")
 )
 
 // Measures
@@ -31,6 +48,23 @@ var (
 	// common
 	TitanInfo          = stats.Int64("info", "Arbitrary counter to tag titan info to", stats.UnitDimensionless)
 	APIRequestDuration = stats.Float64("api/request_duration_ms", "Duration of API requests", stats.UnitMilliseconds)
+
+	// scheduler
+	Assets             = stats.Int64("scheduler/assets", "Number of assets in each state", stats.UnitDimensionless)
+	PullQueueDepth     = stats.Int64("scheduler/pull_queue_depth", "Number of assets being pulled", stats.UnitDimensionless)
+	AssetStateDuration = stats.Float64("scheduler/asset_state_duration_s", "Duration of assets staying in a state", stats.UnitSeconds)
+	ReplicaResults     = stats.Int64("scheduler/replica_results", "Counter of replicas pulled succeeded or failed", stats.UnitDimensionless)
+	OnlineNodes        = stats.Int64("scheduler/online_nodes", "Number of online nodes", stats.UnitDimensionless)
+	ValidationResults  = stats.Int64("scheduler/validation_results", "Counter of validation results", stats.UnitDimensionless)
+	WorkloadResults    = stats.Int64("scheduler/workload_results", "Counter of workload results", stats.UnitDimensionless)
+
+	// edge and candidate
+	BytesServed        = stats.Int64("node/bytes_served", "Bytes of assets served to the clients", stats.UnitBytes)
+	BlockFetchDuration = stats.Float64("node/block_fetch_duration_ms", "Duration of fetching a batch of blocks while pulling assets", stats.UnitMilliseconds)
+	BlockFetchErrors   = stats.Int64("node/block_fetch_errors", "Counter of errors while fetching blocks", stats.UnitDimensionless)
+	CacheLookups       = stats.Int64("node/cache_lookups", "Counter of the lookups of the asset index cache", stats.UnitDimensionless)
+	DiskUsed           = stats.Int64("node/disk_used_bytes", "Used bytes of the disk of the assets path", stats.UnitBytes)
+	DiskTotal          = stats.Int64("node/disk_total_bytes", "Total bytes of the disk of the assets path", stats.UnitBytes)
 )
 
 var (
@@ -47,6 +81,74 @@ var (
 		Aggregation: defaultMillisecondsDistribution,
 		TagKeys:     []tag.Key{APIInterface, Endpoint},
 	}
+
+	// scheduler
+	AssetsView = &view.View{
+		Measure:     Assets,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{AssetState},
+	}
+	PullQueueDepthView = &view.View{
+		Measure:     PullQueueDepth,
+		Aggregation: view.LastValue(),
+	}
+	AssetStateDurationView = &view.View{
+		Measure:     AssetStateDuration,
+		Aggregation: defaultSecondsDistribution,
+		TagKeys:     []tag.Key{AssetState},
+	}
+	ReplicaResultsView = &view.View{
+		Measure:     ReplicaResults,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Status, NodeType},
+	}
+	OnlineNodesView = &view.View{
+		Measure:     OnlineNodes,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{NodeType, NATType, Area},
+	}
+	ValidationResultsView = &view.View{
+		Measure:     ValidationResults,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Status},
+	}
+	WorkloadResultsView = &view.View{
+		Measure:     WorkloadResults,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Status, Reason},
+	}
+
+	// edge and candidate
+	BytesServedView = &view.View{
+		Measure:     BytesServed,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{Format},
+	}
+	BlockFetchDurationView = &view.View{
+		Measure:     BlockFetchDuration,
+		Aggregation: defaultMillisecondsDistribution,
+		TagKeys:     []tag.Key{Source},
+	}
+	BlockFetchErrorsView = &view.View{
+		Measure:     BlockFetchErrors,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{Source, ErrorKind},
+	}
+	CacheLookupsView = &view.View{
+		Measure:     CacheLookups,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{CacheResult},
+	}
+	DiskUsedView = &view.View{
+		Measure:     DiskUsed,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{AssetsPath},
+	}
+	DiskTotalView = &view.View{
+		Measure:     DiskTotal,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{AssetsPath},
+	}
 )
 
 // DefaultViews is an array of OpenCensus views for metric gathering purposes
@@ -59,11 +161,39 @@ var DefaultViews = func() []*view.View {
 	return views
 }()
 
+// SchedulerViews is an array of OpenCensus views of the scheduler
+var SchedulerViews = append([]*view.View{
+	AssetsView,
+	PullQueueDepthView,
+	AssetStateDurationView,
+	ReplicaResultsView,
+	OnlineNodesView,
+	ValidationResultsView,
+	WorkloadResultsView,
+}, DefaultViews...)
+
+// NodeViews is an array of OpenCensus views of the edge and candidate
+var NodeViews = append([]*view.View{
+	BytesServedView,
+	BlockFetchDurationView,
+	BlockFetchErrorsView,
+	CacheLookupsView,
+	DiskUsedView,
+	DiskTotalView,
+}, DefaultViews...)
+
 // SinceInMilliseconds returns the duration of time since the provide time as a float64.
 func SinceInMilliseconds(startTime time.Time) float64 {
 	return float64(time.Since(startTime).Nanoseconds()) / 1e6
 }
 
+// RecordWithTags records the measurement with the tags, the errors of invalid tags are ignored
+func RecordWithTags(ctx context.Context, tags []tag.Mutator, ms ...stats.Measurement) {
+	if err := stats.RecordWithTags(ctx, tags, ms...); err != nil {
+		log.Debugf("record metrics error: %s", err.Error())
+	}
+}
+
 // Timer is a function stopwatch, calling it starts the timer,
 // calling the returned function will record the duration.
 func Timer(ctx context.Context, m *stats.Float64Measure) func() {
@@ -0,0 +1,126 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/merkle"
+	"github.com/Filecoin-Titan/titan/node/asset/index"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	"golang.org/x/xerrors"
+)
+
+// maxChallengedLeaves is the max count of leaves proved in a validation
+const maxChallengedLeaves = 64
+
+// assetLeaves returns the sorted and deduplicated multihashes of the blocks in the car index of asset,
+// they are the leaves of the asset commitment
+func (m *Manager) assetLeaves(root cid.Cid) ([][]byte, error) {
+	idx, err := m.lru.assetIndex(root)
+	if err != nil {
+		return nil, xerrors.Errorf("asset index %w", err)
+	}
+
+	multiIndex, ok := idx.(*index.MultiIndexSorted)
+	if !ok {
+		return nil, xerrors.Errorf("idx is not titan MultiIndexSorted")
+	}
+
+	leaves := make([][]byte, 0, multiIndex.TotalRecordCount())
+	err = multiIndex.ForEach(func(mh multihash.Multihash, offset uint64) error {
+		leaves = append(leaves, mh)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i], leaves[j]) < 0 })
+
+	deduped := leaves[:0]
+	for i, leaf := range leaves {
+		if i > 0 && bytes.Equal(leaf, leaves[i-1]) {
+			continue
+		}
+		deduped = append(deduped, leaf)
+	}
+	return deduped, nil
+}
+
+// assetTree builds the merkle tree of the asset commitment
+func (m *Manager) assetTree(root cid.Cid) (*merkle.Tree, [][]byte, error) {
+	leaves, err := m.assetLeaves(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tree, err := merkle.NewTree(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, leaves, nil
+}
+
+// AssetCommitment returns the merkle commitment of the stored asset
+func (m *Manager) AssetCommitment(root cid.Cid) (*types.AssetCommitment, error) {
+	if v, ok := m.commitments.Load(root.Hash().String()); ok {
+		return v.(*types.AssetCommitment), nil
+	}
+
+	tree, _, err := m.assetTree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	commitment := &types.AssetCommitment{Hash: root.Hash().String(), Root: hex.EncodeToString(tree.Root()), Leaves: tree.Leaves()}
+	m.commitments.Store(root.Hash().String(), commitment)
+	return commitment, nil
+}
+
+// GetStorageProofs answers the challenges with the challenged blocks and their inclusion proofs
+func (m *Manager) GetStorageProofs(ctx context.Context, challenges []*types.StorageChallenge) ([]*types.StorageProof, error) {
+	proofs := make([]*types.StorageProof, 0)
+	for _, challenge := range challenges {
+		root, err := cid.Decode(challenge.AssetCID)
+		if err != nil {
+			return nil, err
+		}
+
+		tree, leaves, err := m.assetTree(root)
+		if err != nil {
+			return nil, xerrors.Errorf("asset %s tree %w", challenge.AssetCID, err)
+		}
+
+		for _, leaf := range challenge.Leaves {
+			if len(proofs) >= maxChallengedLeaves {
+				return proofs, nil
+			}
+
+			proof, err := tree.Proof(leaf)
+			if err != nil {
+				return nil, err
+			}
+
+			blk, err := m.lru.getBlock(ctx, root, cid.NewCidV1(cid.Raw, leaves[leaf]))
+			if err != nil {
+				return nil, xerrors.Errorf("get block of leaf %d %w", leaf, err)
+			}
+
+			proofs = append(proofs, &types.StorageProof{
+				AssetCID:  challenge.AssetCID,
+				Leaf:      leaf,
+				Root:      tree.Root(),
+				Leaves:    tree.Leaves(),
+				Multihash: leaves[leaf],
+				Data:      blk.RawData(),
+				Proof:     proof,
+			})
+		}
+	}
+
+	return proofs, nil
+}
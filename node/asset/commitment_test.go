@@ -0,0 +1,88 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/merkle"
+	"github.com/Filecoin-Titan/titan/node/asset/storage"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipfs/go-merkledag"
+	"github.com/multiformats/go-multihash"
+)
+
+func TestGetStorageProofs(t *testing.T) {
+	root := merkledag.NodeWithData([]byte("root"))
+	blks := make([]blocks.Block, 0)
+	for _, data := range []string{"leaf 1", "leaf 2", "leaf 3"} {
+		c, err := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: multihash.SHA2_256, MhLength: -1}.Sum([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaf, _ := blocks.NewBlockWithCid([]byte(data), c)
+		blks = append(blks, leaf)
+		if err = root.AddRawLink(data, &format.Link{Cid: c, Size: uint64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blks = append(blks, root)
+
+	dir := t.TempDir()
+	storageMgr, err := storage.NewManager(&storage.ManagerOptions{MetaDataPath: dir, AssetsPaths: []string{dir}, MinioConfig: &config.MinioConfig{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = storageMgr.StoreBlocks(context.Background(), root.Cid(), blks); err != nil {
+		t.Fatal(err)
+	}
+	if err = storageMgr.StoreBlocksToCar(context.Background(), root.Cid()); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := newLRUCache(storageMgr, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{lru: cache, commitments: &sync.Map{}}
+
+	commitment, err := m.AssetCommitment(root.Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commitment.Leaves != len(blks) {
+		t.Fatalf("commitment has %d leaves, expect %d", commitment.Leaves, len(blks))
+	}
+
+	challenges := []*types.StorageChallenge{{AssetCID: root.Cid().String(), Leaves: []int{0, 3}}}
+	proofs, err := m.GetStorageProofs(context.Background(), challenges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 2 {
+		t.Fatalf("%d proofs, expect 2", len(proofs))
+	}
+
+	for _, proof := range proofs {
+		sum, err := multihash.Sum(proof.Data, multihash.SHA2_256, -1)
+		if err != nil || !bytes.Equal(sum, proof.Multihash) {
+			t.Fatalf("leaf %d data does not match the multihash", proof.Leaf)
+		}
+
+		if !merkle.Verify(proof.Root, proof.Multihash, proof.Leaf, proof.Leaves, proof.Proof) {
+			t.Fatalf("leaf %d proof is invalid", proof.Leaf)
+		}
+	}
+
+	challenges = []*types.StorageChallenge{{AssetCID: root.Cid().String(), Leaves: []int{len(blks)}}}
+	if _, err = m.GetStorageProofs(context.Background(), challenges); err == nil {
+		t.Fatal("leaf out of the commitment is proved")
+	}
+}
@@ -27,6 +27,16 @@ func NewCandidateFetcher(httpClient *http.Client) *CandidateFetcher {
 	return &CandidateFetcher{httpClient: httpClient}
 }
 
+// Kind returns the kind of block source
+func (c *CandidateFetcher) Kind() string {
+	return KindCandidate
+}
+
+// Available checks if there are candidates to download from
+func (c *CandidateFetcher) Available(dss []*types.CandidateDownloadInfo) bool {
+	return len(dss) > 0
+}
+
 // FetchBlocks fetches blocks for the given cids and candidate download info
 func (c *CandidateFetcher) FetchBlocks(ctx context.Context, cids []string, dss []*types.CandidateDownloadInfo) ([]*ErrMsg, []*types.WorkloadReport, []blocks.Block, error) {
 	return c.retrieveBlocks(ctx, cids, dss)
@@ -111,7 +121,9 @@ func (c *CandidateFetcher) retrieveBlocks(ctx context.Context, cids []string, ds
 			startTime := time.Now()
 			b, err := c.fetchSingleBlock(ctx, ds, cidStr)
 			if err != nil {
-				errMsgs = append(errMsgs, &ErrMsg{Cid: cidStr, Source: ds.NodeID, Msg: err.Error()})
+				lock.Lock()
+				errMsgs = append(errMsgs, &ErrMsg{Cid: cidStr, Source: ds.NodeID, Kind: KindCandidate, Msg: err.Error()})
+				lock.Unlock()
 				return
 			}
 
@@ -0,0 +1,288 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+	carv2 "github.com/ipld/go-car/v2"
+)
+
+// maxCarHeaderSize is the max size of car v1 header
+const maxCarHeaderSize = 32 << 10
+
+var errCarIdleTimeout = errors.New("car stream idle timeout")
+
+// CarProgress records the position of a car stream, the stream can be resumed with a byte range from the same node
+type CarProgress struct {
+	// NodeID of the candidate which serves the car
+	NodeID string
+	// Offset of the next section in the car stream
+	Offset int64
+	// DataEnd is the end offset of the sections, zero means the sections end at EOF
+	DataEnd int64
+}
+
+// CarRequest is the request of fetching the whole dag of asset in a car
+type CarRequest struct {
+	Root   cid.Cid
+	Source *types.CandidateDownloadInfo
+	// Progress is updated after every batch of blocks is handled
+	Progress *CarProgress
+	// BatchSize is the count of blocks handled in a batch
+	BatchSize int
+	// IdleTimeout cancels the stream if no section is received in the duration
+	IdleTimeout time.Duration
+}
+
+// CarFetcher fetches the whole dag of asset in a car stream
+type CarFetcher interface {
+	// FetchCar streams the car of asset, the blocks are verified with their cids and handled in batches
+	FetchCar(ctx context.Context, req *CarRequest, handle func(blks []blocks.Block) error) (*types.WorkloadReport, error)
+}
+
+var _ CarFetcher = (*CandidateFetcher)(nil)
+
+// FetchCar requests /ipfs/<root>?format=car from the candidate, resumes the stream with a byte range
+// if the progress belongs to the same candidate
+func (c *CandidateFetcher) FetchCar(ctx context.Context, req *CarRequest, handle func(blks []blocks.Block) error) (*types.WorkloadReport, error) {
+	ds := req.Source
+	if len(ds.Address) == 0 {
+		return nil, fmt.Errorf("candidate address can not empty")
+	}
+
+	if ds.Tk == nil {
+		return nil, fmt.Errorf("token can not empty")
+	}
+
+	if req.Progress.NodeID != ds.NodeID {
+		*req.Progress = CarProgress{NodeID: ds.NodeID}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var idle atomic.Bool
+	timer := time.AfterFunc(req.IdleTimeout, func() {
+		idle.Store(true)
+		cancel()
+	})
+	defer timer.Stop()
+
+	buf, err := encode(ds.Tk)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s", err.Error())
+	}
+	url := fmt.Sprintf("https://%s/ipfs/%s?format=car", ds.Address, req.Root.String())
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, buf)
+	if err != nil {
+		return nil, fmt.Errorf("newRequest %s", err.Error())
+	}
+
+	if req.Progress.Offset > 0 {
+		httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", req.Progress.Offset))
+	}
+
+	startTime := time.Now()
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("doRequest %s", err.Error())
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore error
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// range is not supported, restart the stream
+		*req.Progress = CarProgress{NodeID: ds.NodeID}
+	default:
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("http status code: %d, error msg: %s", resp.StatusCode, string(data))
+	}
+
+	reader := &carSectionReader{r: bufio.NewReader(resp.Body), offset: req.Progress.Offset}
+	if req.Progress.Offset == 0 {
+		if req.Progress.DataEnd, err = reader.readHeader(); err != nil {
+			return nil, err
+		}
+		req.Progress.Offset = reader.offset
+	}
+
+	report := &types.WorkloadReport{TokenID: ds.Tk.ID, NodeID: ds.NodeID, Workload: &types.Workload{StartTime: startTime}}
+	defer func() {
+		report.Workload.EndTime = time.Now()
+		if duration := report.Workload.EndTime.Sub(startTime); duration > 0 {
+			report.Workload.DownloadSpeed = int64(float64(report.Workload.DownloadSize) / float64(duration) * float64(time.Second))
+		}
+	}()
+
+	// end offset of the last block received
+	blocksEnd := reader.offset
+	blks := make([]blocks.Block, 0, req.BatchSize)
+	flush := func() error {
+		if len(blks) == 0 {
+			return nil
+		}
+
+		if err := handle(blks); err != nil {
+			return err
+		}
+
+		report.Workload.DownloadSize += blocksEnd - req.Progress.Offset
+		req.Progress.Offset = blocksEnd
+		blks = make([]blocks.Block, 0, req.BatchSize)
+		return nil
+	}
+
+	for req.Progress.DataEnd == 0 || reader.offset < req.Progress.DataEnd {
+		b, err := reader.readBlock()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			if idle.Load() {
+				err = errCarIdleTimeout
+			} else if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			// keep the blocks which have been received
+			if e := flush(); e != nil {
+				return report, e
+			}
+			return report, err
+		}
+		timer.Reset(req.IdleTimeout)
+
+		blocksEnd = reader.offset
+		blks = append(blks, b)
+		if len(blks) >= req.BatchSize {
+			if err = flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, flush()
+}
+
+// carSectionReader reads the sections of car v1 or the data payload of car v2, and counts the offset in the stream
+type carSectionReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+// ReadByte implements io.ByteReader
+func (cr *carSectionReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.offset++
+	}
+	return b, err
+}
+
+func (cr *carSectionReader) readFull(buf []byte) error {
+	n, err := io.ReadFull(cr.r, buf)
+	cr.offset += int64(n)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (cr *carSectionReader) discard(n int64) error {
+	discarded, err := io.CopyN(io.Discard, cr.r, n)
+	cr.offset += discarded
+	return err
+}
+
+// readSection reads a section with varint length prefix, returns io.EOF at the end of stream
+func (cr *carSectionReader) readSection(maxSize uint64) ([]byte, error) {
+	offset := cr.offset
+	size, err := binary.ReadUvarint(cr)
+	if err != nil {
+		if err == io.EOF && cr.offset == offset {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	// zero length section is treated as EOF, same as padded car v1 payloads
+	if size == 0 {
+		return nil, io.EOF
+	}
+
+	if size > maxSize {
+		return nil, fmt.Errorf("car section size %d exceeds the max size %d", size, maxSize)
+	}
+
+	buf := make([]byte, size)
+	if err = cr.readFull(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readHeader skips the car headers, returns the end offset of the sections for car v2
+func (cr *carSectionReader) readHeader() (int64, error) {
+	pragma, err := cr.r.Peek(carv2.PragmaSize)
+	if err != nil {
+		return 0, fmt.Errorf("read car pragma %w", err)
+	}
+
+	var dataEnd int64
+	if bytes.Equal(pragma, carv2.Pragma) {
+		if err = cr.discard(carv2.PragmaSize); err != nil {
+			return 0, err
+		}
+
+		header := carv2.Header{}
+		n, err := header.ReadFrom(cr.r)
+		cr.offset += n
+		if err != nil {
+			return 0, fmt.Errorf("read car v2 header %w", err)
+		}
+
+		if int64(header.DataOffset) < cr.offset {
+			return 0, fmt.Errorf("invalid car v2 data offset %d", header.DataOffset)
+		}
+
+		if err = cr.discard(int64(header.DataOffset) - cr.offset); err != nil {
+			return 0, err
+		}
+		dataEnd = int64(header.DataOffset + header.DataSize)
+	}
+
+	// car v1 header
+	if _, err = cr.readSection(maxCarHeaderSize); err != nil {
+		return 0, fmt.Errorf("read car header %w", err)
+	}
+
+	return dataEnd, nil
+}
+
+// readBlock reads and verifies the next block
+func (cr *carSectionReader) readBlock() (blocks.Block, error) {
+	data, err := cr.readSection(maxBlockSize + maxCarHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+
+	n, c, err := cid.CidFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return newVerifiedBlock(c, data[n:])
+}
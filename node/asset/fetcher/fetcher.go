@@ -3,8 +3,11 @@ package fetcher
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
 )
 
@@ -17,7 +20,18 @@ type BlockFetcher interface {
 type ErrMsg struct {
 	Cid    string
 	Source string
-	Msg    string
+	// Kind is the kind of block source
+	Kind string
+	Msg  string
+}
+
+// Stats is the statistics of blocks fetched from a kind of block source
+type Stats struct {
+	Kind     string
+	Blocks   int
+	Size     int64
+	Errors   int
+	Duration time.Duration
 }
 
 func (e *ErrMsg) toString() string {
@@ -28,3 +42,17 @@ func (e *ErrMsg) toString() string {
 	}
 	return string(buf)
 }
+
+// newVerifiedBlock creates the block after checking the data matches the cid
+func newVerifiedBlock(c cid.Cid, data []byte) (blocks.Block, error) {
+	sum, err := c.Prefix().Sum(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sum.Equals(c) {
+		return nil, fmt.Errorf("block data does not match cid %s", c.String())
+	}
+
+	return blocks.NewBlockWithCid(data, c)
+}
@@ -0,0 +1,265 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+	"github.com/ipld/go-car/v2/blockstore"
+)
+
+func newRawBlock(t *testing.T, data string) blocks.Block {
+	prefix := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12, MhLength: -1}
+	c, err := prefix.Sum([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := blocks.NewBlockWithCid([]byte(data), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func writeCar(t *testing.T, blks ...blocks.Block) []byte {
+	buf := &bytes.Buffer{}
+	if err := car.WriteHeader(&car.CarHeader{Roots: []cid.Cid{blks[0].Cid()}, Version: 1}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range blks {
+		if err := carutil.LdWrite(buf, b.Cid().Bytes(), b.RawData()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewLocalFetcher(t.TempDir()), PriorityLocal)
+	registry.Register(NewGatewayFetcher([]string{"http://127.0.0.1"}), PriorityGateway)
+	registry.Register(NewCandidateFetcher(http.DefaultClient), PriorityCandidate)
+
+	kinds := func(dss []*types.CandidateDownloadInfo) []string {
+		ret := make([]string, 0)
+		for _, f := range registry.Fetchers(dss) {
+			ret = append(ret, f.Kind())
+		}
+		return ret
+	}
+
+	if got := kinds(nil); len(got) != 2 || got[0] != KindGateway || got[1] != KindLocal {
+		t.Fatalf("fetchers without download sources %v", got)
+	}
+
+	if got := kinds([]*types.CandidateDownloadInfo{{NodeID: "c_1"}}); len(got) != 3 || got[0] != KindCandidate {
+		t.Fatalf("fetchers with download sources %v", got)
+	}
+
+	registry.Unregister(KindGateway)
+	if got := kinds(nil); len(got) != 1 || got[0] != KindLocal {
+		t.Fatalf("fetchers after unregister %v", got)
+	}
+}
+
+func TestGatewayFetcher(t *testing.T) {
+	rawBlock := newRawBlock(t, "served as raw")
+	carBlock := newRawBlock(t, "served in car")
+	badBlock := newRawBlock(t, "tampered")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := filepath.Base(r.URL.Path)
+		switch {
+		case c == rawBlock.Cid().String() && r.URL.Query().Get("format") == "raw":
+			w.Header().Set("Content-Type", gatewayFormatRaw)
+			w.Write(rawBlock.RawData()) //nolint:errcheck
+		case c == badBlock.Cid().String() && r.URL.Query().Get("format") == "raw":
+			w.Header().Set("Content-Type", gatewayFormatRaw)
+			w.Write([]byte("other data")) //nolint:errcheck
+		case c == carBlock.Cid().String() && r.URL.Query().Get("format") == "car":
+			w.Header().Set("Content-Type", gatewayFormatCar)
+			w.Write(writeCar(t, carBlock)) //nolint:errcheck
+		case r.URL.Query().Get("format") == "raw":
+			w.WriteHeader(http.StatusNotAcceptable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGatewayFetcher([]string{server.URL + "/"})
+	cids := []string{rawBlock.Cid().String(), carBlock.Cid().String(), badBlock.Cid().String()}
+	errMsgs, _, blks, err := g.FetchBlocks(context.Background(), cids, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blks) != 2 {
+		t.Fatalf("fetched %d blocks, expect 2", len(blks))
+	}
+
+	if len(errMsgs) != 1 || errMsgs[0].Cid != badBlock.Cid().String() || errMsgs[0].Kind != KindGateway {
+		t.Fatalf("unexpected errors %v", errMsgs)
+	}
+}
+
+func TestLocalFetcher(t *testing.T) {
+	dir := t.TempDir()
+	fileBlock := newRawBlock(t, "raw block file")
+	carBlock := newRawBlock(t, "block in car")
+
+	if err := os.WriteFile(filepath.Join(dir, fileBlock.Cid().String()), fileBlock.RawData(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "import.car"), writeCar(t, carBlock), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := newRawBlock(t, "missing")
+	l := NewLocalFetcher(dir)
+	errMsgs, _, blks, err := l.FetchBlocks(context.Background(), []string{fileBlock.Cid().String(), carBlock.Cid().String(), missing.Cid().String()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blks) != 2 || !bytes.Equal(blks[1].RawData(), carBlock.RawData()) {
+		t.Fatalf("fetched %d blocks, expect 2", len(blks))
+	}
+
+	if len(errMsgs) != 1 || errMsgs[0].Cid != missing.Cid().String() {
+		t.Fatalf("unexpected errors %v", errMsgs)
+	}
+}
+
+func TestCandidateFetchCar(t *testing.T) {
+	leaf1 := newRawBlock(t, "leaf 1")
+	leaf2 := newRawBlock(t, "leaf 2")
+	root := merkledag.NodeWithData([]byte("root"))
+	if err := root.AddRawLink("1", &format.Link{Cid: leaf1.Cid(), Size: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.AddRawLink("2", &format.Link{Cid: leaf2.Cid(), Size: 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	// car v2 file as the candidate stores the asset
+	path := filepath.Join(t.TempDir(), "asset.car")
+	rw, err := blockstore.OpenReadWrite(path, []cid.Cid{root.Cid()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []blocks.Block{root, leaf1, leaf2} {
+		if err = rw.Put(context.Background(), blocks.NewBlock(b.RawData())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = rw.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := os.Open(path)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		defer f.Close() //nolint:errcheck
+
+		http.ServeContent(w, r, "asset.car", time.Time{}, f)
+	}))
+	defer server.Close()
+
+	c := NewCandidateFetcher(server.Client())
+	req := &CarRequest{
+		Root:        root.Cid(),
+		Source:      &types.CandidateDownloadInfo{NodeID: "c_1", Address: server.Listener.Addr().String(), Tk: &types.Token{ID: "1"}},
+		Progress:    &CarProgress{},
+		BatchSize:   1,
+		IdleTimeout: 10 * time.Second,
+	}
+
+	// stop the stream after the first block, and resume it with a byte range
+	received := make(map[string][]byte)
+	errStop := fmt.Errorf("stop")
+	_, err = c.FetchCar(context.Background(), req, func(blks []blocks.Block) error {
+		if len(received) > 0 {
+			return errStop
+		}
+		for _, b := range blks {
+			received[b.Cid().Hash().String()] = b.RawData()
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Fatalf("expect stop error, got %v", err)
+	}
+
+	if req.Progress.Offset == 0 || req.Progress.DataEnd == 0 {
+		t.Fatalf("unexpected progress %v", req.Progress)
+	}
+
+	report, err := c.FetchCar(context.Background(), req, func(blks []blocks.Block) error {
+		for _, b := range blks {
+			if _, ok := received[b.Cid().Hash().String()]; ok {
+				return fmt.Errorf("block %s received twice", b.Cid().String())
+			}
+			received[b.Cid().Hash().String()] = b.RawData()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 3 || !bytes.Equal(received[leaf2.Cid().Hash().String()], leaf2.RawData()) {
+		t.Fatalf("received %d blocks, expect 3", len(received))
+	}
+
+	if report.Workload.DownloadSize == 0 || req.Progress.Offset != req.Progress.DataEnd {
+		t.Fatalf("unexpected report size %d, progress %v", report.Workload.DownloadSize, req.Progress)
+	}
+}
+
+func TestIPFSClientAvailable(t *testing.T) {
+	down := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down || r.URL.Path != "/api/v0/version" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"Version":"0.20.0"}`)
+	}))
+	defer server.Close()
+
+	ipfs := NewIPFSClient(server.URL)
+	if !ipfs.Available(nil) {
+		t.Fatal("ipfs api responds but is not available")
+	}
+
+	// the probe result is kept until the interval passes
+	down = true
+	if !ipfs.Available(nil) {
+		t.Fatal("probe result is not kept")
+	}
+
+	ipfs.probedAt = time.Time{}
+	if ipfs.Available(nil) {
+		t.Fatal("ipfs api fails but is available")
+	}
+}
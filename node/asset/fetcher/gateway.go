@@ -0,0 +1,194 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+	carv2 "github.com/ipld/go-car/v2"
+)
+
+const (
+	// maxBlockSize is the max size of block accepted from gateways
+	maxBlockSize = 4 << 20
+
+	gatewayFormatRaw = "application/vnd.ipld.raw"
+	gatewayFormatCar = "application/vnd.ipld.car"
+)
+
+// errFormatNotSupported the gateway does not support the response format
+var errFormatNotSupported = errors.New("response format not supported")
+
+// GatewayFetcher fetches blocks from IPFS trustless gateways (https://specs.ipfs.tech/http-gateways/trustless-gateway/),
+// a block is requested with ?format=raw, and ?format=car if the gateway does not support raw response.
+// All blocks are verified with their cids.
+type GatewayFetcher struct {
+	gateways   []string
+	httpClient *http.Client
+}
+
+// NewGatewayFetcher creates a fetcher with the urls of gateways, e.g. https://ipfs.io
+func NewGatewayFetcher(gateways []string) *GatewayFetcher {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 10
+	t.IdleConnTimeout = 120 * time.Second
+
+	urls := make([]string, 0, len(gateways))
+	for _, gateway := range gateways {
+		urls = append(urls, strings.TrimSuffix(gateway, "/"))
+	}
+	return &GatewayFetcher{gateways: urls, httpClient: &http.Client{Transport: t}}
+}
+
+// Kind returns the kind of block source
+func (g *GatewayFetcher) Kind() string {
+	return KindGateway
+}
+
+// Available checks if there is any gateway
+func (g *GatewayFetcher) Available(dss []*types.CandidateDownloadInfo) bool {
+	return len(g.gateways) > 0
+}
+
+// FetchBlocks fetches the blocks from gateways, the cids are spread over the gateways,
+// the next gateway is tried if a gateway fails to serve the block
+func (g *GatewayFetcher) FetchBlocks(ctx context.Context, cids []string, dss []*types.CandidateDownloadInfo) ([]*ErrMsg, []*types.WorkloadReport, []blocks.Block, error) {
+	blks := make([]blocks.Block, 0, len(cids))
+	errMsgs := make([]*ErrMsg, 0)
+	lock := &sync.Mutex{}
+
+	var wg sync.WaitGroup
+	for index, cidStr := range cids {
+		wg.Add(1)
+
+		go func(index int, cidStr string) {
+			defer wg.Done()
+
+			for i := 0; i < len(g.gateways); i++ {
+				gateway := g.gateways[(index+i)%len(g.gateways)]
+				b, err := g.fetchSingleBlock(ctx, gateway, cidStr)
+
+				lock.Lock()
+				if err != nil {
+					errMsgs = append(errMsgs, &ErrMsg{Cid: cidStr, Source: gateway, Kind: KindGateway, Msg: err.Error()})
+				} else {
+					blks = append(blks, b)
+				}
+				lock.Unlock()
+
+				if err == nil || ctx.Err() != nil {
+					return
+				}
+			}
+		}(index, cidStr)
+	}
+	wg.Wait()
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return errMsgs, nil, blks, ctx.Err()
+	}
+
+	return errMsgs, nil, blks, nil
+}
+
+func (g *GatewayFetcher) fetchSingleBlock(ctx context.Context, gateway, cidStr string) (blocks.Block, error) {
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := g.fetchRaw(ctx, gateway, c)
+	if errors.Is(err, errFormatNotSupported) {
+		return g.fetchCar(ctx, gateway, c)
+	}
+	return b, err
+}
+
+// fetchRaw requests the block with ?format=raw
+func (g *GatewayFetcher) fetchRaw(ctx context.Context, gateway string, c cid.Cid) (blocks.Block, error) {
+	body, err := g.request(ctx, fmt.Sprintf("%s/ipfs/%s?format=raw", gateway, c.String()), gatewayFormatRaw)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() //nolint:errcheck // ignore error
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBlockSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxBlockSize {
+		return nil, fmt.Errorf("block %s exceeds the max size %d", c.String(), maxBlockSize)
+	}
+
+	return newVerifiedBlock(c, data)
+}
+
+// fetchCar requests the block in a car with ?format=car&dag-scope=block
+func (g *GatewayFetcher) fetchCar(ctx context.Context, gateway string, c cid.Cid) (blocks.Block, error) {
+	body, err := g.request(ctx, fmt.Sprintf("%s/ipfs/%s?format=car&dag-scope=block", gateway, c.String()), gatewayFormatCar)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() //nolint:errcheck // ignore error
+
+	reader, err := carv2.NewBlockReader(io.LimitReader(body, maxBlockSize*2))
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		b, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("block %s not found in car", c.String())
+			}
+			return nil, err
+		}
+
+		if b.Cid().Equals(c) {
+			return newVerifiedBlock(c, b.RawData())
+		}
+	}
+}
+
+// request sends the request with the accept header, the caller must close the body
+func (g *GatewayFetcher) request(ctx context.Context, url, accept string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close() //nolint:errcheck // ignore error
+
+		switch resp.StatusCode {
+		case http.StatusBadRequest, http.StatusNotAcceptable, http.StatusNotImplemented:
+			return nil, fmt.Errorf("http status code %d: %w", resp.StatusCode, errFormatNotSupported)
+		}
+
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("http status code: %d, error msg: %s", resp.StatusCode, string(data))
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); len(contentType) > 0 && !strings.HasPrefix(contentType, accept) {
+		resp.Body.Close() //nolint:errcheck // ignore error
+		return nil, fmt.Errorf("content type %s: %w", contentType, errFormatNotSupported)
+	}
+
+	return resp.Body, nil
+}
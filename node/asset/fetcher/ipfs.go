@@ -19,9 +19,19 @@ import (
 
 var log = logging.Logger("asset/fetcher")
 
+const (
+	// ipfsProbeInterval is how long the result of probing the ipfs api is kept
+	ipfsProbeInterval = 30 * time.Second
+	ipfsProbeTimeout  = 3 * time.Second
+)
+
 // IPFSClient
 type IPFSClient struct {
 	httpAPI *httpapi.HttpApi
+
+	probeLock sync.Mutex
+	available bool
+	probedAt  time.Time
 }
 
 // NewIPFSClient creates a new IPFSClient with the given API URL, timeout, and retry count
@@ -43,6 +53,34 @@ func NewIPFSClient(ipfsAPIURL string) *IPFSClient {
 	return &IPFSClient{httpAPI: httpAPI}
 }
 
+// Kind returns the kind of block source
+func (ipfs *IPFSClient) Kind() string {
+	return KindIPFS
+}
+
+// Available checks if the ipfs api responds, the ipfs node does not need download sources
+func (ipfs *IPFSClient) Available(dss []*types.CandidateDownloadInfo) bool {
+	ipfs.probeLock.Lock()
+	defer ipfs.probeLock.Unlock()
+
+	if time.Since(ipfs.probedAt) < ipfsProbeInterval {
+		return ipfs.available
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ipfsProbeTimeout)
+	defer cancel()
+
+	var version struct{ Version string }
+	err := ipfs.httpAPI.Request("version").Exec(ctx, &version)
+	if err != nil {
+		log.Warnf("ipfs api is not available: %s", err.Error())
+	}
+
+	ipfs.available = err == nil
+	ipfs.probedAt = time.Now()
+	return ipfs.available
+}
+
 // FetchBlocks retrieves blocks from IPFSClient using the provided context, CIDs, and download info
 func (ipfs *IPFSClient) FetchBlocks(ctx context.Context, cids []string, downloadSources []*types.CandidateDownloadInfo) ([]*ErrMsg, []*types.WorkloadReport, []blocks.Block, error) {
 	return ipfs.retrieveBlocks(ctx, cids)
@@ -79,7 +117,9 @@ func (ipfs *IPFSClient) retrieveBlocks(ctx context.Context, cids []string) ([]*E
 
 			b, err := ipfs.retrieveBlock(ctx, cid)
 			if err != nil {
-				errMsgs = append(errMsgs, &ErrMsg{Cid: cid, Source: "ipfs", Msg: err.Error()})
+				blksLock.Lock()
+				errMsgs = append(errMsgs, &ErrMsg{Cid: cid, Source: "ipfs", Kind: KindIPFS, Msg: err.Error()})
+				blksLock.Unlock()
 				return
 			}
 
@@ -0,0 +1,156 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipld/go-car/v2/blockstore"
+)
+
+const carFileExt = ".car"
+
+// LocalFetcher imports blocks from a local directory, the directory holds raw block files named by cid
+// and car files with extension .car. All blocks are verified with their cids.
+type LocalFetcher struct {
+	dir string
+
+	lock sync.Mutex
+	// opened car files, key is the file path
+	cars map[string]*blockstore.ReadOnly
+}
+
+// NewLocalFetcher creates a fetcher which imports blocks from dir
+func NewLocalFetcher(dir string) *LocalFetcher {
+	return &LocalFetcher{dir: dir, cars: make(map[string]*blockstore.ReadOnly)}
+}
+
+// Kind returns the kind of block source
+func (l *LocalFetcher) Kind() string {
+	return KindLocal
+}
+
+// Available checks if the import directory exists
+func (l *LocalFetcher) Available(dss []*types.CandidateDownloadInfo) bool {
+	info, err := os.Stat(l.dir)
+	return err == nil && info.IsDir()
+}
+
+// FetchBlocks reads the blocks from the raw block files or the car files in the import directory
+func (l *LocalFetcher) FetchBlocks(ctx context.Context, cids []string, dss []*types.CandidateDownloadInfo) ([]*ErrMsg, []*types.WorkloadReport, []blocks.Block, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if err := l.openCars(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	blks := make([]blocks.Block, 0, len(cids))
+	errMsgs := make([]*ErrMsg, 0)
+	for _, cidStr := range cids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		b, err := l.getBlock(ctx, cidStr)
+		if err != nil {
+			errMsgs = append(errMsgs, &ErrMsg{Cid: cidStr, Source: l.dir, Kind: KindLocal, Msg: err.Error()})
+			continue
+		}
+		blks = append(blks, b)
+	}
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return errMsgs, nil, blks, ctx.Err()
+	}
+
+	return errMsgs, nil, blks, nil
+}
+
+func (l *LocalFetcher) getBlock(ctx context.Context, cidStr string) (blocks.Block, error) {
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// raw block file named by the cid string or the multihash
+	for _, name := range []string{c.String(), c.Hash().B58String()} {
+		data, err := l.readBlockFile(filepath.Join(l.dir, name))
+		if err == nil {
+			return newVerifiedBlock(c, data)
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	for path, car := range l.cars {
+		b, err := car.Get(ctx, c)
+		if err != nil {
+			if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+				car.Close() //nolint:errcheck // ignore error
+				delete(l.cars, path)
+			}
+			continue
+		}
+
+		return newVerifiedBlock(c, b.RawData())
+	}
+
+	return nil, fmt.Errorf("block %s not found in %s", cidStr, l.dir)
+}
+
+func (l *LocalFetcher) readBlockFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // ignore error
+
+	data, err := io.ReadAll(io.LimitReader(f, maxBlockSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxBlockSize {
+		return nil, fmt.Errorf("block file %s exceeds the max size %d", path, maxBlockSize)
+	}
+	return data, nil
+}
+
+// openCars opens the car files which are added to the import directory
+func (l *LocalFetcher) openCars() error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), carFileExt) {
+			continue
+		}
+
+		path := filepath.Join(l.dir, entry.Name())
+		if _, ok := l.cars[path]; ok {
+			continue
+		}
+
+		car, err := blockstore.OpenReadOnly(path)
+		if err != nil {
+			log.Warnf("open car %s error %s", path, err.Error())
+			continue
+		}
+		l.cars[path] = car
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package fetcher
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// kinds of block sources
+const (
+	KindCandidate = "candidate"
+	KindIPFS      = "ipfs"
+	KindGateway   = "gateway"
+	KindLocal     = "local"
+)
+
+// default priorities of block sources, the source with lower priority is used first
+const (
+	PriorityCandidate = 10
+	PriorityIPFS      = 20
+	PriorityGateway   = 30
+	PriorityLocal     = 40
+)
+
+// Fetcher is a kind of block source which can be registered in Registry
+type Fetcher interface {
+	BlockFetcher
+	// Kind returns the kind of the block source
+	Kind() string
+	// Available checks if the fetcher can fetch blocks with the download sources of asset
+	Available(dss []*types.CandidateDownloadInfo) bool
+}
+
+type registration struct {
+	fetcher  Fetcher
+	priority int
+}
+
+// Registry holds the fetchers of all kinds of block sources in priority order
+type Registry struct {
+	lock          sync.RWMutex
+	registrations []*registration
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds the fetcher with the priority, the fetcher of the same kind is replaced
+func (r *Registry) Register(f Fetcher, priority int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.remove(f.Kind())
+	r.registrations = append(r.registrations, &registration{fetcher: f, priority: priority})
+	sort.SliceStable(r.registrations, func(i, j int) bool {
+		return r.registrations[i].priority < r.registrations[j].priority
+	})
+}
+
+// Unregister removes the fetcher of the kind
+func (r *Registry) Unregister(kind string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.remove(kind)
+}
+
+func (r *Registry) remove(kind string) {
+	for i, reg := range r.registrations {
+		if reg.fetcher.Kind() == kind {
+			r.registrations = append(r.registrations[:i], r.registrations[i+1:]...)
+			return
+		}
+	}
+}
+
+// Fetcher returns the fetcher of the kind, nil if it is not registered
+func (r *Registry) Fetcher(kind string) Fetcher {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, reg := range r.registrations {
+		if reg.fetcher.Kind() == kind {
+			return reg.fetcher
+		}
+	}
+	return nil
+}
+
+// Fetchers returns the fetchers available for the download sources in priority order
+func (r *Registry) Fetchers(dss []*types.CandidateDownloadInfo) []Fetcher {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	fetchers := make([]Fetcher, 0, len(r.registrations))
+	for _, reg := range r.registrations {
+		if reg.fetcher.Available(dss) {
+			fetchers = append(fetchers, reg.fetcher)
+		}
+	}
+	return fetchers
+}
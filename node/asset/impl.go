@@ -246,10 +246,18 @@ func (a *Asset) progressForAssetPulledSucceeded(root cid.Cid) (*types.AssetPullP
 	progress.Size = int64(linksSize)
 	progress.DoneSize = int64(linksSize)
 
+	if progress.Commitment, err = a.mgr.AssetCommitment(root); err != nil {
+		log.Warnf("asset commitment %s", err.Error())
+	}
+
 	return progress, nil
 }
 
 func (a *Asset) progress(root cid.Cid) (*types.AssetPullProgress, error) {
+	if progress, ok := a.mgr.shardProgress(root); ok {
+		return progress, nil
+	}
+
 	status, err := a.mgr.assetStatus(root)
 	if err != nil {
 		return nil, xerrors.Errorf("asset %s cache status %w", root.Hash(), err)
@@ -314,3 +322,29 @@ func (a *Asset) GetAssetsInBucket(ctx context.Context, bucketID int) ([]string,
 	}
 	return hashes, nil
 }
+
+// CreateAssetShards splits the asset into erasure coded shards, the shards are kept until the asset is deleted
+func (a *Asset) CreateAssetShards(ctx context.Context, assetCID string, info *types.AssetErasureInfo) (*types.AssetErasureInfo, error) {
+	root, err := cid.Decode(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.mgr.CreateAssetShards(ctx, root, info)
+}
+
+// PullAssetShard pull the shard with given index of an erasure coded asset from specified sources
+func (a *Asset) PullAssetShard(ctx context.Context, assetCID string, shardIndex int64, info *types.AssetErasureInfo, sources []*types.CandidateDownloadInfo) error {
+	root, err := cid.Decode(assetCID)
+	if err != nil {
+		return err
+	}
+
+	if shardIndex < 0 || shardIndex >= info.TotalShards() {
+		return fmt.Errorf("invalid shard index %d, total shards %d", shardIndex, info.TotalShards())
+	}
+
+	log.Debugf("Pull shard %d of asset %s", shardIndex, assetCID)
+
+	return a.mgr.PullAssetShard(root, shardIndex, info, sources)
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
 
+	"github.com/Filecoin-Titan/titan/metrics"
 	titanindex "github.com/Filecoin-Titan/titan/node/asset/index"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
 	lru "github.com/hashicorp/golang-lru"
@@ -15,6 +15,7 @@ import (
 	"github.com/ipld/go-car/v2/blockstore"
 	"github.com/ipld/go-car/v2/index"
 	"github.com/multiformats/go-multihash"
+	"go.opencensus.io/tag"
 	"golang.org/x/xerrors"
 )
 
@@ -49,7 +50,7 @@ func newLRUCache(storage storage.Storage, maxSize int) (*lruCache, error) {
 // getBlock gets the block with a given root and block ID from the cache.
 func (lru *lruCache) getBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
 	key := Key(root.Hash().String())
-	v, ok := lru.cache.Get(key)
+	v, ok := lru.get(key)
 	if !ok {
 		if err := lru.add(root); err != nil {
 			return nil, xerrors.Errorf("add cache %s %w", root.String(), err)
@@ -72,7 +73,7 @@ func (lru *lruCache) getBlock(ctx context.Context, root, block cid.Cid) (blocks.
 // hasBlock checks whether the block with a given root and block ID is present in the cache.
 func (lru *lruCache) hasBlock(ctx context.Context, root, block cid.Cid) (bool, error) {
 	key := Key(root.Hash().String())
-	v, ok := lru.cache.Get(key)
+	v, ok := lru.get(key)
 	if !ok {
 		if err := lru.add(root); err != nil {
 			return false, err
@@ -95,7 +96,7 @@ func (lru *lruCache) hasBlock(ctx context.Context, root, block cid.Cid) (bool, e
 // assetIndex returns the index of an asset with a given root.
 func (lru *lruCache) assetIndex(root cid.Cid) (index.Index, error) {
 	key := Key(root.Hash().String())
-	v, ok := lru.cache.Get(key)
+	v, ok := lru.get(key)
 	if !ok {
 		if err := lru.add(root); err != nil {
 			return nil, err
@@ -113,6 +114,19 @@ func (lru *lruCache) assetIndex(root cid.Cid) (index.Index, error) {
 	return nil, xerrors.Errorf("can not convert interface to *cacheValue")
 }
 
+// get gets the cached asset with the key, and records the hit or miss of the lookup
+func (lru *lruCache) get(key Key) (interface{}, bool) {
+	v, ok := lru.cache.Get(key)
+
+	result := "miss"
+	if ok {
+		result = "hit"
+	}
+	metrics.RecordWithTags(context.Background(), []tag.Mutator{tag.Upsert(metrics.CacheResult, result)}, metrics.CacheLookups.M(1))
+
+	return v, ok
+}
+
 // add adds an asset to the cache with a given root.
 func (lru *lruCache) add(root cid.Cid) error {
 	reader, err := lru.storage.GetAsset(root)
@@ -120,9 +134,9 @@ func (lru *lruCache) add(root cid.Cid) error {
 		return err
 	}
 
-	f, ok := reader.(*os.File)
+	f, ok := reader.(io.ReaderAt)
 	if !ok {
-		return xerrors.Errorf("can not convert asset %s reader to file", root.String())
+		return xerrors.Errorf("can not convert asset %s reader to io.ReaderAt", root.String())
 	}
 
 	idx, err := lru.getAssetIndex(f)
@@ -135,7 +149,7 @@ func (lru *lruCache) add(root cid.Cid) error {
 		return err
 	}
 
-	cache := &cacheValue{bs: bs, readerClose: f, idx: idx}
+	cache := &cacheValue{bs: bs, readerClose: reader, idx: idx}
 	lru.cache.Add(Key(root.Hash().String()), cache)
 
 	return nil
@@ -31,7 +31,11 @@ import (
 	"golang.org/x/xerrors"
 )
 
-const maxSizeOfCache = 128
+const (
+	maxSizeOfCache = 128
+	// the timeout of the node behind nat connecting to the downloader
+	natPunchTimeout = 5 * time.Second
+)
 
 // assetWaiter is used by Manager to store waiting assets for pulling
 type assetWaiter struct {
@@ -47,8 +51,10 @@ type Manager struct {
 	waitList     []*assetWaiter
 	waitListLock *sync.Mutex
 	pullCh       chan bool
-	ipfsAPIURL   string
+	fetchers     *fetcher.Registry
 	lru          *lruCache
+	// cars of the erasure coded assets reconstructed from shards
+	reconstructed *reconstructedCache
 	storage.Storage
 	api.Scheduler
 	pullParallel int
@@ -57,9 +63,19 @@ type Manager struct {
 
 	// save asset upload status
 	uploadingAssets *sync.Map
+	// resumable upload sessions which are being written, key is asset hash
+	uploadBusy *sync.Map
 
 	// hold the error msg, and wait for scheduler query asset progress
 	pullAssetErrMsgs *sync.Map
+
+	// erasure coded shards which are pulling or failed to pull, key is asset hash
+	shardPullers *sync.Map
+	// serialize creating shards
+	shardLock sync.Mutex
+
+	// merkle commitments of the stored assets, key is asset hash
+	commitments *sync.Map
 }
 
 // ManagerOptions is the struct that contains options for Manager
@@ -70,6 +86,10 @@ type ManagerOptions struct {
 	PullParallel int
 	PullTimeout  int
 	PullRetry    int
+	// IPFSGateways the trustless gateways to pull blocks from
+	IPFSGateways []string
+	// LocalImportPath the directory to import blocks from
+	LocalImportPath string
 }
 
 // NewManager creates a new instance of Manager
@@ -84,7 +104,7 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		waitListLock: &sync.Mutex{},
 		pullCh:       make(chan bool),
 		Storage:      opts.Storage,
-		ipfsAPIURL:   opts.IPFSAPIURL,
+		fetchers:     newFetcherRegistry(opts),
 		lru:          lru,
 		Scheduler:    opts.SchedulerAPI,
 		pullParallel: opts.PullParallel,
@@ -92,16 +112,44 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		pullRetry:    opts.PullRetry,
 
 		uploadingAssets:  &sync.Map{},
+		uploadBusy:       &sync.Map{},
 		pullAssetErrMsgs: &sync.Map{},
+		shardPullers:     &sync.Map{},
+		commitments:      &sync.Map{},
+	}
+
+	if m.reconstructed, err = newReconstructedCache(m.reconstructCar, maxSizeOfReconstructedCache); err != nil {
+		return nil, err
 	}
 
 	m.restoreWaitListFromStore()
+	m.restoreUploadSessions()
 
 	go m.start()
 
 	return m, nil
 }
 
+// newFetcherRegistry registers the block sources in priority order: candidates, ipfs node, ipfs gateways and local directory
+func newFetcherRegistry(opts *ManagerOptions) *fetcher.Registry {
+	registry := fetcher.NewRegistry()
+	registry.Register(fetcher.NewCandidateFetcher(client.NewHTTP3Client()), fetcher.PriorityCandidate)
+
+	if len(opts.IPFSAPIURL) > 0 {
+		registry.Register(fetcher.NewIPFSClient(opts.IPFSAPIURL), fetcher.PriorityIPFS)
+	}
+
+	if len(opts.IPFSGateways) > 0 {
+		registry.Register(fetcher.NewGatewayFetcher(opts.IPFSGateways), fetcher.PriorityGateway)
+	}
+
+	if len(opts.LocalImportPath) > 0 {
+		registry.Register(fetcher.NewLocalFetcher(opts.LocalImportPath), fetcher.PriorityLocal)
+	}
+
+	return registry
+}
+
 // startTick is a helper function that is used to check waitList and save puller
 func (m *Manager) startTick() {
 	for {
@@ -136,6 +184,7 @@ func (m *Manager) triggerPuller() {
 // start is a helper function that starts the Manager and begins downloading assets
 func (m *Manager) start() {
 	go m.startTick()
+	go m.startMultipartUploadsCleaner()
 
 	// delay 15 second to pull asset if exist waitList
 	time.AfterFunc(15*time.Second, m.triggerPuller)
@@ -162,14 +211,13 @@ func (m *Manager) doPullAsset() {
 	defer m.removeAssetFromWaitList(aw.Root)
 
 	opts := &pullerOptions{
-		root:       aw.Root,
-		dss:        aw.Dss,
-		storage:    m.Storage,
-		ipfsAPIURL: m.ipfsAPIURL,
-		parallel:   m.pullParallel,
-		timeout:    m.pullTimeout,
-		retry:      m.pullRetry,
-		httpClient: client.NewHTTP3Client(),
+		root:     aw.Root,
+		dss:      aw.Dss,
+		storage:  m.Storage,
+		fetchers: m.fetchers,
+		parallel: m.pullParallel,
+		timeout:  m.pullTimeout,
+		retry:    m.pullRetry,
 	}
 
 	assetPuller, err := m.restoreAssetPullerOrNew(opts)
@@ -179,6 +227,7 @@ func (m *Manager) doPullAsset() {
 	}
 
 	aw.puller = assetPuller
+	m.punchNATs(aw.Dss)
 	if err = assetPuller.pullAsset(); err != nil {
 		log.Errorf("pull asset error: %s", err)
 	}
@@ -186,6 +235,30 @@ func (m *Manager) doPullAsset() {
 	m.onPullAssetFinish(assetPuller, aw.isSyncData)
 }
 
+// punchNATs asks the scheduler to let the download sources behind nat connect to this node before downloading from them
+func (m *Manager) punchNATs(dss []*types.CandidateDownloadInfo) {
+	var wg sync.WaitGroup
+	for _, ds := range dss {
+		if len(ds.NatType) == 0 || ds.NatType == types.NatTypeNo.String() || ds.Tk == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(ds *types.CandidateDownloadInfo) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*natPunchTimeout)
+			defer cancel()
+
+			req := &types.NatPunchReq{Tk: ds.Tk, NodeID: ds.NodeID, Timeout: natPunchTimeout}
+			if err := m.NatPunch(ctx, req); err != nil {
+				log.Warnf("punch nat of %s error %s", ds.NodeID, err.Error())
+			}
+		}(ds)
+	}
+	wg.Wait()
+}
+
 // headFromWaitList returns the first assetWaiter in waitList, which is the oldest asset waiting to be downloaded
 
 func (m *Manager) headFromWaitList() *assetWaiter {
@@ -259,6 +332,9 @@ func (m *Manager) savePuller(puller *assetPuller) error {
 // onPullAssetFinish is called when an assetPuller finishes downloading an asset
 func (m *Manager) onPullAssetFinish(puller *assetPuller, isSyncData bool) {
 	log.Debugf("onPullAssetFinish, asset %s totalSize %d doneSize %d", puller.root.String(), puller.totalSize, puller.doneSize)
+	for _, stats := range puller.sourceStats {
+		log.Infof("pull asset %s from %s, blocks %d, size %d, errors %d, duration %s", puller.root.String(), stats.Kind, stats.Blocks, stats.Size, stats.Errors, stats.Duration)
+	}
 
 	if puller.isPulledComplete() {
 		if err := m.DeletePuller(puller.root); err != nil && !os.IsNotExist(err) {
@@ -273,6 +349,8 @@ func (m *Manager) onPullAssetFinish(puller *assetPuller, isSyncData bool) {
 
 			if err := m.StoreBlocksToCar(context.Background(), puller.root); err != nil {
 				log.Errorf("store asset error: %s", err.Error())
+			} else if _, err := m.AssetCommitment(puller.root); err != nil {
+				log.Errorf("asset commitment error: %s", err.Error())
 			}
 
 		}
@@ -373,6 +451,17 @@ func (m *Manager) DeleteAsset(root cid.Cid) error {
 		// TODO remove user asset
 	}
 
+	if err := m.DeleteUpload(root); err != nil {
+		log.Errorf("delete upload error %s", err.Error())
+	}
+
+	m.shardPullers.Delete(root.Hash().String())
+	m.reconstructed.remove(root)
+	m.commitments.Delete(root.Hash().String())
+	if err := m.DeleteShards(root); err != nil {
+		log.Errorf("delete shards error %s", err.Error())
+	}
+
 	if err := m.Storage.DeleteAsset(root); err != nil {
 		if e, ok := err.(*os.PathError); !ok {
 			return err
@@ -625,9 +714,9 @@ func (m *Manager) ScanBlocks(ctx context.Context, root cid.Cid) error {
 		return err
 	}
 
-	f, ok := reader.(*os.File)
+	f, ok := reader.(io.ReaderAt)
 	if !ok {
-		return xerrors.Errorf("can not convert asset %s reader to file", root.String())
+		return xerrors.Errorf("can not convert asset %s reader to io.ReaderAt", root.String())
 	}
 
 	bs, err := blockstore.NewReadOnly(f, nil, carv2.ZeroLengthSectionAsEOF(true))
@@ -688,6 +777,11 @@ func (m *Manager) submitPullerWorkloadReport(puller *assetPuller) error {
 		return err
 	}
 
+	return m.submitWorkloadReports(buf)
+}
+
+// submitWorkloadReports encrypts the encoded workload reports with the scheduler public key and submits them
+func (m *Manager) submitWorkloadReports(buf []byte) error {
 	// TODO: update and get scheduler publicKey from same place
 	pem, err := m.GetSchedulerPublicKey(context.Background())
 	if err != nil {
@@ -4,16 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
-	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/asset/fetcher"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
 	"github.com/Filecoin-Titan/titan/node/ipld"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
+	"go.opencensus.io/tag"
 )
 
 type pulledResult struct {
@@ -31,7 +34,7 @@ type workloadReport struct {
 type assetPuller struct {
 	root            cid.Cid
 	storage         storage.Storage
-	bFetcher        fetcher.BlockFetcher
+	fetchers        *fetcher.Registry
 	downloadSources []*types.CandidateDownloadInfo
 
 	blocksWaitList          []string
@@ -51,19 +54,25 @@ type assetPuller struct {
 	startTime       time.Time
 
 	errMsgs []*fetcher.ErrMsg
+	// statistics of every kind of block source
+	sourceStats map[string]*fetcher.Stats
+
+	// carState is the state of pulling the whole dag in car, nil if the asset is pulled block by block
+	carState *CarPullState
+	// pulledHashes are the multihashes of pulled blocks
+	pulledHashes map[string]struct{}
 }
 
 type pullerOptions struct {
-	root       cid.Cid
-	dss        []*types.CandidateDownloadInfo
-	storage    storage.Storage
-	ipfsAPIURL string
-	parallel   int
+	root     cid.Cid
+	dss      []*types.CandidateDownloadInfo
+	storage  storage.Storage
+	fetchers *fetcher.Registry
+	parallel int
 	// pull block time out
 	timeout int
 	// retry times of pull block on failed
-	retry      int
-	httpClient *http.Client
+	retry int
 }
 
 // newAssetPuller creates a new asset puller with the given options
@@ -72,22 +81,21 @@ func newAssetPuller(opts *pullerOptions) (*assetPuller, error) {
 		return nil, fmt.Errorf("newAssetPuller error, puller options dss cannot empty")
 	}
 
-	var blockFetcher fetcher.BlockFetcher
-	if len(opts.dss) != 0 {
-		blockFetcher = fetcher.NewCandidateFetcher(opts.httpClient)
-	} else {
-		blockFetcher = fetcher.NewIPFSClient(opts.ipfsAPIURL)
+	if len(opts.fetchers.Fetchers(opts.dss)) == 0 {
+		return nil, fmt.Errorf("newAssetPuller error, no block source available")
 	}
+
 	return &assetPuller{
 		root:            opts.root,
 		storage:         opts.storage,
 		downloadSources: opts.dss,
-		bFetcher:        blockFetcher,
+		fetchers:        opts.fetchers,
 		parallel:        opts.parallel,
 		timeout:         opts.timeout,
 		retry:           opts.retry,
 		startTime:       time.Now(),
 		errMsgs:         make([]*fetcher.ErrMsg, 0),
+		sourceStats:     make(map[string]*fetcher.Stats),
 	}, nil
 }
 
@@ -118,6 +126,16 @@ func (ap *assetPuller) pullAsset() error {
 		log.Errorf("pull asset from aws %s", err.Error())
 	}
 
+	if f := ap.carFetcher(); f != nil {
+		err := ap.pullAssetWithCar(f)
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, errNotEnoughDiskSpace) {
+			return err
+		}
+
+		log.Warnf("%s, pull the missing blocks one by one", err.Error())
+		ap.fallbackFromCar()
+	}
+
 	nextLayerCIDs := ap.blocksWaitList
 	if len(nextLayerCIDs) == 0 {
 		nextLayerCIDs = append(nextLayerCIDs, ap.root.String())
@@ -175,22 +193,11 @@ func (ap *assetPuller) pullBlocksWithBreadthFirst(layerCIDs []string) (result *p
 
 // pullBlocks fetches blocks for given cids, stores them in the storage
 func (ap *assetPuller) pullBlocks(cids []string) (*pulledResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ap.timeout)*time.Second)
-	defer cancel()
-
-	ap.cancel = cancel
-
-	errMsgs, workloadReports, blks, err := ap.bFetcher.FetchBlocks(ctx, cids, ap.downloadSources)
+	blks, err := ap.fetchBlocks(cids)
 	if err != nil {
-		log.Errorf("fetch blocks err: %s", err.Error())
 		return nil, err
 	}
 
-	if len(errMsgs) > 0 {
-		ap.errMsgs = append(ap.errMsgs, errMsgs...)
-	}
-
-	ap.mergeWorkloadReports(workloadReports)
 	// retry
 	retryCount := 0
 	cidMap := ap.toMap(cids)
@@ -267,25 +274,116 @@ func (ap *assetPuller) filterUnPulledBlocks(blks []blocks.Block, cidMap map[stri
 }
 
 func (ap *assetPuller) retryFetchBlocks(cids []string) ([]blocks.Block, error) {
+	blks, err := ap.fetchBlocks(cids)
+	if err != nil {
+		log.Errorf("retry fetch blocks err %s", err.Error())
+		return nil, err
+	}
+	return blks, nil
+}
+
+// fetchBlocks fetches the blocks from the available block sources in priority order,
+// the blocks which failed to fetch from a kind of source are fetched from the next kind
+func (ap *assetPuller) fetchBlocks(cids []string) ([]blocks.Block, error) {
+	blks := make([]blocks.Block, 0, len(cids))
+	unPulled := cids
+	cidMap := ap.toMap(cids)
+
+	for _, f := range ap.fetchers.Fetchers(ap.downloadSources) {
+		bs, err := ap.fetchBlocksFrom(f, unPulled)
+		if err != nil {
+			return nil, err
+		}
+
+		blks = append(blks, bs...)
+		if len(blks) >= len(cids) {
+			break
+		}
+
+		unPulled = ap.filterUnPulledBlocks(bs, cidMap)
+	}
+
+	return blks, nil
+}
+
+// fetchBlocksFrom fetches the blocks from a kind of block source, and records its errors, workloads and statistics
+func (ap *assetPuller) fetchBlocksFrom(f fetcher.Fetcher, cids []string) ([]blocks.Block, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ap.timeout)*time.Second)
 	defer cancel()
 
 	ap.cancel = cancel
 
-	errMsgs, workloadReports, blks, err := ap.bFetcher.FetchBlocks(ctx, cids, ap.downloadSources)
-	if err != nil {
-		log.Errorf("retry fetch blocks err %s", err.Error())
+	startTime := time.Now()
+	errMsgs, workloadReports, blks, err := f.FetchBlocks(ctx, cids, ap.downloadSources)
+	if err != nil && errors.Is(err, context.Canceled) {
+		log.Errorf("fetch blocks from %s err: %s", f.Kind(), err.Error())
 		return nil, err
 	}
 
+	if err != nil {
+		errMsgs = append(errMsgs, &fetcher.ErrMsg{Cid: ap.root.String(), Source: f.Kind(), Kind: f.Kind(), Msg: err.Error()})
+	}
+
 	if len(errMsgs) > 0 {
 		ap.errMsgs = append(ap.errMsgs, errMsgs...)
 	}
 
+	recordFetchMetrics(f.Kind(), time.Since(startTime), errMsgs)
+
 	ap.mergeWorkloadReports(workloadReports)
+
+	stats, ok := ap.sourceStats[f.Kind()]
+	if !ok {
+		stats = &fetcher.Stats{Kind: f.Kind()}
+		ap.sourceStats[f.Kind()] = stats
+	}
+
+	stats.Blocks += len(blks)
+	stats.Errors += len(errMsgs)
+	stats.Duration += time.Since(startTime)
+	for _, b := range blks {
+		stats.Size += int64(len(b.RawData()))
+	}
+
 	return blks, nil
 }
 
+// recordFetchMetrics records the duration of fetching blocks from a kind of block source and the kinds of its errors
+func recordFetchMetrics(source string, duration time.Duration, errMsgs []*fetcher.ErrMsg) {
+	ctx := context.Background()
+	metrics.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(metrics.Source, source)}, metrics.BlockFetchDuration.M(float64(duration.Nanoseconds())/1e6))
+
+	errKinds := make(map[string]int64)
+	for _, errMsg := range errMsgs {
+		errKinds[fetchErrorKind(errMsg.Msg)]++
+	}
+
+	for kind, count := range errKinds {
+		tags := []tag.Mutator{tag.Upsert(metrics.Source, source), tag.Upsert(metrics.ErrorKind, kind)}
+		metrics.RecordWithTags(ctx, tags, metrics.BlockFetchErrors.M(count))
+	}
+}
+
+// fetchErrorKind classifies the error message of fetching blocks
+func fetchErrorKind(msg string) string {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "deadline exceeded") || strings.Contains(msg, "timeout"):
+		return "timeout"
+	case strings.Contains(msg, "context canceled"):
+		return "canceled"
+	case strings.Contains(msg, "not found") || strings.Contains(msg, "not exist") || strings.Contains(msg, "404"):
+		return "not_found"
+	case strings.Contains(msg, "connection refused") || strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "no route to host") || strings.Contains(msg, "eof"):
+		return "network"
+	case strings.Contains(msg, "mismatch") || strings.Contains(msg, "invalid"):
+		return "invalid_block"
+	default:
+		return "other"
+	}
+}
+
 // isPulledComplete checks if asset pulling is completed or not
 func (ap *assetPuller) isPulledComplete() bool {
 	if ap.totalSize == 0 {
@@ -318,6 +416,7 @@ func (ap *assetPuller) encode() ([]byte, error) {
 		DownloadSources:         ap.downloadSources,
 		TotalSize:               ap.totalSize,
 		DoneSize:                ap.doneSize,
+		CarState:                ap.carState,
 	}
 
 	return encode(eac)
@@ -343,6 +442,7 @@ func (ap *assetPuller) decode(data []byte) error {
 	ap.downloadSources = eac.DownloadSources
 	ap.totalSize = eac.TotalSize
 	ap.doneSize = eac.DoneSize
+	ap.carState = eac.CarState
 
 	return nil
 }
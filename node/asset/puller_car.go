@@ -0,0 +1,352 @@
+package asset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/asset/fetcher"
+	"github.com/Filecoin-Titan/titan/node/ipld"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+)
+
+// carBlocksBatchSize is the count of blocks stored in a batch when pulling asset in car
+const carBlocksBatchSize = 100
+
+var errNotEnoughDiskSpace = errors.New("not enough disk space")
+
+// CarPullState is the state of pulling the whole dag of asset in car streams.
+// The blocks in car may be in any order, so the dag is tracked with the multihash of blocks
+type CarPullState struct {
+	Progress fetcher.CarProgress
+	// Pending are the blocks referenced by the pulled blocks but not pulled yet, key is multihash, value is cid
+	Pending map[string]string
+	// Orphans are the blocks pulled but not referenced by the pulled blocks yet, key is multihash
+	Orphans map[string]*CarOrphan
+}
+
+// CarOrphan is a pulled block which is not referenced yet
+type CarOrphan struct {
+	// Codec the block is decoded with
+	Codec uint64
+	Size  uint64
+	Links []string
+}
+
+// carFetcher returns the fetcher which can pull the whole dag in car, nil if the asset can not be pulled in car
+func (ap *assetPuller) carFetcher() fetcher.CarFetcher {
+	// the asset is pulling block by block
+	if ap.carState == nil && (len(ap.blocksWaitList) > 0 || len(ap.blocksPulledSuccessList) > 0) {
+		return nil
+	}
+
+	f := ap.fetchers.Fetcher(fetcher.KindCandidate)
+	if f == nil || !f.Available(ap.downloadSources) {
+		return nil
+	}
+
+	carFetcher, ok := f.(fetcher.CarFetcher)
+	if !ok {
+		return nil
+	}
+	return carFetcher
+}
+
+// pullAssetWithCar pulls the whole dag of asset in car streams from the download sources,
+// the stream is resumed with a byte range if it is pulled from the same candidate
+func (ap *assetPuller) pullAssetWithCar(f fetcher.CarFetcher) error {
+	if ap.carState == nil {
+		ap.carState = &CarPullState{
+			Pending: map[string]string{ap.root.Hash().String(): ap.root.String()},
+			Orphans: make(map[string]*CarOrphan),
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ap.cancel = cancel
+
+	for _, ds := range ap.carDownloadSources() {
+		startTime := time.Now()
+		req := &fetcher.CarRequest{
+			Root:        ap.root,
+			Source:      ds,
+			Progress:    &ap.carState.Progress,
+			BatchSize:   carBlocksBatchSize,
+			IdleTimeout: time.Duration(ap.timeout) * time.Second,
+		}
+
+		doneSize := ap.doneSize
+		blockCount := len(ap.blocksPulledSuccessList)
+		report, err := f.FetchCar(ctx, req, ap.storeCarBlocks)
+		if report != nil {
+			ap.mergeWorkloadReports([]*types.WorkloadReport{report})
+		}
+		ap.updateCarStats(len(ap.blocksPulledSuccessList)-blockCount, int64(ap.doneSize)-int64(doneSize), err, time.Since(startTime))
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if errors.Is(err, errNotEnoughDiskSpace) {
+			return err
+		}
+
+		if len(ap.carState.Pending) == 0 {
+			ap.onCarDagComplete()
+			return nil
+		}
+
+		if err == nil {
+			err = fmt.Errorf("%d blocks are missing in car", len(ap.carState.Pending))
+		}
+		ap.errMsgs = append(ap.errMsgs, &fetcher.ErrMsg{Cid: ap.root.String(), Source: ds.NodeID, Kind: fetcher.KindCandidate, Msg: err.Error()})
+	}
+
+	return fmt.Errorf("pull asset %s in car failed, %d blocks are missing", ap.root.String(), len(ap.carState.Pending))
+}
+
+// carDownloadSources returns the download sources, the source of the car progress is the first one to resume the stream
+func (ap *assetPuller) carDownloadSources() []*types.CandidateDownloadInfo {
+	dss := make([]*types.CandidateDownloadInfo, 0, len(ap.downloadSources))
+	for _, ds := range ap.downloadSources {
+		if len(ds.Address) == 0 {
+			continue
+		}
+
+		if ds.NodeID == ap.carState.Progress.NodeID {
+			dss = append([]*types.CandidateDownloadInfo{ds}, dss...)
+		} else {
+			dss = append(dss, ds)
+		}
+	}
+	return dss
+}
+
+// storeCarBlocks stores the blocks received in car stream, the blocks which are already pulled are skipped
+func (ap *assetPuller) storeCarBlocks(blks []blocks.Block) error {
+	pulled := ap.pulledBlocks()
+
+	accepted := make([]blocks.Block, 0, len(blks))
+	for _, b := range blks {
+		if _, ok := pulled[b.Cid().Hash().String()]; ok {
+			continue
+		}
+		accepted = append(accepted, b)
+	}
+
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	if err := ap.storage.StoreBlocks(context.Background(), ap.root, accepted); err != nil {
+		return err
+	}
+
+	for _, b := range accepted {
+		if err := ap.trackCarBlock(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trackCarBlock adds the block to the dag, the block is an orphan if it is not referenced yet
+func (ap *assetPuller) trackCarBlock(b blocks.Block) error {
+	state := ap.carState
+	hash := b.Cid().Hash().String()
+	size := uint64(len(b.RawData()))
+
+	pulled := ap.pulledBlocks()
+	if _, ok := pulled[hash]; ok {
+		return nil
+	}
+
+	// the cid in car may be not the same as the cid in dag, e.g. the raw leaves are stored with cid v0
+	if cidStr, ok := state.Pending[hash]; ok && cidStr != b.Cid().String() {
+		c, err := cid.Decode(cidStr)
+		if err != nil {
+			return err
+		}
+
+		if b, err = blocks.NewBlockWithCid(b.RawData(), c); err != nil {
+			return err
+		}
+	}
+
+	pulled[hash] = struct{}{}
+	ap.blocksPulledSuccessList = append(ap.blocksPulledSuccessList, b.Cid().String())
+	ap.doneSize += size
+
+	node, err := ipld.DecodeNode(context.Background(), b)
+
+	if _, ok := state.Pending[hash]; !ok {
+		orphan := &CarOrphan{Codec: cid.Raw, Size: size}
+		if err == nil {
+			orphan.Codec = node.Cid().Prefix().Codec
+			for _, link := range node.Links() {
+				orphan.Links = append(orphan.Links, link.Cid.String())
+			}
+		}
+		state.Orphans[hash] = orphan
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("decode block %s error %s", b.Cid().String(), err.Error())
+	}
+	delete(state.Pending, hash)
+
+	links := make([]cid.Cid, 0, len(node.Links()))
+	linksSize := uint64(0)
+	for _, link := range node.Links() {
+		links = append(links, link.Cid)
+		linksSize += link.Size
+	}
+
+	if ap.totalSize == 0 && hash == ap.root.Hash().String() {
+		ap.totalSize = size + linksSize
+		// check usabe disk space
+		if ap.totalSize >= uint64(ap.usableDiskSpace()) {
+			return fmt.Errorf("%w, need %d, usable %d, pull asset %s", errNotEnoughDiskSpace, ap.totalSize, ap.usableDiskSpace(), ap.root.String())
+		}
+	}
+
+	return ap.resolveCarLinks(links)
+}
+
+// resolveCarLinks adds the links to the dag, the orphans referenced by the links are resolved with their links
+func (ap *assetPuller) resolveCarLinks(links []cid.Cid) error {
+	state := ap.carState
+	pulled := ap.pulledBlocks()
+
+	for len(links) > 0 {
+		c := links[len(links)-1]
+		links = links[:len(links)-1]
+
+		hash := c.Hash().String()
+		orphan, ok := state.Orphans[hash]
+		if !ok {
+			if _, ok := pulled[hash]; !ok {
+				state.Pending[hash] = c.String()
+			}
+			continue
+		}
+		delete(state.Orphans, hash)
+
+		switch c.Prefix().Codec {
+		case cid.Raw:
+			// raw block has no links
+		case orphan.Codec:
+			for _, link := range orphan.Links {
+				lc, err := cid.Decode(link)
+				if err != nil {
+					return err
+				}
+				links = append(links, lc)
+			}
+		default:
+			// the orphan was decoded with a different codec, its links are unknown, pull it again
+			ap.removePulledBlocks(map[string]uint64{hash: orphan.Size})
+			state.Pending[hash] = c.String()
+		}
+	}
+
+	return nil
+}
+
+// dropCarOrphans removes the orphans from the pulled blocks, they are not in the dag
+func (ap *assetPuller) dropCarOrphans() {
+	if len(ap.carState.Orphans) == 0 {
+		return
+	}
+
+	orphans := make(map[string]uint64, len(ap.carState.Orphans))
+	for hash, orphan := range ap.carState.Orphans {
+		orphans[hash] = orphan.Size
+	}
+	ap.removePulledBlocks(orphans)
+	ap.carState.Orphans = make(map[string]*CarOrphan)
+}
+
+// removePulledBlocks removes the blocks from the pulled list, key of blks is multihash and value is size
+func (ap *assetPuller) removePulledBlocks(blks map[string]uint64) {
+	pulled := ap.pulledBlocks()
+	for hash, size := range blks {
+		delete(pulled, hash)
+		ap.doneSize -= size
+	}
+
+	successList := make([]string, 0, len(ap.blocksPulledSuccessList))
+	for _, cidStr := range ap.blocksPulledSuccessList {
+		c, err := cid.Decode(cidStr)
+		if err == nil {
+			if _, ok := blks[c.Hash().String()]; ok {
+				continue
+			}
+		}
+		successList = append(successList, cidStr)
+	}
+	ap.blocksPulledSuccessList = successList
+}
+
+// onCarDagComplete is called when all blocks of the dag are pulled
+func (ap *assetPuller) onCarDagComplete() {
+	if len(ap.carState.Orphans) > 0 {
+		log.Warnf("pull asset %s in car, %d blocks are not in dag", ap.root.String(), len(ap.carState.Orphans))
+	}
+	ap.dropCarOrphans()
+
+	// the blocks referenced more than once in dag are pulled only once
+	ap.totalSize = ap.doneSize
+	ap.carState = nil
+}
+
+// fallbackFromCar continues pulling the missing blocks of dag block by block
+func (ap *assetPuller) fallbackFromCar() {
+	ap.dropCarOrphans()
+
+	ap.blocksWaitList = make([]string, 0, len(ap.carState.Pending))
+	for _, cidStr := range ap.carState.Pending {
+		ap.blocksWaitList = append(ap.blocksWaitList, cidStr)
+	}
+	ap.nextLayerCIDs = make([]string, 0)
+	ap.carState = nil
+}
+
+// pulledBlocks returns the multihashes of pulled blocks
+func (ap *assetPuller) pulledBlocks() map[string]struct{} {
+	if ap.pulledHashes != nil {
+		return ap.pulledHashes
+	}
+
+	ap.pulledHashes = make(map[string]struct{}, len(ap.blocksPulledSuccessList))
+	for _, cidStr := range ap.blocksPulledSuccessList {
+		c, err := cid.Decode(cidStr)
+		if err != nil {
+			continue
+		}
+		ap.pulledHashes[c.Hash().String()] = struct{}{}
+	}
+	return ap.pulledHashes
+}
+
+func (ap *assetPuller) updateCarStats(blockCount int, size int64, err error, duration time.Duration) {
+	stats, ok := ap.sourceStats[fetcher.KindCandidate]
+	if !ok {
+		stats = &fetcher.Stats{Kind: fetcher.KindCandidate}
+		ap.sourceStats[fetcher.KindCandidate] = stats
+	}
+
+	stats.Blocks += blockCount
+	stats.Size += size
+	stats.Duration += duration
+	if err != nil {
+		stats.Errors++
+	}
+}
@@ -0,0 +1,97 @@
+package asset
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/asset/fetcher"
+	"github.com/Filecoin-Titan/titan/node/asset/storage"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipld/go-car/v2/blockstore"
+)
+
+func TestPullAssetWithCar(t *testing.T) {
+	leaves := make([]blocks.Block, 0)
+	root := merkledag.NodeWithData([]byte("root"))
+	for _, data := range []string{"leaf 1", "leaf 2", "leaf 1"} {
+		c, err := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12, MhLength: -1}.Sum([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaf, _ := blocks.NewBlockWithCid([]byte(data), c)
+		leaves = append(leaves, leaf)
+		if err = root.AddRawLink(data, &format.Link{Cid: c, Size: uint64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the candidate stores the blocks with cid v0, and the leaves are in front of root
+	carPath := filepath.Join(t.TempDir(), "asset.car")
+	rw, err := blockstore.OpenReadWrite(carPath, []cid.Cid{root.Cid()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range append(leaves, root) {
+		if err = rw.Put(context.Background(), blocks.NewBlock(b.RawData())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = rw.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := os.Open(carPath)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		defer f.Close() //nolint:errcheck
+
+		http.ServeContent(w, r, "asset.car", time.Time{}, f)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	manager, err := storage.NewManager(&storage.ManagerOptions{MetaDataPath: dir, AssetsPaths: []string{dir}, MinioConfig: &config.MinioConfig{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchers := fetcher.NewRegistry()
+	fetchers.Register(fetcher.NewCandidateFetcher(server.Client()), fetcher.PriorityCandidate)
+
+	dss := []*types.CandidateDownloadInfo{{NodeID: "c_1", Address: server.Listener.Addr().String(), Tk: &types.Token{ID: "1"}}}
+	opts := &pullerOptions{root: root.Cid(), dss: dss, storage: manager, fetchers: fetchers, parallel: 5, timeout: 10, retry: 2}
+	puller, err := newAssetPuller(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = puller.pullAsset(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !puller.isPulledComplete() || puller.carState != nil {
+		t.Fatalf("asset is not pulled completely, total size %d, done size %d", puller.totalSize, puller.doneSize)
+	}
+
+	if len(puller.blocksPulledSuccessList) != 3 {
+		t.Fatalf("pulled %d blocks, expect 3", len(puller.blocksPulledSuccessList))
+	}
+
+	if err = manager.StoreBlocksToCar(context.Background(), root.Cid()); err != nil {
+		t.Fatal(err)
+	}
+}
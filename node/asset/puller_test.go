@@ -3,6 +3,7 @@ package asset
 import (
 	"testing"
 
+	"github.com/Filecoin-Titan/titan/node/asset/fetcher"
 	"github.com/Filecoin-Titan/titan/node/asset/storage"
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
@@ -33,7 +34,10 @@ func TestCache(t *testing.T) {
 		return
 	}
 
-	opts := &pullerOptions{root: c, dss: nil, storage: manger, ipfsAPIURL: testIPFS, parallel: 5, timeout: 3, retry: 2}
+	fetchers := fetcher.NewRegistry()
+	fetchers.Register(fetcher.NewIPFSClient(testIPFS), fetcher.PriorityIPFS)
+
+	opts := &pullerOptions{root: c, dss: nil, storage: manger, fetchers: fetchers, parallel: 5, timeout: 3, retry: 2}
 	assetPuller, err := newAssetPuller(opts)
 	if err != nil {
 		t.Errorf("newAssetPuller err:%s", err)
@@ -46,3 +50,19 @@ func TestCache(t *testing.T) {
 		return
 	}
 }
+
+func TestFetchErrorKind(t *testing.T) {
+	cases := map[string]string{
+		"Get \"https://ipfs.io/ipfs/bafy\": context deadline exceeded": "timeout",
+		"block bafy not found":                               "not_found",
+		"dial tcp 10.0.0.1:443: connect: connection refused": "network",
+		"block hash mismatch":                                "invalid_block",
+		"unexpected response":                                "other",
+	}
+
+	for msg, kind := range cases {
+		if got := fetchErrorKind(msg); got != kind {
+			t.Errorf("fetchErrorKind(%q) = %s, expected %s", msg, got, kind)
+		}
+	}
+}
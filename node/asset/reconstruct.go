@@ -0,0 +1,191 @@
+package asset
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+	carv2 "github.com/ipld/go-car/v2"
+	"github.com/ipld/go-car/v2/blockstore"
+)
+
+const (
+	// the max number of reconstructed cars kept in the temp dir
+	maxSizeOfReconstructedCache = 8
+	// the max number of assets reconstructed at once
+	maxReconstructions = 2
+	// the timeout of pulling shards and decoding an asset
+	reconstructTimeout = time.Hour
+)
+
+// reconstructedCache caches the cars of the erasure coded assets rebuilt from shards,
+// an asset is reconstructed once for the concurrent reads and only a few assets are reconstructed at once
+type reconstructedCache struct {
+	reconstruct func(ctx context.Context, root cid.Cid) (*tempFile, error)
+	cache       *lru.Cache
+	// reconstructions in progress, key is asset hash
+	pending   *sync.Map
+	semaphore chan struct{}
+}
+
+// reconstruction is a reconstruction in progress, car or err is set before done is closed
+type reconstruction struct {
+	done chan struct{}
+	car  *reconstructedCar
+	err  error
+}
+
+// reconstructedCar is a cached car, it is closed after it is evicted and no read is in progress
+type reconstructedCar struct {
+	bs   *blockstore.ReadOnly
+	file *tempFile
+
+	lock    sync.Mutex
+	refs    int
+	evicted bool
+}
+
+// newReconstructedCache creates the cache of reconstructed cars, reconstruct rebuilds and verifies the car of asset
+func newReconstructedCache(reconstruct func(ctx context.Context, root cid.Cid) (*tempFile, error), maxSize int) (*reconstructedCache, error) {
+	rc := &reconstructedCache{reconstruct: reconstruct, pending: &sync.Map{}, semaphore: make(chan struct{}, maxReconstructions)}
+	cache, err := lru.NewWithEvict(maxSize, rc.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	rc.cache = cache
+
+	return rc, nil
+}
+
+// getBlock gets the block from the reconstructed car of asset, the car is reconstructed if it is not cached
+func (rc *reconstructedCache) getBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	car, err := rc.get(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	defer car.release()
+
+	return car.bs.Get(ctx, block)
+}
+
+// get returns the acquired car of asset, the caller must release it
+func (rc *reconstructedCache) get(ctx context.Context, root cid.Cid) (*reconstructedCar, error) {
+	key := Key(root.Hash().String())
+	for {
+		if v, ok := rc.cache.Get(key); ok && v.(*reconstructedCar).acquire() {
+			return v.(*reconstructedCar), nil
+		}
+
+		r := &reconstruction{done: make(chan struct{})}
+		if v, loaded := rc.pending.LoadOrStore(key, r); loaded {
+			r = v.(*reconstruction)
+		} else {
+			go rc.run(key, root, r)
+		}
+
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		// the car may be evicted before it is read
+		if r.car.acquire() {
+			return r.car, nil
+		}
+	}
+}
+
+// run reconstructs the car of asset and adds it to cache, it is not canceled with the requests waiting for it
+func (rc *reconstructedCache) run(key Key, root cid.Cid, r *reconstruction) {
+	defer func() {
+		rc.pending.Delete(key)
+		close(r.done)
+	}()
+
+	rc.semaphore <- struct{}{}
+	defer func() { <-rc.semaphore }()
+
+	// the car is added by the last reconstruction after the cache is checked
+	if v, ok := rc.cache.Peek(key); ok {
+		r.car = v.(*reconstructedCar)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconstructTimeout)
+	defer cancel()
+
+	file, err := rc.reconstruct(ctx, root)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	bs, err := blockstore.NewReadOnly(file, nil, carv2.ZeroLengthSectionAsEOF(true))
+	if err != nil {
+		file.Close() //nolint:errcheck // ignore error
+		r.err = err
+		return
+	}
+
+	r.car = &reconstructedCar{bs: bs, file: file}
+	rc.cache.Add(key, r.car)
+}
+
+// remove removes the reconstructed car of asset
+func (rc *reconstructedCache) remove(root cid.Cid) {
+	rc.cache.Remove(Key(root.Hash().String()))
+}
+
+func (rc *reconstructedCache) onEvict(key interface{}, value interface{}) {
+	if car, ok := value.(*reconstructedCar); ok {
+		car.evict()
+	}
+}
+
+func (car *reconstructedCar) acquire() bool {
+	car.lock.Lock()
+	defer car.lock.Unlock()
+
+	if car.evicted {
+		return false
+	}
+	car.refs++
+	return true
+}
+
+func (car *reconstructedCar) release() {
+	car.lock.Lock()
+	defer car.lock.Unlock()
+
+	car.refs--
+	if car.evicted && car.refs == 0 {
+		car.close()
+	}
+}
+
+func (car *reconstructedCar) evict() {
+	car.lock.Lock()
+	defer car.lock.Unlock()
+
+	car.evicted = true
+	if car.refs == 0 {
+		car.close()
+	}
+}
+
+func (car *reconstructedCar) close() {
+	if err := car.bs.Close(); err != nil {
+		log.Errorf("close block store of reconstructed car error %s", err.Error())
+	}
+	if err := car.file.Close(); err != nil {
+		log.Errorf("close reconstructed car error %s", err.Error())
+	}
+}
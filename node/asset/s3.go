@@ -0,0 +1,191 @@
+package asset
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// multipartUploadExpiration is the time that an incomplete multipart upload is kept
+	multipartUploadExpiration = 7 * 24 * time.Hour
+	multipartCleanInterval    = time.Hour
+)
+
+// ImportFile builds the asset from the file content read from r and saves it as the user asset
+func (m *Manager) ImportFile(ctx context.Context, userID string, r io.Reader) (*types.FileAsset, error) {
+	return m.StoreFileAsset(ctx, userID, r)
+}
+
+// CreateMultipartUpload creates an s3 multipart upload of the object
+func (m *Manager) CreateMultipartUpload(ctx context.Context, userID, bucket, key string) (*types.MultipartUpload, error) {
+	upload := &types.MultipartUpload{
+		UploadID:    uuid.NewString(),
+		UserID:      userID,
+		Bucket:      bucket,
+		Key:         key,
+		CreatedTime: time.Now(),
+	}
+
+	data, err := encode(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.StoreMultipartUpload(upload.UploadID, data); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// GetMultipartUpload returns the s3 multipart upload
+func (m *Manager) GetMultipartUpload(ctx context.Context, uploadID string) (*types.MultipartUpload, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return nil, types.ErrNoSuchUpload
+	}
+
+	data, err := m.Storage.GetMultipartUpload(uploadID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, types.ErrNoSuchUpload
+		}
+		return nil, err
+	}
+
+	upload := &types.MultipartUpload{}
+	if err := decode(data, upload); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// SaveMultipartPart saves a part of the s3 multipart upload, the part with the same number is replaced
+func (m *Manager) SaveMultipartPart(ctx context.Context, uploadID string, partNumber int, r io.Reader) (*types.MultipartPart, error) {
+	if _, err := m.GetMultipartUpload(ctx, uploadID); err != nil {
+		return nil, err
+	}
+
+	return m.StoreMultipartPart(uploadID, partNumber, r)
+}
+
+// ListMultipartParts returns the uploaded parts of the s3 multipart upload sorted by part number
+func (m *Manager) ListMultipartParts(ctx context.Context, uploadID string) ([]*types.MultipartPart, error) {
+	if _, err := m.GetMultipartUpload(ctx, uploadID); err != nil {
+		return nil, err
+	}
+
+	return m.GetMultipartParts(uploadID)
+}
+
+// AbortMultipartUpload removes the s3 multipart upload and its parts
+func (m *Manager) AbortMultipartUpload(ctx context.Context, uploadID string) error {
+	if _, err := m.GetMultipartUpload(ctx, uploadID); err != nil {
+		return err
+	}
+
+	return m.DeleteMultipartUpload(uploadID)
+}
+
+// CompleteMultipartUpload concatenates the given parts into the object content and saves it as the user asset,
+// parts must be in ascending order and match the etags of the uploaded parts. The upload is removed after succeeded.
+func (m *Manager) CompleteMultipartUpload(ctx context.Context, uploadID string, parts []*types.MultipartPart) (*types.FileAsset, error) {
+	upload, err := m.GetMultipartUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parts) == 0 {
+		return nil, types.ErrInvalidPart
+	}
+
+	uploaded, err := m.GetMultipartParts(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadedParts := make(map[int]*types.MultipartPart, len(uploaded))
+	for _, part := range uploaded {
+		uploadedParts[part.PartNumber] = part
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	closers := make([]io.Closer, 0, len(parts))
+	defer func() {
+		for _, c := range closers {
+			c.Close() //nolint:errcheck // ignore error
+		}
+	}()
+
+	for i, part := range parts {
+		if i > 0 && part.PartNumber <= parts[i-1].PartNumber {
+			return nil, types.ErrInvalidPartOrder
+		}
+
+		p, ok := uploadedParts[part.PartNumber]
+		if !ok || p.ETag != part.ETag {
+			return nil, xerrors.Errorf("part %d: %w", part.PartNumber, types.ErrInvalidPart)
+		}
+
+		reader, err := m.GetMultipartPart(uploadID, p)
+		if err != nil {
+			return nil, err
+		}
+
+		readers = append(readers, reader)
+		closers = append(closers, reader)
+	}
+
+	asset, err := m.ImportFile(ctx, upload.UserID, io.MultiReader(readers...))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.DeleteMultipartUpload(uploadID); err != nil {
+		log.Errorf("delete multipart upload %s error %s", uploadID, err.Error())
+	}
+
+	return asset, nil
+}
+
+// startMultipartUploadsCleaner removes the expired multipart uploads periodically
+func (m *Manager) startMultipartUploadsCleaner() {
+	ticker := time.NewTicker(multipartCleanInterval)
+	defer ticker.Stop()
+
+	for {
+		m.removeExpiredMultipartUploads()
+		<-ticker.C
+	}
+}
+
+// removeExpiredMultipartUploads removes the multipart uploads which are not completed in time
+func (m *Manager) removeExpiredMultipartUploads() {
+	uploadIDs, err := m.ListMultipartUploads()
+	if err != nil {
+		log.Errorf("list multipart uploads error %s", err.Error())
+		return
+	}
+
+	for _, uploadID := range uploadIDs {
+		upload, err := m.GetMultipartUpload(context.Background(), uploadID)
+		if err != nil && err != types.ErrNoSuchUpload {
+			log.Errorf("get multipart upload %s error %s", uploadID, err.Error())
+			continue
+		}
+
+		if upload != nil && upload.CreatedTime.Add(multipartUploadExpiration).After(time.Now()) {
+			continue
+		}
+
+		if err := m.DeleteMultipartUpload(uploadID); err != nil {
+			log.Errorf("delete multipart upload %s error %s", uploadID, err.Error())
+		}
+	}
+}
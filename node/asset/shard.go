@@ -0,0 +1,482 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/erasure"
+	"github.com/Filecoin-Titan/titan/node/ipld"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+	carv2 "github.com/ipld/go-car/v2"
+	"github.com/multiformats/go-multihash"
+	"golang.org/x/xerrors"
+)
+
+// shardPuller pulls an erasure coded shard of asset from candidate
+type shardPuller struct {
+	root       cid.Cid
+	index      int64
+	info       *types.AssetErasureInfo
+	dss        []*types.CandidateDownloadInfo
+	httpClient *http.Client
+
+	doneSize        int64
+	workloadReports []*types.WorkloadReport
+
+	// err is written by the pull goroutine and read by the progress queries
+	lock sync.Mutex
+	err  error
+}
+
+// CreateAssetShards splits the asset into erasure coded shards, it does nothing if the shards already exist
+func (m *Manager) CreateAssetShards(ctx context.Context, root cid.Cid, info *types.AssetErasureInfo) (*types.AssetErasureInfo, error) {
+	codec, err := erasure.New(int(info.DataShards), int(info.ParityShards))
+	if err != nil {
+		return nil, err
+	}
+
+	m.shardLock.Lock()
+	defer m.shardLock.Unlock()
+
+	reader, err := m.GetAsset(root)
+	if err != nil {
+		return nil, xerrors.Errorf("get asset %s: %w", root.String(), err)
+	}
+	defer reader.Close() //nolint:errcheck // ignore error
+
+	carSize, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := *info
+	ret.CarSize = carSize
+	ret.ShardSize = codec.ShardSize(carSize, int(info.StripeSize))
+
+	indexes, err := m.GetShardIndexes(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(indexes)) == info.TotalShards() {
+		log.Debugf("shards of asset %s already exist", root.String())
+		return &ret, nil
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	indexes = make([]int64, 0, info.TotalShards())
+	for i := int64(0); i < info.TotalShards(); i++ {
+		indexes = append(indexes, i)
+	}
+
+	err = m.StoreShards(root, indexes, func(writers []io.Writer) error {
+		_, err := codec.Encode(reader, writers, int(info.StripeSize))
+		return err
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("encode asset %s: %w", root.String(), err)
+	}
+
+	log.Infof("create %d shards for asset %s, car size %d, shard size %d", info.TotalShards(), root.String(), ret.CarSize, ret.ShardSize)
+	return &ret, nil
+}
+
+// PullAssetShard adds a task to pull the shard with given index of an erasure coded asset
+func (m *Manager) PullAssetShard(root cid.Cid, index int64, info *types.AssetErasureInfo, dss []*types.CandidateDownloadInfo) error {
+	if len(dss) == 0 {
+		return fmt.Errorf("candidate download infos can not empty")
+	}
+
+	indexes, err := m.GetShardIndexes(root)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range indexes {
+		if i == index {
+			log.Debugf("shard %d of asset %s already exist", index, root.String())
+			return nil
+		}
+	}
+
+	if v, ok := m.shardPullers.Load(root.Hash().String()); ok {
+		if puller := v.(*shardPuller); puller.getErr() == nil {
+			return fmt.Errorf("shard %d of asset %s is pulling", puller.index, root.String())
+		}
+	}
+
+	puller := &shardPuller{root: root, index: index, info: info, dss: dss, httpClient: client.NewHTTP3Client()}
+	m.shardPullers.Store(root.Hash().String(), puller)
+
+	go func() {
+		m.punchNATs(dss)
+		if err := puller.pull(m); err != nil {
+			log.Errorf("pull shard %d of asset %s error: %s", index, root.String(), err.Error())
+			puller.setErr(err)
+		} else {
+			m.shardPullers.Delete(root.Hash().String())
+		}
+
+		if len(puller.workloadReports) > 0 {
+			buf, err := encode(puller.workloadReports)
+			if err != nil {
+				log.Errorf("encode workload reports error %s", err.Error())
+				return
+			}
+
+			if err := m.submitWorkloadReports(buf); err != nil {
+				log.Errorf("submit shard workload report error %s", err.Error())
+			}
+		}
+	}()
+
+	return nil
+}
+
+// shardProgress returns the pull progress if the node holds or pulls only a shard of the asset
+func (m *Manager) shardProgress(root cid.Cid) (*types.AssetPullProgress, bool) {
+	if v, ok := m.shardPullers.Load(root.Hash().String()); ok {
+		puller := v.(*shardPuller)
+		progress := &types.AssetPullProgress{
+			CID:      root.String(),
+			Status:   types.ReplicaStatusPulling,
+			Size:     puller.info.ShardSize,
+			DoneSize: atomic.LoadInt64(&puller.doneSize),
+		}
+
+		if err := puller.getErr(); err != nil {
+			progress.Status = types.ReplicaStatusFailed
+			progress.Msg = err.Error()
+		}
+		return progress, true
+	}
+
+	if ok, err := m.AssetExists(root); err != nil || ok {
+		return nil, false
+	}
+
+	indexes, err := m.GetShardIndexes(root)
+	if err != nil || len(indexes) == 0 {
+		return nil, false
+	}
+
+	progress := &types.AssetPullProgress{CID: root.String(), Status: types.ReplicaStatusSucceeded}
+	if reader, err := m.GetShard(root, indexes[0]); err == nil {
+		if size, err := reader.Seek(0, io.SeekEnd); err == nil {
+			progress.Size = size
+			progress.DoneSize = size
+		}
+		reader.Close() //nolint:errcheck // ignore error
+	}
+
+	return progress, true
+}
+
+// HasShards checks if the node holds erasure coded shards of the asset
+func (m *Manager) HasShards(root cid.Cid) (bool, error) {
+	indexes, err := m.GetShardIndexes(root)
+	if err != nil {
+		return false, err
+	}
+	return len(indexes) > 0, nil
+}
+
+// GetReconstructedBlock gets the block of an erasure coded asset from the car reconstructed from shards,
+// the reconstructed cars are cached and only a few assets are reconstructed at once
+func (m *Manager) GetReconstructedBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	return m.reconstructed.getBlock(ctx, root, block)
+}
+
+// reconstructCar rebuilds the car file of an erasure coded asset from the local shards and the shards of other nodes.
+// The car is verified against the root, the caller must close the file.
+func (m *Manager) reconstructCar(ctx context.Context, root cid.Cid) (*tempFile, error) {
+	indexes, err := m.GetShardIndexes(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(indexes) == 0 {
+		return nil, xerrors.Errorf("no shard of asset %s", root.String())
+	}
+
+	shardSources, err := m.GetAssetShardSources(ctx, root.String())
+	if err != nil {
+		return nil, xerrors.Errorf("get shard sources: %w", err)
+	}
+
+	info := shardSources.ErasureInfo
+	codec, err := erasure.New(int(info.DataShards), int(info.ParityShards))
+	if err != nil {
+		return nil, err
+	}
+
+	readers := make([]io.Reader, info.TotalShards())
+	closers := make([]io.Closer, 0, info.DataShards)
+	closeAll := func() {
+		for _, c := range closers {
+			c.Close() //nolint:errcheck // ignore error
+		}
+	}
+
+	count := int64(0)
+	for _, index := range indexes {
+		if count >= info.DataShards || index >= info.TotalShards() {
+			break
+		}
+
+		reader, err := m.GetShard(root, index)
+		if err != nil {
+			log.Warnf("get local shard %d of asset %s error %s", index, root.String(), err.Error())
+			continue
+		}
+
+		readers[index] = reader
+		closers = append(closers, reader)
+		count++
+	}
+
+	remotes := make([]*types.CandidateDownloadInfo, 0, len(shardSources.Sources))
+	for _, source := range shardSources.Sources {
+		if source.ShardIndex < info.TotalShards() && readers[source.ShardIndex] == nil {
+			remotes = append(remotes, source.CandidateDownloadInfo)
+		}
+	}
+	m.punchNATs(remotes)
+
+	httpClient := client.NewHTTP3Client()
+	for _, source := range shardSources.Sources {
+		if count >= info.DataShards {
+			break
+		}
+
+		if source.ShardIndex >= info.TotalShards() || readers[source.ShardIndex] != nil {
+			continue
+		}
+
+		body, err := openRemoteShard(ctx, httpClient, root, source.ShardIndex, source.CandidateDownloadInfo)
+		if err != nil {
+			log.Warnf("open shard %d of asset %s from %s error %s", source.ShardIndex, root.String(), source.NodeID, err.Error())
+			continue
+		}
+
+		readers[source.ShardIndex] = body
+		closers = append(closers, body)
+		count++
+	}
+
+	if count < info.DataShards {
+		closeAll()
+		return nil, xerrors.Errorf("not enough shards to reconstruct asset %s, require %d, got %d", root.String(), info.DataShards, count)
+	}
+
+	defer closeAll()
+
+	// the shards of other nodes may be corrupted, the car is verified against the root before it is returned
+	f, err := os.CreateTemp("", "reconstruct-*.car")
+	if err != nil {
+		return nil, err
+	}
+	car := &tempFile{File: f}
+
+	if err := codec.Decode(readers, f, info.CarSize, int(info.StripeSize)); err != nil {
+		car.Close() //nolint:errcheck // ignore error
+		return nil, xerrors.Errorf("decode asset %s: %w", root.String(), err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		car.Close() //nolint:errcheck // ignore error
+		return nil, err
+	}
+
+	if err := verifyCar(ctx, f, root); err != nil {
+		car.Close() //nolint:errcheck // ignore error
+		return nil, xerrors.Errorf("verify reconstructed asset %s: %w", root.String(), err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		car.Close() //nolint:errcheck // ignore error
+		return nil, err
+	}
+
+	return car, nil
+}
+
+// verifyCar checks every block of the car matches its cid and the car holds the complete dag of root
+func verifyCar(ctx context.Context, r io.Reader, root cid.Cid) error {
+	br, err := carv2.NewBlockReader(r, carv2.WithTrustedCAR(false), carv2.ZeroLengthSectionAsEOF(true))
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(br.Roots, root.Equals) {
+		return xerrors.Errorf("root %s not found in car roots", root.String())
+	}
+
+	blks := make(map[string]struct{})
+	links := make(map[string]struct{})
+	for {
+		blk, err := br.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		blks[blk.Cid().Hash().String()] = struct{}{}
+
+		node, err := ipld.DecodeNode(ctx, blk)
+		if err != nil {
+			return err
+		}
+
+		for _, link := range node.Links() {
+			if link.Cid.Prefix().MhType != multihash.IDENTITY {
+				links[link.Cid.Hash().String()] = struct{}{}
+			}
+		}
+	}
+
+	if _, ok := blks[root.Hash().String()]; !ok {
+		return xerrors.Errorf("root block %s not found", root.String())
+	}
+
+	for link := range links {
+		if _, ok := blks[link]; !ok {
+			return xerrors.Errorf("block %s of the dag not found", link)
+		}
+	}
+
+	return nil
+}
+
+// tempFile removes the file when it is closed
+type tempFile struct {
+	*os.File
+}
+
+func (tf *tempFile) Close() error {
+	err := tf.File.Close()
+	os.Remove(tf.Name()) //nolint:errcheck // ignore error
+	return err
+}
+
+func (sp *shardPuller) setErr(err error) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	sp.err = err
+}
+
+func (sp *shardPuller) getErr() error {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	return sp.err
+}
+
+// pull downloads the shard from download sources in turn until succeeded
+func (sp *shardPuller) pull(m *Manager) error {
+	var lastErr error
+	for _, ds := range sp.dss {
+		atomic.StoreInt64(&sp.doneSize, 0)
+
+		startTime := time.Now()
+		if err := sp.pullFromSource(m, ds); err != nil {
+			log.Warnf("pull shard %d of asset %s from %s error %s", sp.index, sp.root.String(), ds.NodeID, err.Error())
+			lastErr = err
+			continue
+		}
+
+		size := atomic.LoadInt64(&sp.doneSize)
+		speed := float64(size) / float64(time.Since(startTime)) * float64(time.Second)
+		workload := &types.Workload{DownloadSpeed: int64(speed), DownloadSize: size, StartTime: startTime, EndTime: time.Now()}
+		sp.workloadReports = append(sp.workloadReports, &types.WorkloadReport{TokenID: ds.Tk.ID, NodeID: ds.NodeID, Workload: workload})
+		return nil
+	}
+
+	return lastErr
+}
+
+func (sp *shardPuller) pullFromSource(m *Manager, ds *types.CandidateDownloadInfo) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	body, err := openRemoteShard(ctx, sp.httpClient, sp.root, sp.index, ds)
+	if err != nil {
+		return err
+	}
+	defer body.Close() //nolint:errcheck // ignore error
+
+	reader := &countReader{r: body, count: &sp.doneSize}
+	return m.StoreShards(sp.root, []int64{sp.index}, func(writers []io.Writer) error {
+		n, err := io.Copy(writers[0], io.LimitReader(reader, sp.info.ShardSize+1))
+		if err != nil {
+			return err
+		}
+
+		if n != sp.info.ShardSize {
+			return fmt.Errorf("shard size %d, expect %d", n, sp.info.ShardSize)
+		}
+		return nil
+	})
+}
+
+// openRemoteShard requests the shard with the given index from the download source, the caller must close the body
+func openRemoteShard(ctx context.Context, httpClient *http.Client, root cid.Cid, index int64, ds *types.CandidateDownloadInfo) (io.ReadCloser, error) {
+	if len(ds.Address) == 0 {
+		return nil, fmt.Errorf("address can not empty")
+	}
+
+	if ds.Tk == nil {
+		return nil, fmt.Errorf("token can not empty")
+	}
+
+	buf, err := encode(ds.Tk)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://%s/ipfs/%s?format=shard&index=%d", ds.Address, root.String(), index)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close() //nolint:errcheck // ignore error
+
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("http status code: %d, error msg: %s", resp.StatusCode, string(data))
+	}
+
+	return resp.Body, nil
+}
+
+// countReader counts the bytes read from r
+type countReader struct {
+	r     io.Reader
+	count *int64
+}
+
+func (cr *countReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	atomic.AddInt64(cr.count, int64(n))
+	return n, err
+}
@@ -0,0 +1,118 @@
+package asset
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/lib/carutil"
+	"github.com/ipfs/go-cid"
+)
+
+func TestVerifyCar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("reconstructed asset"), 100000), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var car bytes.Buffer
+	root, err := carutil.GenerateFileCar(context.Background(), path, &car)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyCar(context.Background(), bytes.NewReader(car.Bytes()), root); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := cid.Decode("QmTcAg1KeDYJFpTJh3rkZGLhnnVKeXWNtjwPufjVvwPTpG")
+	if err := verifyCar(context.Background(), bytes.NewReader(car.Bytes()), other); err == nil {
+		t.Fatal("car is verified against other root")
+	}
+
+	// a corrupted byte in the last block is detected
+	corrupted := bytes.Clone(car.Bytes())
+	corrupted[len(corrupted)-10] ^= 0xff
+	if err := verifyCar(context.Background(), bytes.NewReader(corrupted), root); err == nil {
+		t.Fatal("corrupted car is verified")
+	}
+}
+
+func TestReconstructedCache(t *testing.T) {
+	dir := t.TempDir()
+	cars := make(map[string][]byte)
+	roots := make([]cid.Cid, 0, 2)
+	for _, data := range []string{"asset a", "asset b"} {
+		path := filepath.Join(dir, data)
+		if err := os.WriteFile(path, bytes.Repeat([]byte(data), 100000), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		var car bytes.Buffer
+		root, err := carutil.GenerateFileCar(context.Background(), path, &car)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cars[root.String()] = car.Bytes()
+		roots = append(roots, root)
+	}
+
+	var reconstructions int32
+	files := make(chan string, 8)
+	reconstruct := func(ctx context.Context, root cid.Cid) (*tempFile, error) {
+		atomic.AddInt32(&reconstructions, 1)
+		f, err := os.CreateTemp(dir, "reconstruct-*.car")
+		if err != nil {
+			return nil, err
+		}
+		files <- f.Name()
+		if _, err := f.Write(cars[root.String()]); err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return &tempFile{File: f}, nil
+	}
+
+	rc, err := newReconstructedCache(reconstruct, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the concurrent reads share one reconstruction
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := rc.getBlock(context.Background(), roots[0], roots[0]); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := rc.getBlock(context.Background(), roots[0], roots[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&reconstructions); n != 1 {
+		t.Fatalf("reconstructions %d, expected 1", n)
+	}
+
+	// the car of the other asset evicts the first car, and the evicted car is removed
+	if _, err := rc.getBlock(context.Background(), roots[1], roots[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(<-files); !os.IsNotExist(err) {
+		t.Fatalf("evicted car is not removed: %v", err)
+	}
+	if n := atomic.LoadInt32(&reconstructions); n != 2 {
+		t.Fatalf("reconstructions %d, expected 2", n)
+	}
+}
@@ -0,0 +1,647 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	carv2 "github.com/ipld/go-car/v2"
+	"golang.org/x/xerrors"
+)
+
+const (
+	dedupBlocksDir    = "blocks"
+	dedupManifestsDir = "manifests"
+	manifestSuffix    = ".manifest"
+)
+
+// dedupAsset stores the blocks of all assets once in a shared blockstore keyed by multihash,
+// every asset keeps a manifest to rebuild its car. The blocks are refcounted by the assets using them.
+//
+// The assets are pulled and verified as car files by asset, then the car is split into the manifest
+// and the shared blocks. The car files stored before the deduplication is enabled are still served.
+type dedupAsset struct {
+	*asset
+	// the blocks are spread over the assets paths by the hash
+	blocksDirs   []string
+	manifestsDir string
+	// key is multihash of the block, value is the count of assets using the block
+	refs ds.Batching
+	lock sync.Mutex
+}
+
+// manifest rebuilds the car of an asset, the car is the segments in order
+type manifest struct {
+	Segments []segment
+	// the bytes of the car except the block data, such as the headers, the cids and the index
+	Inline []byte
+}
+
+// segment is a part of the car, the block data of Hash or the bytes at Offset of the inline bytes
+type segment struct {
+	Hash   string
+	Offset int64
+	Length int64
+}
+
+// newDedupAsset initializes the deduplicated store in the base directories,
+// the manifests are kept in the first one and the refs are kept in refsDir
+func newDedupAsset(a *asset, baseDirs []string, refsDir string) (*dedupAsset, error) {
+	if len(baseDirs) == 0 {
+		return nil, xerrors.New("no base directory of the deduplicated store")
+	}
+
+	manifestsDir := filepath.Join(baseDirs[0], dedupManifestsDir)
+	blocksDirs := make([]string, 0, len(baseDirs))
+	for _, baseDir := range baseDirs {
+		blocksDirs = append(blocksDirs, filepath.Join(baseDir, dedupBlocksDir))
+	}
+
+	for _, dir := range append([]string{manifestsDir}, blocksDirs...) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	refs, err := createDatastore(refsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dedupAsset{asset: a, blocksDirs: blocksDirs, manifestsDir: manifestsDir, refs: refs}, nil
+}
+
+func (d *dedupAsset) manifestPath(root cid.Cid) string {
+	return filepath.Join(d.manifestsDir, root.Hash().String()+manifestSuffix)
+}
+
+// blockPath selects the blocks directory by the hash of the multihash,
+// and shards the blocks by the next to last 2 chars of the multihash, like flatfs
+func (d *dedupAsset) blockPath(hash string) string {
+	h := fnv.New32a()
+	h.Write([]byte(hash)) //nolint:errcheck // never returns an error
+	blocksDir := d.blocksDirs[h.Sum32()%uint32(len(d.blocksDirs))]
+
+	dir := "_"
+	if len(hash) > 3 {
+		dir = hash[len(hash)-3 : len(hash)-1]
+	}
+	return filepath.Join(blocksDir, dir, hash)
+}
+
+// storeBlocksToCar builds the car of the pulled blocks and splits it into the shared blocks
+func (d *dedupAsset) storeBlocksToCar(ctx context.Context, root cid.Cid) error {
+	if err := d.asset.storeBlocksToCar(ctx, root); err != nil {
+		return err
+	}
+
+	return d.split(ctx, root)
+}
+
+// saveUserAsset saves and verifies the car uploaded by the user and splits it into the shared blocks
+func (d *dedupAsset) saveUserAsset(ctx context.Context, userID string, root cid.Cid, assetSize int64, r io.Reader) error {
+	if ok, err := d.exists(root); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	if err := d.asset.saveUserAsset(ctx, userID, root, assetSize, r); err != nil {
+		return err
+	}
+
+	return d.split(ctx, root)
+}
+
+// split moves the blocks of the car into the shared blockstore and replaces the car with the manifest
+func (d *dedupAsset) split(ctx context.Context, root cid.Cid) error {
+	baseDir, err := d.assetsPaths.findPath(root)
+	if err != nil {
+		return err
+	}
+
+	carPath := filepath.Join(baseDir, d.generateAssetName(root))
+	m, hashes, err := d.splitCar(carPath)
+	if err != nil {
+		return xerrors.Errorf("split car %s: %w", carPath, err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// a block stored by the split may be removed by the release of another asset before its ref is added,
+	// the missing blocks are stored again with the lock held
+	if d.missingBlocks(hashes) {
+		if _, _, err := d.splitCar(carPath); err != nil {
+			return xerrors.Errorf("split car %s: %w", carPath, err)
+		}
+	}
+
+	if err := d.addRefs(ctx, hashes); err != nil {
+		return err
+	}
+
+	if err := d.writeManifest(root, m); err != nil {
+		if e := d.releaseRefs(ctx, hashes); e != nil {
+			log.Errorf("release refs of %s error %s", root.String(), e.Error())
+		}
+		return err
+	}
+
+	return os.Remove(carPath)
+}
+
+// splitCar writes the blocks of the car to the blockstore, returns the manifest and the unique hashes of the blocks
+func (d *dedupAsset) splitCar(carPath string) (*manifest, []string, error) {
+	f, err := os.Open(carPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close() //nolint:errcheck // ignore error
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr, err := carv2.NewReader(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cr.Version != 2 {
+		return nil, nil, xerrors.Errorf("unsupported car version %d", cr.Version)
+	}
+
+	dataOffset, dataSize := int64(cr.Header.DataOffset), int64(cr.Header.DataSize)
+
+	m := &manifest{}
+	seen := make(map[string]struct{})
+	hashes := make([]string, 0)
+
+	// pragma, header and padding of car v2
+	if err := m.addInline(io.NewSectionReader(f, 0, dataOffset), dataOffset); err != nil {
+		return nil, nil, err
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, dataOffset, dataSize))
+	read := int64(0)
+
+	// header of car v1
+	length, raw, err := readUvarint(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.appendInline(raw)
+	if err := m.addInline(r, int64(length)); err != nil {
+		return nil, nil, err
+	}
+	read += int64(len(raw)) + int64(length)
+
+	for read < dataSize {
+		length, raw, err := readUvarint(r)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// zero length section is the end of the data
+		if length == 0 {
+			m.appendInline(raw)
+			read += int64(len(raw))
+			break
+		}
+
+		section := make([]byte, length)
+		if _, err := io.ReadFull(r, section); err != nil {
+			return nil, nil, err
+		}
+		read += int64(len(raw)) + int64(length)
+
+		n, c, err := cid.CidFromBytes(section)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m.appendInline(raw)
+		m.appendInline(section[:n])
+
+		hash := c.Hash().String()
+		if err := d.putBlock(c, section[n:]); err != nil {
+			return nil, nil, err
+		}
+		m.Segments = append(m.Segments, segment{Hash: hash, Length: int64(len(section) - n)})
+
+		if _, ok := seen[hash]; !ok {
+			seen[hash] = struct{}{}
+			hashes = append(hashes, hash)
+		}
+	}
+
+	// the rest of the data section, the padding and the index
+	rest := stat.Size() - dataOffset - read
+	if err := m.addInline(io.NewSectionReader(f, dataOffset+read, rest), rest); err != nil {
+		return nil, nil, err
+	}
+
+	return m, hashes, nil
+}
+
+// missingBlocks checks if any of the blocks is not stored
+func (d *dedupAsset) missingBlocks(hashes []string) bool {
+	for _, hash := range hashes {
+		if _, err := os.Stat(d.blockPath(hash)); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// putBlock writes the block data if the block is not stored yet
+func (d *dedupAsset) putBlock(c cid.Cid, data []byte) error {
+	path := d.blockPath(c.Hash().String())
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	hash, err := c.Prefix().Sum(data)
+	if err != nil {
+		return err
+	}
+
+	if !hash.Equals(c) {
+		return xerrors.Errorf("block %s does not match its data", c.String())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temp file and rename, so a block file is never partial,
+	// the temp file is unique because the same block may be put by the splits of several assets
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()           //nolint:errcheck // ignore error
+		os.Remove(f.Name()) //nolint:errcheck // ignore error
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name()) //nolint:errcheck // ignore error
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (d *dedupAsset) writeManifest(root cid.Cid, m *manifest) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(m); err != nil {
+		return err
+	}
+
+	path := d.manifestPath(root)
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(temp, path)
+}
+
+func (d *dedupAsset) readManifest(root cid.Cid) (*manifest, error) {
+	data, err := os.ReadFile(d.manifestPath(root))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// addRefs increases the refs of the blocks, the caller must hold the lock
+func (d *dedupAsset) addRefs(ctx context.Context, hashes []string) error {
+	batch, err := d.refs.Batch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		count, err := d.refCount(ctx, hash)
+		if err != nil {
+			return err
+		}
+
+		if err := batch.Put(ctx, ds.NewKey(hash), encodeRefCount(count+1)); err != nil {
+			return err
+		}
+	}
+
+	return batch.Commit(ctx)
+}
+
+// releaseRefs decreases the refs of the blocks and removes the blocks not used any more, the caller must hold the lock
+func (d *dedupAsset) releaseRefs(ctx context.Context, hashes []string) error {
+	batch, err := d.refs.Batch(ctx)
+	if err != nil {
+		return err
+	}
+
+	unused := make([]string, 0)
+	for _, hash := range hashes {
+		count, err := d.refCount(ctx, hash)
+		if err != nil {
+			return err
+		}
+
+		if count > 1 {
+			err = batch.Put(ctx, ds.NewKey(hash), encodeRefCount(count-1))
+		} else {
+			err = batch.Delete(ctx, ds.NewKey(hash))
+			unused = append(unused, hash)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := batch.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, hash := range unused {
+		if err := os.Remove(d.blockPath(hash)); err != nil && !os.IsNotExist(err) {
+			log.Errorf("remove block %s error %s", hash, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (d *dedupAsset) refCount(ctx context.Context, hash string) (uint32, error) {
+	val, err := d.refs.Get(ctx, ds.NewKey(hash))
+	if err != nil {
+		if err == ds.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(val), nil
+}
+
+func encodeRefCount(count uint32) []byte {
+	bs := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bs, count)
+	return bs
+}
+
+// get returns the car of the asset rebuilt from the manifest, or the car file stored before the deduplication
+func (d *dedupAsset) get(root cid.Cid) (io.ReadSeekCloser, error) {
+	m, err := d.readManifest(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d.asset.get(root)
+		}
+		return nil, err
+	}
+
+	return newManifestReader(m, d.blockPath), nil
+}
+
+// exists checks if the manifest or the car file of the asset exists
+func (d *dedupAsset) exists(root cid.Cid) (bool, error) {
+	if _, err := os.Stat(d.manifestPath(root)); err == nil {
+		return true, nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	return d.asset.exists(root)
+}
+
+// remove deletes the manifest of the asset and releases its blocks
+func (d *dedupAsset) remove(root cid.Cid) error {
+	m, err := d.readManifest(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d.asset.remove(root)
+		}
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if err := os.Remove(d.manifestPath(root)); err != nil {
+		return err
+	}
+
+	if err := d.releaseRefs(context.Background(), m.hashes()); err != nil {
+		return err
+	}
+
+	// remove the pulling blocks and release the path of the asset
+	if err := d.asset.remove(root); err != nil && !os.IsNotExist(err) {
+		log.Debugf("remove asset %s error %s", root.String(), err.Error())
+	}
+	return nil
+}
+
+// count returns the number of the deduplicated assets and the car files
+func (d *dedupAsset) count() (int, error) {
+	entries, err := os.ReadDir(d.manifestsDir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == manifestSuffix {
+			count++
+		}
+	}
+
+	cars, err := d.asset.count()
+	if err != nil {
+		return 0, err
+	}
+
+	return count + cars, nil
+}
+
+// addInline appends n bytes of r to the inline bytes
+func (m *manifest) addInline(r io.Reader, n int64) error {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+
+	m.appendInline(buf)
+	return nil
+}
+
+// appendInline appends the bytes to the inline bytes, merged with the last segment if it is inline too
+func (m *manifest) appendInline(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	if last := len(m.Segments) - 1; last >= 0 && m.Segments[last].Hash == "" {
+		m.Segments[last].Length += int64(len(b))
+	} else {
+		m.Segments = append(m.Segments, segment{Offset: int64(len(m.Inline)), Length: int64(len(b))})
+	}
+	m.Inline = append(m.Inline, b...)
+}
+
+// hashes returns the unique hashes of the blocks
+func (m *manifest) hashes() []string {
+	seen := make(map[string]struct{})
+	hashes := make([]string, 0)
+	for _, s := range m.Segments {
+		if s.Hash == "" {
+			continue
+		}
+
+		if _, ok := seen[s.Hash]; !ok {
+			seen[s.Hash] = struct{}{}
+			hashes = append(hashes, s.Hash)
+		}
+	}
+	return hashes
+}
+
+// manifestReader reads the car rebuilt from the manifest
+type manifestReader struct {
+	m         *manifest
+	blockPath func(hash string) string
+	// offsets of the segments in the car
+	offsets []int64
+	size    int64
+	pos     int64
+}
+
+func newManifestReader(m *manifest, blockPath func(hash string) string) *manifestReader {
+	offsets := make([]int64, len(m.Segments))
+	size := int64(0)
+	for i, s := range m.Segments {
+		offsets[i] = size
+		size += s.Length
+	}
+
+	return &manifestReader{m: m, blockPath: blockPath, offsets: offsets, size: size}
+}
+
+// ReadAt implements io.ReaderAt, so the car is opened as a blockstore
+func (r *manifestReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, xerrors.Errorf("negative offset %d", off)
+	}
+
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	i := sort.Search(len(r.offsets), func(i int) bool { return r.offsets[i] > off }) - 1
+
+	n := 0
+	for n < len(p) && i < len(r.m.Segments) {
+		s := r.m.Segments[i]
+		start := off + int64(n) - r.offsets[i]
+		length := min(int64(len(p)-n), s.Length-start)
+
+		if s.Hash == "" {
+			copy(p[n:n+int(length)], r.m.Inline[s.Offset+start:s.Offset+start+length])
+		} else if err := r.readBlock(s.Hash, p[n:n+int(length)], start); err != nil {
+			return n, err
+		}
+
+		n += int(length)
+		i++
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *manifestReader) readBlock(hash string, p []byte, off int64) error {
+	f, err := os.Open(r.blockPath(hash))
+	if err != nil {
+		return xerrors.Errorf("open block %s: %w", hash, err)
+	}
+	defer f.Close() //nolint:errcheck // ignore error
+
+	_, err = f.ReadAt(p, off)
+	return err
+}
+
+func (r *manifestReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.pos)
+	r.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *manifestReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, xerrors.Errorf("invalid whence %d", whence)
+	}
+
+	if offset < 0 {
+		return 0, xerrors.Errorf("negative position %d", offset)
+	}
+
+	r.pos = offset
+	return offset, nil
+}
+
+func (r *manifestReader) Close() error {
+	return nil
+}
+
+// readUvarint reads a varint, returns the value and the raw bytes
+func readUvarint(r io.ByteReader) (uint64, []byte, error) {
+	raw := make([]byte, 0, binary.MaxVarintLen64)
+	for i := 0; i < binary.MaxVarintLen64; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		raw = append(raw, b)
+		if b < 0x80 {
+			v, _ := binary.Uvarint(raw)
+			return v, raw, nil
+		}
+	}
+
+	return 0, nil, xerrors.New("varint overflows 64 bits")
+}
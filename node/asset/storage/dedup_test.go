@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/lib/carutil"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-car/v2/blockstore"
+)
+
+// generateCar builds the car of the data, returns the root and the car
+func generateCar(t *testing.T, data []byte) (cid.Cid, []byte) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	car := &bytes.Buffer{}
+	root, err := carutil.GenerateFileCar(context.Background(), path, car)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, car.Bytes()
+}
+
+func readAsset(t *testing.T, m *Manager, root cid.Cid) []byte {
+	r, err := m.GetAsset(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close() //nolint:errcheck // ignore error
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func countFiles(t *testing.T, dir string) int {
+	count := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			count++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestDedupAsset(t *testing.T) {
+	ctx := context.Background()
+
+	// the second asset shares the leading chunks of the first one
+	data := make([]byte, 3<<20)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	root1, car1 := generateCar(t, data)
+	root2, car2 := generateCar(t, append(data, data[:1<<20]...))
+
+	dir := t.TempDir()
+	plain, err := NewManager(&ManagerOptions{MetaDataPath: filepath.Join(dir, "plain"), AssetsPaths: []string{filepath.Join(dir, "plain")}, MinioConfig: &config.MinioConfig{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dedup, err := NewManager(&ManagerOptions{MetaDataPath: filepath.Join(dir, "dedup"), AssetsPaths: []string{filepath.Join(dir, "dedup")}, MinioConfig: &config.MinioConfig{}, Dedup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []*Manager{plain, dedup} {
+		if err := m.StoreUserAsset(ctx, "user", root1, int64(len(car1)), bytes.NewReader(car1)); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.StoreUserAsset(ctx, "user", root2, int64(len(car2)), bytes.NewReader(car2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the rebuilt car is the same as the car file
+	for _, root := range []cid.Cid{root1, root2} {
+		expect := readAsset(t, plain, root)
+		if got := readAsset(t, dedup, root); !bytes.Equal(expect, got) {
+			t.Fatalf("asset %s: rebuilt car differs, %d bytes vs %d bytes", root.String(), len(got), len(expect))
+		}
+	}
+
+	r, err := dedup.GetAsset(root2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := blockstore.NewReadOnly(r.(io.ReaderAt), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bs.Get(ctx, root2); err != nil {
+		t.Fatal(err)
+	}
+
+	blocksDir := filepath.Join(dir, "dedup", dedupDir, dedupBlocksDir)
+	shared := countFiles(t, blocksDir)
+
+	dedupAsset := dedup.asset.(*dedupAsset)
+	m1, err := dedupAsset.readManifest(root1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := dedupAsset.readManifest(root2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared >= len(m1.hashes())+len(m2.hashes()) {
+		t.Fatalf("expect the shared blocks stored once, %d blocks for %d + %d", shared, len(m1.hashes()), len(m2.hashes()))
+	}
+
+	if count, err := dedup.AssetCount(); err != nil || count != 2 {
+		t.Fatalf("expect 2 assets, got %d %v", count, err)
+	}
+
+	if err := dedup.DeleteAsset(root1); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(readAsset(t, dedup, root2), readAsset(t, plain, root2)) {
+		t.Fatal("the asset is broken by deleting the asset sharing its blocks")
+	}
+
+	if err := dedup.DeleteAsset(root2); err != nil {
+		t.Fatal(err)
+	}
+	if n := countFiles(t, blocksDir); n != 0 {
+		t.Fatalf("expect no blocks left, got %d", n)
+	}
+}
+
+func TestDedupAssetPaths(t *testing.T) {
+	ctx := context.Background()
+
+	data := make([]byte, 16<<20)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	root, car := generateCar(t, data)
+
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "disk1"), filepath.Join(dir, "disk2")}
+	m, err := NewManager(&ManagerOptions{MetaDataPath: filepath.Join(dir, "meta"), AssetsPaths: paths, MinioConfig: &config.MinioConfig{}, Dedup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.StoreUserAsset(ctx, "user", root, int64(len(car)), bytes.NewReader(car)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the blocks are spread over all the assets paths
+	for _, path := range paths {
+		if n := countFiles(t, filepath.Join(path, dedupDir, dedupBlocksDir)); n == 0 {
+			t.Fatalf("no blocks stored in %s", path)
+		}
+	}
+
+	r, err := m.GetAsset(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close() //nolint:errcheck // ignore error
+
+	bs, err := blockstore.NewReadOnly(r.(io.ReaderAt), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bs.Get(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+}
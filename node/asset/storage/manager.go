@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/hex"
 	"io"
+	"os"
 	"path/filepath"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/carutil"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/shirou/gopsutil/v3/disk"
+	"golang.org/x/xerrors"
 )
 
 var log = logging.Logger("asset/store")
@@ -24,17 +28,37 @@ const (
 	countDir      = "count"
 	assetSuffix   = ".car"
 	assetsViewDir = "assets-view"
+	shardsDir     = "shards"
+	uploadsDir    = "uploads"
+	multipartDir  = "multipart"
+	tempDir       = "tmp"
+	dedupDir      = "dedup"
+	dedupRefsDir  = "dedup-refs"
 	sizeOfBucket  = 128
 )
 
+// assetStore stores the assets as car files or deduplicated blocks
+type assetStore interface {
+	storeBlocks(ctx context.Context, root cid.Cid, blks []blocks.Block) error
+	storeBlocksToCar(ctx context.Context, root cid.Cid) error
+	saveUserAsset(ctx context.Context, userID string, root cid.Cid, assetSize int64, r io.Reader) error
+	get(root cid.Cid) (io.ReadSeekCloser, error)
+	exists(root cid.Cid) (bool, error)
+	remove(root cid.Cid) error
+	count() (int, error)
+}
+
 // Manager handles storage operations
 type Manager struct {
 	opts         *ManagerOptions
-	asset        *asset
+	asset        assetStore
 	wl           *waitList
 	puller       *puller
 	blockCount   *blockCount
 	assetsView   *assetsView
+	shard        *shard
+	upload       *upload
+	multipart    *multipart
 	minioService IMinioService
 }
 
@@ -44,6 +68,8 @@ type ManagerOptions struct {
 	AssetsPaths  []string
 	MinioConfig  *config.MinioConfig
 	SchedulerAPI api.Scheduler
+	// Dedup stores the blocks shared by the assets once
+	Dedup bool
 }
 
 // NewManager creates a new Manager instance
@@ -58,11 +84,28 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		return nil, err
 	}
 
-	asset, err := newAsset(assetsPaths, assetSuffix)
+	carAsset, err := newAsset(assetsPaths, assetSuffix)
 	if err != nil {
 		return nil, err
 	}
 
+	var asset assetStore = carAsset
+	if opts.Dedup {
+		// the shared blocks are spread over the assets paths
+		dedupBaseDirs := []string{filepath.Join(opts.MetaDataPath, dedupDir)}
+		if len(opts.AssetsPaths) > 0 {
+			dedupBaseDirs = make([]string, 0, len(opts.AssetsPaths))
+			for _, path := range opts.AssetsPaths {
+				dedupBaseDirs = append(dedupBaseDirs, filepath.Join(path, dedupDir))
+			}
+		}
+
+		asset, err = newDedupAsset(carAsset, dedupBaseDirs, filepath.Join(opts.MetaDataPath, dedupRefsDir))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	puller, err := newPuller(filepath.Join(opts.MetaDataPath, pullerDir))
 	if err != nil {
 		return nil, err
@@ -78,16 +121,47 @@ func NewManager(opts *ManagerOptions) (*Manager, error) {
 		return nil, err
 	}
 
+	// shards are saved in the first assets path
+	shardsBaseDir := opts.MetaDataPath
+	if len(opts.AssetsPaths) > 0 {
+		shardsBaseDir = opts.AssetsPaths[0]
+	}
+
+	shard, err := newShard(filepath.Join(shardsBaseDir, shardsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	// the data of uploads is large, it is saved in the same path with shards
+	upload, err := newUpload(filepath.Join(opts.MetaDataPath, uploadsDir), filepath.Join(shardsBaseDir, uploadsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	multipart, err := newMultipart(filepath.Join(shardsBaseDir, multipartDir), filepath.Join(shardsBaseDir, tempDir))
+	if err != nil {
+		return nil, err
+	}
+
 	waitList := newWaitList(filepath.Join(opts.MetaDataPath, waitListFile))
-	return &Manager{
+	m := &Manager{
 		asset:        asset,
 		assetsView:   assetsView,
+		shard:        shard,
+		upload:       upload,
+		multipart:    multipart,
 		wl:           waitList,
 		puller:       puller,
 		blockCount:   blockCount,
 		opts:         opts,
 		minioService: minio,
-	}, nil
+	}
+
+	if len(opts.AssetsPaths) > 0 {
+		go m.startRecordDiskUsage()
+	}
+
+	return m, nil
 }
 
 // StorePuller stores puller data in storage
@@ -155,6 +229,148 @@ func (m *Manager) SetBlockCount(ctx context.Context, root cid.Cid, count uint32)
 	return m.blockCount.storeBlockCount(ctx, root, count)
 }
 
+// Shard API
+
+// StoreShards creates the erasure coded shards with the given indexes and writes them by the write func
+func (m *Manager) StoreShards(root cid.Cid, indexes []int64, write func(writers []io.Writer) error) error {
+	return m.shard.store(root, indexes, write)
+}
+
+// GetShard retrieves an erasure coded shard of an asset
+func (m *Manager) GetShard(root cid.Cid, index int64) (io.ReadSeekCloser, error) {
+	return m.shard.get(root, index)
+}
+
+// GetShardIndexes returns the indexes of the shards stored for an asset
+func (m *Manager) GetShardIndexes(root cid.Cid) ([]int64, error) {
+	return m.shard.indexes(root)
+}
+
+// DeleteShards removes all shards of an asset
+func (m *Manager) DeleteShards(root cid.Cid) error {
+	return m.shard.remove(root)
+}
+
+// Upload API
+
+// StoreUploadSession stores the session of a resumable upload
+func (m *Manager) StoreUploadSession(root cid.Cid, data []byte) error {
+	return m.upload.storeSession(root, data)
+}
+
+// GetUploadSession retrieves the session of a resumable upload
+func (m *Manager) GetUploadSession(root cid.Cid) ([]byte, error) {
+	return m.upload.getSession(root)
+}
+
+// ListUploadSessions retrieves the sessions of all resumable uploads
+func (m *Manager) ListUploadSessions() ([][]byte, error) {
+	return m.upload.sessions()
+}
+
+// AppendUploadData writes the data of a resumable upload at the offset, returns the number of bytes written
+func (m *Manager) AppendUploadData(root cid.Cid, offset int64, r io.Reader) (int64, error) {
+	return m.upload.appendData(root, offset, r)
+}
+
+// GetUploadData retrieves the received data of a resumable upload
+func (m *Manager) GetUploadData(root cid.Cid) (io.ReadCloser, error) {
+	return m.upload.getData(root)
+}
+
+// DeleteUpload removes the session and the data of a resumable upload
+func (m *Manager) DeleteUpload(root cid.Cid) error {
+	return m.upload.remove(root)
+}
+
+// S3 API
+
+// StoreFileAsset builds the car of the file read from r and stores it as the user asset
+func (m *Manager) StoreFileAsset(ctx context.Context, userID string, r io.Reader) (*types.FileAsset, error) {
+	file, err := m.multipart.createTemp("file-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		file.Close() //nolint:errcheck // ignore error
+		os.Remove(file.Name())
+	}()
+
+	size, err := io.Copy(file, r)
+	if err != nil {
+		return nil, err
+	}
+
+	carFile, err := m.multipart.createTemp("car-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		carFile.Close() //nolint:errcheck // ignore error
+		os.Remove(carFile.Name())
+	}()
+
+	root, err := carutil.GenerateFileCar(ctx, file.Name(), carFile)
+	if err != nil {
+		return nil, xerrors.Errorf("generate car: %w", err)
+	}
+
+	carSize, err := carFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := carFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	existed, err := m.asset.exists(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.asset.saveUserAsset(ctx, userID, root, carSize, carFile); err != nil {
+		return nil, err
+	}
+
+	return &types.FileAsset{CID: root.String(), Size: size, CarSize: carSize, Existed: existed}, nil
+}
+
+// StoreMultipartUpload stores the information of an s3 multipart upload
+func (m *Manager) StoreMultipartUpload(uploadID string, data []byte) error {
+	return m.multipart.storeUpload(uploadID, data)
+}
+
+// GetMultipartUpload retrieves the information of an s3 multipart upload
+func (m *Manager) GetMultipartUpload(uploadID string) ([]byte, error) {
+	return m.multipart.getUpload(uploadID)
+}
+
+// ListMultipartUploads returns the ids of all s3 multipart uploads
+func (m *Manager) ListMultipartUploads() ([]string, error) {
+	return m.multipart.uploads()
+}
+
+// StoreMultipartPart stores a part of an s3 multipart upload
+func (m *Manager) StoreMultipartPart(uploadID string, partNumber int, r io.Reader) (*types.MultipartPart, error) {
+	return m.multipart.storePart(uploadID, partNumber, r)
+}
+
+// GetMultipartParts returns the parts of an s3 multipart upload
+func (m *Manager) GetMultipartParts(uploadID string) ([]*types.MultipartPart, error) {
+	return m.multipart.parts(uploadID)
+}
+
+// GetMultipartPart retrieves the data of a part of an s3 multipart upload
+func (m *Manager) GetMultipartPart(uploadID string, part *types.MultipartPart) (io.ReadCloser, error) {
+	return m.multipart.getPart(uploadID, part)
+}
+
+// DeleteMultipartUpload removes an s3 multipart upload and its parts
+func (m *Manager) DeleteMultipartUpload(uploadID string) error {
+	return m.multipart.remove(uploadID)
+}
+
 // AssetsView API
 // GetTopHash retrieves the top hash of assets
 func (m *Manager) GetTopHash(ctx context.Context) (string, error) {
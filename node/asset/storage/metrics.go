@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/metrics"
+	"github.com/shirou/gopsutil/v3/disk"
+	"go.opencensus.io/tag"
+)
+
+// diskUsageInterval is the interval to record the disk usage of the assets paths
+const diskUsageInterval = time.Minute
+
+// startRecordDiskUsage periodically records the disk usage of every assets path
+func (m *Manager) startRecordDiskUsage() {
+	ticker := time.NewTicker(diskUsageInterval)
+	defer ticker.Stop()
+
+	for {
+		m.recordDiskUsage()
+		<-ticker.C
+	}
+}
+
+func (m *Manager) recordDiskUsage() {
+	for _, path := range m.opts.AssetsPaths {
+		usageStat, err := disk.Usage(path)
+		if err != nil {
+			log.Errorf("get disk usage stat of %s error: %s", path, err)
+			continue
+		}
+
+		tags := []tag.Mutator{tag.Upsert(metrics.AssetsPath, path)}
+		metrics.RecordWithTags(context.Background(), tags, metrics.DiskUsed.M(int64(usageStat.Used)), metrics.DiskTotal.M(int64(usageStat.Total)))
+	}
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+const (
+	multipartUploadFile = "upload"
+	tempPartSuffix      = ".tmp"
+)
+
+// multipart saves the parts of s3 multipart uploads, the parts of an upload are saved in the directory
+// named by the upload id, the file name of part is "<part-number>.<md5>"
+type multipart struct {
+	baseDir string
+	// temporary files of importing assets
+	tempDir string
+}
+
+// newMultipart initializes a new multipart instance, the temporary files left by last run are removed.
+func newMultipart(baseDir, tempDir string) (*multipart, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := os.RemoveAll(tempDir); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(tempDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &multipart{baseDir: baseDir, tempDir: tempDir}, nil
+}
+
+func (mp *multipart) uploadDir(uploadID string) (string, error) {
+	if len(uploadID) == 0 || filepath.Base(uploadID) != uploadID {
+		return "", fmt.Errorf("invalid upload id %s", uploadID)
+	}
+	return filepath.Join(mp.baseDir, uploadID), nil
+}
+
+// storeUpload writes the information of upload
+func (mp *multipart) storeUpload(uploadID string, data []byte) error {
+	dir, err := mp.uploadDir(uploadID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, multipartUploadFile), data, 0o644)
+}
+
+// getUpload reads the information of upload
+func (mp *multipart) getUpload(uploadID string) ([]byte, error) {
+	dir, err := mp.uploadDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(filepath.Join(dir, multipartUploadFile))
+}
+
+// uploads returns the ids of all uploads
+func (mp *multipart) uploads() ([]string, error) {
+	entries, err := os.ReadDir(mp.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			ids = append(ids, entry.Name())
+		}
+	}
+	return ids, nil
+}
+
+// storePart writes the part read from r, the part with the same number is replaced
+func (mp *multipart) storePart(uploadID string, partNumber int, r io.Reader) (*types.MultipartPart, error) {
+	dir, err := mp.uploadDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, multipartUploadFile)); err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp(dir, strconv.Itoa(partNumber)+".*"+tempPartSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		f.Close() //nolint:errcheck // ignore error
+		os.Remove(f.Name())
+	}()
+
+	h := md5.New()
+	size, err := io.Copy(f, io.TeeReader(r, h))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+
+	old, err := mp.parts(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &types.MultipartPart{PartNumber: partNumber, ETag: hex.EncodeToString(h.Sum(nil)), Size: size}
+	if err := os.Rename(f.Name(), mp.partPath(dir, part)); err != nil {
+		return nil, err
+	}
+
+	for _, p := range old {
+		if p.PartNumber == partNumber && p.ETag != part.ETag {
+			os.Remove(mp.partPath(dir, p))
+		}
+	}
+
+	return part, nil
+}
+
+func (mp *multipart) partPath(dir string, part *types.MultipartPart) string {
+	return filepath.Join(dir, fmt.Sprintf("%d.%s", part.PartNumber, part.ETag))
+}
+
+// parts returns the parts of upload sorted by part number
+func (mp *multipart) parts(uploadID string) ([]*types.MultipartPart, error) {
+	dir, err := mp.uploadDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*types.MultipartPart, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == multipartUploadFile || strings.HasSuffix(name, tempPartSuffix) {
+			continue
+		}
+
+		fields := strings.SplitN(name, ".", 2)
+		number, err := strconv.Atoi(fields[0])
+		if err != nil || len(fields) != 2 {
+			return nil, fmt.Errorf("invalid part file %s", name)
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, &types.MultipartPart{PartNumber: number, ETag: fields[1], Size: info.Size(), LastModified: info.ModTime()})
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+// getPart returns a reader of the part, the caller must close the reader
+func (mp *multipart) getPart(uploadID string, part *types.MultipartPart) (io.ReadCloser, error) {
+	dir, err := mp.uploadDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(mp.partPath(dir, part))
+}
+
+// remove deletes the upload and all its parts
+func (mp *multipart) remove(uploadID string) error {
+	dir, err := mp.uploadDir(uploadID)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// createTemp creates a temporary file, the caller must remove the file
+func (mp *multipart) createTemp(pattern string) (*os.File, error) {
+	return os.CreateTemp(mp.tempDir, pattern)
+}
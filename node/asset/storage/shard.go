@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+)
+
+const tempShardSuffix = ".tmp"
+
+// shard save erasure coded shards of assets,
+// shards of an asset are saved in the directory named by the asset hash, the file name is the shard index
+type shard struct {
+	baseDir string
+}
+
+// newShard initializes a new shard instance.
+func newShard(baseDir string) (*shard, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+	return &shard{baseDir: baseDir}, nil
+}
+
+func (s *shard) shardDir(root cid.Cid) string {
+	return filepath.Join(s.baseDir, root.Hash().String())
+}
+
+func (s *shard) shardPath(root cid.Cid, index int64) string {
+	return filepath.Join(s.shardDir(root), strconv.FormatInt(index, 10))
+}
+
+// store creates the shards with the given indexes and passes their writers to write,
+// the shards are visible only after write returns without error
+func (s *shard) store(root cid.Cid, indexes []int64, write func(writers []io.Writer) error) error {
+	if err := os.MkdirAll(s.shardDir(root), 0o755); err != nil {
+		return err
+	}
+
+	files := make([]*os.File, 0, len(indexes))
+	defer func() {
+		for _, f := range files {
+			f.Close() //nolint:errcheck // ignore error
+			os.Remove(f.Name())
+		}
+	}()
+
+	writers := make([]io.Writer, 0, len(indexes))
+	for _, index := range indexes {
+		f, err := os.Create(s.shardPath(root, index) + tempShardSuffix)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		writers = append(writers, f)
+	}
+
+	if err := write(writers); err != nil {
+		return err
+	}
+
+	for i, f := range files {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+
+		if err := os.Rename(f.Name(), s.shardPath(root, indexes[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// get returns a ReadSeekCloser for the shard with the given index.
+// The caller must close the reader.
+func (s *shard) get(root cid.Cid, index int64) (io.ReadSeekCloser, error) {
+	return os.Open(s.shardPath(root, index))
+}
+
+// indexes returns the indexes of the shards stored for the asset
+func (s *shard) indexes(root cid.Cid) ([]int64, error) {
+	entries, err := os.ReadDir(s.shardDir(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	indexes := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) == tempShardSuffix {
+			continue
+		}
+
+		index, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shard file %s", entry.Name())
+		}
+		indexes = append(indexes, index)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	return indexes, nil
+}
+
+// remove deletes all shards of the asset
+func (s *shard) remove(root cid.Cid) error {
+	return os.RemoveAll(s.shardDir(root))
+}
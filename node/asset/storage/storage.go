@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-libipfs/blocks"
 )
@@ -26,6 +27,30 @@ type Storage interface {
 	GetBlockCount(ctx context.Context, root cid.Cid) (uint32, error)
 	SetBlockCount(ctx context.Context, root cid.Cid, count uint32) error
 
+	// erasure coded shards
+	StoreShards(root cid.Cid, indexes []int64, write func(writers []io.Writer) error) error
+	GetShard(root cid.Cid, index int64) (io.ReadSeekCloser, error)
+	GetShardIndexes(root cid.Cid) ([]int64, error)
+	DeleteShards(root cid.Cid) error
+
+	// resumable uploads
+	StoreUploadSession(root cid.Cid, data []byte) error
+	GetUploadSession(root cid.Cid) ([]byte, error)
+	ListUploadSessions() ([][]byte, error)
+	AppendUploadData(root cid.Cid, offset int64, r io.Reader) (int64, error)
+	GetUploadData(root cid.Cid) (io.ReadCloser, error)
+	DeleteUpload(root cid.Cid) error
+
+	// s3 file assets and multipart uploads
+	StoreFileAsset(ctx context.Context, userID string, r io.Reader) (*types.FileAsset, error)
+	StoreMultipartUpload(uploadID string, data []byte) error
+	GetMultipartUpload(uploadID string) ([]byte, error)
+	ListMultipartUploads() ([]string, error)
+	StoreMultipartPart(uploadID string, partNumber int, r io.Reader) (*types.MultipartPart, error)
+	GetMultipartParts(uploadID string) ([]*types.MultipartPart, error)
+	GetMultipartPart(uploadID string, part *types.MultipartPart) (io.ReadCloser, error)
+	DeleteMultipartUpload(uploadID string) error
+
 	// assets view
 	GetTopHash(ctx context.Context) (string, error)
 	GetBucketHashes(ctx context.Context) (map[uint32]string, error)
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ipfs/go-cid"
+)
+
+// upload saves the sessions and the received data of resumable uploads,
+// the session is saved in metaDir and the data is saved in dataDir, both are named by the asset hash
+type upload struct {
+	metaDir string
+	dataDir string
+}
+
+// newUpload initializes a new upload instance.
+func newUpload(metaDir, dataDir string) (*upload, error) {
+	if err := os.MkdirAll(metaDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &upload{metaDir: metaDir, dataDir: dataDir}, nil
+}
+
+func (u *upload) sessionPath(root cid.Cid) string {
+	return filepath.Join(u.metaDir, root.Hash().String())
+}
+
+func (u *upload) dataPath(root cid.Cid) string {
+	return filepath.Join(u.dataDir, root.Hash().String())
+}
+
+// storeSession writes the session of upload
+func (u *upload) storeSession(root cid.Cid, data []byte) error {
+	tempPath := u.sessionPath(root) + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, u.sessionPath(root))
+}
+
+// getSession reads the session of upload
+func (u *upload) getSession(root cid.Cid) ([]byte, error) {
+	return os.ReadFile(u.sessionPath(root))
+}
+
+// sessions reads all sessions of uploads
+func (u *upload) sessions() ([][]byte, error) {
+	entries, err := os.ReadDir(u.metaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) == ".tmp" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(u.metaDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+
+	return out, nil
+}
+
+// appendData writes the data read from r at the offset, the data after the offset is discarded.
+// It returns the number of bytes written, which are synced to disk even if an error occurs.
+func (u *upload) appendData(root cid.Cid, offset int64, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(u.dataPath(root), os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() //nolint:errcheck // ignore error
+
+	// the data after the committed offset may be left by an interrupted write
+	if err := f.Truncate(offset); err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(f, r)
+	if e := f.Sync(); e != nil && err == nil {
+		err = e
+	}
+
+	return n, err
+}
+
+// getData returns a reader of the received data
+func (u *upload) getData(root cid.Cid) (io.ReadCloser, error) {
+	return os.Open(u.dataPath(root))
+}
+
+// remove deletes the session and the data of upload
+func (u *upload) remove(root cid.Cid) error {
+	if err := os.Remove(u.dataPath(root)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Remove(u.sessionPath(root)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
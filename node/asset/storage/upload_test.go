@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+type failReader struct {
+	data []byte
+}
+
+func (fr *failReader) Read(p []byte) (int, error) {
+	if len(fr.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, fr.data)
+	fr.data = fr.data[n:]
+	return n, nil
+}
+
+func TestUploadResume(t *testing.T) {
+	dir := t.TempDir()
+	u, err := newUpload(filepath.Join(dir, "meta"), filepath.Join(dir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := cid.Decode("QmUS9e1GisPtRtB6NAda23jtCEB5Go2SGyZy5Yf9YBLd8a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("0123456789abcdefghij")
+
+	// the connection drops after 8 bytes
+	n, err := u.appendData(root, 0, &failReader{data: data[:8]})
+	if err == nil || n != 8 {
+		t.Fatalf("append %d bytes, error %v, expect 8 bytes with error", n, err)
+	}
+
+	// resume from a stale offset, the data after it is discarded
+	if n, err = u.appendData(root, 5, bytes.NewReader(data[5:])); err != nil || n != int64(len(data)-5) {
+		t.Fatalf("append %d bytes, error %v", n, err)
+	}
+
+	reader, err := u.getData(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	received, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(received, data) {
+		t.Fatalf("received %s, expect %s", received, data)
+	}
+
+	if err := u.storeSession(root, []byte("session")); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := u.sessions()
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("sessions %d, error %v", len(sessions), err)
+	}
+
+	if err := u.remove(root); err != nil {
+		t.Fatal(err)
+	}
+
+	if sessions, _ = u.sessions(); len(sessions) != 0 {
+		t.Fatalf("sessions %d after remove", len(sessions))
+	}
+}
@@ -0,0 +1,205 @@
+package asset
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+// CreateUploadSession creates a resumable upload session for the user asset,
+// the existing session of the same user and size is resumed with the new expiration,
+// the session of another user is never discarded.
+func (m *Manager) CreateUploadSession(ctx context.Context, userID string, root cid.Cid, size int64, expiration time.Time) (*types.UploadSession, error) {
+	if _, loaded := m.uploadBusy.LoadOrStore(root.Hash().String(), struct{}{}); loaded {
+		return nil, types.ErrUploadSessionBusy
+	}
+	defer m.uploadBusy.Delete(root.Hash().String())
+
+	session, err := m.loadUploadSession(root)
+	if err != nil && err != types.ErrUploadSessionNotFound {
+		return nil, err
+	}
+
+	if session != nil && session.UserID != userID {
+		return nil, types.ErrUploadSessionNotOwned
+	}
+
+	if session == nil || session.Size != size {
+		if session != nil {
+			log.Infof("discard upload session of asset %s, user %s, size %d", root.String(), session.UserID, session.Size)
+		}
+
+		if err := m.DeleteUpload(root); err != nil {
+			return nil, err
+		}
+
+		session = &types.UploadSession{AssetCID: root.String(), UserID: userID, Size: size}
+	}
+
+	session.Expiration = expiration
+	if err := m.saveUploadSession(root, session); err != nil {
+		return nil, err
+	}
+
+	m.setUploadSessionProgress(root, session)
+	return session, nil
+}
+
+// GetUploadSession returns the resumable upload session of the asset
+func (m *Manager) GetUploadSession(ctx context.Context, root cid.Cid) (*types.UploadSession, error) {
+	return m.loadUploadSession(root)
+}
+
+// AppendUploadSession writes the data read from r to the upload session at the offset,
+// the asset is saved after all data received. It returns the session with the new offset.
+func (m *Manager) AppendUploadSession(ctx context.Context, root cid.Cid, offset int64, r io.Reader) (*types.UploadSession, error) {
+	if _, loaded := m.uploadBusy.LoadOrStore(root.Hash().String(), struct{}{}); loaded {
+		return nil, types.ErrUploadSessionBusy
+	}
+	defer m.uploadBusy.Delete(root.Hash().String())
+
+	session, err := m.loadUploadSession(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != session.Offset {
+		return session, types.ErrUploadOffsetMismatch
+	}
+
+	n, err := m.AppendUploadData(root, offset, io.LimitReader(r, session.Size-offset))
+	session.Offset += n
+
+	if e := m.saveUploadSession(root, session); e != nil {
+		return nil, e
+	}
+
+	if err != nil {
+		// the received data is kept, the client can resume from the new offset
+		m.setUploadSessionProgress(root, session)
+		return session, err
+	}
+
+	if session.Offset < session.Size {
+		m.setUploadSessionProgress(root, session)
+		return session, nil
+	}
+
+	return session, m.finishUploadSession(ctx, root, session)
+}
+
+// DeleteUploadSession terminates the resumable upload session and removes the received data
+func (m *Manager) DeleteUploadSession(ctx context.Context, root cid.Cid) error {
+	if _, loaded := m.uploadBusy.LoadOrStore(root.Hash().String(), struct{}{}); loaded {
+		return types.ErrUploadSessionBusy
+	}
+	defer m.uploadBusy.Delete(root.Hash().String())
+
+	return m.DeleteUpload(root)
+}
+
+// finishUploadSession saves the received data as the user asset, the data is verified with the root cid
+func (m *Manager) finishUploadSession(ctx context.Context, root cid.Cid, session *types.UploadSession) error {
+	reader, err := m.GetUploadData(root)
+	if err != nil {
+		return err
+	}
+	defer reader.Close() //nolint:errcheck // ignore error
+
+	err = m.SaveUserAsset(ctx, session.UserID, root, session.Size, reader)
+
+	// the data can not be resumed if it is not a valid car of the root
+	if e := m.DeleteUpload(root); e != nil {
+		log.Errorf("delete upload %s error %s", root.String(), e.Error())
+	}
+
+	if err != nil {
+		return xerrors.Errorf("save user asset error %w", err)
+	}
+
+	progress := &types.UploadProgress{TotalSize: session.Size, DoneSize: session.Size}
+	return m.SetAssetUploadProgress(ctx, root, progress)
+}
+
+func (m *Manager) loadUploadSession(root cid.Cid) (*types.UploadSession, error) {
+	data, err := m.Storage.GetUploadSession(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, types.ErrUploadSessionNotFound
+		}
+		return nil, err
+	}
+
+	session := &types.UploadSession{}
+	if err := decode(data, session); err != nil {
+		return nil, err
+	}
+
+	if session.Expiration.Before(time.Now()) {
+		if err := m.DeleteUpload(root); err != nil {
+			log.Errorf("delete expired upload %s error %s", root.String(), err.Error())
+		}
+		return nil, types.ErrUploadSessionNotFound
+	}
+
+	return session, nil
+}
+
+func (m *Manager) saveUploadSession(root cid.Cid, session *types.UploadSession) error {
+	data, err := encode(session)
+	if err != nil {
+		return err
+	}
+
+	return m.StoreUploadSession(root, data)
+}
+
+// setUploadSessionProgress reports the offset of session as the upload progress
+func (m *Manager) setUploadSessionProgress(root cid.Cid, session *types.UploadSession) {
+	v, ok := m.uploadingAssets.Load(root.Hash().String())
+	if !ok {
+		v = &types.UploadingAsset{UserID: session.UserID, TokenExpiration: session.Expiration}
+	}
+
+	asset := v.(*types.UploadingAsset)
+	asset.Progress = &types.UploadProgress{TotalSize: session.Size, DoneSize: session.Offset}
+	m.uploadingAssets.Store(root.Hash().String(), asset)
+}
+
+// restoreUploadSessions restores the upload progress of unexpired sessions after restart
+func (m *Manager) restoreUploadSessions() {
+	sessions, err := m.ListUploadSessions()
+	if err != nil {
+		log.Errorf("list upload sessions error %s", err.Error())
+		return
+	}
+
+	for _, data := range sessions {
+		session := &types.UploadSession{}
+		if err := decode(data, session); err != nil {
+			log.Errorf("decode upload session error %s", err.Error())
+			continue
+		}
+
+		root, err := cid.Decode(session.AssetCID)
+		if err != nil {
+			log.Errorf("decode upload session cid error %s", err.Error())
+			continue
+		}
+
+		if session.Expiration.Before(time.Now()) {
+			if err := m.DeleteUpload(root); err != nil {
+				log.Errorf("delete expired upload %s error %s", root.String(), err.Error())
+			}
+			continue
+		}
+
+		m.setUploadSessionProgress(root, session)
+		log.Infof("restore upload session of asset %s, %d/%d", root.String(), session.Offset, session.Size)
+	}
+}
@@ -0,0 +1,44 @@
+package asset
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/asset/storage"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/ipfs/go-cid"
+)
+
+func TestCreateUploadSessionOwner(t *testing.T) {
+	root, err := cid.Decode("QmPXME1oRtoT627YKaDPDQ3PwA8tdP9rWuAAweLzqSwAWT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	storageMgr, err := storage.NewManager(&storage.ManagerOptions{MetaDataPath: dir, AssetsPaths: []string{filepath.Join(dir, "assets")}, MinioConfig: &config.MinioConfig{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{Storage: storageMgr, uploadingAssets: &sync.Map{}, uploadBusy: &sync.Map{}}
+	ctx := context.Background()
+	expiration := time.Now().Add(time.Hour)
+
+	if _, err := m.CreateUploadSession(ctx, "owner", root, 10, expiration); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.CreateUploadSession(ctx, "other", root, 10, expiration); err != types.ErrUploadSessionNotOwned {
+		t.Fatalf("create session of other user: %v", err)
+	}
+
+	session, err := m.GetUploadSession(ctx, root)
+	if err != nil || session.UserID != "owner" {
+		t.Fatalf("session of owner is discarded: %+v, %v", session, err)
+	}
+}
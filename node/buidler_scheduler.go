@@ -17,6 +17,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
 	"github.com/Filecoin-Titan/titan/node/scheduler/nat"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/sharding"
 	"github.com/Filecoin-Titan/titan/node/scheduler/sync"
 	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
 	"github.com/Filecoin-Titan/titan/node/scheduler/workload"
@@ -70,8 +71,10 @@ func ConfigScheduler(c interface{}) Option {
 		Override(new(*assets.Manager), modules.NewStorageManager),
 		Override(new(*sync.DataSync), sync.NewDataSync),
 		Override(new(*validation.Manager), modules.NewValidation),
+		Override(new(*sharding.Manager), modules.NewSharding),
 		Override(new(*nat.Manager), nat.NewManager),
 		Override(new(*scheduler.EdgeUpdateManager), scheduler.NewEdgeUpdateManager),
+		Override(new(*scheduler.AuditLogCleaner), modules.NewAuditLogCleaner),
 		Override(new(dtypes.SetSchedulerConfigFunc), modules.NewSetSchedulerConfigFunc),
 		Override(new(dtypes.GetSchedulerConfigFunc), modules.NewGetSchedulerConfigFunc),
 		Override(new(*rsa.PrivateKey), modules.NewPrivateKey),
@@ -49,8 +49,8 @@ func ConfigEdge(c interface{}) Option {
 		Override(new(*config.EdgeCfg), cfg),
 		Override(new(*device.Device), modules.NewDevice(&cfg.CPU, &cfg.Memory, &cfg.Storage, &cfg.Bandwidth)),
 		Override(new(*config.MinioConfig), &config.MinioConfig{}),
-		Override(new(*storage.Manager), modules.NewNodeStorageManager),
-		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.PullBlockParallel, cfg.PullBlockTimeout, cfg.PullBlockRetry, cfg.IPFSAPIURL)),
+		Override(new(*storage.Manager), modules.NewNodeStorageManager(cfg.Storage.Dedup)),
+		Override(new(*asset.Manager), modules.NewAssetsManager(cfg.PullBlockParallel, cfg.PullBlockTimeout, cfg.PullBlockRetry, cfg.IPFSAPIURL, cfg.IPFSGateways, cfg.LocalImportPath)),
 		Override(new(*validation.Validation), modules.NewNodeValidation),
 		Override(new(*rate.Limiter), modules.NewRateLimiter),
 		Override(new(*asset.Asset), asset.NewAsset),
@@ -49,6 +49,7 @@ func ConfigLocator(c interface{}) Option {
 		Override(new(region.Region), modules.NewRegion),
 		Override(new(locator.Storage), modules.NewLocatorStorage),
 		Override(new(locator.SchedulerAPIMap), modules.NewSchedulerAPIMap),
+		Override(new(*locator.HealthChecker), locator.NewHealthChecker),
 		Override(new(dtypes.EtcdAddresses), func() dtypes.EtcdAddresses {
 			return dtypes.EtcdAddresses(cfg.EtcdAddresses)
 		}),
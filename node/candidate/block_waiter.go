@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/merkle"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/ipfs/go-cid"
 	mh "github.com/multiformats/go-multihash"
@@ -90,6 +92,14 @@ func (bw *blockWaiter) wait() {
 			}
 			size += int64(tcpMsg.length)
 			bw.result.RandomCount++
+		case api.TCPMsgTypeProof:
+			result, err := verifyStorageProof(tcpMsg.msg)
+			if err != nil {
+				log.Errorf("waitBlock, verify storage proof error:%v", err)
+				continue
+			}
+			bw.result.Proofs = append(bw.result.Proofs, result)
+			size += int64(tcpMsg.length)
 		}
 
 	}
@@ -142,3 +152,26 @@ func cidFromData(data []byte) (string, error) {
 
 	return c.String(), nil
 }
+
+// verifyStorageProof checks the block data matches the multihash of the leaf, and the leaf is included in the committed root
+func verifyStorageProof(data []byte) (*types.StorageProofResult, error) {
+	proof := &types.StorageProof{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(proof); err != nil {
+		return nil, err
+	}
+
+	result := &types.StorageProofResult{AssetCID: proof.AssetCID, Leaf: proof.Leaf, Root: hex.EncodeToString(proof.Root), Leaves: proof.Leaves}
+
+	decoded, err := mh.Decode(proof.Multihash)
+	if err != nil {
+		return result, nil
+	}
+
+	sum, err := mh.Sum(proof.Data, decoded.Code, decoded.Length)
+	if err != nil || !bytes.Equal(sum, proof.Multihash) {
+		return result, nil
+	}
+
+	result.Verified = merkle.Verify(proof.Root, proof.Multihash, proof.Leaf, proof.Leaves, proof.Proof)
+	return result, nil
+}
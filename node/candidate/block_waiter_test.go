@@ -0,0 +1,64 @@
+package candidate
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/merkle"
+	mh "github.com/multiformats/go-multihash"
+)
+
+func TestVerifyStorageProof(t *testing.T) {
+	data := [][]byte{[]byte("block 1"), []byte("block 2"), []byte("block 3")}
+	leaves := make([][]byte, 0, len(data))
+	for _, d := range data {
+		sum, err := mh.Sum(d, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves = append(leaves, sum)
+	}
+
+	tree, err := merkle.NewTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := func(leaf int, blockData []byte) []byte {
+		proof, err := tree.Proof(leaf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		sp := &types.StorageProof{AssetCID: "asset", Leaf: leaf, Root: tree.Root(), Leaves: tree.Leaves(), Multihash: leaves[leaf], Data: blockData, Proof: proof}
+		if err := gob.NewEncoder(&buf).Encode(sp); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	result, err := verifyStorageProof(encode(1, data[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified || result.Root != hex.EncodeToString(tree.Root()) || result.Leaves != len(data) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// the data does not match the multihash of the leaf
+	result, err = verifyStorageProof(encode(1, data[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verified {
+		t.Fatal("proof with other block data is verified")
+	}
+
+	if _, err = verifyStorageProof([]byte("invalid")); err == nil {
+		t.Fatal("invalid proof is decoded")
+	}
+}
@@ -126,6 +126,7 @@ func DefaultLocatorCfg() *LocatorCfg {
 		EtcdAddresses:      []string{"127.0.0.1:2379"},
 		DefaultAreaID:      "Asia-China-Guangdong-Shenzhen",
 		DNSServerAddress:   "0.0.0.0:53",
+		DNSMaxAnswers:      3,
 	}
 }
 
@@ -146,7 +147,14 @@ func DefaultSchedulerCfg() *SchedulerCfg {
 		ValidatorRatio:          1,
 		ValidatorBaseBwDn:       100,
 		ValidationProfit:        0,
+		EnableStorageProof:      true,
+		StorageProofAssets:      8,
+		StorageProofLeaves:      4,
 		WorkloadProfit:          0,
+		WorkloadSpeedTolerance:  2,
+		WorkloadMaxTokenIPs:     3,
+		WorkloadClientVolume:    500 << 30,
+		WorkloadPairDailyLimit:  500,
 		ElectionCycle:           5,
 		LotusRPCAddress:         "http://api.node.glif.io/rpc/v0",
 		LotusToken:              "",
@@ -175,6 +183,14 @@ func DefaultSchedulerCfg() *SchedulerCfg {
 		MaxAPIKey:                5,
 		// Maximum number of node registrations for the same IP on the same day
 		MaxNumberOfRegistrations: 15,
+		RetrievalsPerEdgeReplica: 1000,
+		MaxScaleEdgeReplicas:     50,
+		AuditLogRetentionDays:    180,
+
+		// 10G and 2M/s for the non vip user, 1T and unlimited bandwidth for the vip user
+		UserFreeMonthlyEgress: 10737418240,
+		UserVipMonthlyEgress:  1099511627776,
+		UserFreeLimitRate:     2097152,
 	}
 }
 
@@ -65,6 +65,15 @@ func GetUserID(ctx context.Context) string {
 	return v
 }
 
+// GetCallerID returns the id in the token of the client whatever its role
+func GetCallerID(ctx context.Context) string {
+	v, ok := ctx.Value(ID{}).(string)
+	if !ok {
+		return ""
+	}
+	return v
+}
+
 // New returns a new HTTP handler with the given auth handler and additional request context fields
 func New(verify func(ctx context.Context, token string) (*types.JWTPayload, error), next http.HandlerFunc) http.Handler {
 	return &Handler{verify, next}
@@ -3,6 +3,7 @@ package httpserver
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/ipfs/go-cid"
@@ -25,4 +26,34 @@ type Asset interface {
 	SetAssetUploadProgress(ctx context.Context, root cid.Cid, progress *types.UploadProgress) error
 	// GetUploadingAsset get asset which uploading
 	GetUploadingAsset(ctx context.Context, root cid.Cid) (*types.UploadingAsset, error)
+	// GetShard retrieves the erasure coded shard with the given index of an asset
+	GetShard(root cid.Cid, index int64) (io.ReadSeekCloser, error)
+	// HasShards checks if the erasure coded shards of an asset exist
+	HasShards(root cid.Cid) (bool, error)
+	// GetReconstructedBlock retrieves a block of an erasure coded asset from the car reconstructed from shards
+	GetReconstructedBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error)
+	// CreateUploadSession creates or resumes the resumable upload session of a user asset
+	CreateUploadSession(ctx context.Context, userID string, root cid.Cid, size int64, expiration time.Time) (*types.UploadSession, error)
+	// GetUploadSession returns the resumable upload session of an asset
+	GetUploadSession(ctx context.Context, root cid.Cid) (*types.UploadSession, error)
+	// AppendUploadSession writes data at the offset of the upload session, the asset is saved after all data received
+	AppendUploadSession(ctx context.Context, root cid.Cid, offset int64, r io.Reader) (*types.UploadSession, error)
+	// DeleteUploadSession terminates the upload session and removes the received data
+	DeleteUploadSession(ctx context.Context, root cid.Cid) error
+	// DeleteAsset removes the asset from local
+	DeleteAsset(root cid.Cid) error
+	// ImportFile builds the asset from the file content and saves it as the user asset
+	ImportFile(ctx context.Context, userID string, r io.Reader) (*types.FileAsset, error)
+	// CreateMultipartUpload creates an s3 multipart upload of the object
+	CreateMultipartUpload(ctx context.Context, userID, bucket, key string) (*types.MultipartUpload, error)
+	// GetMultipartUpload returns the s3 multipart upload
+	GetMultipartUpload(ctx context.Context, uploadID string) (*types.MultipartUpload, error)
+	// SaveMultipartPart saves a part of the s3 multipart upload
+	SaveMultipartPart(ctx context.Context, uploadID string, partNumber int, r io.Reader) (*types.MultipartPart, error)
+	// ListMultipartParts returns the uploaded parts of the s3 multipart upload
+	ListMultipartParts(ctx context.Context, uploadID string) ([]*types.MultipartPart, error)
+	// AbortMultipartUpload removes the s3 multipart upload and its parts
+	AbortMultipartUpload(ctx context.Context, uploadID string) error
+	// CompleteMultipartUpload saves the concatenated parts as the user asset and removes the upload
+	CompleteMultipartUpload(ctx context.Context, uploadID string, parts []*types.MultipartPart) (*types.FileAsset, error)
 }
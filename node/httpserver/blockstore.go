@@ -21,12 +21,12 @@ func (robs *readOnlyBlockStore) DeleteBlock(context.Context, cid.Cid) error {
 
 // Has checks if a block with a given CID exists in the block store.
 func (robs *readOnlyBlockStore) Has(ctx context.Context, c cid.Cid) (bool, error) {
-	return robs.hs.asset.HasBlock(ctx, robs.root, c)
+	return robs.hs.hasBlock(ctx, robs.root, c)
 }
 
 // Get retrieves a block with a given CID from the block store.
 func (robs *readOnlyBlockStore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
-	return robs.hs.asset.GetBlock(ctx, robs.root, c)
+	return robs.hs.getBlock(ctx, robs.root, c)
 }
 
 // GetSize returns the size of the block with the given CID. Since the store is read-only, it always returns 0.
@@ -0,0 +1,449 @@
+package httpserver
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/asset/fetcher"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/blocks"
+)
+
+const (
+	// cacheSourcesTTL is the lifetime of the candidate download infos of an asset
+	cacheSourcesTTL = time.Minute
+	// cacheReportInterval is the interval of reporting the partial assets to the scheduler
+	cacheReportInterval = 5 * time.Minute
+)
+
+// ReadThroughCacheOptions enables serving the assets the edge does not hold,
+// the missing blocks are fetched from the candidates on demand
+type ReadThroughCacheOptions struct {
+	// Path is the directory of the cached blocks
+	Path string
+	// Budget is the max bytes of the cached blocks, the least recently used blocks are evicted
+	Budget     int64
+	HTTPClient *http.Client
+}
+
+type cachedBlock struct {
+	root  string
+	block string
+	size  int64
+}
+
+type cacheSources struct {
+	infos   []*types.CandidateDownloadInfo
+	expires time.Time
+}
+
+type cacheFetch struct {
+	done chan struct{}
+	blk  blocks.Block
+	err  error
+}
+
+// readThroughCache keeps the blocks fetched from the candidates in <path>/<root>/<block>,
+// the blocks are evicted in least recently used order when the cache exceeds the budget
+type readThroughCache struct {
+	path      string
+	budget    int64
+	scheduler api.Scheduler
+	fetcher   *fetcher.CandidateFetcher
+
+	lock sync.Mutex
+	size int64
+	// front is the most recently used block
+	blocks   *list.List
+	index    map[string]*list.Element
+	sources  map[string]*cacheSources
+	fetching map[string]*cacheFetch
+	changed  bool
+}
+
+func newReadThroughCache(opts *ReadThroughCacheOptions, scheduler api.Scheduler) (*readThroughCache, error) {
+	if err := os.MkdirAll(opts.Path, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &readThroughCache{
+		path:      opts.Path,
+		budget:    opts.Budget,
+		scheduler: scheduler,
+		fetcher:   fetcher.NewCandidateFetcher(opts.HTTPClient),
+		blocks:    list.New(),
+		index:     make(map[string]*list.Element),
+		sources:   make(map[string]*cacheSources),
+		fetching:  make(map[string]*cacheFetch),
+		changed:   true,
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func cacheKey(root, block string) string {
+	return root + "/" + block
+}
+
+// load restores the cached blocks, the modification time of the files is the last used time
+func (c *readThroughCache) load() error {
+	type file struct {
+		*cachedBlock
+		modTime time.Time
+	}
+
+	roots, err := os.ReadDir(c.path)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*file, 0)
+	for _, root := range roots {
+		if !root.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(c.path, root.Name()))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			// the block was not completely written
+			if strings.HasSuffix(entry.Name(), ".tmp") {
+				os.Remove(filepath.Join(c.path, root.Name(), entry.Name())) //nolint:errcheck // ignore error
+				continue
+			}
+
+			files = append(files, &file{cachedBlock: &cachedBlock{root: root.Name(), block: entry.Name(), size: info.Size()}, modTime: info.ModTime()})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, f := range files {
+		c.index[cacheKey(f.root, f.block)] = c.blocks.PushFront(f.cachedBlock)
+		c.size += f.size
+	}
+	c.evict()
+
+	return nil
+}
+
+// has checks if the block of the asset is cached
+func (c *readThroughCache) has(root, block cid.Cid) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok := c.index[cacheKey(root.String(), block.String())]
+	return ok
+}
+
+// getBlock returns the cached block, the block is fetched from the candidates holding the asset if it is not cached
+func (c *readThroughCache) getBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	key := cacheKey(root.String(), block.String())
+
+	c.lock.Lock()
+	if e, ok := c.index[key]; ok {
+		c.blocks.MoveToFront(e)
+		c.lock.Unlock()
+
+		blk, err := c.readBlock(root, block)
+		if err == nil {
+			return blk, nil
+		}
+		log.Warnf("read cached block %s error %s", key, err.Error())
+
+		c.lock.Lock()
+		c.remove(key)
+	}
+
+	f, ok := c.fetching[key]
+	if !ok {
+		f = &cacheFetch{done: make(chan struct{})}
+		c.fetching[key] = f
+		c.lock.Unlock()
+
+		f.blk, f.err = c.fetch(ctx, root, block)
+		if f.err == nil {
+			c.put(root, f.blk)
+		}
+
+		c.lock.Lock()
+		delete(c.fetching, key)
+		c.lock.Unlock()
+		close(f.done)
+
+		return f.blk, f.err
+	}
+	c.lock.Unlock()
+
+	select {
+	case <-f.done:
+		return f.blk, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *readThroughCache) readBlock(root, block cid.Cid) (blocks.Block, error) {
+	filePath := filepath.Join(c.path, root.String(), block.String())
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(filePath, now, now); err != nil {
+		log.Debugf("touch cached block %s error %s", filePath, err.Error())
+	}
+
+	return blocks.NewBlockWithCid(data, block)
+}
+
+// fetch fetches the block from the candidates holding the asset
+func (c *readThroughCache) fetch(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	infos, err := c.downloadInfos(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	errMsgs, _, blks, err := c.fetcher.FetchBlocks(ctx, []string{block.String()}, infos)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(blks) == 0 {
+		// the tokens may be expired or the candidates offline, get new download infos next time
+		c.lock.Lock()
+		delete(c.sources, root.String())
+		c.lock.Unlock()
+
+		if len(errMsgs) > 0 {
+			return nil, fmt.Errorf("fetch block %s error %s", block.String(), errMsgs[0].Msg)
+		}
+		return nil, fmt.Errorf("fetch block %s failed", block.String())
+	}
+
+	data := blks[0].RawData()
+	sum, err := block.Prefix().Sum(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sum.Equals(block) {
+		return nil, fmt.Errorf("block data does not match cid %s", block.String())
+	}
+
+	return blks[0], nil
+}
+
+// downloadInfos returns the download infos of the candidates holding the asset
+func (c *readThroughCache) downloadInfos(ctx context.Context, root cid.Cid) ([]*types.CandidateDownloadInfo, error) {
+	c.lock.Lock()
+	s, ok := c.sources[root.String()]
+	c.lock.Unlock()
+
+	if ok && time.Now().Before(s.expires) {
+		return s.infos, nil
+	}
+
+	infos, err := c.scheduler.GetCandidateDownloadInfos(ctx, root.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no candidate holds asset %s", root.String())
+	}
+
+	c.lock.Lock()
+	c.sources[root.String()] = &cacheSources{infos: infos, expires: time.Now().Add(cacheSourcesTTL)}
+	c.lock.Unlock()
+
+	return infos, nil
+}
+
+// put saves the block and evicts the least recently used blocks over the budget
+func (c *readThroughCache) put(root cid.Cid, blk blocks.Block) {
+	dir := filepath.Join(c.path, root.String())
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Errorf("create cache dir %s error %s", dir, err.Error())
+		return
+	}
+
+	filePath := filepath.Join(dir, blk.Cid().String())
+	tempPath := filePath + ".tmp"
+	if err := os.WriteFile(tempPath, blk.RawData(), 0o644); err != nil {
+		log.Errorf("write cached block %s error %s", filePath, err.Error())
+		return
+	}
+
+	if err := os.Rename(tempPath, filePath); err != nil {
+		log.Errorf("rename cached block %s error %s", filePath, err.Error())
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := cacheKey(root.String(), blk.Cid().String())
+	if e, ok := c.index[key]; ok {
+		c.blocks.MoveToFront(e)
+		return
+	}
+
+	size := int64(len(blk.RawData()))
+	c.index[key] = c.blocks.PushFront(&cachedBlock{root: root.String(), block: blk.Cid().String(), size: size})
+	c.size += size
+	c.changed = true
+	c.evict()
+}
+
+// evict removes the least recently used blocks until the cache fits the budget, the caller must hold the lock
+func (c *readThroughCache) evict() {
+	for c.size > c.budget && c.blocks.Len() > 0 {
+		b := c.blocks.Back().Value.(*cachedBlock)
+		c.remove(cacheKey(b.root, b.block))
+	}
+}
+
+// remove removes the block, the caller must hold the lock
+func (c *readThroughCache) remove(key string) {
+	e, ok := c.index[key]
+	if !ok {
+		return
+	}
+
+	b := e.Value.(*cachedBlock)
+	c.blocks.Remove(e)
+	delete(c.index, key)
+	c.size -= b.size
+	c.changed = true
+
+	if err := os.Remove(filepath.Join(c.path, b.root, b.block)); err != nil && !os.IsNotExist(err) {
+		log.Errorf("remove cached block %s error %s", key, err.Error())
+	}
+	// remove the directory of the asset if it is empty
+	os.Remove(filepath.Join(c.path, b.root)) //nolint:errcheck // ignore error
+}
+
+// partialAssets returns the assets of the cached blocks
+func (c *readThroughCache) partialAssets() []*types.PartialAsset {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	assets := make(map[string]*types.PartialAsset)
+	for e := c.blocks.Front(); e != nil; e = e.Next() {
+		b := e.Value.(*cachedBlock)
+		asset, ok := assets[b.root]
+		if !ok {
+			asset = &types.PartialAsset{CID: b.root}
+			assets[b.root] = asset
+		}
+		asset.Blocks++
+		asset.Size += b.size
+	}
+
+	out := make([]*types.PartialAsset, 0, len(assets))
+	for _, asset := range assets {
+		out = append(out, asset)
+	}
+
+	return out
+}
+
+// startReport reports the partial assets to the scheduler when the cache changes
+func (c *readThroughCache) startReport() {
+	for {
+		c.lock.Lock()
+		changed := c.changed
+		c.changed = false
+		c.lock.Unlock()
+
+		if changed {
+			if err := c.scheduler.ReportPartialAssets(context.Background(), c.partialAssets()); err != nil {
+				log.Errorf("ReportPartialAssets error:%s", err.Error())
+
+				c.lock.Lock()
+				c.changed = true
+				c.lock.Unlock()
+			}
+		}
+
+		time.Sleep(cacheReportInterval)
+	}
+}
+
+// getBlock returns the block of the asset, the blocks of the erasure coded assets are read from the car reconstructed from shards,
+// and the blocks of the other assets the edge does not hold are read through the cache
+func (hs *HttpServer) getBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	blk, err := hs.asset.GetBlock(ctx, root, block)
+	if err == nil {
+		return blk, nil
+	}
+
+	if ok, e := hs.asset.AssetExists(root); e != nil || ok {
+		return nil, err
+	}
+
+	if ok, e := hs.asset.HasShards(root); e == nil && ok {
+		return hs.asset.GetReconstructedBlock(ctx, root, block)
+	}
+
+	if hs.cache == nil {
+		return nil, err
+	}
+
+	return hs.cache.getBlock(ctx, root, block)
+}
+
+// hasBlock checks if the block of the asset is held, the cached blocks are checked if the edge does not hold the asset,
+// the block is not fetched because the check is not authorized
+func (hs *HttpServer) hasBlock(ctx context.Context, root, block cid.Cid) (bool, error) {
+	has, err := hs.asset.HasBlock(ctx, root, block)
+	if err == nil && has {
+		return true, nil
+	}
+
+	// the erasure coded asset is held as shards, it is not reconstructed for the check
+	if root.Equals(block) {
+		if ok, e := hs.asset.HasShards(root); e == nil && ok {
+			return true, nil
+		}
+	}
+
+	if hs.cache == nil {
+		return has, err
+	}
+
+	if hs.cache.has(root, block) {
+		return true, nil
+	}
+
+	return has, err
+}
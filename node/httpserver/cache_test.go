@@ -0,0 +1,218 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipld/go-car"
+)
+
+// cacheScheduler returns the candidate server as the download source of all assets
+type cacheScheduler struct {
+	api.Scheduler
+	address string
+}
+
+func (cs *cacheScheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) ([]*types.CandidateDownloadInfo, error) {
+	return []*types.CandidateDownloadInfo{{NodeID: "c_test", Address: cs.address, Tk: &types.Token{ID: "token"}}}, nil
+}
+
+// missingAsset is the storage of an edge not holding any asset
+type missingAsset struct {
+	Asset
+}
+
+func (a *missingAsset) AssetExists(root cid.Cid) (bool, error) {
+	return false, nil
+}
+
+func (a *missingAsset) HasShards(root cid.Cid) (bool, error) {
+	return false, nil
+}
+
+func (a *missingAsset) HasBlock(ctx context.Context, root, block cid.Cid) (bool, error) {
+	return false, nil
+}
+
+func (a *missingAsset) GetBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	return nil, fmt.Errorf("block %s not found", block.String())
+}
+
+func TestReadThroughCache(t *testing.T) {
+	blks := make(map[string]blocks.Block)
+	for _, data := range []string{"block-1", "block-2", "block-3"} {
+		blk := blocks.NewBlock([]byte(data))
+		blks[blk.Cid().String()] = blk
+	}
+	root := blocks.NewBlock([]byte("root")).Cid()
+
+	var requests int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		blk, ok := blks[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(blk.RawData()) //nolint:errcheck // ignore error
+	}))
+	defer srv.Close()
+
+	opts := &ReadThroughCacheOptions{Path: t.TempDir(), Budget: 14, HTTPClient: srv.Client()}
+	cache, err := newReadThroughCache(opts, &cacheScheduler{address: strings.TrimPrefix(srv.URL, "https://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cids := make([]cid.Cid, 0, len(blks))
+	for _, data := range []string{"block-1", "block-2", "block-3"} {
+		c := blocks.NewBlock([]byte(data)).Cid()
+		cids = append(cids, c)
+
+		blk, err := cache.getBlock(context.Background(), root, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(blk.RawData()) != data {
+			t.Fatalf("block data %s, expected %s", blk.RawData(), data)
+		}
+	}
+
+	// the budget holds two blocks, the first block is evicted
+	if cache.has(root, cids[0]) || !cache.has(root, cids[1]) || !cache.has(root, cids[2]) {
+		t.Fatal("the least recently used block is not evicted")
+	}
+
+	if _, err := cache.getBlock(context.Background(), root, cids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Fatalf("requests %d, expected 3", requests)
+	}
+
+	assets := cache.partialAssets()
+	if len(assets) != 1 || assets[0].CID != root.String() || assets[0].Blocks != 2 || assets[0].Size != 14 {
+		t.Fatalf("unexpected partial assets %+v", assets)
+	}
+
+	// the cached blocks are restored
+	cache, err = newReadThroughCache(opts, &cacheScheduler{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.size != 14 || !cache.has(root, cids[1]) || !cache.has(root, cids[2]) {
+		t.Fatal("the cached blocks are not restored")
+	}
+
+	if _, err := cache.getBlock(context.Background(), root, blocks.NewBlock([]byte("missing")).Cid()); err == nil {
+		t.Fatal("fetch missing block without error")
+	}
+}
+
+func TestServeCachedCar(t *testing.T) {
+	blks := make(map[string]blocks.Block)
+	root := merkledag.NodeWithData([]byte("root"))
+	for _, data := range []string{"block-1", "block-2"} {
+		leaf := merkledag.NewRawNode([]byte(data))
+		blks[leaf.Cid().String()] = leaf
+		if err := root.AddRawLink(data, &format.Link{Cid: leaf.Cid(), Size: uint64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blks[root.Cid().String()] = root
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blk, ok := blks[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(blk.RawData()) //nolint:errcheck // ignore error
+	}))
+	defer srv.Close()
+
+	cache, err := newReadThroughCache(&ReadThroughCacheOptions{Path: t.TempDir(), Budget: 1 << 20, HTTPClient: srv.Client()}, &cacheScheduler{address: strings.TrimPrefix(srv.URL, "https://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := &HttpServer{asset: &missingAsset{}, cache: cache}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/ipfs/"+root.Cid().String(), nil)
+	if status, err := hs.serveCar(w, r, root.Cid().String(), ""); err != nil {
+		t.Fatalf("serve car status %d error %s", status, err.Error())
+	}
+
+	cr, err := car.NewCarReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	for {
+		blk, err := cr.Next()
+		if err != nil {
+			break
+		}
+		if _, ok := blks[blk.Cid().String()]; !ok {
+			t.Fatalf("unexpected block %s", blk.Cid().String())
+		}
+		count++
+	}
+	if count != len(blks) {
+		t.Fatalf("car has %d blocks, expected %d", count, len(blks))
+	}
+
+	// the asset not cached and not held by any candidate is not found
+	hs.cache = nil
+	if status, _ := hs.serveCar(httptest.NewRecorder(), r, root.Cid().String(), ""); status != http.StatusNotFound {
+		t.Fatalf("status %d, expected %d", status, http.StatusNotFound)
+	}
+}
+
+// shardedAsset is the storage of a node holding only the shards of assets
+type shardedAsset struct {
+	missingAsset
+	blks map[string]blocks.Block
+}
+
+func (a *shardedAsset) HasShards(root cid.Cid) (bool, error) {
+	return true, nil
+}
+
+func (a *shardedAsset) GetReconstructedBlock(ctx context.Context, root, block cid.Cid) (blocks.Block, error) {
+	blk, ok := a.blks[block.String()]
+	if !ok {
+		return nil, format.ErrNotFound{Cid: block}
+	}
+	return blk, nil
+}
+
+func TestGetBlockOfShardedAsset(t *testing.T) {
+	blk := blocks.NewBlock([]byte("block"))
+	hs := &HttpServer{asset: &shardedAsset{blks: map[string]blocks.Block{blk.Cid().String(): blk}}}
+
+	// the blocks are read from the reconstructed car without the read through cache
+	got, err := hs.getBlock(context.Background(), blk.Cid(), blk.Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.RawData()) != "block" {
+		t.Fatalf("block data %s, expected block", got.RawData())
+	}
+
+	if ok, err := hs.hasBlock(context.Background(), blk.Cid(), blk.Cid()); err != nil || !ok {
+		t.Fatalf("root of sharded asset is not held, %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package httpserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/limiter"
+	"golang.org/x/time/rate"
+)
+
+// errEgressLimit is returned when the download reaches the traffic limit of the token
+var errEgressLimit = errors.New("egress allowance of the token is exhausted")
+
+// egressWriter throttles the response with the limit rate of the token and stops it at the traffic limit
+type egressWriter struct {
+	http.ResponseWriter
+	w         io.Writer
+	limited   bool
+	remaining int64
+}
+
+func newEgressWriter(w http.ResponseWriter, payload *types.TokenPayload) http.ResponseWriter {
+	if payload.LimitRate <= 0 && payload.TrafficLimit <= 0 {
+		return w
+	}
+
+	ew := &egressWriter{ResponseWriter: w, w: w, limited: payload.TrafficLimit > 0, remaining: payload.TrafficLimit}
+	if payload.LimitRate > 0 {
+		// allow a burst of one second of data
+		ew.w = limiter.NewWriter(w, rate.NewLimiter(rate.Limit(payload.LimitRate), int(payload.LimitRate)))
+	}
+
+	return ew
+}
+
+func (ew *egressWriter) Write(buf []byte) (int, error) {
+	if ew.limited && int64(len(buf)) > ew.remaining {
+		n, err := ew.w.Write(buf[:ew.remaining])
+		ew.remaining -= int64(n)
+		if err == nil {
+			err = errEgressLimit
+		}
+		return n, err
+	}
+
+	n, err := ew.w.Write(buf)
+	ew.remaining -= int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the wrapped response writer does
+func (ew *egressWriter) Flush() {
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,56 @@
+package httpserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestEgressWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newEgressWriter(rec, &types.TokenPayload{TrafficLimit: 10})
+
+	if n, err := w.Write(bytes.Repeat([]byte("a"), 6)); n != 6 || err != nil {
+		t.Fatalf("write %d bytes, err %v", n, err)
+	}
+
+	if n, err := w.Write(bytes.Repeat([]byte("b"), 6)); n != 4 || err != errEgressLimit {
+		t.Fatalf("write %d bytes, err %v", n, err)
+	}
+
+	if rec.Body.String() != "aaaaaabbbb" {
+		t.Fatalf("unexpected body %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	w = newEgressWriter(rec, &types.TokenPayload{LimitRate: 1000})
+
+	start := time.Now()
+	if _, err := w.Write(make([]byte, 1500)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first second of data is the burst
+	if cost := time.Since(start); cost < 400*time.Millisecond {
+		t.Fatalf("write is not throttled, cost %s", cost)
+	}
+
+	if w := newEgressWriter(rec, &types.TokenPayload{}); w != rec {
+		t.Fatal("unlimited token is wrapped")
+	}
+
+	// the streamed responses are flushed through the wrapper
+	rec = httptest.NewRecorder()
+	f, ok := newEgressWriter(rec, &types.TokenPayload{TrafficLimit: 10}).(http.Flusher)
+	if !ok {
+		t.Fatal("egress writer is not a flusher")
+	}
+	f.Flush()
+	if !rec.Flushed {
+		t.Fatal("response is not flushed")
+	}
+}
@@ -20,7 +20,7 @@ type nodeGetter struct {
 // implementation, this may involve fetching the Node from a remote
 // machine; consider setting a deadline in the context.
 func (ng *nodeGetter) Get(ctx context.Context, block cid.Cid) (ipldformat.Node, error) {
-	blk, err := ng.hs.asset.GetBlock(ctx, ng.root, block)
+	blk, err := ng.hs.getBlock(ctx, ng.root, block)
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+	"github.com/Filecoin-Titan/titan/lib/sigv4"
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/ipfs/interface-go-ipfs-core/path"
@@ -45,6 +47,10 @@ func resetPath(r *http.Request) {
 
 	fields := strings.Split(host, ".")
 	if len(fields) >= domainFields {
+		if ipns.IsName(fields[0]) {
+			r.URL.Path = ipnsPathPrefix + fields[0] + r.URL.Path
+			return
+		}
 		r.URL.Path = "/ipfs/" + fields[0] + r.URL.Path
 	}
 
@@ -52,15 +58,26 @@ func resetPath(r *http.Request) {
 
 // ServeHTTP checks if the request path starts with the IPFS path prefix and delegates to the appropriate handler
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.hs.enableS3Gateway && sigv4.IsSigned(r) {
+		h.hs.s3Handler(w, r)
+		return
+	}
+
 	if !strings.Contains(r.URL.Path, ipfsPathPrefix) &&
+		!strings.Contains(r.URL.Path, ipnsPathPrefix) &&
 		!strings.Contains(r.URL.Path, uploadPathPrefix) &&
 		!strings.Contains(r.URL.Path, rpcPathPrefix) {
 		resetPath(r)
+		h.hs.resetDNSLinkPath(r)
 	}
 
 	switch {
 	case strings.HasPrefix(r.URL.Path, ipfsPathPrefix):
 		h.hs.handler(w, r)
+	case strings.HasPrefix(r.URL.Path, ipnsPathPrefix):
+		h.hs.ipnsHandler(w, r)
+	case strings.HasPrefix(r.URL.Path, resumableUploadPathPrefix):
+		h.hs.resumableUploadHandler(w, r)
 	case strings.HasPrefix(r.URL.Path, uploadPathPrefix):
 		h.hs.uploadHandler(w, r)
 	default:
@@ -28,7 +28,7 @@ func (hs *HttpServer) serveRawBlock(w http.ResponseWriter, r *http.Request, asse
 	}
 
 	c := resolvedPath.Cid()
-	block, err := hs.asset.GetBlock(ctx, root, c)
+	block, err := hs.getBlock(ctx, root, c)
 	if err != nil {
 		return http.StatusInternalServerError, fmt.Errorf("can not get block %s, %s", c.String(), err.Error())
 	}
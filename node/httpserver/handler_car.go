@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/Filecoin-Titan/titan/lib/carutil"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/interface-go-ipfs-core/path"
 )
@@ -40,7 +41,15 @@ func (hs *HttpServer) serveCar(w http.ResponseWriter, r *http.Request, assetCID
 	}
 
 	if !has {
-		return http.StatusNotFound, fmt.Errorf("can not found car %s", contentPath.String())
+		// the erasure coded asset is read from the car reconstructed from shards,
+		// and the asset partly cached is read through the cache
+		if hasShards, err := hs.asset.HasShards(root); (err != nil || !hasShards) && hs.cache == nil {
+			return http.StatusNotFound, fmt.Errorf("can not found car %s", contentPath.String())
+		}
+
+		if _, err := hs.getBlock(ctx, root, rootCID); err != nil {
+			return http.StatusNotFound, fmt.Errorf("can not found car %s: %s", contentPath.String(), err.Error())
+		}
 	}
 
 	// Set Content-Disposition
@@ -73,6 +82,14 @@ func (hs *HttpServer) serveCar(w http.ResponseWriter, r *http.Request, assetCID
 
 	modtime := addCacheControlHeaders(w, r, contentPath, rootCID)
 
+	if !has {
+		// the car is streamed, the length is unknown and the range requests are not supported
+		if err := carutil.WriteCar(ctx, &readOnlyBlockStore{hs, root}, rootCID, w); err != nil {
+			log.Errorf("serve car %s from block store error %s", rootCID.String(), err.Error())
+		}
+		return 0, nil
+	}
+
 	// TODO limit rate
 	reader, err := hs.asset.GetAsset(rootCID)
 	if err != nil {
@@ -10,13 +10,16 @@ import (
 	"fmt"
 	"io"
 	"mime"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
+	"go.opencensus.io/tag"
 	"golang.org/x/xerrors"
 )
 
@@ -28,6 +31,7 @@ const (
 	formatDagCbor = "application/vnd.ipld.dag-cbor"
 	formatJSON    = "application/json"
 	formatCbor    = "application/cbor"
+	formatShard   = "application/vnd.titan.shard"
 )
 
 func (hs *HttpServer) isNeedRedirect(r *http.Request) bool {
@@ -71,7 +75,9 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	assetCID := tkPayload.AssetCID
-	speedCountWriter := &SpeedCountWriter{w: w, startTime: time.Now()}
+	speedCountWriter := &SpeedCountWriter{w: newEgressWriter(w, tkPayload), startTime: time.Now()}
+	defer recordBytesServed(respFormat, speedCountWriter)
+
 	var statusCode int
 	var isDirectory bool
 
@@ -89,6 +95,8 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 		statusCode, err = hs.serveTAR(speedCountWriter, r, assetCID)
 	case formatDagJSON, formatDagCbor:
 		statusCode, err = hs.serveCodec(speedCountWriter, r, assetCID)
+	case formatShard:
+		statusCode, err = hs.serveShard(speedCountWriter, r, assetCID)
 	default: // catch-all for unsuported application/vnd.*
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("unsupported format %s", respFormat)
@@ -97,16 +105,55 @@ func (hs *HttpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		http.Error(w, err.Error(), statusCode)
 		log.Errorf("get handler error %s", err.Error())
+		// the traffic served before the error is still accounted to the user
+		hs.userAssetDownloadResult(tkPayload, speedCountWriter)
 		return
 	}
 
 	log.Debugf("tokenID:%s, clientID:%s, download size %d, speed %d, cost time %fms", tkPayload.ID, tkPayload.ClientID, speedCountWriter.dataSize, speedCountWriter.speed(), speedCountWriter.CostTime())
 	// stat upload speed
-	report := speedCountWriter.generateReport(tkPayload)
+	report := speedCountWriter.generateReport(tkPayload, remoteIP(r))
 	if report != nil {
 		hs.reporter.addReport(report)
 	}
 
+	hs.userAssetDownloadResult(tkPayload, speedCountWriter)
+}
+
+// recordBytesServed records the bytes served in the response format
+func recordBytesServed(respFormat string, speedCountWriter *SpeedCountWriter) {
+	if speedCountWriter.dataSize == 0 {
+		return
+	}
+
+	tags := []tag.Mutator{tag.Upsert(metrics.Format, formatName(respFormat))}
+	metrics.RecordWithTags(context.Background(), tags, metrics.BytesServed.M(speedCountWriter.dataSize))
+}
+
+// formatName returns the short name of the response format used in the metrics
+func formatName(respFormat string) string {
+	switch respFormat {
+	case "", formatJSON, formatCbor:
+		return "unixfs"
+	case formatRaw:
+		return "raw"
+	case formatCar:
+		return "car"
+	case formatTar:
+		return "tar"
+	case formatDagJSON:
+		return "dag-json"
+	case formatDagCbor:
+		return "dag-cbor"
+	case formatShard:
+		return "shard"
+	default:
+		return "unknown"
+	}
+}
+
+// userAssetDownloadResult reports the traffic of the download with the token of user
+func (hs *HttpServer) userAssetDownloadResult(tkPayload *types.TokenPayload, speedCountWriter *SpeedCountWriter) {
 	if len(tkPayload.ID) == 0 && len(tkPayload.ClientID) != 0 && speedCountWriter.speed() > 0 {
 		hs.scheduler.UserAssetDownloadResult(context.Background(), tkPayload.ClientID, tkPayload.AssetCID, speedCountWriter.dataSize, speedCountWriter.speed())
 	}
@@ -191,7 +238,22 @@ func (hs *HttpServer) verifyToken(w http.ResponseWriter, r *http.Request) (*type
 }
 
 func (hs *HttpServer) parseJWTToken(token string, r *http.Request) (*types.TokenPayload, error) {
-	jwtPayload, err := hs.scheduler.VerifyTokenWithLimitCount(context.Background(), token)
+	// the revoked and expired presigned url is rejected before the scheduler counts the download,
+	// the claims, client ip and password are verified by the scheduler then
+	claims, err := decodeJWTClaims(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwtPayload *types.JWTPayload
+	if len(claims.LinkID) > 0 {
+		if err = hs.checkPresignedURL(claims); err != nil {
+			return nil, err
+		}
+		jwtPayload, err = hs.scheduler.VerifyPresignedToken(context.Background(), token, remoteIP(r), presignPassword(r))
+	} else {
+		jwtPayload, err = hs.scheduler.VerifyTokenWithLimitCount(context.Background(), token)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -210,7 +272,13 @@ func (hs *HttpServer) parseJWTToken(token string, r *http.Request) (*types.Token
 		return nil, fmt.Errorf("request asset cid %s, parse token cid %s", root.String(), payload.AssetCID)
 	}
 
-	return &types.TokenPayload{AssetCID: payload.AssetCID, ClientID: payload.UserID, Expiration: payload.Expiration}, nil
+	return &types.TokenPayload{
+		AssetCID:     payload.AssetCID,
+		ClientID:     payload.UserID,
+		Expiration:   payload.Expiration,
+		LimitRate:    payload.LimitRate,
+		TrafficLimit: payload.TrafficLimit,
+	}, nil
 }
 
 // customResponseFormat checks the request's Accept header and query parameters to determine the desired response format
@@ -232,6 +300,8 @@ func customResponseFormat(r *http.Request) (mediaType string, params map[string]
 			return formatDagJSON, nil, nil
 		case "dag-cbor":
 			return formatDagCbor, nil, nil
+		case "shard":
+			return formatShard, nil, nil
 		}
 	}
 	// Browsers and other user agents will send Accept header with generic types like:
@@ -272,3 +342,12 @@ func authResult(w http.ResponseWriter, code int, err error) {
 
 	w.Write(buf)
 }
+
+// remoteIP returns the ip of the request, the remote address if it has no port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -17,7 +17,7 @@ func (hs *HttpServer) headHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// TODO get root from c
-	if ok, err := hs.asset.HasBlock(context.Background(), c, c); err != nil || !ok {
+	if ok, err := hs.hasBlock(context.Background(), c, c); err != nil || !ok {
 		w.WriteHeader(http.StatusPreconditionFailed)
 		return
 	}
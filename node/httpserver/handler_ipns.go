@@ -0,0 +1,178 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	ipnsPathPrefix = "/ipns/"
+	// maxNameDepth limits the names and dnslinks resolved in a chain
+	maxNameDepth = 4
+	// dnslinkTTL is the time the dnslink of a domain is cached, the resolver does not return the ttl of the txt record
+	dnslinkTTL = time.Minute
+	// maxCachedNames is the max count of the cached names
+	maxCachedNames = 10000
+)
+
+// resolvedName is the /ipfs/ path of a name or a dnslink domain, err is cached for the names failing to resolve
+type resolvedName struct {
+	path    string
+	expires time.Time
+	err     error
+}
+
+func newNameCache() *lru.Cache {
+	cache, err := lru.New(maxCachedNames)
+	if err != nil {
+		log.Errorf("new name cache error %s", err.Error())
+	}
+	return cache
+}
+
+// ipnsHandler serves /ipns/<name>[/<path>] by resolving the name to an /ipfs/ path
+func (hs *HttpServer) ipnsHandler(w http.ResponseWriter, r *http.Request) {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, ipnsPathPrefix), "/")
+	if len(rest) > 0 {
+		rest = "/" + rest
+	}
+
+	p, ttl, err := hs.resolveName(r.Context(), name, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("resolve name %s error: %s", name, err.Error()), http.StatusNotFound)
+		return
+	}
+
+	log.Debugf("resolve %s to %s", r.URL.Path, p+rest)
+
+	w.Header().Set("X-Ipfs-Path", r.URL.Path)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ttl.Seconds())))
+	r.URL.Path = p + rest
+	hs.handler(w, r)
+}
+
+// resolveName resolves the name or the dnslink domain to an /ipfs/ path,
+// returns the path and the time the path can be cached
+func (hs *HttpServer) resolveName(ctx context.Context, name string, depth int) (string, time.Duration, error) {
+	if depth >= maxNameDepth {
+		return "", 0, fmt.Errorf("name %s resolves too deep", name)
+	}
+
+	name = strings.ToLower(name)
+	if hs.names != nil {
+		if v, ok := hs.names.Get(name); ok {
+			rn := v.(*resolvedName)
+			if ttl := time.Until(rn.expires); ttl > 0 {
+				return rn.path, ttl, rn.err
+			}
+		}
+	}
+
+	var p string
+	var ttl time.Duration
+	var err error
+	if ipns.IsName(name) {
+		p, ttl, err = hs.resolveNameRecord(ctx, name)
+	} else {
+		p, ttl, err = hs.resolveDNSLink(ctx, name, depth)
+	}
+
+	if err != nil {
+		ttl = dnslinkTTL
+	}
+
+	if hs.names != nil && ttl > 0 {
+		hs.names.Add(name, &resolvedName{path: p, expires: time.Now().Add(ttl), err: err})
+	}
+
+	return p, ttl, err
+}
+
+// resolveNameRecord resolves the name with the signed record published to the scheduler
+func (hs *HttpServer) resolveNameRecord(ctx context.Context, name string) (string, time.Duration, error) {
+	record, err := hs.scheduler.ResolveName(ctx, name)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := ipns.Verify(record); err != nil {
+		return "", 0, err
+	}
+
+	if record.Name != name {
+		return "", 0, fmt.Errorf("scheduler returns the record of name %s", record.Name)
+	}
+
+	if ipns.Expired(record, time.Now()) {
+		return "", 0, fmt.Errorf("record of name %s is expired", name)
+	}
+
+	return record.Value, min(record.TTL, time.Until(record.Validity)), nil
+}
+
+// resolveDNSLink resolves the domain with its _dnslink txt record
+func (hs *HttpServer) resolveDNSLink(ctx context.Context, domain string, depth int) (string, time.Duration, error) {
+	txts, err := net.DefaultResolver.LookupTXT(ctx, ipns.DNSLinkLabel+"."+domain)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, txt := range txts {
+		value, err := ipns.ParseDNSLink(txt)
+		if err != nil {
+			continue
+		}
+
+		if strings.HasPrefix(value, ipns.IPFSPrefix) {
+			if _, _, err := ipns.ParsePath(value); err != nil {
+				return "", 0, err
+			}
+			return value, dnslinkTTL, nil
+		}
+
+		name, rest, _ := strings.Cut(strings.TrimPrefix(value, ipns.IPNSPrefix), "/")
+		p, ttl, err := hs.resolveName(ctx, name, depth+1)
+		if err != nil {
+			return "", 0, err
+		}
+
+		if len(rest) > 0 {
+			p = strings.TrimSuffix(p, "/") + "/" + rest
+		}
+		return p, min(ttl, dnslinkTTL), nil
+	}
+
+	return "", 0, fmt.Errorf("domain %s has no dnslink", domain)
+}
+
+// resetDNSLinkPath serves the request to a configured domain with a dnslink as /ipns/<domain>/<path>,
+// the other hosts are not resolved, so the requests can not make the node look up arbitrary domains
+func (hs *HttpServer) resetDNSLinkPath(r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, ipfsPathPrefix) || strings.HasPrefix(r.URL.Path, ipnsPathPrefix) {
+		return
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(r.Host); err == nil {
+		host = h
+	}
+
+	host = strings.ToLower(host)
+	if _, ok := hs.dnslinkDomains[host]; !ok {
+		return
+	}
+
+	if _, _, err := hs.resolveName(r.Context(), host, 0); err != nil {
+		log.Debugf("resolve dnslink of %s error %s", host, err.Error())
+		return
+	}
+
+	r.URL.Path = ipnsPathPrefix + host + r.URL.Path
+}
@@ -0,0 +1,118 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+)
+
+// nameScheduler resolves the names of the records
+type nameScheduler struct {
+	api.Scheduler
+	records  map[string]*types.NameRecord
+	resolved int
+}
+
+func (ns *nameScheduler) ResolveName(ctx context.Context, name string) (*types.NameRecord, error) {
+	ns.resolved++
+	record, ok := ns.records[name]
+	if !ok {
+		return nil, fmt.Errorf("name %s not found", name)
+	}
+	return record, nil
+}
+
+func TestResolveName(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &types.NameRecord{
+		Value:    "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		Sequence: 1,
+		Validity: time.Now().Add(time.Hour),
+		TTL:      time.Minute,
+	}
+	if err := ipns.Sign(key, record); err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := &nameScheduler{records: map[string]*types.NameRecord{record.Name: record}}
+	hs := &HttpServer{scheduler: scheduler, names: newNameCache()}
+
+	for i := 0; i < 2; i++ {
+		p, ttl, err := hs.resolveName(context.Background(), record.Name, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p != record.Value || ttl <= 0 || ttl > time.Minute {
+			t.Fatalf("unexpected path %s, ttl %s", p, ttl)
+		}
+	}
+
+	// the resolved name is cached for the ttl
+	if scheduler.resolved != 1 {
+		t.Fatalf("expect the name is resolved once, resolved %d", scheduler.resolved)
+	}
+
+	// the record signed by other key is rejected
+	_, other, _ := ed25519.GenerateKey(nil)
+	forged := *record
+	forged.Sequence = 2
+	forged.PublicKey = other.Public().(ed25519.PublicKey)
+	forged.Signature = ed25519.Sign(other, forged.SignedMessage())
+	scheduler.records[record.Name] = &forged
+	hs.names.Purge()
+
+	if _, _, err := hs.resolveName(context.Background(), record.Name, 0); err == nil {
+		t.Fatal("resolve forged record without error")
+	}
+}
+
+func TestResetNamePath(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, err := ipns.Name(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "https://"+name+".ipfs.titannet.io:443/index.html", nil)
+	resetPath(r)
+
+	if r.URL.Path != ipnsPathPrefix+name+"/index.html" {
+		t.Fatalf("unexpected path %s", r.URL.Path)
+	}
+}
+
+func TestResetDNSLinkPath(t *testing.T) {
+	hs := &HttpServer{names: newNameCache(), dnslinkDomains: map[string]struct{}{"docs.example.com": {}}}
+	for _, domain := range []string{"docs.example.com", "other.example.com"} {
+		hs.names.Add(domain, &resolvedName{path: "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku", expires: time.Now().Add(time.Minute)})
+	}
+
+	r := httptest.NewRequest("GET", "https://Docs.Example.com/index.html", nil)
+	hs.resetDNSLinkPath(r)
+	if r.URL.Path != ipnsPathPrefix+"docs.example.com/index.html" {
+		t.Fatalf("unexpected path %s", r.URL.Path)
+	}
+
+	// the dnslink of a domain not configured is not resolved
+	r = httptest.NewRequest("GET", "https://other.example.com/index.html", nil)
+	hs.resetDNSLinkPath(r)
+	if r.URL.Path != "/index.html" {
+		t.Fatalf("unexpected path %s", r.URL.Path)
+	}
+}
@@ -0,0 +1,344 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/sigv4"
+	"github.com/google/uuid"
+)
+
+// s3 gateway serves the path style requests of aws s3 api signed with signature version 4,
+// buckets are the root asset groups of user and objects are the user assets in them.
+// The access key id is "<user-id>:<api-key-name>" and the secret access key is the api key,
+// the signature is verified by scheduler.
+const (
+	s3XMLNamespace   = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3DefaultMaxKeys = 1000
+	s3TimeFormat     = "2006-01-02T15:04:05.000Z"
+)
+
+// s3Error is the error response of s3 api
+type s3Error struct {
+	XMLName    xml.Name `xml:"Error"`
+	Code       string
+	Message    string
+	Resource   string
+	RequestID  string `xml:"RequestId"`
+	statusCode int
+}
+
+func (e *s3Error) Error() string {
+	return e.Message
+}
+
+func newS3Error(statusCode int, code, msg string) *s3Error {
+	return &s3Error{Code: code, Message: msg, statusCode: statusCode}
+}
+
+var (
+	errS3AccessDenied       = newS3Error(http.StatusForbidden, "AccessDenied", "Access Denied")
+	errS3MethodNotAllowed   = newS3Error(http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource")
+	errS3NotImplemented     = newS3Error(http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented")
+	errS3BadDigest          = newS3Error(http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed")
+	errS3EntityTooLarge     = newS3Error(http.StatusBadRequest, "EntityTooLarge", "Your proposed upload exceeds the maximum allowed object size")
+	errS3MalformedXML       = newS3Error(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+	errS3InvalidPartNumber  = newS3Error(http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000")
+	errS3MissingContentSize = newS3Error(http.StatusLengthRequired, "MissingContentLength", "You must provide the Content-Length HTTP header")
+)
+
+// s3Request is an authenticated s3 request
+type s3Request struct {
+	identity *types.S3Identity
+	bucket   string
+	key      string
+}
+
+func (hs *HttpServer) s3Handler(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("s3Handler %s %s", r.Method, r.URL.Path)
+
+	req, err := hs.authS3Request(r)
+	if err != nil {
+		writeS3Error(w, r, err)
+		return
+	}
+
+	if err := hs.serveS3(w, r, req); err != nil {
+		writeS3Error(w, r, err)
+	}
+}
+
+// authS3Request verifies the signature of request by scheduler and wraps the body to verify the payload
+func (hs *HttpServer) authS3Request(r *http.Request) (*s3Request, error) {
+	signed, err := sigv4.Parse(r, time.Now())
+	if err != nil {
+		return nil, newS3Error(http.StatusForbidden, "AccessDenied", err.Error())
+	}
+
+	authReq := &types.S3AuthReq{
+		AccessKeyID:  signed.AccessKeyID,
+		Date:         signed.Date,
+		Region:       signed.Region,
+		Service:      signed.Service,
+		StringToSign: signed.StringToSign,
+		Signature:    signed.Signature,
+	}
+
+	identity, err := hs.scheduler.AuthS3Request(r.Context(), authReq)
+	if err != nil {
+		return nil, err
+	}
+
+	switch signed.PayloadHash {
+	case sigv4.UnsignedPayload:
+	case sigv4.StreamingPayload:
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{sigv4.NewChunkedReader(r.Body), r.Body}
+
+		r.ContentLength = -1
+		if size := r.Header.Get("X-Amz-Decoded-Content-Length"); len(size) > 0 {
+			if r.ContentLength, err = strconv.ParseInt(size, 10, 64); err != nil {
+				return nil, newS3Error(http.StatusBadRequest, "InvalidArgument", "invalid x-amz-decoded-content-length")
+			}
+		}
+	default:
+		expected, err := hex.DecodeString(signed.PayloadHash)
+		if err != nil || len(expected) != sha256.Size {
+			return nil, newS3Error(http.StatusBadRequest, "InvalidArgument", "invalid x-amz-content-sha256")
+		}
+		r.Body = &sha256Reader{ReadCloser: r.Body, hash: sha256.New(), expected: expected}
+	}
+
+	bucket, key := parseS3Path(r.URL.Path)
+	return &s3Request{identity: identity, bucket: bucket, key: key}, nil
+}
+
+// serveS3 dispatches the s3 request by the method, bucket, key and query
+func (hs *HttpServer) serveS3(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	query := r.URL.Query()
+
+	if len(req.bucket) == 0 {
+		if r.Method != http.MethodGet {
+			return errS3MethodNotAllowed
+		}
+		return hs.listS3Buckets(w, r, req)
+	}
+
+	if len(req.key) == 0 {
+		switch r.Method {
+		case http.MethodPut:
+			return hs.createS3Bucket(w, r, req)
+		case http.MethodDelete:
+			return hs.deleteS3Bucket(w, r, req)
+		case http.MethodHead:
+			return hs.headS3Bucket(w, r, req)
+		case http.MethodPost:
+			if query.Has("delete") {
+				return hs.deleteS3Objects(w, r, req)
+			}
+			return errS3NotImplemented
+		case http.MethodGet:
+			switch {
+			case query.Has("location"):
+				return hs.getS3BucketLocation(w, r, req)
+			case query.Has("uploads"), query.Has("versions"), query.Has("acl"), query.Has("policy"):
+				return errS3NotImplemented
+			case query.Get("list-type") == "2":
+				return hs.listS3ObjectsV2(w, r, req)
+			default:
+				return hs.listS3Objects(w, r, req)
+			}
+		default:
+			return errS3MethodNotAllowed
+		}
+	}
+
+	if query.Has("uploadId") {
+		switch r.Method {
+		case http.MethodPut:
+			return hs.uploadS3Part(w, r, req)
+		case http.MethodGet:
+			return hs.listS3Parts(w, r, req)
+		case http.MethodPost:
+			return hs.completeS3MultipartUpload(w, r, req)
+		case http.MethodDelete:
+			return hs.abortS3MultipartUpload(w, r, req)
+		default:
+			return errS3MethodNotAllowed
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		if query.Has("uploads") {
+			return hs.createS3MultipartUpload(w, r, req)
+		}
+		return errS3NotImplemented
+	case http.MethodPut:
+		if len(r.Header.Get("X-Amz-Copy-Source")) > 0 {
+			return errS3NotImplemented
+		}
+		return hs.putS3Object(w, r, req)
+	case http.MethodGet:
+		return hs.getS3Object(w, r, req)
+	case http.MethodHead:
+		return hs.headS3Object(w, r, req)
+	case http.MethodDelete:
+		return hs.deleteS3Object(w, r, req)
+	default:
+		return errS3MethodNotAllowed
+	}
+}
+
+// checkS3Access checks if the api key which signed the request has the permission
+func checkS3Access(req *s3Request, acl types.UserAccessControl) error {
+	if !req.identity.Allowed(acl) {
+		return errS3AccessDenied
+	}
+	return nil
+}
+
+// parseS3Path splits the path of path style request into bucket and key
+func parseS3Path(p string) (string, string) {
+	p = strings.TrimPrefix(p, "/")
+	index := strings.Index(p, "/")
+	if index < 0 {
+		return p, ""
+	}
+	return p[:index], p[index+1:]
+}
+
+// s3ErrorFrom converts the error of scheduler and asset manager to s3 error
+func s3ErrorFrom(err error) *s3Error {
+	var s3Err *s3Error
+	if errors.As(err, &s3Err) {
+		return s3Err
+	}
+
+	switch {
+	case errors.Is(err, types.ErrNoSuchUpload):
+		return newS3Error(http.StatusNotFound, "NoSuchUpload", err.Error())
+	case errors.Is(err, types.ErrInvalidPart):
+		return newS3Error(http.StatusBadRequest, "InvalidPart", err.Error())
+	case errors.Is(err, types.ErrInvalidPartOrder):
+		return newS3Error(http.StatusBadRequest, "InvalidPartOrder", err.Error())
+	case errors.Is(err, errSHA256Mismatch):
+		return errS3BadDigest
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errS3EntityTooLarge
+	}
+
+	var errWeb *api.ErrWeb
+	if !errors.As(err, &errWeb) {
+		return newS3Error(http.StatusInternalServerError, "InternalError", err.Error())
+	}
+
+	switch terrors.TError(errWeb.Code) {
+	case terrors.GroupNotExist:
+		return newS3Error(http.StatusNotFound, "NoSuchBucket", errWeb.Message)
+	case terrors.NotFound:
+		return newS3Error(http.StatusNotFound, "NoSuchKey", errWeb.Message)
+	case terrors.GroupNotEmptyCannotBeDelete:
+		return newS3Error(http.StatusConflict, "BucketNotEmpty", errWeb.Message)
+	case terrors.GroupAlreadyExist:
+		return newS3Error(http.StatusConflict, "BucketAlreadyOwnedByYou", errWeb.Message)
+	case terrors.GroupLimit:
+		return newS3Error(http.StatusBadRequest, "TooManyBuckets", errWeb.Message)
+	case terrors.APPKeyNotFound, terrors.UserNotFound:
+		return newS3Error(http.StatusForbidden, "InvalidAccessKeyId", errWeb.Message)
+	case terrors.VerifyTokenError:
+		return newS3Error(http.StatusForbidden, "SignatureDoesNotMatch", errWeb.Message)
+	case terrors.UserStorageSizeNotEnough:
+		return newS3Error(http.StatusForbidden, "QuotaExceeded", errWeb.Message)
+	case terrors.ParametersAreWrong, terrors.NoDuplicateUploads:
+		return newS3Error(http.StatusBadRequest, "InvalidRequest", errWeb.Message)
+	default:
+		return newS3Error(http.StatusInternalServerError, "InternalError", errWeb.Message)
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, err error) {
+	s3Err := *s3ErrorFrom(err)
+	s3Err.Resource = r.URL.Path
+	s3Err.RequestID = uuid.NewString()
+
+	if s3Err.statusCode == http.StatusInternalServerError {
+		log.Errorf("s3 %s %s error: %s", r.Method, r.URL.Path, err.Error())
+	} else {
+		log.Debugf("s3 %s %s error: %s", r.Method, r.URL.Path, err.Error())
+	}
+
+	w.Header().Set("X-Amz-Request-Id", s3Err.RequestID)
+
+	// the response of head request has no body
+	if r.Method == http.MethodHead {
+		w.WriteHeader(s3Err.statusCode)
+		return
+	}
+
+	writeS3XML(w, s3Err.statusCode, &s3Err)
+}
+
+func writeS3XML(w http.ResponseWriter, statusCode int, v interface{}) {
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		log.Errorf("marshal s3 response error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(buf)))
+	w.WriteHeader(statusCode)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		log.Errorf("write s3 response error: %s", err.Error())
+		return
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		log.Errorf("write s3 response error: %s", err.Error())
+	}
+}
+
+// s3Time formats the time in the format of s3 xml response
+func s3Time(t time.Time) string {
+	return t.UTC().Format(s3TimeFormat)
+}
+
+var errSHA256Mismatch = fmt.Errorf("sha256 of payload mismatch")
+
+// sha256Reader verifies the sha256 of the payload after all data is read
+type sha256Reader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+}
+
+func (sr *sha256Reader) Read(p []byte) (int, error) {
+	n, err := sr.ReadCloser.Read(p)
+	sr.hash.Write(p[:n]) //nolint:errcheck // never return error
+
+	if err == io.EOF && !bytes.Equal(sr.hash.Sum(nil), sr.expected) {
+		return n, errSHA256Mismatch
+	}
+	return n, err
+}
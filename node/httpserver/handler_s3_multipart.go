@@ -0,0 +1,277 @@
+package httpserver
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+const (
+	s3MaxPartNumber   = 10000
+	s3DefaultMaxParts = 1000
+	maxS3XMLBodySize  = 1 << 20
+)
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string
+	Key      string
+	UploadID string `xml:"UploadId"`
+}
+
+type s3Part struct {
+	PartNumber   int
+	LastModified string `xml:",omitempty"`
+	ETag         string
+	Size         int64 `xml:",omitempty"`
+}
+
+type listPartsResult struct {
+	XMLName              xml.Name `xml:"ListPartsResult"`
+	Xmlns                string   `xml:"xmlns,attr"`
+	Bucket               string
+	Key                  string
+	UploadID             string `xml:"UploadId"`
+	PartNumberMarker     int
+	NextPartNumberMarker int
+	MaxParts             int
+	IsTruncated          bool
+	Parts                []s3Part `xml:"Part"`
+}
+
+type completeMultipartUploadRequest struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []s3Part `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string
+	Bucket   string
+	Key      string
+	ETag     string
+}
+
+func (hs *HttpServer) createS3MultipartUpload(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyCreateFile); err != nil {
+		return err
+	}
+
+	if err := hs.checkS3Bucket(r.Context(), req); err != nil {
+		return err
+	}
+
+	upload, err := hs.asset.CreateMultipartUpload(r.Context(), req.identity.UserID, req.bucket, req.key)
+	if err != nil {
+		return err
+	}
+
+	ret := &initiateMultipartUploadResult{Xmlns: s3XMLNamespace, Bucket: req.bucket, Key: req.key, UploadID: upload.UploadID}
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+// loadS3MultipartUpload returns the multipart upload of request, the upload must be created for the same object by the user
+func (hs *HttpServer) loadS3MultipartUpload(r *http.Request, req *s3Request) (*types.MultipartUpload, error) {
+	upload, err := hs.asset.GetMultipartUpload(r.Context(), r.URL.Query().Get("uploadId"))
+	if err != nil {
+		return nil, err
+	}
+
+	if upload.UserID != req.identity.UserID || upload.Bucket != req.bucket || upload.Key != req.key {
+		return nil, types.ErrNoSuchUpload
+	}
+
+	return upload, nil
+}
+
+func (hs *HttpServer) uploadS3Part(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyCreateFile); err != nil {
+		return err
+	}
+
+	if len(r.Header.Get("X-Amz-Copy-Source")) > 0 {
+		return errS3NotImplemented
+	}
+
+	partNumber, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > s3MaxPartNumber {
+		return errS3InvalidPartNumber
+	}
+
+	if r.ContentLength < 0 {
+		return errS3MissingContentSize
+	}
+
+	if r.ContentLength > int64(hs.maxSizeOfUploadFile) {
+		return errS3EntityTooLarge
+	}
+
+	upload, err := hs.loadS3MultipartUpload(r, req)
+	if err != nil {
+		return err
+	}
+
+	body := http.MaxBytesReader(w, r.Body, r.ContentLength)
+	part, err := hs.asset.SaveMultipartPart(r.Context(), upload.UploadID, partNumber, body)
+	if err != nil {
+		return err
+	}
+
+	if part.Size != r.ContentLength {
+		return newS3Error(http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header")
+	}
+
+	w.Header().Set("ETag", s3ETag(part.ETag))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (hs *HttpServer) listS3Parts(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFile); err != nil {
+		return err
+	}
+
+	upload, err := hs.loadS3MultipartUpload(r, req)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	marker, maxParts := 0, s3DefaultMaxParts
+	if query.Has("part-number-marker") {
+		if marker, err = strconv.Atoi(query.Get("part-number-marker")); err != nil || marker < 0 {
+			return newS3Error(http.StatusBadRequest, "InvalidArgument", "invalid part-number-marker")
+		}
+	}
+
+	if query.Has("max-parts") {
+		if maxParts, err = strconv.Atoi(query.Get("max-parts")); err != nil || maxParts < 0 {
+			return newS3Error(http.StatusBadRequest, "InvalidArgument", "invalid max-parts")
+		}
+
+		if maxParts > s3DefaultMaxParts {
+			maxParts = s3DefaultMaxParts
+		}
+	}
+
+	parts, err := hs.asset.ListMultipartParts(r.Context(), upload.UploadID)
+	if err != nil {
+		return err
+	}
+
+	ret := &listPartsResult{
+		Xmlns:            s3XMLNamespace,
+		Bucket:           req.bucket,
+		Key:              req.key,
+		UploadID:         upload.UploadID,
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+
+	for _, part := range parts {
+		if part.PartNumber <= marker {
+			continue
+		}
+
+		if len(ret.Parts) == maxParts {
+			ret.IsTruncated = true
+			break
+		}
+
+		ret.Parts = append(ret.Parts, s3Part{
+			PartNumber:   part.PartNumber,
+			LastModified: s3Time(part.LastModified),
+			ETag:         s3ETag(part.ETag),
+			Size:         part.Size,
+		})
+		ret.NextPartNumberMarker = part.PartNumber
+	}
+
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+func (hs *HttpServer) completeS3MultipartUpload(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyCreateFile); err != nil {
+		return err
+	}
+
+	upload, err := hs.loadS3MultipartUpload(r, req)
+	if err != nil {
+		return err
+	}
+
+	completeReq := &completeMultipartUploadRequest{}
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxS3XMLBodySize)).Decode(completeReq); err != nil {
+		return errS3MalformedXML
+	}
+
+	uploaded, err := hs.asset.ListMultipartParts(r.Context(), upload.UploadID)
+	if err != nil {
+		return err
+	}
+
+	sizes := make(map[int]int64, len(uploaded))
+	for _, part := range uploaded {
+		sizes[part.PartNumber] = part.Size
+	}
+
+	totalSize := int64(0)
+	parts := make([]*types.MultipartPart, 0, len(completeReq.Parts))
+	for _, part := range completeReq.Parts {
+		totalSize += sizes[part.PartNumber]
+		parts = append(parts, &types.MultipartPart{PartNumber: part.PartNumber, ETag: strings.Trim(part.ETag, `"`)})
+	}
+
+	if totalSize > int64(hs.maxSizeOfUploadFile) {
+		return errS3EntityTooLarge
+	}
+
+	// limit max concurrent
+	semaphore <- struct{}{}
+	defer func() { <-semaphore }()
+
+	asset, err := hs.asset.CompleteMultipartUpload(r.Context(), upload.UploadID, parts)
+	if err != nil {
+		return err
+	}
+
+	if err := hs.saveS3Object(r.Context(), req, asset); err != nil {
+		return err
+	}
+
+	ret := &completeMultipartUploadResult{
+		Xmlns:    s3XMLNamespace,
+		Location: r.URL.Path,
+		Bucket:   req.bucket,
+		Key:      req.key,
+		ETag:     s3ETag(asset.CID),
+	}
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+func (hs *HttpServer) abortS3MultipartUpload(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyCreateFile); err != nil {
+		return err
+	}
+
+	upload, err := hs.loadS3MultipartUpload(r, req)
+	if err != nil {
+		return err
+	}
+
+	if err := hs.asset.AbortMultipartUpload(r.Context(), upload.UploadID); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
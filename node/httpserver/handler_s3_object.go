@@ -0,0 +1,603 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	gopath "path"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-libipfs/files"
+	"github.com/ipfs/interface-go-ipfs-core/path"
+)
+
+type s3Owner struct {
+	ID          string
+	DisplayName string
+}
+
+type s3Bucket struct {
+	Name         string
+	CreationDate string
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Owner   s3Owner
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+type locationConstraint struct {
+	XMLName  xml.Name `xml:"LocationConstraint"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:",chardata"`
+}
+
+type s3ObjectContent struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+type s3CommonPrefix struct {
+	Prefix string
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Xmlns                 string   `xml:"xmlns,attr"`
+	Name                  string
+	Prefix                string
+	Marker                *string `xml:",omitempty"`
+	NextMarker            string  `xml:",omitempty"`
+	ContinuationToken     string  `xml:",omitempty"`
+	NextContinuationToken string  `xml:",omitempty"`
+	StartAfter            string  `xml:",omitempty"`
+	KeyCount              *int    `xml:",omitempty"`
+	MaxKeys               int
+	Delimiter             string `xml:",omitempty"`
+	EncodingType          string `xml:",omitempty"`
+	IsTruncated           bool
+	Contents              []s3ObjectContent
+	CommonPrefixes        []s3CommonPrefix
+}
+
+type deleteObjectsRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool
+	Objects []struct {
+		Key string
+	} `xml:"Object"`
+}
+
+type deletedObject struct {
+	Key string
+}
+
+type deleteObjectError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+type deleteObjectsResult struct {
+	XMLName xml.Name            `xml:"DeleteResult"`
+	Xmlns   string              `xml:"xmlns,attr"`
+	Deleted []deletedObject     `xml:"Deleted"`
+	Errors  []deleteObjectError `xml:"Error"`
+}
+
+// s3ListResult is a page of keys, the keys which contain the delimiter after the prefix are rolled up into common prefixes
+type s3ListResult struct {
+	objects     []*types.S3Object
+	prefixes    []string
+	isTruncated bool
+	// next is the last key or common prefix in the page
+	next string
+}
+
+func (hs *HttpServer) listS3Buckets(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFolder); err != nil {
+		return err
+	}
+
+	groups, err := hs.scheduler.ListS3Buckets(r.Context(), req.identity.Token)
+	if err != nil {
+		return err
+	}
+
+	ret := &listAllMyBucketsResult{
+		Xmlns: s3XMLNamespace,
+		Owner: s3Owner{ID: req.identity.UserID, DisplayName: req.identity.UserID},
+	}
+	for _, group := range groups {
+		ret.Buckets = append(ret.Buckets, s3Bucket{Name: group.Name, CreationDate: s3Time(group.CreatedTime)})
+	}
+
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+func (hs *HttpServer) createS3Bucket(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyCreateFolder); err != nil {
+		return err
+	}
+
+	if err := hs.scheduler.CreateS3Bucket(r.Context(), req.identity.Token, req.bucket); err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", "/"+req.bucket)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (hs *HttpServer) deleteS3Bucket(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyDeleteFolder); err != nil {
+		return err
+	}
+
+	if err := hs.scheduler.DeleteS3Bucket(r.Context(), req.identity.Token, req.bucket); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (hs *HttpServer) headS3Bucket(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFolder); err != nil {
+		return err
+	}
+
+	if err := hs.checkS3Bucket(r.Context(), req); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (hs *HttpServer) getS3BucketLocation(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFolder); err != nil {
+		return err
+	}
+
+	if err := hs.checkS3Bucket(r.Context(), req); err != nil {
+		return err
+	}
+
+	writeS3XML(w, http.StatusOK, &locationConstraint{Xmlns: s3XMLNamespace})
+	return nil
+}
+
+// checkS3Bucket returns NoSuchBucket error if the bucket of request does not exist
+func (hs *HttpServer) checkS3Bucket(ctx context.Context, req *s3Request) error {
+	groups, err := hs.scheduler.ListS3Buckets(ctx, req.identity.Token)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if group.Name == req.bucket {
+			return nil
+		}
+	}
+
+	return newS3Error(http.StatusNotFound, "NoSuchBucket", fmt.Sprintf("bucket %s not exist", req.bucket))
+}
+
+func (hs *HttpServer) listS3Objects(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFile); err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	maxKeys, err := parseMaxKeys(query)
+	if err != nil {
+		return err
+	}
+
+	prefix, delimiter, marker := query.Get("prefix"), query.Get("delimiter"), query.Get("marker")
+	page, err := hs.listS3Keys(r.Context(), req, prefix, delimiter, marker, maxKeys)
+	if err != nil {
+		return err
+	}
+
+	ret := newListBucketResult(req.bucket, prefix, delimiter, maxKeys, query.Get("encoding-type"), page)
+	encodedMarker := ret.encode(marker)
+	ret.Marker = &encodedMarker
+	if page.isTruncated && len(delimiter) > 0 {
+		ret.NextMarker = ret.encode(page.next)
+	}
+
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+func (hs *HttpServer) listS3ObjectsV2(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFile); err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	maxKeys, err := parseMaxKeys(query)
+	if err != nil {
+		return err
+	}
+
+	startAfter := query.Get("start-after")
+	token := query.Get("continuation-token")
+	if len(token) > 0 {
+		buf, err := base64.URLEncoding.DecodeString(token)
+		if err != nil {
+			return newS3Error(http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect")
+		}
+		startAfter = string(buf)
+	}
+
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	page, err := hs.listS3Keys(r.Context(), req, prefix, delimiter, startAfter, maxKeys)
+	if err != nil {
+		return err
+	}
+
+	ret := newListBucketResult(req.bucket, prefix, delimiter, maxKeys, query.Get("encoding-type"), page)
+	ret.ContinuationToken = token
+	ret.StartAfter = ret.encode(query.Get("start-after"))
+	if page.isTruncated {
+		ret.NextContinuationToken = base64.URLEncoding.EncodeToString([]byte(page.next))
+	}
+
+	keyCount := len(ret.Contents) + len(ret.CommonPrefixes)
+	ret.KeyCount = &keyCount
+
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+// listS3Keys lists at most maxKeys objects and common prefixes after startAfter,
+// the objects are loaded from scheduler page by page until enough keys are collected
+func (hs *HttpServer) listS3Keys(ctx context.Context, req *s3Request, prefix, delimiter, startAfter string, maxKeys int) (*s3ListResult, error) {
+	ret := &s3ListResult{}
+	if maxKeys == 0 {
+		return ret, nil
+	}
+
+	marker := startAfter
+	for {
+		rsp, err := hs.scheduler.ListS3Objects(ctx, req.identity.Token, req.bucket, prefix, marker, maxKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range rsp.Objects {
+			marker = object.Key
+
+			if len(delimiter) > 0 {
+				if index := strings.Index(object.Key[len(prefix):], delimiter); index >= 0 {
+					commonPrefix := object.Key[:len(prefix)+index+len(delimiter)]
+					if commonPrefix == startAfter || commonPrefix == ret.next {
+						continue
+					}
+
+					if len(ret.objects)+len(ret.prefixes) == maxKeys {
+						ret.isTruncated = true
+						return ret, nil
+					}
+
+					ret.prefixes = append(ret.prefixes, commonPrefix)
+					ret.next = commonPrefix
+					continue
+				}
+			}
+
+			if len(ret.objects)+len(ret.prefixes) == maxKeys {
+				ret.isTruncated = true
+				return ret, nil
+			}
+
+			ret.objects = append(ret.objects, object)
+			ret.next = object.Key
+		}
+
+		if !rsp.IsTruncated || len(rsp.Objects) == 0 {
+			return ret, nil
+		}
+	}
+}
+
+func newListBucketResult(bucket, prefix, delimiter string, maxKeys int, encodingType string, page *s3ListResult) *listBucketResult {
+	ret := &listBucketResult{
+		Xmlns:        s3XMLNamespace,
+		Name:         bucket,
+		MaxKeys:      maxKeys,
+		IsTruncated:  page.isTruncated,
+		EncodingType: encodingType,
+	}
+	ret.Prefix = ret.encode(prefix)
+	ret.Delimiter = ret.encode(delimiter)
+
+	for _, object := range page.objects {
+		ret.Contents = append(ret.Contents, s3ObjectContent{
+			Key:          ret.encode(object.Key),
+			LastModified: s3Time(object.LastModified),
+			ETag:         s3ETag(object.CID),
+			Size:         object.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	for _, p := range page.prefixes {
+		ret.CommonPrefixes = append(ret.CommonPrefixes, s3CommonPrefix{Prefix: ret.encode(p)})
+	}
+
+	return ret
+}
+
+// encode escapes the key if the client requests url encoding
+func (ret *listBucketResult) encode(key string) string {
+	if ret.EncodingType == "url" {
+		return url.QueryEscape(key)
+	}
+	return key
+}
+
+func parseMaxKeys(query url.Values) (int, error) {
+	if !query.Has("max-keys") {
+		return s3DefaultMaxKeys, nil
+	}
+
+	maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+	if err != nil || maxKeys < 0 {
+		return 0, newS3Error(http.StatusBadRequest, "InvalidArgument", "max-keys must be a non-negative integer")
+	}
+
+	if maxKeys > s3DefaultMaxKeys {
+		maxKeys = s3DefaultMaxKeys
+	}
+	return maxKeys, nil
+}
+
+func (hs *HttpServer) putS3Object(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyCreateFile); err != nil {
+		return err
+	}
+
+	if r.ContentLength > int64(hs.maxSizeOfUploadFile) {
+		return errS3EntityTooLarge
+	}
+
+	if err := hs.checkS3Bucket(r.Context(), req); err != nil {
+		return err
+	}
+
+	// limit max concurrent
+	semaphore <- struct{}{}
+	defer func() { <-semaphore }()
+
+	body := http.MaxBytesReader(w, r.Body, int64(hs.maxSizeOfUploadFile))
+	asset, err := hs.asset.ImportFile(r.Context(), req.identity.UserID, body)
+	if err != nil {
+		return err
+	}
+
+	if err := hs.saveS3Object(r.Context(), req, asset); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", s3ETag(asset.CID))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// saveS3Object saves the imported asset as the object of request in scheduler,
+// the asset is removed if it fails and the asset is not stored before importing
+func (hs *HttpServer) saveS3Object(ctx context.Context, req *s3Request, asset *types.FileAsset) error {
+	object := &types.S3Object{Key: req.key, CID: asset.CID, Size: asset.Size, CarSize: asset.CarSize}
+	err := hs.scheduler.PutS3Object(ctx, req.identity.Token, req.bucket, object)
+	if err == nil || asset.Existed {
+		return err
+	}
+
+	if root, e := cid.Decode(asset.CID); e == nil {
+		if e := hs.asset.DeleteAsset(root); e != nil {
+			log.Errorf("delete asset %s error: %s", asset.CID, e.Error())
+		}
+	}
+
+	return err
+}
+
+func (hs *HttpServer) headS3Object(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFile); err != nil {
+		return err
+	}
+
+	object, err := hs.scheduler.GetS3Object(r.Context(), req.identity.Token, req.bucket, req.key)
+	if err != nil {
+		return err
+	}
+
+	setS3ObjectHeaders(w, object)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (hs *HttpServer) getS3Object(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyReadFile); err != nil {
+		return err
+	}
+
+	object, err := hs.scheduler.GetS3Object(r.Context(), req.identity.Token, req.bucket, req.key)
+	if err != nil {
+		return err
+	}
+
+	root, err := cid.Decode(object.CID)
+	if err != nil {
+		return err
+	}
+
+	setS3ObjectHeaders(w, object)
+
+	if ok, err := hs.asset.AssetExists(root); err != nil {
+		return err
+	} else if !ok {
+		return hs.proxyS3Object(w, r, object)
+	}
+
+	node, err := hs.getUnixFsNode(r.Context(), path.IpfsPath(root), root)
+	if err != nil {
+		return err
+	}
+	defer node.Close() //nolint:errcheck // ignore error
+
+	file, ok := node.(files.File)
+	if !ok {
+		return newS3Error(http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("object %s is not a file", req.key))
+	}
+
+	// Lazy seeker enables efficient range-requests
+	http.ServeContent(w, r, "", object.LastModified, &lazySeeker{size: object.Size, reader: file})
+	return nil
+}
+
+// proxyS3Object serves the object from the candidates which hold the asset
+func (hs *HttpServer) proxyS3Object(w http.ResponseWriter, r *http.Request, object *types.S3Object) error {
+	infos, err := hs.scheduler.GetCandidateDownloadInfos(r.Context(), object.CID)
+	if err != nil {
+		return err
+	}
+
+	httpClient := client.NewHTTP3Client()
+	for _, info := range infos {
+		if info.Tk == nil || len(info.Address) == 0 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(info.Tk); err != nil {
+			return err
+		}
+
+		url := fmt.Sprintf("https://%s/ipfs/%s", info.Address, object.CID)
+		proxyReq, err := http.NewRequestWithContext(r.Context(), http.MethodGet, url, bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			return err
+		}
+
+		if rng := r.Header.Get("Range"); len(rng) > 0 {
+			proxyReq.Header.Set("Range", rng)
+		}
+
+		resp, err := httpClient.Do(proxyReq)
+		if err != nil {
+			log.Warnf("get object %s from %s error: %s", object.Key, info.NodeID, err.Error())
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close() //nolint:errcheck // ignore error
+			log.Warnf("get object %s from %s, http status code %d", object.Key, info.NodeID, resp.StatusCode)
+			continue
+		}
+
+		for _, header := range []string{"Content-Length", "Content-Range", "Accept-Ranges"} {
+			if v := resp.Header.Get(header); len(v) > 0 {
+				w.Header().Set(header, v)
+			}
+		}
+
+		w.WriteHeader(resp.StatusCode)
+		_, err = io.Copy(w, resp.Body)
+		resp.Body.Close() //nolint:errcheck // ignore error
+		if err != nil {
+			log.Errorf("proxy object %s error: %s", object.Key, err.Error())
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no candidate can serve the asset %s of object %s", object.CID, object.Key)
+}
+
+func (hs *HttpServer) deleteS3Object(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyDeleteFile); err != nil {
+		return err
+	}
+
+	// deleting a missing object succeeds in s3
+	if err := hs.scheduler.DeleteS3Object(r.Context(), req.identity.Token, req.bucket, req.key); err != nil {
+		if s3ErrorFrom(err).Code != "NoSuchKey" {
+			return err
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (hs *HttpServer) deleteS3Objects(w http.ResponseWriter, r *http.Request, req *s3Request) error {
+	if err := checkS3Access(req, types.UserAPIKeyDeleteFile); err != nil {
+		return err
+	}
+
+	deleteReq := &deleteObjectsRequest{}
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxS3XMLBodySize)).Decode(deleteReq); err != nil {
+		return errS3MalformedXML
+	}
+
+	if len(deleteReq.Objects) > s3DefaultMaxKeys {
+		return errS3MalformedXML
+	}
+
+	ret := &deleteObjectsResult{Xmlns: s3XMLNamespace}
+	for _, object := range deleteReq.Objects {
+		err := hs.scheduler.DeleteS3Object(r.Context(), req.identity.Token, req.bucket, object.Key)
+		if err != nil {
+			if s3Err := s3ErrorFrom(err); s3Err.Code != "NoSuchKey" {
+				ret.Errors = append(ret.Errors, deleteObjectError{Key: object.Key, Code: s3Err.Code, Message: s3Err.Message})
+				continue
+			}
+		}
+
+		if !deleteReq.Quiet {
+			ret.Deleted = append(ret.Deleted, deletedObject{Key: object.Key})
+		}
+	}
+
+	writeS3XML(w, http.StatusOK, ret)
+	return nil
+}
+
+func setS3ObjectHeaders(w http.ResponseWriter, object *types.S3Object) {
+	ctype := mime.TypeByExtension(gopath.Ext(object.Key))
+	if len(ctype) == 0 {
+		ctype = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("ETag", s3ETag(object.CID))
+	w.Header().Set("Last-Modified", object.LastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set("Accept-Ranges", "bytes")
+}
+
+// s3ETag quotes the etag, the etag of object is the cid of asset and the etag of part is its md5
+func s3ETag(tag string) string {
+	return `"` + tag + `"`
+}
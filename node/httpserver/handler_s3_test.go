@@ -0,0 +1,81 @@
+package httpserver
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// listScheduler serves ListS3Objects from the sorted keys
+type listScheduler struct {
+	api.Scheduler
+	keys []string
+}
+
+func (ls *listScheduler) ListS3Objects(ctx context.Context, token, bucket, prefix, startAfter string, limit int) (*types.ListS3ObjectsRsp, error) {
+	rsp := &types.ListS3ObjectsRsp{}
+	for _, key := range ls.keys {
+		if !strings.HasPrefix(key, prefix) || key <= startAfter {
+			continue
+		}
+
+		if len(rsp.Objects) == limit {
+			rsp.IsTruncated = true
+			break
+		}
+		rsp.Objects = append(rsp.Objects, &types.S3Object{Key: key})
+	}
+	return rsp, nil
+}
+
+func TestListS3Keys(t *testing.T) {
+	keys := []string{"a.txt", "dir/1", "dir/2", "dir/sub/3", "docs/1", "e.txt", "f/1"}
+	sort.Strings(keys)
+
+	hs := &HttpServer{scheduler: &listScheduler{keys: keys}}
+	req := &s3Request{identity: &types.S3Identity{}}
+
+	// list the root with delimiter page by page
+	var entries []string
+	startAfter := ""
+	for i := 0; ; i++ {
+		page, err := hs.listS3Keys(context.Background(), req, "", "/", startAfter, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, object := range page.objects {
+			entries = append(entries, object.Key)
+		}
+		entries = append(entries, page.prefixes...)
+
+		if !page.isTruncated {
+			break
+		}
+
+		if i > len(keys) {
+			t.Fatal("list does not end")
+		}
+		startAfter = page.next
+	}
+
+	sort.Strings(entries)
+	expect := []string{"a.txt", "dir/", "docs/", "e.txt", "f/"}
+	if !reflect.DeepEqual(entries, expect) {
+		t.Fatalf("list %v, expect %v", entries, expect)
+	}
+
+	page, err := hs.listS3Keys(context.Background(), req, "dir/", "/", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.objects) != 2 || !reflect.DeepEqual(page.prefixes, []string{"dir/sub/"}) || page.isTruncated {
+		t.Fatalf("unexpected list of dir/: %d objects, prefixes %v, truncated %v", len(page.objects), page.prefixes, page.isTruncated)
+	}
+}
@@ -0,0 +1,35 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+// serveShard serves the erasure coded shard with the index in query
+func (hs *HttpServer) serveShard(w http.ResponseWriter, r *http.Request, assetCID string) (int, error) {
+	root, err := cid.Decode(assetCID)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("decode root cid error: %s", err.Error())
+	}
+
+	index, err := strconv.ParseInt(r.URL.Query().Get("index"), 10, 64)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("parse shard index error: %s", err.Error())
+	}
+
+	reader, err := hs.asset.GetShard(root, index)
+	if err != nil {
+		return http.StatusNotFound, fmt.Errorf("can not found shard %d of asset %s", index, assetCID)
+	}
+	defer reader.Close() //nolint:errcheck // ignore error
+
+	w.Header().Set("Content-Type", formatShard)
+
+	name := fmt.Sprintf("%s.%d", root.String(), index)
+	http.ServeContent(w, r, name, time.Time{}, reader)
+	return 0, nil
+}
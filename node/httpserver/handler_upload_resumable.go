@@ -0,0 +1,215 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+)
+
+// resumable uploads follow the tus protocol (https://tus.io/protocols/resumable-upload):
+// POST creates the session of the asset in token, PATCH appends data at the offset,
+// HEAD queries the committed offset and DELETE terminates the session.
+// The asset is saved after the last byte received.
+const (
+	resumableUploadPathPrefix = "/upload/resumable"
+	tusVersion                = "1.0.0"
+	tusExtensions             = "creation,termination"
+	offsetOctetStream         = "application/offset+octet-stream"
+)
+
+func (hs *HttpServer) resumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("resumableUploadHandler %s %s", r.Method, r.URL.Path)
+
+	setAccessControlAllowForHeader(w)
+	w.Header().Set("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, DELETE")
+	w.Header().Set("Access-Control-Expose-Headers", "Authorization, Location, Upload-Offset, Upload-Length, Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size")
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Tus-Version", tusVersion)
+		w.Header().Set("Tus-Extension", tusExtensions)
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(int64(hs.maxSizeOfUploadFile), 10))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	payload, err := hs.verifyUserToken(r)
+	if err != nil {
+		log.Errorf("verfiy token error: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	root, err := cid.Decode(payload.AssetCID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("decode asset cid error: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, resumableUploadPathPrefix), "/")
+	if r.Method == http.MethodPost && len(path) == 0 {
+		hs.createUploadSession(w, r, root, payload)
+		return
+	}
+
+	c, err := cid.Decode(path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("decode cid %s error: %s", path, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if !c.Equals(root) {
+		http.Error(w, fmt.Sprintf("token is not allowed to upload asset %s", path), http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		hs.headUploadSession(w, root, payload)
+	case http.MethodPatch:
+		hs.patchUploadSession(w, r, root, payload)
+	case http.MethodDelete:
+		hs.deleteUploadSession(w, root, payload)
+	default:
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+	}
+}
+
+func (hs *HttpServer) createUploadSession(w http.ResponseWriter, r *http.Request, root cid.Cid, payload *types.AuthUserUploadDownloadAsset) {
+	if payload.AssetSize > int64(hs.maxSizeOfUploadFile) {
+		http.Error(w, fmt.Sprintf("asset size %d, out of max size %d", payload.AssetSize, hs.maxSizeOfUploadFile), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if length := r.Header.Get("Upload-Length"); len(length) > 0 {
+		size, err := strconv.ParseInt(length, 10, 64)
+		if err != nil || size != payload.AssetSize {
+			http.Error(w, fmt.Sprintf("Upload-Length %s does not match the asset size %d", length, payload.AssetSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// a new session requires the asset to be allowed to upload by the scheduler,
+	// the session of another user is not replaced
+	if session, err := hs.asset.GetUploadSession(r.Context(), root); errors.Is(err, types.ErrUploadSessionNotFound) {
+		if _, err := hs.asset.GetUploadingAsset(r.Context(), root); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if err == nil && session.UserID != payload.UserID {
+		http.Error(w, types.ErrUploadSessionNotOwned.Error(), http.StatusForbidden)
+		return
+	}
+
+	session, err := hs.asset.CreateUploadSession(r.Context(), payload.UserID, root, payload.AssetSize, payload.Expiration)
+	if err != nil {
+		log.Errorf("create upload session error: %s", err.Error())
+		http.Error(w, err.Error(), uploadSessionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", resumableUploadPathPrefix, root.String()))
+	setUploadSessionHeader(w, session)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (hs *HttpServer) headUploadSession(w http.ResponseWriter, root cid.Cid, payload *types.AuthUserUploadDownloadAsset) {
+	session, err := hs.getUploadSessionOfUser(root, payload.UserID)
+	if err != nil {
+		w.WriteHeader(uploadSessionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	setUploadSessionHeader(w, session)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (hs *HttpServer) patchUploadSession(w http.ResponseWriter, r *http.Request, root cid.Cid, payload *types.AuthUserUploadDownloadAsset) {
+	if contentType := getContentType(r); contentType != offsetOctetStream {
+		http.Error(w, fmt.Sprintf("unsupported Content-type %s", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "invalid Upload-Offset", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := hs.getUploadSessionOfUser(root, payload.UserID); err != nil {
+		http.Error(w, err.Error(), uploadSessionErrorStatus(err))
+		return
+	}
+
+	// limit max concurrent
+	semaphore <- struct{}{}
+	defer func() { <-semaphore }()
+
+	session, err := hs.asset.AppendUploadSession(r.Context(), root, offset, r.Body)
+	if session != nil {
+		setUploadSessionHeader(w, session)
+	}
+
+	if err != nil {
+		log.Errorf("append upload session %s error: %s", root.String(), err.Error())
+		http.Error(w, err.Error(), uploadSessionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (hs *HttpServer) deleteUploadSession(w http.ResponseWriter, root cid.Cid, payload *types.AuthUserUploadDownloadAsset) {
+	if _, err := hs.getUploadSessionOfUser(root, payload.UserID); err != nil {
+		http.Error(w, err.Error(), uploadSessionErrorStatus(err))
+		return
+	}
+
+	if err := hs.asset.DeleteUploadSession(context.Background(), root); err != nil {
+		http.Error(w, err.Error(), uploadSessionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getUploadSessionOfUser returns the upload session of the asset, the session of another user is not accessible
+func (hs *HttpServer) getUploadSessionOfUser(root cid.Cid, userID string) (*types.UploadSession, error) {
+	session, err := hs.asset.GetUploadSession(context.Background(), root)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != userID {
+		return nil, types.ErrUploadSessionNotOwned
+	}
+
+	return session, nil
+}
+
+func setUploadSessionHeader(w http.ResponseWriter, session *types.UploadSession) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(session.Size, 10))
+}
+
+func uploadSessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, types.ErrUploadSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, types.ErrUploadOffsetMismatch):
+		return http.StatusConflict
+	case errors.Is(err, types.ErrUploadSessionBusy):
+		return http.StatusLocked
+	case errors.Is(err, types.ErrUploadSessionNotOwned):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/ipfs/go-cid"
+)
+
+type uploadSessionAsset struct {
+	Asset
+	session *types.UploadSession
+	deleted bool
+	created bool
+}
+
+func (a *uploadSessionAsset) GetUploadSession(ctx context.Context, root cid.Cid) (*types.UploadSession, error) {
+	return a.session, nil
+}
+
+func (a *uploadSessionAsset) DeleteUploadSession(ctx context.Context, root cid.Cid) error {
+	a.deleted = true
+	return nil
+}
+
+func (a *uploadSessionAsset) CreateUploadSession(ctx context.Context, userID string, root cid.Cid, size int64, expiration time.Time) (*types.UploadSession, error) {
+	a.created = true
+	return a.session, nil
+}
+
+func TestUploadSessionOwner(t *testing.T) {
+	root, err := cid.Decode("QmPXME1oRtoT627YKaDPDQ3PwA8tdP9rWuAAweLzqSwAWT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset := &uploadSessionAsset{session: &types.UploadSession{AssetCID: root.String(), UserID: "owner", Size: 10, Offset: 5}}
+	hs := &HttpServer{asset: asset, maxSizeOfUploadFile: 100}
+
+	w := httptest.NewRecorder()
+	hs.headUploadSession(w, root, &types.AuthUserUploadDownloadAsset{UserID: "other"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("head session of other user: status %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	hs.deleteUploadSession(w, root, &types.AuthUserUploadDownloadAsset{UserID: "other"})
+	if w.Code != http.StatusForbidden || asset.deleted {
+		t.Fatalf("delete session of other user: status %d, deleted %v", w.Code, asset.deleted)
+	}
+
+	// the session of the owner is not replaced by the session of other user
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, resumableUploadPathPrefix, nil)
+	hs.createUploadSession(w, r, root, &types.AuthUserUploadDownloadAsset{UserID: "other", AssetSize: 10})
+	if w.Code != http.StatusForbidden || asset.created {
+		t.Fatalf("create session of other user: status %d, created %v", w.Code, asset.created)
+	}
+
+	w = httptest.NewRecorder()
+	hs.headUploadSession(w, root, &types.AuthUserUploadDownloadAsset{UserID: "owner"})
+	if w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "5" {
+		t.Fatalf("head session of owner: status %d, offset %s", w.Code, w.Header().Get("Upload-Offset"))
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"crypto/rsa"
 	"fmt"
 	gopath "path"
+	"strings"
 	"sync"
 
 	"github.com/Filecoin-Titan/titan/api"
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"github.com/gbrlsnchs/jwt/v3"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/ipfs/go-blockservice"
 	"github.com/ipfs/go-cid"
 	bsfetcher "github.com/ipfs/go-fetcher/impl/blockservice"
@@ -42,6 +44,11 @@ type HttpServer struct {
 	apiSecret           *jwt.HMACSHA
 	maxSizeOfUploadFile int
 	webRedirect         string
+	enableS3Gateway     bool
+	cache               *readThroughCache
+	names               *lru.Cache
+	dnslinkDomains      map[string]struct{}
+	revokedLinks        *revokedLinks
 }
 
 type HttpServerOptions struct {
@@ -52,6 +59,12 @@ type HttpServerOptions struct {
 	APISecret           *jwt.HMACSHA
 	MaxSizeOfUploadFile int
 	WebRedirect         string
+	// EnableS3Gateway serves the s3 requests signed with the api keys of user
+	EnableS3Gateway bool
+	// ReadThroughCache serves the assets not held by fetching the missing blocks from the candidates, nil disables it
+	ReadThroughCache *ReadThroughCacheOptions
+	// DNSLinkDomains are the domains served by their dnslink on the root path
+	DNSLinkDomains []string
 }
 
 // NewHttpServer creates a new HttpServer with the given Asset, Scheduler, and RSA private key.
@@ -65,8 +78,27 @@ func NewHttpServer(opts *HttpServerOptions) *HttpServer {
 		tokens:              &sync.Map{},
 		maxSizeOfUploadFile: opts.MaxSizeOfUploadFile,
 		webRedirect:         opts.WebRedirect,
+		enableS3Gateway:     opts.EnableS3Gateway,
+		names:               newNameCache(),
+		revokedLinks:        newRevokedLinks(),
+		dnslinkDomains:      make(map[string]struct{}),
+	}
+
+	for _, domain := range opts.DNSLinkDomains {
+		hs.dnslinkDomains[strings.ToLower(domain)] = struct{}{}
 	}
 	hs.reporter = newReporter(hs)
+	go hs.revokedLinks.startRefresh(opts.Scheduler)
+
+	if opts.ReadThroughCache != nil {
+		cache, err := newReadThroughCache(opts.ReadThroughCache, opts.Scheduler)
+		if err != nil {
+			log.Errorf("newReadThroughCache error %s", err.Error())
+		} else {
+			hs.cache = cache
+			go cache.startReport()
+		}
+	}
 
 	if hs.validation != nil {
 		hs.validation.SetFunc(hs.FirstToken)
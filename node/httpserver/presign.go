@@ -0,0 +1,116 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// revokedLinksRefreshInterval is the interval the revoked presigned urls are loaded from the scheduler
+	revokedLinksRefreshInterval = time.Minute
+	// presignPasswordHeader carries the password of the presigned url, the query parameter password is used if it is empty
+	presignPasswordHeader = "X-Titan-Password"
+)
+
+// revokedLinks caches the ids of the revoked presigned urls
+type revokedLinks struct {
+	lock sync.RWMutex
+	ids  map[string]struct{}
+}
+
+func newRevokedLinks() *revokedLinks {
+	return &revokedLinks{ids: make(map[string]struct{})}
+}
+
+func (rl *revokedLinks) has(id string) bool {
+	rl.lock.RLock()
+	defer rl.lock.RUnlock()
+
+	_, ok := rl.ids[id]
+	return ok
+}
+
+func (rl *revokedLinks) set(ids []string) {
+	m := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		m[id] = struct{}{}
+	}
+
+	rl.lock.Lock()
+	rl.ids = m
+	rl.lock.Unlock()
+}
+
+// startRefresh loads the revoked presigned urls from the scheduler periodically
+func (rl *revokedLinks) startRefresh(scheduler api.Scheduler) {
+	for {
+		ids, err := scheduler.GetRevokedPresignedURLs(context.Background())
+		if err != nil {
+			log.Errorf("GetRevokedPresignedURLs error:%s", err.Error())
+		} else {
+			rl.set(ids)
+		}
+
+		time.Sleep(revokedLinksRefreshInterval)
+	}
+}
+
+// decodeJWTClaims decodes the claims of the token without verifying it
+func decodeJWTClaims(token string) (*types.AuthUserUploadDownloadAsset, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, xerrors.New("malformed token")
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, xerrors.Errorf("decode token error %w", err)
+	}
+
+	jwtPayload := &types.JWTPayload{}
+	if err = json.Unmarshal(buf, jwtPayload); err != nil {
+		return nil, err
+	}
+
+	claims := &types.AuthUserUploadDownloadAsset{}
+	if len(jwtPayload.Extend) > 0 {
+		if err = json.Unmarshal([]byte(jwtPayload.Extend), claims); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+// checkPresignedURL rejects the revoked or expired presigned url before the scheduler is asked,
+// the client ip and password are checked by the scheduler
+func (hs *HttpServer) checkPresignedURL(claims *types.AuthUserUploadDownloadAsset) error {
+	if hs.revokedLinks != nil && hs.revokedLinks.has(claims.LinkID) {
+		return &api.ErrWeb{Code: terrors.PresignedURLRevoked.Int(), Message: "presigned url is revoked"}
+	}
+
+	if !claims.Expiration.IsZero() && claims.Expiration.Before(time.Now()) {
+		return xerrors.New("presigned url is expired")
+	}
+
+	return nil
+}
+
+// presignPassword returns the password of the presigned url supplied by the request
+func presignPassword(r *http.Request) string {
+	password := r.Header.Get(presignPasswordHeader)
+	if len(password) == 0 {
+		password = r.URL.Query().Get("password")
+	}
+	return password
+}
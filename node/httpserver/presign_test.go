@@ -0,0 +1,63 @@
+package httpserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func presignToken(t *testing.T, claims *types.AuthUserUploadDownloadAsset) string {
+	extend, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(&types.JWTPayload{Extend: string(extend)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "header." + base64.RawURLEncoding.EncodeToString(payload) + ".sign"
+}
+
+func TestCheckPresignedURL(t *testing.T) {
+	token := presignToken(t, &types.AuthUserUploadDownloadAsset{LinkID: "link", Expiration: time.Now().Add(time.Hour)})
+	claims, err := decodeJWTClaims(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := &HttpServer{revokedLinks: newRevokedLinks()}
+	if err := hs.checkPresignedURL(claims); err != nil {
+		t.Fatal(err)
+	}
+
+	hs.revokedLinks.set([]string{"link"})
+	if err := hs.checkPresignedURL(claims); err == nil {
+		t.Fatal("revoked url is allowed")
+	}
+
+	expired, err := decodeJWTClaims(presignToken(t, &types.AuthUserUploadDownloadAsset{LinkID: "other", Expiration: time.Now().Add(-time.Minute)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hs.checkPresignedURL(expired); err == nil {
+		t.Fatal("expired url is allowed")
+	}
+}
+
+func TestPresignPassword(t *testing.T) {
+	r := httptest.NewRequest("GET", "/ipfs/bafy?password=query", nil)
+	if password := presignPassword(r); password != "query" {
+		t.Fatalf("password %q, expect query", password)
+	}
+
+	r.Header.Set(presignPasswordHeader, "header")
+	if password := presignPassword(r); password != "header" {
+		t.Fatalf("password %q, expect header", password)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto"
 	"encoding/gob"
+	"slices"
 	"sync"
 	"time"
 
@@ -103,6 +104,7 @@ func (r *reporter) mergeReports(rps []*report) []*types.WorkloadReport {
 		accumulateSpeed int64
 		startTime       time.Time
 		endTime         time.Time
+		clientIPs       []string
 	}
 	// reportMap := make(map[string]*types.WorkloadReport)
 	reportStatsMap := make(map[string]*reportStats)
@@ -123,6 +125,11 @@ func (r *reporter) mergeReports(rps []*report) []*types.WorkloadReport {
 		if rp.EndTime.After(r.endTime) {
 			r.endTime = rp.EndTime
 		}
+		for _, ip := range rp.ClientIPs {
+			if !slices.Contains(r.clientIPs, ip) {
+				r.clientIPs = append(r.clientIPs, ip)
+			}
+		}
 		reportStatsMap[rp.TokenID] = r
 	}
 
@@ -132,7 +139,7 @@ func (r *reporter) mergeReports(rps []*report) []*types.WorkloadReport {
 		if v.speedCount > 0 {
 			downloadSpeed = v.accumulateSpeed / int64(v.speedCount)
 		}
-		workload := &types.Workload{DownloadSpeed: downloadSpeed, DownloadSize: v.downloadSize, StartTime: v.startTime, EndTime: v.endTime}
+		workload := &types.Workload{DownloadSpeed: downloadSpeed, DownloadSize: v.downloadSize, StartTime: v.startTime, EndTime: v.endTime, ClientIPs: v.clientIPs}
 		workloadReport := &types.WorkloadReport{TokenID: v.tokenID, ClientID: v.clientID, Workload: workload}
 		reports = append(reports, workloadReport)
 	}
@@ -22,8 +22,9 @@ func (w *SpeedCountWriter) Write(bytes []byte) (int, error) {
 		w.startTime = time.Now()
 	}
 
-	w.dataSize += int64(len(bytes))
-	return w.w.Write(bytes)
+	n, err := w.w.Write(bytes)
+	w.dataSize += int64(n)
+	return n, err
 }
 
 func (w *SpeedCountWriter) WriteHeader(statusCode int) {
@@ -49,7 +50,7 @@ func (w *SpeedCountWriter) speed() int64 {
 	return int64(speed)
 }
 
-func (w *SpeedCountWriter) generateReport(payload *types.TokenPayload) *report {
+func (w *SpeedCountWriter) generateReport(payload *types.TokenPayload, clientIP string) *report {
 	if len(payload.ID) == 0 {
 		return nil
 	}
@@ -59,6 +60,7 @@ func (w *SpeedCountWriter) generateReport(payload *types.TokenPayload) *report {
 		DownloadSize:  int64(w.dataSize),
 		StartTime:     w.startTime,
 		EndTime:       time.Now(),
+		ClientIPs:     []string{clientIP},
 	}
 
 	return &report{
@@ -3,12 +3,16 @@ package locator
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
 	"github.com/Filecoin-Titan/titan/node/config"
 	"github.com/Filecoin-Titan/titan/node/handler"
 	"github.com/Filecoin-Titan/titan/region"
@@ -21,11 +25,21 @@ const (
 	lengthOfNodeID          = 32
 	maxTXTRecord            = 10000
 	txtRecordExpireTime     = 30 * time.Minute
+	dnsRecordTTL            = 60 // 60s
+	defaultDNSMaxAnswers    = 3
+	resolveNameTimeout      = 3 * time.Second
 )
 
 type TXTRecord struct {
 	time  time.Time
 	value string
+	// the record is not answered after the ttl, 0 if the record does not expire
+	ttl time.Duration
+}
+
+// expired checks if the record with ttl is expired
+func (r *TXTRecord) expired(now time.Time) bool {
+	return r.ttl > 0 && r.time.Add(r.ttl).Before(now)
 }
 
 type DNSServer struct {
@@ -65,8 +79,8 @@ func (ds *DNSServer) deleteExpireTXTRecord() {
 		ds.txtRecords.Range(func(key, value interface{}) {
 			// can not delete key in this range
 			domain := key.(string)
-			record := value.(TXTRecord)
-			if record.time.Add(txtRecordExpireTime).Before(time.Now()) {
+			record := value.(*TXTRecord)
+			if record.expired(time.Now()) || record.time.Add(txtRecordExpireTime).Before(time.Now()) {
 				toBeDeleteDomain = append(toBeDeleteDomain, domain)
 			}
 		})
@@ -79,6 +93,11 @@ func (ds *DNSServer) deleteExpireTXTRecord() {
 	}
 }
 func (ds *DNSServer) SetTXTRecord(domain string, value string) error {
+	return ds.setTXTRecord(domain, value, 0)
+}
+
+// setTXTRecord sets the txt record which expires after the ttl
+func (ds *DNSServer) setTXTRecord(domain string, value string, ttl time.Duration) error {
 	// release record
 	ds.deleteExpireTXTRecord()
 
@@ -87,7 +106,7 @@ func (ds *DNSServer) SetTXTRecord(domain string, value string) error {
 	}
 
 	domain = strings.TrimSuffix(domain, ".")
-	ds.txtRecords.Set(domain, &TXTRecord{time: time.Now(), value: value})
+	ds.txtRecords.Set(domain, &TXTRecord{time: time.Now(), value: value, ttl: ttl})
 
 	return nil
 }
@@ -101,14 +120,41 @@ type dnsHandler struct {
 	dnsServer *DNSServer
 }
 
+// candidateTarget is a candidate serving the asset, ranked by the distance to the client
+type candidateTarget struct {
+	nodeID   string
+	ip       net.IP
+	port     uint16
+	distance float64
+}
+
 func (h *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	m := new(dns.Msg)
 	m.SetReply(r)
 	m.Authoritative = true
 
+	remoteAddr := w.RemoteAddr().String()
+	if opt := r.IsEdns0(); opt != nil {
+		reply := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+		reply.SetUDPSize(opt.UDPSize())
+
+		// the resolver sends the subnet of the client, which locates the client better than the resolver itself
+		if subnet := clientSubnet(opt); subnet != nil {
+			remoteAddr = net.JoinHostPort(subnet.Address.String(), "0")
+			reply.Option = append(reply.Option, &dns.EDNS0_SUBNET{
+				Code:          dns.EDNS0SUBNET,
+				Family:        subnet.Family,
+				SourceNetmask: subnet.SourceNetmask,
+				SourceScope:   subnet.SourceNetmask,
+				Address:       subnet.Address,
+			})
+		}
+		m.Extra = append(m.Extra, reply)
+	}
+
 	switch r.Opcode {
 	case dns.OpcodeQuery:
-		h.HandlerQuery(m, w.RemoteAddr().String())
+		h.HandlerQuery(m, remoteAddr)
 	}
 
 	if err := w.WriteMsg(m); err != nil {
@@ -116,12 +162,32 @@ func (h *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
+// clientSubnet returns the EDNS client subnet of the query, nil if the client does not send or hides its subnet
+func clientSubnet(opt *dns.OPT) *dns.EDNS0_SUBNET {
+	for _, o := range opt.Option {
+		subnet, ok := o.(*dns.EDNS0_SUBNET)
+		if !ok {
+			continue
+		}
+
+		if subnet.SourceNetmask == 0 || subnet.Address == nil || subnet.Address.IsUnspecified() {
+			return nil
+		}
+		return subnet
+	}
+	return nil
+}
+
 func (h *dnsHandler) HandlerQuery(m *dns.Msg, remoteAddr string) {
 	log.Debugf("HandlerQuery request %#v", *m)
 	for _, q := range m.Question {
 		domain := strings.TrimSuffix(strings.ToLower(q.Name), ".")
 		switch q.Qtype {
 		case dns.TypeTXT:
+			if err := h.handlerDNSLinkRecord(domain); err != nil {
+				log.Infof("handlerDNSLinkRecord %s", err.Error())
+			}
+
 			if err := h.handlerTXTRecord(m, domain); err != nil {
 				log.Errorf("handlerTXTRecord error %s", err.Error())
 			}
@@ -129,40 +195,50 @@ func (h *dnsHandler) HandlerQuery(m *dns.Msg, remoteAddr string) {
 			if err := h.handlerCAARecord(m, domain); err != nil {
 				log.Errorf("handlerCAARecord error %s", err.Error())
 			}
-		case dns.TypeA:
+		case dns.TypeA, dns.TypeAAAA:
 			log.Debugf("Query for %s, remote address %s\n", q.Name, remoteAddr)
 
-			if ok, err := h.handlerLocalRecord(m, domain); err != nil {
+			if ok, err := h.handlerLocalRecord(m, q.Qtype, domain); err != nil {
 				log.Infof("handlerLocalRecord %s", err.Error())
 				return
 			} else if ok {
 				return
 			}
 
-			if ok, err := h.handlerNodeLocation(m, domain); err != nil {
+			if ok, err := h.handlerNodeLocation(m, q.Qtype, domain); err != nil {
 				log.Infof("handlerCandidateLocation %s", err.Error())
 				return
 			} else if ok {
 				return
 			}
 
-			if ok, err := h.handlerAssetLocation(m, domain, remoteAddr); err != nil {
+			if ok, err := h.handlerAssetLocation(m, q.Qtype, domain, remoteAddr); err != nil {
 				log.Infof("handlerAssetLocation %s", err.Error())
 				return
 			} else if ok {
 				return
 			}
+		case dns.TypeSRV, dns.TypeHTTPS:
+			if err := h.handlerAssetService(m, q.Qtype, domain, remoteAddr); err != nil {
+				log.Infof("handlerAssetService %s", err.Error())
+				return
+			}
 		}
 	}
 }
 
 func (h *dnsHandler) handlerTXTRecord(m *dns.Msg, domain string) error {
 	value, ok := h.dnsServer.txtRecords.Get(domain)
-	if ok {
+	if ok && !value.(*TXTRecord).expired(time.Now()) {
 		record := value.(*TXTRecord)
 
+		ttl := uint32(dnsRecordTTL)
+		if record.ttl > 0 {
+			ttl = uint32(max(time.Until(record.time.Add(record.ttl)), time.Second) / time.Second)
+		}
+
 		txt := &dns.TXT{}
-		txt.Hdr = dns.RR_Header{Name: m.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60}
+		txt.Hdr = dns.RR_Header{Name: m.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: ttl}
 		txt.Txt = []string{record.value}
 
 		m.Answer = append(m.Answer, txt)
@@ -171,6 +247,34 @@ func (h *dnsHandler) handlerTXTRecord(m *dns.Msg, domain string) error {
 	return fmt.Errorf("can not find %s txt record", domain)
 }
 
+// handlerDNSLinkRecord resolves the name of _dnslink.<name>.<zone> into a dnslink txt record,
+// the record is kept for the ttl of the name record
+func (h *dnsHandler) handlerDNSLinkRecord(domain string) error {
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 || labels[0] != ipns.DNSLinkLabel || !ipns.IsName(labels[1]) {
+		return nil
+	}
+
+	if value, ok := h.dnsServer.txtRecords.Get(domain); ok && !value.(*TXTRecord).expired(time.Now()) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveNameTimeout)
+	defer cancel()
+
+	record, err := h.dnsServer.ResolveName(ctx, labels[1])
+	if err != nil {
+		return err
+	}
+
+	ttl := min(record.TTL, time.Until(record.Validity))
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+
+	return h.dnsServer.setTXTRecord(domain, ipns.DNSLinkPrefix+record.Value, ttl)
+}
+
 func (h *dnsHandler) handlerCAARecord(m *dns.Msg, domain string) error {
 	rr, err := dns.NewRR(fmt.Sprintf("%s CAA 0 issue letsencrypt.org", domain))
 	if err == nil {
@@ -179,20 +283,24 @@ func (h *dnsHandler) handlerCAARecord(m *dns.Msg, domain string) error {
 	return err
 }
 
-func (h *dnsHandler) handlerLocalRecord(m *dns.Msg, domain string) (bool, error) {
+func (h *dnsHandler) handlerLocalRecord(m *dns.Msg, qtype uint16, domain string) (bool, error) {
 	ip, ok := h.dnsServer.DNSRecords[domain]
 	if !ok {
 		return false, nil
 	}
 
-	rr, err := dns.NewRR(fmt.Sprintf("%s A %s", domain, ip))
-	if err == nil {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return true, fmt.Errorf("invalid ip %s of local record %s", ip, domain)
+	}
+
+	if rr := addressRR(domain, qtype, addr); rr != nil {
 		m.Answer = append(m.Answer, rr)
 	}
-	return true, err
+	return true, nil
 }
 
-func (h *dnsHandler) handlerNodeLocation(m *dns.Msg, domain string) (bool, error) {
+func (h *dnsHandler) handlerNodeLocation(m *dns.Msg, qtype uint16, domain string) (bool, error) {
 	fields := strings.Split(domain, ".")
 	if len(fields) < 4 {
 		return false, fmt.Errorf("invalid domain %s", domain)
@@ -211,13 +319,19 @@ func (h *dnsHandler) handlerNodeLocation(m *dns.Msg, domain string) (bool, error
 		return false, err
 	}
 
-	if rr, err := dns.NewRR(fmt.Sprintf("%s A %s", domain, ip)); err == nil {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false, fmt.Errorf("invalid ip %s of node %s", ip, nodeID)
+	}
+
+	if rr := addressRR(domain, qtype, addr); rr != nil {
 		m.Answer = append(m.Answer, rr)
 	}
-	return true, err
+	return true, nil
 }
 
-func (h *dnsHandler) handlerAssetLocation(m *dns.Msg, domain, remoteAddr string) (bool, error) {
+// handlerAssetLocation answers the addresses of the candidates nearest to the client, the nearest first
+func (h *dnsHandler) handlerAssetLocation(m *dns.Msg, qtype uint16, domain, remoteAddr string) (bool, error) {
 	fields := strings.Split(domain, ".")
 	if len(fields) < 4 {
 		return false, fmt.Errorf("invalid domain %s", domain)
@@ -228,52 +342,183 @@ func (h *dnsHandler) handlerAssetLocation(m *dns.Msg, domain, remoteAddr string)
 		return false, fmt.Errorf("invalid cid %s", cid)
 	}
 
+	targets, err := h.candidateTargets(cid, remoteAddr)
+	if err != nil {
+		return false, err
+	}
+
+	for _, target := range targets {
+		if rr := addressRR(domain, qtype, target.ip); rr != nil {
+			m.Answer = append(m.Answer, rr)
+		}
+	}
+
+	return true, nil
+}
+
+// handlerAssetService answers the SRV or HTTPS records of the candidates serving the asset,
+// the service labels before the cid, such as _https._tcp, are ignored.
+// The targets are the node domains of the candidates, which are resolved by handlerNodeLocation
+func (h *dnsHandler) handlerAssetService(m *dns.Msg, qtype uint16, domain, remoteAddr string) error {
+	fields := strings.Split(domain, ".")
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "_") {
+		fields = fields[1:]
+	}
+
+	if len(fields) < 4 {
+		return fmt.Errorf("invalid domain %s", domain)
+	}
+
+	cid := fields[0]
+	if !h.isValidCID(cid) {
+		return fmt.Errorf("invalid cid %s", cid)
+	}
+
+	targets, err := h.candidateTargets(cid, remoteAddr)
+	if err != nil {
+		return err
+	}
+
+	zone := strings.Join(fields[1:], ".")
+	for i, target := range targets {
+		host := dns.Fqdn(fmt.Sprintf("%s.%s", strings.TrimPrefix(target.nodeID, prefixOfCandidateNodeID), zone))
+		hdr := dns.RR_Header{Name: dns.Fqdn(domain), Rrtype: qtype, Class: dns.ClassINET, Ttl: dnsRecordTTL}
+
+		switch qtype {
+		case dns.TypeSRV:
+			m.Answer = append(m.Answer, &dns.SRV{
+				Hdr:      hdr,
+				Priority: 0,
+				Weight:   srvWeight(targets[0].distance, target.distance),
+				Port:     target.port,
+				Target:   host,
+			})
+		case dns.TypeHTTPS:
+			value := []dns.SVCBKeyValue{&dns.SVCBPort{Port: target.port}}
+			if target.ip.To4() != nil {
+				value = append(value, &dns.SVCBIPv4Hint{Hint: []net.IP{target.ip}})
+			} else {
+				value = append(value, &dns.SVCBIPv6Hint{Hint: []net.IP{target.ip}})
+			}
+
+			m.Answer = append(m.Answer, &dns.HTTPS{SVCB: dns.SVCB{
+				Hdr:      hdr,
+				Priority: uint16(i + 1),
+				Target:   host,
+				Value:    value,
+			}})
+		}
+
+		// glue the address of the target, so the client does not query it again
+		if rr := addressRR(host, dns.TypeA, target.ip); rr != nil {
+			m.Extra = append(m.Extra, rr)
+		} else if rr := addressRR(host, dns.TypeAAAA, target.ip); rr != nil {
+			m.Extra = append(m.Extra, rr)
+		}
+	}
+
+	return nil
+}
+
+// candidateTargets returns the candidates serving the asset, ordered by the distance to the client
+// and limited to the max answers of the config
+func (h *dnsHandler) candidateTargets(cid, remoteAddr string) ([]*candidateTarget, error) {
 	ctx := context.WithValue(context.Background(), handler.RemoteAddr{}, remoteAddr)
 	infos, err := h.dnsServer.CandidateDownloadInfos(ctx, cid)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	userIP, _, err := net.SplitHostPort(remoteAddr)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	ipList := h.getIPList(infos)
-	if len(ipList) == 0 {
-		return false, fmt.Errorf("can not get candidate for cid %s", cid)
+	targets := h.getTargets(infos)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("can not get candidate for cid %s", cid)
 	}
 
-	targetIP := getUserNearestIP(userIP, ipList, h.dnsServer.reg)
-	rr := &dns.A{
-		Hdr: dns.RR_Header{
-			Name:   domain,
-			Rrtype: dns.TypeA,
-			Class:  dns.ClassINET,
-			Ttl:    60, // 60s
-		},
-		A: net.ParseIP(targetIP),
+	for _, target := range targets {
+		target.distance, err = calculateTwoIPDistance(userIP, target.ip.String(), h.dnsServer.reg)
+		if err != nil {
+			log.Errorf("calculate tow ip distance error %s", err.Error())
+			target.distance = math.MaxFloat64
+		}
 	}
-	m.Answer = append(m.Answer, rr)
 
-	return true, nil
-}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].distance < targets[j].distance
+	})
 
-func (h *dnsHandler) getIPList(downloadInfos []*types.CandidateDownloadInfo) []string {
-	if len(downloadInfos) == 0 {
-		return []string{}
+	limit := h.dnsServer.DNSMaxAnswers
+	if limit <= 0 {
+		limit = defaultDNSMaxAnswers
+	}
+	if len(targets) > limit {
+		targets = targets[:limit]
 	}
 
-	ips := make([]string, 0, len(downloadInfos))
+	return targets, nil
+}
+
+func (h *dnsHandler) getTargets(downloadInfos []*types.CandidateDownloadInfo) []*candidateTarget {
+	targets := make([]*candidateTarget, 0, len(downloadInfos))
+	seen := make(map[string]struct{}, len(downloadInfos))
 	for _, info := range downloadInfos {
-		ip, _, err := net.SplitHostPort(info.Address)
+		host, port, err := net.SplitHostPort(info.Address)
 		if err != nil {
 			log.Errorf("parse candidate address error %s, address %s", err.Error(), info.Address)
 			continue
 		}
-		ips = append(ips, ip)
+
+		ip := net.ParseIP(host)
+		if ip == nil {
+			log.Errorf("parse candidate ip error, address %s", info.Address)
+			continue
+		}
+
+		p, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			log.Errorf("parse candidate port error %s, address %s", err.Error(), info.Address)
+			continue
+		}
+
+		if _, ok := seen[info.Address]; ok {
+			continue
+		}
+		seen[info.Address] = struct{}{}
+
+		targets = append(targets, &candidateTarget{nodeID: info.NodeID, ip: ip, port: uint16(p)})
 	}
-	return ips
+	return targets
+}
+
+// addressRR returns the A or AAAA record of the ip, nil if the ip is not of the query type
+func addressRR(domain string, qtype uint16, ip net.IP) dns.RR {
+	hdr := dns.RR_Header{Name: dns.Fqdn(domain), Rrtype: qtype, Class: dns.ClassINET, Ttl: dnsRecordTTL}
+
+	switch qtype {
+	case dns.TypeA:
+		if ip4 := ip.To4(); ip4 != nil {
+			return &dns.A{Hdr: hdr, A: ip4}
+		}
+	case dns.TypeAAAA:
+		if ip.To4() == nil && ip.To16() != nil {
+			return &dns.AAAA{Hdr: hdr, AAAA: ip}
+		}
+	}
+	return nil
+}
+
+// srvWeight weights the candidate by its distance relative to the nearest candidate, in 1-100
+func srvWeight(nearest, distance float64) uint16 {
+	if distance == math.MaxFloat64 {
+		return 1
+	}
+
+	weight := math.Round(100 * (nearest + 1) / (distance + 1))
+	return uint16(math.Max(1, math.Min(100, weight)))
 }
 
 func (h *dnsHandler) isMatchNodeID(id string) bool {
@@ -0,0 +1,143 @@
+package locator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+	"github.com/Filecoin-Titan/titan/node/config"
+	"github.com/miekg/dns"
+)
+
+// nameLocator resolves the names of the records
+type nameLocator struct {
+	api.Locator
+	records  map[string]*types.NameRecord
+	resolved int
+}
+
+func (nl *nameLocator) ResolveName(ctx context.Context, name string) (*types.NameRecord, error) {
+	nl.resolved++
+	record, ok := nl.records[name]
+	if !ok {
+		return nil, fmt.Errorf("name %s not found", name)
+	}
+	return record, nil
+}
+
+func TestClientSubnet(t *testing.T) {
+	opt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	if clientSubnet(opt) != nil {
+		t.Fatal("expect no subnet")
+	}
+
+	opt.Option = append(opt.Option, &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 0, Address: net.ParseIP("0.0.0.0")})
+	if clientSubnet(opt) != nil {
+		t.Fatal("expect the hidden subnet is ignored")
+	}
+
+	opt.Option[0] = &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 24, Address: net.ParseIP("8.8.4.0")}
+	subnet := clientSubnet(opt)
+	if subnet == nil || subnet.Address.String() != "8.8.4.0" {
+		t.Fatalf("unexpected subnet %v", subnet)
+	}
+}
+
+func TestLocalRecordFamily(t *testing.T) {
+	h := &dnsHandler{dnsServer: &DNSServer{LocatorCfg: &config.LocatorCfg{DNSRecords: map[string]string{
+		"v4.titannet.io": "192.168.0.1",
+		"v6.titannet.io": "2001:db8::1",
+	}}}}
+
+	cases := []struct {
+		name   string
+		qtype  uint16
+		answer int
+	}{
+		{"v4.titannet.io.", dns.TypeA, 1},
+		{"v4.titannet.io.", dns.TypeAAAA, 0},
+		{"v6.titannet.io.", dns.TypeA, 0},
+		{"v6.titannet.io.", dns.TypeAAAA, 1},
+	}
+
+	for _, c := range cases {
+		m := new(dns.Msg)
+		m.SetQuestion(c.name, c.qtype)
+		h.HandlerQuery(m, "127.0.0.1:53")
+
+		if len(m.Answer) != c.answer {
+			t.Fatalf("%s %s: expect %d answers, got %d", c.name, dns.TypeToString[c.qtype], c.answer, len(m.Answer))
+		}
+
+		if c.answer > 0 && m.Answer[0].Header().Rrtype != c.qtype {
+			t.Fatalf("%s: unexpected answer %s", c.name, m.Answer[0].String())
+		}
+	}
+}
+
+func TestSRVWeight(t *testing.T) {
+	if w := srvWeight(100, 100); w != 100 {
+		t.Fatalf("expect the nearest weight 100, got %d", w)
+	}
+
+	if w := srvWeight(100, 1000); w <= 1 || w >= 100 {
+		t.Fatalf("unexpected weight %d", w)
+	}
+
+	if w := srvWeight(0, 1e9); w != 1 {
+		t.Fatalf("expect the min weight 1, got %d", w)
+	}
+}
+
+func TestDNSLinkRecord(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &types.NameRecord{
+		Value:    "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		Sequence: 1,
+		Validity: time.Now().Add(time.Hour),
+		TTL:      time.Minute,
+	}
+	if err := ipns.Sign(key, record); err != nil {
+		t.Fatal(err)
+	}
+
+	locator := &nameLocator{records: map[string]*types.NameRecord{record.Name: record}}
+	h := &dnsHandler{dnsServer: &DNSServer{LocatorCfg: &config.LocatorCfg{}, Locator: locator, txtRecords: NewSafeMap()}}
+
+	for i := 0; i < 2; i++ {
+		m := new(dns.Msg)
+		m.SetQuestion("_dnslink."+record.Name+".titannet.io.", dns.TypeTXT)
+		h.HandlerQuery(m, "127.0.0.1:53")
+
+		if len(m.Answer) != 1 {
+			t.Fatalf("expect 1 answer, got %d", len(m.Answer))
+		}
+
+		txt := m.Answer[0].(*dns.TXT)
+		if txt.Txt[0] != "dnslink="+record.Value || txt.Hdr.Ttl > 60 {
+			t.Fatalf("unexpected answer %s", txt.String())
+		}
+	}
+
+	// the record is cached for the ttl
+	if locator.resolved != 1 {
+		t.Fatalf("expect the name is resolved once, resolved %d", locator.resolved)
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("_dnslink.k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8.titannet.io.", dns.TypeTXT)
+	h.HandlerQuery(m, "127.0.0.1:53")
+	if len(m.Answer) != 0 {
+		t.Fatalf("expect no answer of unknown name, got %d", len(m.Answer))
+	}
+}
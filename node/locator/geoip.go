@@ -1,8 +1,6 @@
 package locator
 
 import (
-	"math"
-
 	"github.com/Filecoin-Titan/titan/region"
 	"github.com/golang/geo/s2"
 )
@@ -40,26 +38,3 @@ func calculateTwoIPDistance(ip1, ip2 string, rg region.Region) (float64, error)
 	distance := calculateDistance(lat1, lon1, lat2, lon2)
 	return distance, nil
 }
-
-func getUserNearestIP(userIP string, ipList []string, reg region.Region) string {
-	ipDistanceMap := make(map[string]float64)
-	for _, ip := range ipList {
-		distance, err := calculateTwoIPDistance(userIP, ip, reg)
-		if err != nil {
-			log.Errorf("calculate tow ip distance error %s", err.Error())
-			continue
-		}
-		ipDistanceMap[ip] = distance
-	}
-
-	minDistance := math.MaxFloat64
-	var nearestIP string
-	for ip, distance := range ipDistanceMap {
-		if distance < minDistance {
-			minDistance = distance
-			nearestIP = ip
-		}
-	}
-
-	return nearestIP
-}
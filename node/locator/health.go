@@ -0,0 +1,271 @@
+package locator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+const (
+	// probeInterval is the interval of probing the schedulers
+	probeInterval = 10 * time.Second
+	// probeTimeout is the timeout of a probe
+	probeTimeout = 3 * time.Second
+	// the scheduler is ejected after failing the count of probes in a row
+	unhealthyFailures = 3
+	// the ejected scheduler comes back after passing the count of probes in a row
+	healthySuccesses = 2
+	// smoothing factor of the moving averages
+	probeAlpha = 0.3
+)
+
+// schedulerHealth is the probe state of a scheduler
+type schedulerHealth struct {
+	api.SchedulerHealth
+	failures  int
+	successes int
+}
+
+// HealthChecker probes the schedulers continuously, the locator selects the healthy schedulers with low latency
+type HealthChecker struct {
+	storage Storage
+
+	lock   sync.RWMutex
+	apis   map[string]*SchedulerAPI
+	health map[string]*schedulerHealth
+}
+
+// NewHealthChecker creates a health checker and starts probing the schedulers in the storage
+func NewHealthChecker(storage Storage) *HealthChecker {
+	hc := &HealthChecker{
+		storage: storage,
+		apis:    make(map[string]*SchedulerAPI),
+		health:  make(map[string]*schedulerHealth),
+	}
+
+	go hc.start()
+
+	return hc
+}
+
+func (hc *HealthChecker) start() {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	for {
+		hc.probeAll()
+		<-ticker.C
+	}
+}
+
+// probeAll probes the schedulers in the storage, the schedulers removed from the storage are dropped
+func (hc *HealthChecker) probeAll() {
+	configs := hc.storage.GetAllSchedulerConfigs()
+
+	urls := make(map[string]struct{}, len(configs))
+	wg := &sync.WaitGroup{}
+	for _, config := range configs {
+		urls[config.SchedulerURL] = struct{}{}
+
+		s, err := hc.schedulerAPI(config)
+		if err != nil {
+			log.Errorf("new scheduler api error %s", err.Error())
+			hc.update(config, 0, 0, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *SchedulerAPI) {
+			defer wg.Done()
+
+			latency, onlineNodes, err := probe(s)
+			hc.update(s.config, latency, onlineNodes, err)
+		}(s)
+	}
+	wg.Wait()
+
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	for url := range hc.health {
+		if _, ok := urls[url]; !ok {
+			delete(hc.health, url)
+			delete(hc.apis, url)
+		}
+	}
+}
+
+// probe calls Version for the latency and gets the count of online edges
+func probe(s *SchedulerAPI) (time.Duration, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := s.Version(ctx); err != nil {
+		return 0, 0, err
+	}
+	latency := time.Since(start)
+
+	onlineNodes, err := s.GetOnlineNodeCount(ctx, types.NodeEdge)
+	if err != nil {
+		log.Debugf("get online node count of %s error %s", s.config.SchedulerURL, err.Error())
+	}
+
+	return latency, onlineNodes, nil
+}
+
+func (hc *HealthChecker) schedulerAPI(config *types.SchedulerCfg) (*SchedulerAPI, error) {
+	hc.lock.RLock()
+	s, ok := hc.apis[config.SchedulerURL]
+	hc.lock.RUnlock()
+
+	// the token of the scheduler changes when it restarts
+	if ok && s.config.AccessToken == config.AccessToken {
+		return s, nil
+	}
+
+	s, err := newSchedulerAPI(config)
+	if err != nil {
+		return nil, err
+	}
+
+	hc.lock.Lock()
+	hc.apis[config.SchedulerURL] = s
+	hc.lock.Unlock()
+
+	return s, nil
+}
+
+func (hc *HealthChecker) update(config *types.SchedulerCfg, latency time.Duration, onlineNodes int, err error) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	h, ok := hc.health[config.SchedulerURL]
+	if !ok {
+		h = &schedulerHealth{SchedulerHealth: api.SchedulerHealth{SchedulerURL: config.SchedulerURL, Healthy: true, Latency: latency}}
+		hc.health[config.SchedulerURL] = h
+	}
+
+	h.AreaID = config.AreaID
+	h.Weight = config.Weight
+	h.LastProbe = time.Now()
+
+	if err != nil {
+		h.failures++
+		h.successes = 0
+		h.ErrorRate = h.ErrorRate*(1-probeAlpha) + probeAlpha
+		h.LastError = err.Error()
+
+		if h.Healthy && h.failures >= unhealthyFailures {
+			h.Healthy = false
+			log.Warnf("scheduler %s is unhealthy: %s", config.SchedulerURL, err.Error())
+		}
+		return
+	}
+
+	h.successes++
+	h.failures = 0
+	h.ErrorRate = h.ErrorRate * (1 - probeAlpha)
+	h.Latency = time.Duration(float64(h.Latency)*(1-probeAlpha) + float64(latency)*probeAlpha)
+	h.OnlineNodes = onlineNodes
+	h.LastError = ""
+
+	if !h.Healthy && h.successes >= healthySuccesses {
+		h.Healthy = true
+		log.Infof("scheduler %s is healthy again", config.SchedulerURL)
+	}
+}
+
+// isHealthy returns false only if the scheduler is probed and ejected
+func (hc *HealthChecker) isHealthy(url string) bool {
+	if hc == nil {
+		return true
+	}
+
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	h, ok := hc.health[url]
+	return !ok || h.Healthy
+}
+
+// latency returns the latency of the scheduler, the scheduler not probed yet has the max latency
+func (hc *HealthChecker) latency(url string) time.Duration {
+	if hc == nil {
+		return 0
+	}
+
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	h, ok := hc.health[url]
+	if !ok {
+		return probeTimeout
+	}
+	return h.Latency
+}
+
+// weight returns the weight of the scheduler adjusted by its health,
+// the weight decreases with the error rate and the latency
+func (hc *HealthChecker) weight(config *types.SchedulerCfg) float64 {
+	if hc == nil {
+		return float64(config.Weight)
+	}
+
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	h, ok := hc.health[config.SchedulerURL]
+	if !ok {
+		return float64(config.Weight)
+	}
+
+	if !h.Healthy {
+		return 0
+	}
+
+	return float64(config.Weight) * (1 - h.ErrorRate) / (1 + float64(h.Latency)/float64(100*time.Millisecond))
+}
+
+// healthyConfigs returns the healthy schedulers ordered by the latency,
+// all the schedulers are returned if none is healthy, so the nodes still have a chance to connect
+func (hc *HealthChecker) healthyConfigs(configs []*types.SchedulerCfg) []*types.SchedulerCfg {
+	healthy := make([]*types.SchedulerCfg, 0, len(configs))
+	for _, config := range configs {
+		if hc.isHealthy(config.SchedulerURL) {
+			healthy = append(healthy, config)
+		}
+	}
+
+	if len(healthy) == 0 {
+		healthy = append(healthy, configs...)
+	}
+
+	sort.SliceStable(healthy, func(i, j int) bool {
+		return hc.latency(healthy[i].SchedulerURL) < hc.latency(healthy[j].SchedulerURL)
+	})
+
+	return healthy
+}
+
+// table returns the health of the schedulers ordered by the url
+func (hc *HealthChecker) table() []*api.SchedulerHealth {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	out := make([]*api.SchedulerHealth, 0, len(hc.health))
+	for _, h := range hc.health {
+		health := h.SchedulerHealth
+		out = append(out, &health)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].SchedulerURL < out[j].SchedulerURL
+	})
+
+	return out
+}
@@ -0,0 +1,58 @@
+package locator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestHealthChecker(t *testing.T) {
+	hc := &HealthChecker{apis: make(map[string]*SchedulerAPI), health: make(map[string]*schedulerHealth)}
+
+	fast := &types.SchedulerCfg{SchedulerURL: "https://192.168.0.1:3456/rpc/v0", Weight: 100}
+	slow := &types.SchedulerCfg{SchedulerURL: "https://192.168.0.2:3456/rpc/v0", Weight: 100}
+	fresh := &types.SchedulerCfg{SchedulerURL: "https://192.168.0.3:3456/rpc/v0", Weight: 100}
+
+	hc.update(fast, 10*time.Millisecond, 10, nil)
+	hc.update(slow, 500*time.Millisecond, 10, nil)
+
+	configs := hc.healthyConfigs([]*types.SchedulerCfg{fresh, slow, fast})
+	if len(configs) != 3 || configs[0] != fast || configs[1] != slow {
+		t.Fatalf("expect the schedulers ordered by latency, got %v", configs)
+	}
+
+	if hc.weight(fast) <= hc.weight(slow) {
+		t.Fatalf("expect the fast scheduler weighs more")
+	}
+
+	for i := 0; i < unhealthyFailures; i++ {
+		hc.update(slow, 0, 0, errors.New("timeout"))
+	}
+
+	if hc.isHealthy(slow.SchedulerURL) || hc.weight(slow) != 0 {
+		t.Fatal("expect the failed scheduler is ejected")
+	}
+
+	configs = hc.healthyConfigs([]*types.SchedulerCfg{slow, fast})
+	if len(configs) != 1 || configs[0] != fast {
+		t.Fatalf("expect only the healthy scheduler, got %v", configs)
+	}
+
+	if configs = hc.healthyConfigs([]*types.SchedulerCfg{slow}); len(configs) != 1 {
+		t.Fatal("expect all the schedulers if none is healthy")
+	}
+
+	for i := 0; i < healthySuccesses; i++ {
+		hc.update(slow, 100*time.Millisecond, 10, nil)
+	}
+
+	if !hc.isHealthy(slow.SchedulerURL) {
+		t.Fatal("expect the recovered scheduler is healthy")
+	}
+
+	if table := hc.table(); len(table) != 2 || table[0].SchedulerURL != fast.SchedulerURL {
+		t.Fatalf("unexpected health table %v", table)
+	}
+}
@@ -47,6 +47,7 @@ type Locator struct {
 	Storage
 	*config.LocatorCfg
 	*DNSServer
+	Health        *HealthChecker
 	Rand          *rand.Rand
 	ScheduelrAPIs SchedulerAPIMap
 }
@@ -122,9 +123,16 @@ func (l *Locator) selectBestSchedulers(apis []*SchedulerAPI, nodeID string) ([]s
 }
 
 func (l *Locator) randomSchedulerConfigWithWeight(configs []*types.SchedulerCfg) []string {
-	totalWeight := 0
+	// the static weights are adjusted by the health of the schedulers
+	weights := make(map[string]float32, len(configs))
+	totalWeight := float32(0)
 	for _, config := range configs {
-		totalWeight += config.Weight
+		weights[config.SchedulerURL] = float32(l.Health.weight(config))
+		totalWeight += weights[config.SchedulerURL]
+	}
+
+	if totalWeight == 0 {
+		return nil
 	}
 
 	sort.Slice(configs, func(i, j int) bool {
@@ -135,9 +143,9 @@ func (l *Locator) randomSchedulerConfigWithWeight(configs []*types.SchedulerCfg)
 	preAddWeight := float32(0)
 
 	for _, config := range configs {
-		nextAddWeight := preAddWeight + float32(config.Weight)
-		preWeight := preAddWeight / float32(totalWeight)
-		nextWeight := nextAddWeight / float32(totalWeight)
+		nextAddWeight := preAddWeight + weights[config.SchedulerURL]
+		preWeight := preAddWeight / totalWeight
+		nextWeight := nextAddWeight / totalWeight
 
 		if randomWeight > preWeight && randomWeight <= nextWeight {
 			return []string{config.SchedulerURL}
@@ -149,7 +157,10 @@ func (l *Locator) randomSchedulerConfigWithWeight(configs []*types.SchedulerCfg)
 	return nil
 }
 
+// getOrNewSchedulerAPIs returns the apis of the healthy schedulers, ordered by the latency
 func (l *Locator) getOrNewSchedulerAPIs(configs []*types.SchedulerCfg) ([]*SchedulerAPI, error) {
+	configs = l.Health.healthyConfigs(configs)
+
 	schedulerAPIs := make([]*SchedulerAPI, 0, len(configs))
 
 	for _, config := range configs {
@@ -174,6 +185,17 @@ func (l *Locator) getOrNewSchedulerAPI(config *types.SchedulerCfg) (*SchedulerAP
 		return scheduler, nil
 	}
 
+	scheduler, err := newSchedulerAPI(config)
+	if err != nil {
+		return nil, err
+	}
+
+	l.ScheduelrAPIs[config.SchedulerURL] = scheduler
+
+	return scheduler, nil
+}
+
+func newSchedulerAPI(config *types.SchedulerCfg) (*SchedulerAPI, error) {
 	log.Debugf("newSchedulerAPI, url:%s, areaID:%s, accessToken:%s", config.SchedulerURL, config.AreaID, config.AccessToken)
 
 	headers := http.Header{}
@@ -183,10 +205,12 @@ func (l *Locator) getOrNewSchedulerAPI(config *types.SchedulerCfg) (*SchedulerAP
 		return nil, err
 	}
 
-	scheduler = &SchedulerAPI{api, config}
-	l.ScheduelrAPIs[config.SchedulerURL] = scheduler
+	return &SchedulerAPI{api, config}, nil
+}
 
-	return scheduler, nil
+// GetSchedulerHealth retrieves the health of the schedulers probed by the locator
+func (l *Locator) GetSchedulerHealth(ctx context.Context) ([]*api.SchedulerHealth, error) {
+	return l.Health.table(), nil
 }
 
 func (l *Locator) EdgeDownloadInfos(ctx context.Context, cid string) ([]*types.EdgeDownloadInfoList, error) {
@@ -380,7 +404,7 @@ func (l *Locator) GetUserAccessPoint(ctx context.Context, userIP string) (*api.A
 		areaID = geoInfo.Geo
 	}
 
-	configs := l.GetAllSchedulerConfigs()
+	configs := l.Health.healthyConfigs(l.GetAllSchedulerConfigs())
 	if cfg, err := l.getSchedulerWithUserLastEntry(configs, userIP); err != nil {
 		return nil, err
 	} else if cfg != nil {
@@ -0,0 +1,70 @@
+package locator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+)
+
+// ResolveName retrieves the record of the mutable name from the schedulers, the valid record with the highest sequence wins
+func (l *Locator) ResolveName(ctx context.Context, name string) (*types.NameRecord, error) {
+	if !ipns.IsName(name) {
+		return nil, fmt.Errorf("%s is not a name", name)
+	}
+
+	schedulerAPIs, err := l.getOrNewSchedulerAPIs(l.GetAllSchedulerConfigs())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schedulerAPIs) == 0 {
+		return nil, fmt.Errorf("no scheduler exist")
+	}
+
+	timeout, err := time.ParseDuration(l.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var best *types.NameRecord
+	lock := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for _, api := range schedulerAPIs {
+		wg.Add(1)
+
+		go func(s *SchedulerAPI) {
+			defer wg.Done()
+
+			record, err := s.ResolveName(ctx, name)
+			if err != nil {
+				log.Debugf("ResolveName %s from %s error: %s", name, s.config.SchedulerURL, err.Error())
+				return
+			}
+
+			if err := ipns.Verify(record); err != nil || ipns.Expired(record, time.Now()) {
+				log.Warnf("scheduler %s returns invalid record of name %s", s.config.SchedulerURL, name)
+				return
+			}
+
+			lock.Lock()
+			if best == nil || record.Sequence > best.Sequence {
+				best = record
+			}
+			lock.Unlock()
+		}(api)
+	}
+	wg.Wait()
+
+	if best == nil {
+		return nil, fmt.Errorf("can not resolve name %s", name)
+	}
+
+	return best, nil
+}
@@ -25,19 +25,22 @@ func NewRateLimiter(device *device.Device) *rate.Limiter {
 	return rate.NewLimiter(rate.Limit(device.GetBandwidthUp()), int(device.GetBandwidthUp()))
 }
 
-// NewNodeStorageManager creates a new instance of storage.Manager with the given carfile store path.
-func NewNodeStorageManager(metadataPaths dtypes.NodeMetadataPath, assetsPaths dtypes.AssetsPaths, minioConfig *config.MinioConfig, schedulerAPI api.Scheduler) (*storage.Manager, error) {
-	opts := &storage.ManagerOptions{
-		MetaDataPath: string(metadataPaths),
-		AssetsPaths:  assetsPaths,
-		MinioConfig:  minioConfig,
-		SchedulerAPI: schedulerAPI,
+// NewNodeStorageManager creates a function that generates new instances of storage.Manager with the given carfile store path.
+func NewNodeStorageManager(dedup bool) func(metadataPaths dtypes.NodeMetadataPath, assetsPaths dtypes.AssetsPaths, minioConfig *config.MinioConfig, schedulerAPI api.Scheduler) (*storage.Manager, error) {
+	return func(metadataPaths dtypes.NodeMetadataPath, assetsPaths dtypes.AssetsPaths, minioConfig *config.MinioConfig, schedulerAPI api.Scheduler) (*storage.Manager, error) {
+		opts := &storage.ManagerOptions{
+			MetaDataPath: string(metadataPaths),
+			AssetsPaths:  assetsPaths,
+			MinioConfig:  minioConfig,
+			SchedulerAPI: schedulerAPI,
+			Dedup:        dedup,
+		}
+		return storage.NewManager(opts)
 	}
-	return storage.NewManager(opts)
 }
 
 // NewAssetsManager creates a function that generates new instances of asset.Manager.
-func NewAssetsManager(pullParallel int, pullTimeout int, pullRetry int, ipfsAPIURL string) func(storageMgr *storage.Manager, schedulerAPI api.Scheduler) (*asset.Manager, error) {
+func NewAssetsManager(pullParallel int, pullTimeout int, pullRetry int, ipfsAPIURL string, ipfsGateways []string, localImportPath string) func(storageMgr *storage.Manager, schedulerAPI api.Scheduler) (*asset.Manager, error) {
 	return func(storageMgr *storage.Manager, schedulerAPI api.Scheduler) (*asset.Manager, error) {
 		opts := &asset.ManagerOptions{
 			Storage:      storageMgr,
@@ -46,6 +49,9 @@ func NewAssetsManager(pullParallel int, pullTimeout int, pullRetry int, ipfsAPIU
 			PullParallel: pullParallel,
 			PullTimeout:  pullTimeout,
 			PullRetry:    pullRetry,
+
+			IPFSGateways:    ipfsGateways,
+			LocalImportPath: localImportPath,
 		}
 		return asset.NewManager(opts)
 	}
@@ -10,9 +10,11 @@ import (
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/modules/helpers"
 	"github.com/Filecoin-Titan/titan/node/repo"
+	"github.com/Filecoin-Titan/titan/node/scheduler"
 	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
+	"github.com/Filecoin-Titan/titan/node/scheduler/sharding"
 	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
 	"github.com/Filecoin-Titan/titan/node/sqldb"
 	"github.com/filecoin-project/go-jsonrpc/auth"
@@ -53,6 +55,7 @@ type StorageManagerParams struct {
 	NodeManger *node.Manager
 	dtypes.GetSchedulerConfigFunc
 	*db.SQLDB
+	*pubsub.PubSub
 }
 
 // NewStorageManager creates a new storage manager instance
@@ -64,10 +67,11 @@ func NewStorageManager(params StorageManagerParams) *assets.Manager {
 		ds      = params.MetadataDS
 		cfgFunc = params.GetSchedulerConfigFunc
 		sdb     = params.SQLDB
+		p       = params.PubSub
 	)
 
 	ctx := helpers.LifecycleCtx(mctx, lc)
-	m := assets.NewManager(nodeMgr, ds, cfgFunc, sdb)
+	m := assets.NewManager(nodeMgr, ds, cfgFunc, sdb, p)
 
 	lc.Append(fx.Hook{
 		OnStart: func(context.Context) error {
@@ -96,6 +100,39 @@ func NewValidation(mctx helpers.MetricsCtx, l fx.Lifecycle, nm *node.Manager, am
 	return v
 }
 
+// NewSharding creates a new sharding manager instance, which takes over the assets of the lost schedulers in the area
+func NewSharding(mctx helpers.MetricsCtx, l fx.Lifecycle, ec *etcdcli.Client, sdb *db.SQLDB, am *assets.Manager, serverID dtypes.ServerID, configFunc dtypes.GetSchedulerConfigFunc) (*sharding.Manager, error) {
+	s, err := sharding.NewManager(ec, sdb, am, serverID, configFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := helpers.LifecycleCtx(mctx, l)
+	l.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go s.Start(ctx)
+			return nil
+		},
+	})
+
+	return s, nil
+}
+
+// NewAuditLogCleaner creates a new audit log cleaner instance, which deletes the audit logs out of the retention
+func NewAuditLogCleaner(mctx helpers.MetricsCtx, l fx.Lifecycle, sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc) *scheduler.AuditLogCleaner {
+	c := scheduler.NewAuditLogCleaner(sdb, configFunc)
+
+	ctx := helpers.LifecycleCtx(mctx, l)
+	l.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go c.Start(ctx)
+			return nil
+		},
+	})
+
+	return c
+}
+
 // NewSetSchedulerConfigFunc creates a function to set the scheduler config
 func NewSetSchedulerConfigFunc(r repo.LockedRepo) func(config.SchedulerCfg) error {
 	return func(cfg config.SchedulerCfg) (err error) {
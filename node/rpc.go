@@ -2,6 +2,8 @@ package node
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"runtime"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/lib/rpcenc"
 	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/metrics/proxy"
@@ -55,6 +58,11 @@ func ServeRPC(h http.Handler, id string, addr string) (StopFunc, error) {
 	return srv.Shutdown, err
 }
 
+// auditRecorder records the privileged calls of the scheduler api
+type auditRecorder interface {
+	RecordAudit(ctx context.Context, method string, args []interface{}, err error)
+}
+
 // SchedulerHandler returns a scheduler handler, to be mounted as-is on the server.
 func SchedulerHandler(a api.Scheduler, permission bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
 	m := mux.NewRouter()
@@ -76,10 +84,21 @@ func SchedulerHandler(a api.Scheduler, permission bool, opts ...jsonrpc.ServerOp
 	fnapi := proxy.MetricedSchedulerAPI(a)
 	if permission {
 		fnapi = api.PermissionedSchedulerAPI(fnapi)
+		// the privileged calls are audited, including the ones denied by the permission
+		if recorder, ok := a.(auditRecorder); ok {
+			fnapi = api.AuditedSchedulerAPI(fnapi, recorder.RecordAudit)
+		}
 	}
 
 	serveRPC("/rpc/v0", fnapi)
 
+	// server-sent events of the scheduler
+	var eventsHandler http.Handler = handleEvents(fnapi)
+	if permission {
+		eventsHandler = mhandler.New(a.AuthVerify, handleEvents(fnapi))
+	}
+	m.Handle("/rpc/v0/events", eventsHandler)
+
 	// debugging
 	m.Handle("/debug/metrics", metrics.Exporter())
 	m.Handle("/debug/pprof-set/mutex", handleFractionOpt("MutexProfileFraction", func(x int) {
@@ -124,6 +143,48 @@ func LocatorHandler(a api.Locator, permission bool) (http.Handler, error) {
 	return rootMux, nil
 }
 
+// handleEvents streams the scheduler events as server-sent events, filtered by the query parameters topic, node_id and hash
+func handleEvents(a api.Scheduler) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		flusher, ok := rw.(http.Flusher)
+		if !ok {
+			http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		filter := &types.EventFilter{NodeID: query.Get("node_id"), Hash: query.Get("hash")}
+		for _, topic := range query["topic"] {
+			filter.Topics = append(filter.Topics, types.EventTopics(topic))
+		}
+
+		events, err := a.SubscribeEvents(r.Context(), filter)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.Header().Set("Cache-Control", "no-cache")
+		rw.Header().Set("Connection", "keep-alive")
+		rw.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				rpclog.Errorf("marshal event err:%s", err.Error())
+				continue
+			}
+
+			if _, err = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event.Topic, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
 func handleFractionOpt(name string, setter func(int)) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -3,14 +3,18 @@ package scheduler
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"database/sql"
 	"encoding/gob"
 	"fmt"
 	"time"
 
+	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
 	"github.com/Filecoin-Titan/titan/node/handler"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
 	"golang.org/x/xerrors"
 )
 
@@ -163,7 +167,7 @@ func (s *Scheduler) PullAsset(ctx context.Context, info *types.PullAssetReq) err
 
 	info.Hash = hash
 
-	if info.Replicas < 1 {
+	if info.DataShards == 0 && info.Replicas < 1 {
 		return xerrors.Errorf("replicas %d must greater than 1", info.Replicas)
 	}
 
@@ -171,9 +175,38 @@ func (s *Scheduler) PullAsset(ctx context.Context, info *types.PullAssetReq) err
 		return xerrors.Errorf("expiration %s less than now(%v)", info.Expiration.String(), time.Now())
 	}
 
+	owner, err := s.assetOwner(info)
+	if err != nil {
+		return err
+	}
+
+	if owner != nil {
+		info.Forwarded = true
+		return owner.PullAsset(ctx, info)
+	}
+
 	return s.AssetManager.CreateAssetPullTask(info) // TODO UserID
 }
 
+// assetOwner returns the scheduler which owns the new asset in the area, nil if the asset is pulled by this scheduler.
+// The asset pulled to the given nodes is kept by this scheduler, the nodes are connected to it
+func (s *Scheduler) assetOwner(info *types.PullAssetReq) (api.Scheduler, error) {
+	if s.ShardingManager == nil || info.Forwarded || info.SeedNodeID != "" || len(info.CandidateNodeList) > 0 || len(info.EdgeNodeList) > 0 {
+		return nil, nil
+	}
+
+	_, err := s.AssetManager.LoadAssetRecord(info.Hash)
+	if err == nil {
+		return nil, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, xerrors.Errorf("LoadAssetRecord err:%s", err.Error())
+	}
+
+	return s.ShardingManager.OwnerAPI(info.Hash)
+}
+
 // GetAssetListForBucket retrieves a list of asset hashes for the specified node's bucket.
 func (s *Scheduler) GetAssetListForBucket(ctx context.Context, bucketID uint32) ([]string, error) {
 	nodeID := handler.GetNodeID(ctx)
@@ -343,3 +376,180 @@ func (s *Scheduler) SwitchFillDiskTimer(ctx context.Context, open bool) error {
 
 	return nil
 }
+
+// GetAssetShardSources retrieves the erasure coding info of an asset and the download infos of its shards,
+// the shards held by the requesting node are excluded
+func (s *Scheduler) GetAssetShardSources(ctx context.Context, assetCID string) (*types.AssetShardSources, error) {
+	nodeID := handler.GetNodeID(ctx)
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", assetCID, err.Error())
+	}
+
+	eInfo, err := s.db.LoadAssetErasureInfo(hash)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadAssetErasureInfo err:%s", err.Error())
+	}
+
+	shards, err := s.db.LoadReplicaShards(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return nil, xerrors.Errorf("LoadReplicaShards err:%s", err.Error())
+	}
+
+	titanRsa := titanrsa.New(crypto.SHA256, crypto.SHA256.New())
+	sources := make([]*types.ShardSource, 0, len(shards))
+	workloadRecords := make([]*types.WorkloadRecord, 0, len(shards))
+
+	for _, shard := range shards {
+		if shard.NodeID == nodeID {
+			continue
+		}
+
+		cNode := s.NodeManager.GetNode(shard.NodeID)
+		if cNode == nil {
+			continue
+		}
+
+		if !cNode.Traversable() {
+			continue
+		}
+
+		token, tkPayload, err := cNode.Token(assetCID, nodeID, titanRsa, s.NodeManager.PrivateKey)
+		if err != nil {
+			continue
+		}
+
+		workloadRecord := &types.WorkloadRecord{TokenPayload: *tkPayload, Status: types.WorkloadStatusCreate, ClientEndTime: tkPayload.Expiration.Unix()}
+		workloadRecords = append(workloadRecords, workloadRecord)
+
+		sources = append(sources, &types.ShardSource{
+			ShardIndex: shard.ShardIndex,
+			CandidateDownloadInfo: &types.CandidateDownloadInfo{
+				NodeID:  shard.NodeID,
+				Address: cNode.DownloadAddr(),
+				Tk:      token,
+				NatType: cNode.NATType.String(),
+			},
+		})
+	}
+
+	if len(workloadRecords) > 0 {
+		if err = s.NodeManager.SaveWorkloadRecord(workloadRecords); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.AssetShardSources{ErasureInfo: eInfo, Sources: sources}, nil
+}
+
+// CreateLifecyclePolicy creates an asset lifecycle policy and returns its id
+func (s *Scheduler) CreateLifecyclePolicy(ctx context.Context, policy *types.LifecyclePolicy) (int64, error) {
+	if policy == nil || (len(policy.Rules) == 0 && !policy.DeleteExpired) {
+		return 0, xerrors.New("policy has no rules")
+	}
+
+	for _, rule := range policy.Rules {
+		if rule.AfterDays < 0 || rule.MaxRetrievals < 0 || rule.EdgeReplicas < 0 {
+			return 0, xerrors.Errorf("invalid rule %+v", *rule)
+		}
+	}
+
+	return s.db.SaveLifecyclePolicy(policy)
+}
+
+// ListLifecyclePolicies lists all asset lifecycle policies
+func (s *Scheduler) ListLifecyclePolicies(ctx context.Context) ([]*types.LifecyclePolicy, error) {
+	return s.db.LoadLifecyclePolicies()
+}
+
+// DeleteLifecyclePolicy deletes an asset lifecycle policy and detaches it from assets and groups
+func (s *Scheduler) DeleteLifecyclePolicy(ctx context.Context, policyID int64) error {
+	return s.db.DeleteLifecyclePolicy(policyID)
+}
+
+// SetAssetLifecyclePolicy attaches a lifecycle policy to an asset, policyID 0 detaches the policy
+func (s *Scheduler) SetAssetLifecyclePolicy(ctx context.Context, cid string, policyID int64) error {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	if policyID == 0 {
+		return s.db.DeleteAssetLifecyclePolicy(hash)
+	}
+
+	if err := s.checkLifecyclePolicy(policyID); err != nil {
+		return err
+	}
+
+	return s.db.SaveAssetLifecyclePolicy(hash, policyID)
+}
+
+// SetGroupLifecyclePolicy attaches a lifecycle policy to a user asset group, policyID 0 detaches the policy
+func (s *Scheduler) SetGroupLifecyclePolicy(ctx context.Context, userID string, groupID int, policyID int64) error {
+	if policyID == 0 {
+		return s.db.DeleteGroupLifecyclePolicy(userID, groupID)
+	}
+
+	if groupID != rootGroup {
+		exist, err := s.db.AssetGroupExists(userID, groupID)
+		if err != nil {
+			return err
+		}
+
+		if !exist {
+			return xerrors.Errorf("group %d of user %s is not exist", groupID, userID)
+		}
+	}
+
+	if err := s.checkLifecyclePolicy(policyID); err != nil {
+		return err
+	}
+
+	return s.db.SaveGroupLifecyclePolicy(userID, groupID, policyID)
+}
+
+func (s *Scheduler) checkLifecyclePolicy(policyID int64) error {
+	exist, err := s.db.LifecyclePolicyExists(policyID)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return xerrors.Errorf("lifecycle policy %d is not exist", policyID)
+	}
+
+	return nil
+}
+
+// ReportPartialAssets replaces the assets the edge partially holds in its read-through cache
+func (s *Scheduler) ReportPartialAssets(ctx context.Context, assets []*types.PartialAsset) error {
+	nodeID := handler.GetNodeID(ctx)
+	if s.NodeManager.GetEdgeNode(nodeID) == nil {
+		return xerrors.Errorf("edge %s not exists", nodeID)
+	}
+
+	infos := make([]*types.PartialReplica, 0, len(assets))
+	for _, asset := range assets {
+		hash, err := cidutil.CIDToHash(asset.CID)
+		if err != nil {
+			log.Warnf("ReportPartialAssets %s cid to hash err:%s", asset.CID, err.Error())
+			continue
+		}
+
+		infos = append(infos, &types.PartialReplica{NodeID: nodeID, Hash: hash, Blocks: asset.Blocks, Size: asset.Size})
+	}
+
+	return s.db.ReplacePartialReplicas(nodeID, infos)
+}
+
+// GetPartialReplicas retrieves the edges partially holding the asset with the specified CID
+func (s *Scheduler) GetPartialReplicas(ctx context.Context, cid string) ([]*types.PartialReplica, error) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return nil, xerrors.Errorf("%s cid to hash err:%s", cid, err.Error())
+	}
+
+	return s.db.LoadPartialReplicas(hash)
+}
@@ -26,7 +26,7 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{177}); err != nil {
+	if _, err := cw.Write([]byte{179}); err != nil {
 		return err
 	}
 
@@ -211,6 +211,28 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.DataShards (int64) (int64)
+	if len("DataShards") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataShards\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataShards"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataShards")); err != nil {
+		return err
+	}
+
+	if t.DataShards >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.DataShards)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.DataShards-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.RetryCount (int64) (int64)
 	if len("RetryCount") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"RetryCount\" was too long")
@@ -300,6 +322,28 @@ func (t *AssetPullingInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.ParityShards (int64) (int64)
+	if len("ParityShards") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ParityShards\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ParityShards"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ParityShards")); err != nil {
+		return err
+	}
+
+	if t.ParityShards >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ParityShards)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.ParityShards-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.CandidateReplicas (int64) (int64)
 	if len("CandidateReplicas") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"CandidateReplicas\" was too long")
@@ -603,6 +647,32 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Bandwidth = int64(extraI)
 			}
+			// t.DataShards (int64) (int64)
+		case "DataShards":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.DataShards = int64(extraI)
+			}
 			// t.RetryCount (int64) (int64)
 		case "RetryCount":
 			{
@@ -692,6 +762,32 @@ func (t *AssetPullingInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.EdgeWaitings = int64(extraI)
 			}
+			// t.ParityShards (int64) (int64)
+		case "ParityShards":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.ParityShards = int64(extraI)
+			}
 			// t.CandidateReplicas (int64) (int64)
 		case "CandidateReplicas":
 			{
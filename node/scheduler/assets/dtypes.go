@@ -43,6 +43,10 @@ type AssetPullingInfo struct {
 	SeedNodeID string
 
 	Note string
+
+	// erasure coding, DataShards is 0 if the edges store full replicas
+	DataShards   int64
+	ParityShards int64
 }
 
 // ToAssetRecord converts AssetPullingInfo to types.AssetRecord
@@ -77,6 +81,11 @@ func assetPullingInfoFrom(info *types.AssetRecord, assetDB *db.SQLDB) *AssetPull
 		Note:              info.Note,
 	}
 
+	if eInfo, err := assetDB.LoadAssetErasureInfo(info.Hash); err == nil {
+		cInfo.DataShards = eInfo.DataShards
+		cInfo.ParityShards = eInfo.ParityShards
+	}
+
 	for _, r := range info.ReplicaInfos {
 		switch r.Status {
 		case types.ReplicaStatusSucceeded:
@@ -0,0 +1,156 @@
+package assets
+
+import (
+	"database/sql"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/filecoin-project/go-statemachine"
+	"golang.org/x/xerrors"
+)
+
+// handleEdgeShardsSelect selects edge nodes for the missing shards of an erasure coded asset,
+// the seed candidate encodes the asset and every selected edge pulls one shard from it
+func (m *Manager) handleEdgeShardsSelect(ctx statemachine.Context, info AssetPullingInfo) error {
+	hash := info.Hash.String()
+
+	eInfo, err := m.LoadAssetErasureInfo(hash)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("LoadAssetErasureInfo; %s", err.Error())})
+	}
+
+	shards, err := m.LoadReplicaShards(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded, types.ReplicaStatusPulling, types.ReplicaStatusWaiting})
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("LoadReplicaShards; %s", err.Error())})
+	}
+
+	held := make(map[int64]bool)
+	holders := make([]string, 0, len(shards))
+	for _, shard := range shards {
+		held[shard.ShardIndex] = true
+		holders = append(holders, shard.NodeID)
+	}
+
+	missing := make([]int64, 0)
+	for i := int64(0); i < eInfo.TotalShards(); i++ {
+		if !held[i] {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) == 0 {
+		// Every shard has been assigned
+		return ctx.Send(SkipStep{})
+	}
+
+	sources := m.getDownloadSources(hash, info.Note)
+
+	var seed *node.Node
+	var source *types.CandidateDownloadInfo
+	for _, s := range sources {
+		if cNode := m.nodeMgr.GetCandidateNode(s.NodeID); cNode != nil {
+			seed = cNode
+			source = s
+			break
+		}
+	}
+
+	if seed == nil {
+		return ctx.Send(SelectFailed{error: xerrors.New("source node not found")})
+	}
+
+	eInfo, err = seed.CreateAssetShards(ctx.Context(), info.CID, eInfo)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("CreateAssetShards; %s", err.Error())})
+	}
+
+	err = m.SaveAssetErasureInfo(eInfo)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("SaveAssetErasureInfo; %s", err.Error())})
+	}
+
+	nodes, str := m.chooseEdgeNodes(len(missing), 0, holders, float64(eInfo.ShardSize))
+	if len(nodes) < 1 {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("node not found; %s", str)})
+	}
+
+	downloadSources, payloads, err := m.GenerateToken(info.CID, []*types.CandidateDownloadInfo{source}, nodes)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("GenerateToken; %s", err.Error())})
+	}
+
+	err = m.SaveTokenPayload(payloads)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("SaveTokenPayload; %s", err.Error())})
+	}
+
+	// save to db
+	err = m.saveReplicaInformation(nodes, hash, false)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("saveReplicaInformation; %s", err.Error())})
+	}
+
+	replicaShards := make([]*types.ReplicaShard, 0, len(nodes))
+	for _, node := range nodes {
+		replicaShards = append(replicaShards, &types.ReplicaShard{Hash: hash, NodeID: node.NodeID, ShardIndex: missing[len(replicaShards)]})
+	}
+
+	err = m.SaveReplicaShards(replicaShards)
+	if err != nil {
+		return ctx.Send(SelectFailed{error: xerrors.Errorf("SaveReplicaShards; %s", err.Error())})
+	}
+
+	m.startAssetTimeoutCounting(hash, 0)
+
+	// send a pull request to the node
+	go func() {
+		for _, shard := range replicaShards {
+			node := nodes[shard.NodeID]
+			err := node.PullAssetShard(ctx.Context(), info.CID, shard.ShardIndex, eInfo, downloadSources[node.NodeID])
+			if err != nil {
+				log.Errorf("%s pull asset shard err:%s", node.NodeID, err.Error())
+				continue
+			}
+		}
+	}()
+
+	return ctx.Send(PullRequestSent{})
+}
+
+// checkErasureMode checks that the pull request does not change the replica mode of an existing asset
+func (m *Manager) checkErasureMode(record *types.AssetRecord, info *types.PullAssetReq) error {
+	if record.State == Remove.String() {
+		// the erasure coding info has been deleted with the asset
+		if info.DataShards == 0 {
+			return nil
+		}
+
+		return m.SaveAssetErasureInfo(&types.AssetErasureInfo{
+			Hash:         info.Hash,
+			DataShards:   info.DataShards,
+			ParityShards: info.ParityShards,
+			StripeSize:   erasureStripeSize,
+		})
+	}
+
+	eInfo, err := m.LoadAssetErasureInfo(record.Hash)
+	if err != nil && err != sql.ErrNoRows {
+		return xerrors.Errorf("LoadAssetErasureInfo err:%s", err.Error())
+	}
+
+	if eInfo == nil && info.DataShards == 0 {
+		return nil
+	}
+
+	if eInfo != nil && eInfo.DataShards == info.DataShards && eInfo.ParityShards == info.ParityShards {
+		return nil
+	}
+
+	return xerrors.Errorf("The replica mode of asset %s can not be changed", record.Hash)
+}
+
+// isShardReplica checks if the node holds only a shard of the asset
+func (m *Manager) isShardReplica(hash, nodeID string) bool {
+	_, err := m.LoadReplicaShard(hash, nodeID)
+	return err == nil
+}
@@ -0,0 +1,44 @@
+package assets
+
+import (
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// publishStateEvent publishes the asset state transition to the event subscribers
+func (m *Manager) publishStateEvent(info *AssetPullingInfo, prev AssetState) {
+	if m.notify == nil || info.State == prev {
+		return
+	}
+
+	m.notify.Pub(&types.SchedulerEvent{
+		Topic: types.EventAssetState,
+		Time:  time.Now(),
+		Asset: &types.AssetStateEvent{
+			Hash:      info.Hash.String(),
+			CID:       info.CID,
+			PrevState: prev.String(),
+			State:     info.State.String(),
+		},
+	}, types.EventAssetState.String())
+}
+
+// publishReplicaEvent publishes the replica added to or removed from the node to the event subscribers
+func (m *Manager) publishReplicaEvent(hash, cid, nodeID string, event types.ReplicaEvent, size int64) {
+	if m.notify == nil {
+		return
+	}
+
+	m.notify.Pub(&types.SchedulerEvent{
+		Topic: types.EventReplica,
+		Time:  time.Now(),
+		Replica: &types.ReplicaChangedEvent{
+			Hash:   hash,
+			CID:    cid,
+			NodeID: nodeID,
+			Event:  event,
+			Size:   size,
+		},
+	}, types.EventReplica.String())
+}
@@ -0,0 +1,177 @@
+package assets
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// startCheckLifecycleTimer Periodically evaluates the lifecycle policies of assets
+func (m *Manager) startCheckLifecycleTimer() {
+	ticker := time.NewTicker(checkLifecycleInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		m.processLifecyclePolicies()
+	}
+}
+
+// processLifecyclePolicies applies the lifecycle policies to the assets they are attached to
+func (m *Manager) processLifecyclePolicies() {
+	policies, err := m.LoadLifecyclePolicies()
+	if err != nil {
+		log.Errorf("LoadLifecyclePolicies err:%s", err.Error())
+		return
+	}
+
+	if len(policies) == 0 {
+		return
+	}
+
+	policyMap := make(map[int64]*types.LifecyclePolicy, len(policies))
+	for _, policy := range policies {
+		policyMap[policy.ID] = policy
+	}
+
+	bindings, err := m.LoadAssetLifecyclePolicies()
+	if err != nil {
+		log.Errorf("LoadAssetLifecyclePolicies err:%s", err.Error())
+		return
+	}
+
+	for hash, policyID := range bindings {
+		policy, ok := policyMap[policyID]
+		if !ok {
+			continue
+		}
+
+		if exist, _ := m.assetStateMachines.Has(AssetHash(hash)); !exist {
+			continue
+		}
+
+		record, err := m.LoadAssetRecord(hash)
+		if err != nil {
+			log.Errorf("processLifecyclePolicies %s LoadAssetRecord err:%s", hash, err.Error())
+			continue
+		}
+
+		err = m.applyLifecyclePolicy(record, policy)
+		if err != nil {
+			log.Errorf("applyLifecyclePolicy %s policy %d err:%s", hash, policy.ID, err.Error())
+		}
+	}
+}
+
+// applyLifecyclePolicy removes the asset or reduces its edge replicas according to the policy
+func (m *Manager) applyLifecyclePolicy(record *types.AssetRecord, policy *types.LifecyclePolicy) error {
+	if record.State != Servicing.String() {
+		return nil
+	}
+
+	now := time.Now()
+	if policy.DeleteExpired && now.After(record.Expiration) {
+		log.Infof("lifecycle policy %d: the asset cid(%s) has expired, being removed", policy.ID, record.CID)
+		return m.RemoveAsset(record.Hash, false)
+	}
+
+	rule, err := m.matchLifecycleRule(record, policy.Rules, now)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	if rule.Delete {
+		log.Infof("lifecycle policy %d: the asset cid(%s) is older than %d days, being removed", policy.ID, record.CID, rule.AfterDays)
+		return m.RemoveAsset(record.Hash, false)
+	}
+
+	if rule.EdgeReplicas >= record.NeedEdgeReplica {
+		return nil
+	}
+
+	log.Infof("lifecycle policy %d: reduce edge replicas of the asset cid(%s) from %d to %d", policy.ID, record.CID, record.NeedEdgeReplica, rule.EdgeReplicas)
+	return m.decayEdgeReplicas(record, rule.EdgeReplicas)
+}
+
+// matchLifecycleRule returns the rule with the largest AfterDays of all rules applicable to the asset, nil if none
+func (m *Manager) matchLifecycleRule(record *types.AssetRecord, rules []*types.LifecycleRule, now time.Time) (*types.LifecycleRule, error) {
+	age := now.Sub(record.CreatedTime)
+
+	var matched *types.LifecycleRule
+	for _, rule := range rules {
+		window := time.Duration(rule.AfterDays) * 24 * time.Hour
+		if age < window {
+			continue
+		}
+
+		if matched != nil && matched.AfterDays >= rule.AfterDays {
+			continue
+		}
+
+		if rule.MaxRetrievals > 0 {
+			count, err := m.LoadRetrieveCountOfAsset(record.CID, now.Add(-window).Unix())
+			if err != nil {
+				return nil, err
+			}
+
+			if count >= rule.MaxRetrievals {
+				continue
+			}
+		}
+
+		matched = rule
+	}
+
+	return matched, nil
+}
+
+// decayEdgeReplicas reduces the edge replicas of the asset to the given count, the replicas on offline nodes are removed first
+func (m *Manager) decayEdgeReplicas(record *types.AssetRecord, count int64) error {
+	if _, err := m.LoadAssetErasureInfo(record.Hash); err == nil {
+		// every shard of an erasure coded asset is required to keep it recoverable
+		return nil
+	}
+
+	replicas, err := m.LoadReplicasByStatus(record.Hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
+	if err != nil {
+		return err
+	}
+
+	candidates := 0
+	edges := make([]*types.ReplicaInfo, 0, len(replicas))
+	for _, replica := range replicas {
+		if replica.IsCandidate {
+			candidates++
+		} else {
+			edges = append(edges, replica)
+		}
+	}
+
+	if count == 0 && candidates == 0 {
+		// keep the asset available on candidates before dropping all edge replicas
+		return m.replenishAssetReplicas(record, 0, record.Note, "lifecycle policy", CandidatesSelect, "")
+	}
+
+	err = m.UpdateAssetRecordReplicaCount(record.CID, int(count))
+	if err != nil {
+		return err
+	}
+
+	if int64(len(edges)) <= count {
+		return nil
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		return m.nodeMgr.GetNode(edges[i].NodeID) == nil && m.nodeMgr.GetNode(edges[j].NodeID) != nil
+	})
+
+	for _, replica := range edges[count:] {
+		err = m.RemoveReplica(record.CID, record.Hash, replica.NodeID)
+		if err != nil {
+			log.Errorf("decayEdgeReplicas %s RemoveReplica %s err:%s", record.Hash, replica.NodeID, err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package assets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestMatchLifecycleRule(t *testing.T) {
+	now := time.Now()
+	rules := []*types.LifecycleRule{{AfterDays: 30, EdgeReplicas: 1}, {AfterDays: 90, Delete: true}, {AfterDays: 60, EdgeReplicas: 0}}
+
+	cases := []struct {
+		ageDays int
+		expect  int64
+	}{
+		{ageDays: 10, expect: -1},
+		{ageDays: 30, expect: 30},
+		{ageDays: 70, expect: 60},
+		{ageDays: 100, expect: 90},
+	}
+
+	m := &Manager{}
+	for _, c := range cases {
+		record := &types.AssetRecord{CreatedTime: now.Add(-time.Duration(c.ageDays) * 24 * time.Hour)}
+		rule, err := m.matchLifecycleRule(record, rules, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		after := int64(-1)
+		if rule != nil {
+			after = rule.AfterDays
+		}
+		if after != c.expect {
+			t.Errorf("age %d days: expect rule of %d days, got %d", c.ageDays, c.expect, after)
+		}
+	}
+}
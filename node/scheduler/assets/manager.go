@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/erasure"
 	"github.com/filecoin-project/go-statemachine"
+	"github.com/filecoin-project/pubsub"
 	"github.com/ipfs/go-datastore"
 
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
@@ -48,6 +50,14 @@ const (
 	assetTimeoutLimit = 3
 
 	checkAssetReplicaLimit = 100
+	// Interval to evaluate the lifecycle policies of assets
+	checkLifecycleInterval = 1 * time.Hour
+	// Interval to scale the edge replicas of assets by their retrievals
+	checkPopularityInterval = 10 * time.Minute
+	// The window of retrievals used for popularity scaling, must be a multiple of checkPopularityInterval
+	popularityWindow = 1 * time.Hour
+	// The size of every shard piece in a stripe of erasure coded asset
+	erasureStripeSize = 256 * 1024
 )
 
 // Manager manages asset replicas
@@ -67,10 +77,19 @@ type Manager struct {
 	fillAssetNodes sync.Map
 
 	isPullSpecifyAsset bool
+
+	// retrievals of assets not recorded as retrieve events
+	retrievals *retrievalCounter
+
+	// publish the asset and replica events
+	notify *pubsub.PubSub
+
+	// the time the assets entered their current state, map[AssetHash]time.Time
+	stateEnteredAt sync.Map
 }
 
 // NewManager returns a new AssetManager instance
-func NewManager(nodeManager *node.Manager, ds datastore.Batching, configFunc dtypes.GetSchedulerConfigFunc, sdb *db.SQLDB) *Manager {
+func NewManager(nodeManager *node.Manager, ds datastore.Batching, configFunc dtypes.GetSchedulerConfigFunc, sdb *db.SQLDB, p *pubsub.PubSub) *Manager {
 	m := &Manager{
 		nodeMgr: nodeManager,
 		// pullingAssets:        make(map[string]int),
@@ -78,6 +97,8 @@ func NewManager(nodeManager *node.Manager, ds datastore.Batching, configFunc dty
 		SQLDB:                sdb,
 		assetRemoveWaitGroup: make(map[string]*sync.WaitGroup),
 		fillSwitch:           true,
+		retrievals:           newRetrievalCounter(int(popularityWindow / checkPopularityInterval)),
+		notify:               p,
 	}
 
 	// state machine initialization
@@ -95,6 +116,8 @@ func (m *Manager) Start(ctx context.Context) {
 
 	// go m.startCheckAssetsTimer()
 	go m.startCheckPullProgressesTimer()
+	go m.startCheckLifecycleTimer()
+	go m.startCheckPopularityTimer()
 	// go m.startCheckCandidateBackupTimer()
 	go m.initFillDiskTimer()
 }
@@ -150,6 +173,7 @@ func (m *Manager) startCheckPullProgressesTimer() {
 	for {
 		<-ticker.C
 		m.retrieveNodePullProgresses()
+		m.recordAssetMetrics()
 	}
 }
 
@@ -383,6 +407,49 @@ func (m *Manager) CreateAssetUploadTask(hash string, req *types.CreateAssetReq)
 	// return &types.CreateAssetRsp{UploadURL: uploadURL, Token: token}, nil
 }
 
+// CreateUploadedAssetTask creates the task of a user asset which is already stored in the candidate,
+// the candidate is the seed of the asset and other replicas are pulled from it
+func (m *Manager) CreateUploadedAssetTask(hash, cid string, size int64, expiration time.Time, nodeID string) error {
+	// Waiting for state machine initialization
+	m.stateMachineWait.Wait()
+	log.Infof("asset event: %s, add uploaded asset from %s", cid, nodeID)
+
+	cfg, err := m.config()
+	if err != nil {
+		return xerrors.Errorf("get scheduler config err:%s", err.Error())
+	}
+
+	assetRecord, err := m.LoadAssetRecord(hash)
+	if err != nil && err != sql.ErrNoRows {
+		return xerrors.Errorf("LoadAssetRecord err:%s", err.Error())
+	}
+
+	if assetRecord != nil && assetRecord.State != "" && assetRecord.State != Remove.String() && assetRecord.State != UploadFailed.String() {
+		if assetRecord.Expiration.Before(expiration) {
+			return m.UpdateAssetRecordExpiration(hash, expiration)
+		}
+		return nil
+	}
+
+	record := &types.AssetRecord{
+		Hash:                  hash,
+		CID:                   cid,
+		ServerID:              m.nodeMgr.ServerID,
+		NeedEdgeReplica:       int64(cfg.UploadAssetReplicaCount),
+		NeedCandidateReplicas: int64(m.GetCandidateReplicaCount()),
+		Expiration:            expiration,
+		State:                 UploadInit.String(),
+		TotalSize:             size,
+		CreatedTime:           time.Now(),
+	}
+
+	if err = m.SaveAssetRecord(record); err != nil {
+		return xerrors.Errorf("SaveAssetRecord err:%s", err.Error())
+	}
+
+	return m.assetStateMachines.Send(AssetHash(hash), AssetForceState{State: UploadInit, SeedNodeID: nodeID})
+}
+
 func (m *Manager) CreateBaseAsset(cid, nodeID string, size, replicas int64) error {
 	log.Infof("CreateBaseAsset cid:%s , nodeID:%s", cid, nodeID)
 	return xerrors.Errorf("interface not implemented")
@@ -413,6 +480,21 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 		return xerrors.Errorf("The number of bandwidthDown %d exceeds the limit %d", info.Bandwidth, assetBandwidthLimit)
 	}
 
+	if info.DataShards > 0 {
+		if info.ParityShards < 1 {
+			return xerrors.Errorf("The number of parity shards %d must greater than 0", info.ParityShards)
+		}
+
+		total := info.DataShards + info.ParityShards
+		if total > erasure.MaxTotalShards || total > assetEdgeReplicasLimit {
+			return xerrors.Errorf("The number of shards %d exceeds the limit %d", total, erasure.MaxTotalShards)
+		}
+
+		// every edge holds one shard, the bandwidth can not be guaranteed by shards
+		info.Replicas = total
+		info.Bandwidth = 0
+	}
+
 	log.Infof("asset event: %s, add asset replica: %d,expiration: %s", info.CID, info.Replicas, info.Expiration.String())
 
 	assetRecord, err := m.LoadAssetRecord(info.Hash)
@@ -438,6 +520,18 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 			Note:                  info.Bucket,
 		}
 
+		if info.DataShards > 0 {
+			err = m.SaveAssetErasureInfo(&types.AssetErasureInfo{
+				Hash:         info.Hash,
+				DataShards:   info.DataShards,
+				ParityShards: info.ParityShards,
+				StripeSize:   erasureStripeSize,
+			})
+			if err != nil {
+				return xerrors.Errorf("SaveAssetErasureInfo err:%s", err.Error())
+			}
+		}
+
 		err = m.SaveAssetRecord(record)
 		if err != nil {
 			return xerrors.Errorf("SaveAssetRecord err:%s", err.Error())
@@ -467,6 +561,10 @@ func (m *Manager) CreateAssetPullTask(info *types.PullAssetReq) error {
 		assetRecord.NeedBandwidth = 0
 	}
 
+	if err := m.checkErasureMode(assetRecord, info); err != nil {
+		return err
+	}
+
 	if info.Replicas <= assetRecord.NeedEdgeReplica && info.Bandwidth <= assetRecord.NeedBandwidth {
 		return xerrors.New("No increase in the number of replicas or bandwidth")
 	}
@@ -545,15 +643,20 @@ func (m *Manager) RestartPullAssets(hashes []types.AssetHash) error {
 
 // RemoveReplica remove a replica for node
 func (m *Manager) RemoveReplica(cid, hash, nodeID string) error {
+	isShard := m.isShardReplica(hash, nodeID)
+
 	err := m.DeleteAssetReplica(hash, nodeID)
 	if err != nil {
 		return xerrors.Errorf("RemoveReplica %s DeleteAssetReplica err: %s", hash, err.Error())
 	}
+	m.publishReplicaEvent(hash, cid, nodeID, types.ReplicaEventRemove, 0)
 
-	// asset view
-	err = m.removeAssetFromView(nodeID, cid)
-	if err != nil {
-		return xerrors.Errorf("RemoveReplica %s removeAssetFromView err: %s", hash, err.Error())
+	// asset view, shards are not in the view
+	if !isShard {
+		err = m.removeAssetFromView(nodeID, cid)
+		if err != nil {
+			return xerrors.Errorf("RemoveReplica %s removeAssetFromView err: %s", hash, err.Error())
+		}
 	}
 
 	go m.requestAssetDelete(nodeID, cid)
@@ -672,6 +775,10 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 			continue
 		}
 
+		if progress.Status == types.ReplicaStatusSucceeded || progress.Status == types.ReplicaStatusFailed {
+			m.recordReplicaResult(nodeID, progress.Status)
+		}
+
 		if progress.Status == types.ReplicaStatusPulling {
 			err = m.assetStateMachines.Send(AssetHash(hash), InfoUpdate{
 				Blocks: int64(progress.BlocksCount),
@@ -692,7 +799,6 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 				log.Errorf("updateAssetPullResults %s LoadAssetRecord err:%s", nodeID, err.Error())
 				continue
 			}
-			cids = append(cids, record.CID)
 
 			err = m.SaveReplicaEvent(cInfo.Hash, record.CID, cInfo.NodeID, cInfo.DoneSize, record.Expiration, types.ReplicaEventAdd)
 			if err != nil {
@@ -700,11 +806,25 @@ func (m *Manager) updateAssetPullResults(nodeID string, result *types.PullResult
 				continue
 			}
 
-			// asset view
-			err = m.addAssetToView(nodeID, progress.CID)
-			if err != nil {
-				log.Errorf("updateAssetPullResults %s addAssetToView err:%s", nodeID, err.Error())
-				continue
+			m.publishReplicaEvent(hash, record.CID, nodeID, types.ReplicaEventAdd, cInfo.DoneSize)
+
+			if progress.Commitment != nil {
+				commitment := &types.AssetCommitment{Hash: hash, NodeID: nodeID, Root: progress.Commitment.Root, Leaves: progress.Commitment.Leaves}
+				if err = m.SaveAssetCommitment(commitment); err != nil {
+					log.Errorf("updateAssetPullResults %s SaveAssetCommitment err:%s", nodeID, err.Error())
+				}
+			}
+
+			// the node holds only a shard, which can not be synced or validated as a whole asset
+			if !m.isShardReplica(hash, nodeID) {
+				cids = append(cids, record.CID)
+
+				// asset view
+				err = m.addAssetToView(nodeID, progress.CID)
+				if err != nil {
+					log.Errorf("updateAssetPullResults %s addAssetToView err:%s", nodeID, err.Error())
+					continue
+				}
 			}
 		}
 
@@ -926,16 +1046,15 @@ func (m *Manager) getDownloadSources(hash, bucket string) []*types.CandidateDown
 			continue
 		}
 
-		if cNode.Type != types.NodeCandidate {
-			if cNode.NATType != types.NatTypeNo || cNode.ExternalIP == "" {
-				continue
-			}
+		if cNode.Type != types.NodeCandidate && !cNode.Traversable() {
+			continue
 		}
 
 		source := &types.CandidateDownloadInfo{
 			NodeID:    nodeID,
 			Address:   cNode.DownloadAddr(),
 			AWSBucket: bucket,
+			NatType:   cNode.NATType.String(),
 		}
 
 		sources = append(sources, source)
@@ -0,0 +1,67 @@
+package assets
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// allStates is the list of asset states reported in the metrics
+var allStates = append([]string{Remove.String()}, ActiveStates...)
+
+// recordAssetMetrics records the number of assets in every state and the number of assets being pulled
+func (m *Manager) recordAssetMetrics() {
+	ctx := context.Background()
+	stats.Record(ctx, metrics.PullQueueDepth.M(int64(m.getPullingAssetLen())))
+
+	counts, err := m.LoadAssetStateCounts(m.nodeMgr.ServerID)
+	if err != nil {
+		log.Errorf("recordAssetMetrics LoadAssetStateCounts err:%s", err.Error())
+		return
+	}
+
+	// the count of every state is reported, an emptied state must be reset explicitly
+	for _, state := range allStates {
+		metrics.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(metrics.AssetState, state)}, metrics.Assets.M(int64(counts[state])))
+	}
+}
+
+// recordStateDuration records how long the asset stays in the previous state when it moves to a new state
+func (m *Manager) recordStateDuration(info *AssetPullingInfo, prev AssetState) {
+	if info.State == prev {
+		return
+	}
+
+	now := time.Now()
+	if v, ok := m.stateEnteredAt.Load(info.Hash); ok {
+		duration := now.Sub(v.(time.Time)).Seconds()
+		metrics.RecordWithTags(context.Background(), []tag.Mutator{tag.Upsert(metrics.AssetState, prev.String())}, metrics.AssetStateDuration.M(duration))
+	}
+
+	switch info.State {
+	case Servicing, Remove, Stop:
+		m.stateEnteredAt.Delete(info.Hash)
+	default:
+		m.stateEnteredAt.Store(info.Hash, now)
+	}
+}
+
+// recordReplicaResult counts the replica pulled succeeded or failed by the node
+func (m *Manager) recordReplicaResult(nodeID string, status types.ReplicaStatus) {
+	result := "succeeded"
+	if status == types.ReplicaStatusFailed {
+		result = "failed"
+	}
+
+	nodeType := "unknown"
+	if node := m.nodeMgr.GetNode(nodeID); node != nil {
+		nodeType = node.Type.String()
+	}
+
+	tags := []tag.Mutator{tag.Upsert(metrics.Status, result), tag.Upsert(metrics.NodeType, nodeType)}
+	metrics.RecordWithTags(context.Background(), tags, metrics.ReplicaResults.M(1))
+}
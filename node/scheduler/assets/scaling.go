@@ -0,0 +1,246 @@
+package assets
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+)
+
+// retrievalCounter counts the retrievals of assets in a sliding window made of buckets
+type retrievalCounter struct {
+	lock sync.Mutex
+	// the last bucket is the current one
+	buckets []map[string]int64
+}
+
+func newRetrievalCounter(size int) *retrievalCounter {
+	buckets := make([]map[string]int64, size)
+	for i := range buckets {
+		buckets[i] = make(map[string]int64)
+	}
+
+	return &retrievalCounter{buckets: buckets}
+}
+
+// add counts a retrieval of the asset in the current bucket
+func (c *retrievalCounter) add(hash string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.buckets[len(c.buckets)-1][hash]++
+}
+
+// counts returns the retrievals of every asset in the window
+func (c *retrievalCounter) counts() map[string]int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	out := make(map[string]int64)
+	for _, bucket := range c.buckets {
+		for hash, count := range bucket {
+			out[hash] += count
+		}
+	}
+
+	return out
+}
+
+// rotate drops the oldest bucket and starts a new one
+func (c *retrievalCounter) rotate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.buckets = append(c.buckets[1:], make(map[string]int64))
+}
+
+// AddAssetRetrieval counts a retrieval of the asset which is not recorded as a retrieve event
+func (m *Manager) AddAssetRetrieval(cid string) {
+	hash, err := cidutil.CIDToHash(cid)
+	if err != nil {
+		return
+	}
+
+	m.retrievals.add(hash)
+}
+
+// startCheckPopularityTimer Periodically scales the edge replicas of assets by their retrievals
+func (m *Manager) startCheckPopularityTimer() {
+	ticker := time.NewTicker(checkPopularityInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		m.processPopularityScaling()
+		m.retrievals.rotate()
+	}
+}
+
+// processPopularityScaling adds edge replicas to the assets whose demand spikes and removes them after the demand subsides.
+// A scheduler serves a single area, so the replicas are added in the area where the assets are retrieved.
+func (m *Manager) processPopularityScaling() {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get schedulerConfig err:%s", err.Error())
+		return
+	}
+
+	if cfg.RetrievalsPerEdgeReplica <= 0 {
+		return
+	}
+
+	demands := m.retrievals.counts()
+
+	counts, err := m.LoadRetrieveCounts(time.Now().Add(-popularityWindow).Unix())
+	if err != nil {
+		log.Errorf("LoadRetrieveCounts err:%s", err.Error())
+		return
+	}
+
+	for cid, count := range counts {
+		hash, err := cidutil.CIDToHash(cid)
+		if err != nil {
+			continue
+		}
+		demands[hash] += count
+	}
+
+	scalings, err := m.LoadAssetScalings()
+	if err != nil {
+		log.Errorf("LoadAssetScalings err:%s", err.Error())
+		return
+	}
+
+	scalingMap := make(map[string]*types.AssetScaling, len(scalings))
+	for _, scaling := range scalings {
+		scalingMap[scaling.Hash] = scaling
+
+		extra := scaleReplicas(demands[scaling.Hash], scaling.BaseReplicas, cfg.RetrievalsPerEdgeReplica, cfg.MaxScaleEdgeReplicas)
+		// wait until the demand falls to half to avoid flapping
+		if extra*2 > scaling.ScaledReplicas {
+			continue
+		}
+
+		if err := m.scaleDownAsset(scaling, extra); err != nil {
+			log.Errorf("scaleDownAsset %s err:%s", scaling.Hash, err.Error())
+		}
+	}
+
+	for hash, demand := range demands {
+		if demand < int64(cfg.RetrievalsPerEdgeReplica) {
+			continue
+		}
+
+		if err := m.scaleUpAsset(hash, demand, scalingMap[hash], cfg.RetrievalsPerEdgeReplica, cfg.MaxScaleEdgeReplicas); err != nil {
+			log.Errorf("scaleUpAsset %s err:%s", hash, err.Error())
+		}
+	}
+}
+
+// scaleReplicas returns the number of edge replicas to add for the demand (retrievals per hour)
+func scaleReplicas(demand, baseReplicas int64, retrievalsPerReplica, maxScaleReplicas int) int64 {
+	need := (demand + int64(retrievalsPerReplica) - 1) / int64(retrievalsPerReplica)
+
+	extra := need - baseReplicas
+	if extra > int64(maxScaleReplicas) {
+		extra = int64(maxScaleReplicas)
+	}
+
+	if extra > assetEdgeReplicasLimit-baseReplicas {
+		extra = assetEdgeReplicasLimit - baseReplicas
+	}
+
+	if extra < 0 {
+		extra = 0
+	}
+
+	return extra
+}
+
+// scaleUpAsset adds edge replicas to the asset if its demand exceeds what the current replicas serve
+func (m *Manager) scaleUpAsset(hash string, demand int64, scaling *types.AssetScaling, retrievalsPerReplica, maxScaleReplicas int) error {
+	if exist, _ := m.assetStateMachines.Has(AssetHash(hash)); !exist {
+		return nil
+	}
+
+	record, err := m.LoadAssetRecord(hash)
+	if err != nil {
+		return err
+	}
+
+	if record.State != Servicing.String() {
+		return nil
+	}
+
+	if _, err := m.LoadAssetErasureInfo(hash); err == nil {
+		// the shards of erasure coded asset are fixed
+		return nil
+	}
+
+	baseReplicas := record.NeedEdgeReplica
+	scaledReplicas := int64(0)
+	if scaling != nil {
+		baseReplicas = scaling.BaseReplicas
+		scaledReplicas = scaling.ScaledReplicas
+	}
+
+	extra := scaleReplicas(demand, baseReplicas, retrievalsPerReplica, maxScaleReplicas)
+	if extra <= scaledReplicas {
+		return nil
+	}
+
+	if m.getPullingAssetLen() >= m.getAssetPullTaskLimit() {
+		return fmt.Errorf("the asset in the pulling exceeds the limit %d", m.getAssetPullTaskLimit())
+	}
+
+	err = m.SaveAssetScaling(&types.AssetScaling{Hash: hash, BaseReplicas: baseReplicas, ScaledReplicas: extra})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("popularity scaling: %d retrievals/h of the asset cid(%s), edge replicas %d -> %d", demand, record.CID, record.NeedEdgeReplica, baseReplicas+extra)
+
+	record.NeedEdgeReplica = baseReplicas + extra
+	return m.replenishAssetReplicas(record, 0, record.Note, fmt.Sprintf("popularity scaling, %d retrievals/h", demand), EdgesSelect, "")
+}
+
+// scaleDownAsset removes the edge replicas added by scaling until extra replicas are left
+func (m *Manager) scaleDownAsset(scaling *types.AssetScaling, extra int64) error {
+	record, err := m.LoadAssetRecord(scaling.Hash)
+	if err == sql.ErrNoRows {
+		return m.DeleteAssetScaling(scaling.Hash)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if exist, _ := m.assetStateMachines.Has(AssetHash(scaling.Hash)); !exist {
+		return nil
+	}
+
+	if record.State != Servicing.String() {
+		return nil
+	}
+
+	// the replicas may have been reduced by lifecycle policy
+	target := scaling.BaseReplicas + extra
+	if record.NeedEdgeReplica > target {
+		log.Infof("popularity scaling: demand of the asset cid(%s) subsides, edge replicas %d -> %d", record.CID, record.NeedEdgeReplica, target)
+
+		err = m.decayEdgeReplicas(record, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	if extra == 0 {
+		return m.DeleteAssetScaling(scaling.Hash)
+	}
+
+	scaling.ScaledReplicas = extra
+	return m.SaveAssetScaling(scaling)
+}
@@ -0,0 +1,48 @@
+package assets
+
+import "testing"
+
+func TestRetrievalCounter(t *testing.T) {
+	c := newRetrievalCounter(3)
+
+	c.add("a")
+	c.rotate()
+	c.add("a")
+	c.add("b")
+
+	counts := c.counts()
+	if counts["a"] != 2 || counts["b"] != 1 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	c.rotate()
+	c.rotate()
+
+	counts = c.counts()
+	if counts["a"] != 1 || counts["b"] != 1 {
+		t.Fatalf("unexpected counts after rotating %v", counts)
+	}
+
+	c.rotate()
+	if counts = c.counts(); len(counts) != 0 {
+		t.Fatalf("expect empty counts, got %v", counts)
+	}
+}
+
+func TestScaleReplicas(t *testing.T) {
+	cases := []struct {
+		demand, base, expect int64
+	}{
+		{demand: 0, base: 2, expect: 0},
+		{demand: 1500, base: 2, expect: 0},
+		{demand: 2001, base: 2, expect: 1},
+		{demand: 10000, base: 2, expect: 8},
+		{demand: 1000000, base: 2, expect: 50},
+	}
+
+	for _, c := range cases {
+		if extra := scaleReplicas(c.demand, c.base, 1000, 50); extra != c.expect {
+			t.Errorf("demand %d base %d: expect %d, got %d", c.demand, c.base, c.expect, extra)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package assets
 import (
 	"context"
 	"reflect"
+	"slices"
 
 	"github.com/filecoin-project/go-statemachine"
 	"golang.org/x/xerrors"
@@ -10,7 +11,12 @@ import (
 
 // Plan prepares a plan for asset pulling
 func (m *Manager) Plan(events []statemachine.Event, user interface{}) (interface{}, uint64, error) {
-	next, processed, err := m.plan(events, user.(*AssetPullingInfo))
+	info := user.(*AssetPullingInfo)
+	prev := info.State
+
+	next, processed, err := m.plan(events, info)
+	m.publishStateEvent(info, prev)
+	m.recordStateDuration(info, prev)
 	if err != nil || next == nil {
 		return nil, processed, nil
 	}
@@ -238,3 +244,27 @@ func (m *Manager) ListAssets() ([]AssetPullingInfo, error) {
 	}
 	return list, nil
 }
+
+// ResumeAssets restarts the state machines of the assets adopted from another scheduler
+func (m *Manager) ResumeAssets(hashes []string) {
+	m.stateMachineWait.Wait()
+
+	for _, hash := range hashes {
+		info, err := m.LoadAssetStateInfo(hash, m.nodeMgr.ServerID)
+		if err != nil {
+			log.Errorf("ResumeAssets %s LoadAssetStateInfo err:%s", hash, err.Error())
+			continue
+		}
+
+		if !slices.Contains(PullingStates, info.State) {
+			continue
+		}
+
+		if err := m.assetStateMachines.Send(AssetHash(hash), PullAssetRestart{}); err != nil {
+			log.Errorf("ResumeAssets asset send %s , err %s", hash, err.Error())
+			continue
+		}
+
+		m.startAssetTimeoutCounting(hash, 0)
+	}
+}
@@ -255,6 +255,10 @@ func (m *Manager) getCurBandwidthUp(nodes []string) int64 {
 func (m *Manager) handleEdgesSelect(ctx statemachine.Context, info AssetPullingInfo) error {
 	log.Debugf("handle edges select , %s", info.Hash)
 
+	if info.DataShards > 0 {
+		return m.handleEdgeShardsSelect(ctx, info)
+	}
+
 	needCount := info.EdgeReplicas - int64(len(info.EdgeReplicaSucceeds))
 
 	if info.ReplenishReplicas > 0 {
@@ -412,6 +416,31 @@ func (m *Manager) handleRemove(ctx statemachine.Context, info AssetPullingInfo)
 		}
 	}
 
+	err = m.DeleteAssetErasureInfo(hash)
+	if err != nil {
+		return xerrors.Errorf("RemoveAsset %s DeleteAssetErasureInfo err: %s", hash, err.Error())
+	}
+
+	err = m.DeleteAssetLifecyclePolicy(hash)
+	if err != nil {
+		log.Errorf("RemoveAsset %s DeleteAssetLifecyclePolicy err: %s", hash, err.Error())
+	}
+
+	err = m.DeleteAssetScaling(hash)
+	if err != nil {
+		log.Errorf("RemoveAsset %s DeleteAssetScaling err: %s", hash, err.Error())
+	}
+
+	err = m.DeleteAssetKeyEnvelopes(hash)
+	if err != nil {
+		log.Errorf("RemoveAsset %s DeleteAssetKeyEnvelopes err: %s", hash, err.Error())
+	}
+
+	err = m.DeletePresignedLinks(hash)
+	if err != nil {
+		log.Errorf("RemoveAsset %s DeletePresignedLinks err: %s", hash, err.Error())
+	}
+
 	// remove user asset
 	users, err := m.ListUsersForAsset(hash)
 	for _, user := range users {
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+)
+
+// cleanAuditLogsInterval is the interval the audit logs out of the retention are deleted
+const cleanAuditLogsInterval = time.Hour
+
+// AuditLogCleaner deletes the audit logs older than the retention days of the config
+type AuditLogCleaner struct {
+	db     *db.SQLDB
+	config dtypes.GetSchedulerConfigFunc
+}
+
+// NewAuditLogCleaner creates a new AuditLogCleaner
+func NewAuditLogCleaner(db *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc) *AuditLogCleaner {
+	return &AuditLogCleaner{db: db, config: configFunc}
+}
+
+// Start deletes the audit logs out of the retention periodically until the context is done
+func (c *AuditLogCleaner) Start(ctx context.Context) {
+	ticker := time.NewTicker(cleanAuditLogsInterval)
+	defer ticker.Stop()
+
+	for {
+		c.cleanAuditLogs()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *AuditLogCleaner) cleanAuditLogs() {
+	cfg, err := c.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return
+	}
+
+	if cfg.AuditLogRetentionDays <= 0 {
+		return
+	}
+
+	deleted, err := c.db.DeleteAuditLogs(time.Now().AddDate(0, 0, -cfg.AuditLogRetentionDays))
+	if err != nil {
+		log.Errorf("DeleteAuditLogs err:%s", err.Error())
+		return
+	}
+
+	if deleted > 0 {
+		log.Infof("deleted %d audit logs out of %d days", deleted, cfg.AuditLogRetentionDays)
+	}
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+)
+
+const (
+	// maxAuditArgsSize is the max size of the json of the audited arguments
+	maxAuditArgsSize = 4096
+	// maxAuditResultSize is the max size of the error message of the audited call
+	maxAuditResultSize = 1024
+	// redacted replaces the secrets in the audited arguments
+	redacted = "[REDACTED]"
+)
+
+// auditSecretKeys are the parts of the field names whose values are redacted in the audit logs
+var auditSecretKeys = []string{"password", "secret", "token", "privatekey"}
+
+// RecordAudit saves the audit log of the privileged call with the identity of the caller
+func (s *Scheduler) RecordAudit(ctx context.Context, method string, args []interface{}, err error) {
+	roles := make([]string, 0)
+	for _, perm := range api.GetPerms(ctx) {
+		roles = append(roles, string(perm))
+	}
+
+	auditLog := &types.AuditLog{
+		Caller:     handler.GetCallerID(ctx),
+		Role:       strings.Join(roles, ","),
+		RemoteAddr: handler.GetRemoteAddr(ctx),
+		Method:     method,
+		Args:       sanitizeAuditArgs(args),
+		Success:    err == nil,
+	}
+
+	if err != nil {
+		auditLog.Result = truncate(err.Error(), maxAuditResultSize)
+	}
+
+	if err := s.db.SaveAuditLog(auditLog); err != nil {
+		log.Errorf("SaveAuditLog %s of %s error %s", method, auditLog.Caller, err.Error())
+	}
+}
+
+// GetAuditLog lists the audit logs of the privileged calls matching the query
+func (s *Scheduler) GetAuditLog(ctx context.Context, query *types.AuditLogQuery) (*types.ListAuditLogRsp, error) {
+	if query == nil {
+		query = &types.AuditLogQuery{}
+	}
+
+	return s.db.LoadAuditLogs(query)
+}
+
+// sanitizeAuditArgs returns the json of the arguments with the secrets redacted
+func sanitizeAuditArgs(args []interface{}) string {
+	buf, err := json.Marshal(args)
+	if err != nil {
+		names := make([]string, 0, len(args))
+		for _, arg := range args {
+			names = append(names, fmt.Sprintf("%T", arg))
+		}
+		return truncate(strings.Join(names, ","), maxAuditArgsSize)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return truncate(string(buf), maxAuditArgsSize)
+	}
+
+	buf, err = json.Marshal(redactSecrets(v))
+	if err != nil {
+		return ""
+	}
+
+	return truncate(string(buf), maxAuditArgsSize)
+}
+
+// redactSecrets replaces the values of the secret fields
+func redactSecrets(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if isSecretKey(k) {
+				val[k] = redacted
+			} else {
+				val[k] = redactSecrets(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactSecrets(item)
+		}
+	}
+
+	return v
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range auditSecretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	return s[:size] + "..."
+}
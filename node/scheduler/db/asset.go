@@ -171,6 +171,31 @@ func (n *SQLDB) LoadAWSRecords(statuses []string, serverID dtypes.ServerID) ([]*
 	return out, nil
 }
 
+// LoadAssetCIDs load the cid of assets, key is the asset hash
+func (n *SQLDB) LoadAssetCIDs(hashes []string) (map[string]string, error) {
+	out := make(map[string]string)
+	if len(hashes) == 0 {
+		return out, nil
+	}
+
+	sQuery := fmt.Sprintf(`SELECT hash, cid FROM %s WHERE hash in (?)`, assetRecordTable)
+	query, args, err := sqlx.In(sQuery, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*types.AssetRecord
+	if err := n.db.Select(&records, n.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		out[record.Hash] = record.CID
+	}
+
+	return out, nil
+}
+
 // LoadAssetRecords load the asset records from the incoming scheduler
 func (n *SQLDB) LoadAssetRecords(statuses []string, limit, offset int, serverID dtypes.ServerID) (*sqlx.Rows, error) {
 	if limit > loadAssetRecordsDefaultLimit || limit == 0 {
@@ -343,6 +368,20 @@ func (n *SQLDB) DeleteAssetReplica(hash, nodeID string) error {
 		return err
 	}
 
+	// erasure coded shard
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND node_id=?`, replicaShardTable)
+	_, err = tx.Exec(query, hash, nodeID)
+	if err != nil {
+		return err
+	}
+
+	// merkle commitment
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND node_id=?`, assetCommitmentTable)
+	_, err = tx.Exec(query, hash, nodeID)
+	if err != nil {
+		return err
+	}
+
 	// replica event
 	query = fmt.Sprintf(
 		`INSERT INTO %s (hash, event, node_id) 
@@ -358,8 +397,14 @@ func (n *SQLDB) DeleteAssetReplica(hash, nodeID string) error {
 
 // DeleteUnfinishedReplicas deletes the incomplete replicas with the given hash from the database.
 func (n *SQLDB) DeleteUnfinishedReplicas(hash string) error {
-	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND status!=?`, replicaInfoTable)
-	_, err := n.db.Exec(query, hash, types.ReplicaStatusSucceeded)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND node_id IN (SELECT node_id FROM %s WHERE hash=? AND status!=?)`, replicaShardTable, replicaInfoTable)
+	_, err := n.db.Exec(query, hash, hash, types.ReplicaStatusSucceeded)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND status!=?`, replicaInfoTable)
+	_, err = n.db.Exec(query, hash, types.ReplicaStatusSucceeded)
 
 	return err
 }
@@ -397,6 +442,24 @@ func (n *SQLDB) LoadAssetCount(serverID dtypes.ServerID, filterState string) (in
 	return size, nil
 }
 
+// LoadAssetStateCounts counts the assets in every state
+func (n *SQLDB) LoadAssetStateCounts(serverID dtypes.ServerID) (map[string]int, error) {
+	var rows []struct {
+		State string `db:"state"`
+		Count int    `db:"count"`
+	}
+	query := fmt.Sprintf(`SELECT state, COUNT(*) AS count FROM %s GROUP BY state`, assetStateTable(serverID))
+	if err := n.db.Select(&rows, query); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]int, len(rows))
+	for _, row := range rows {
+		out[row.State] = row.Count
+	}
+	return out, nil
+}
+
 // LoadAllAssetRecords loads all asset records for a given server ID.
 func (n *SQLDB) LoadAllAssetRecords(serverID dtypes.ServerID, limit, offset int, statuses []string) (*sqlx.Rows, error) {
 	sQuery := fmt.Sprintf(`SELECT * FROM %s a LEFT JOIN %s b ON a.hash = b.hash WHERE a.state in (?) order by a.hash asc limit ? offset ?`, assetStateTable(serverID), assetRecordTable)
@@ -587,11 +650,11 @@ func (n *SQLDB) LoadReplenishBackups() ([]string, error) {
 	return out, nil
 }
 
-// DeleteAssetRecordsOfNode clean asset records of node
-func (n *SQLDB) DeleteAssetRecordsOfNode(nodeID string) error {
+// DeleteAssetRecordsOfNode clean asset records of node, returns the succeeded replicas of the node which are deleted
+func (n *SQLDB) DeleteAssetRecordsOfNode(nodeID string) ([]*types.NodeAssetInfo, error) {
 	tx, err := n.db.Beginx()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() {
@@ -601,25 +664,44 @@ func (n *SQLDB) DeleteAssetRecordsOfNode(nodeID string) error {
 		}
 	}()
 
-	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=? `, replicaInfoTable)
+	var replicas []*types.NodeAssetInfo
+	query := fmt.Sprintf("SELECT a.hash,a.end_time,b.cid,b.total_size,b.expiration FROM %s a JOIN %s b ON a.hash = b.hash WHERE a.node_id=? AND a.status=?", replicaInfoTable, assetRecordTable)
+	err = tx.Select(&replicas, query, nodeID, types.ReplicaStatusSucceeded)
+	if err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=? `, replicaInfoTable)
 	_, err = tx.Exec(query, nodeID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=? `, replicaShardTable)
+	_, err = tx.Exec(query, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=? `, assetCommitmentTable)
+	_, err = tx.Exec(query, nodeID)
+	if err != nil {
+		return nil, err
 	}
 
 	query = fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, assetsViewTable)
 	_, err = tx.Exec(query, nodeID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	query = fmt.Sprintf(`DELETE FROM %s WHERE bucket_id LIKE ?`, bucketTable)
 	_, err = tx.Exec(query, nodeID+"%")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return tx.Commit()
+	return replicas, tx.Commit()
 }
 
 // DeleteReplicaEvents delete events
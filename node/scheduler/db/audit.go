@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveAuditLog inserts the audit log
+func (n *SQLDB) SaveAuditLog(log *types.AuditLog) error {
+	query := fmt.Sprintf(`INSERT INTO %s (caller, role, remote_addr, method, args, success, result) 
+			VALUES (:caller, :role, :remote_addr, :method, :args, :success, :result)`, auditLogTable)
+	_, err := n.db.NamedExec(query, log)
+	return err
+}
+
+// LoadAuditLogs loads the audit logs matching the query, the latest logs come first
+func (n *SQLDB) LoadAuditLogs(q *types.AuditLogQuery) (*types.ListAuditLogRsp, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if len(q.Caller) > 0 {
+		conditions = append(conditions, "caller=?")
+		args = append(args, q.Caller)
+	}
+	if len(q.Method) > 0 {
+		conditions = append(conditions, "method=?")
+		args = append(args, q.Method)
+	}
+	if !q.Start.IsZero() {
+		conditions = append(conditions, "created_time>=?")
+		args = append(args, q.Start)
+	}
+	if !q.End.IsZero() {
+		conditions = append(conditions, "created_time<?")
+		args = append(args, q.End)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > loadAuditLogDefaultLimit {
+		limit = loadAuditLogDefaultLimit
+	}
+
+	res := new(types.ListAuditLogRsp)
+	query := fmt.Sprintf(`SELECT * FROM %s %s ORDER BY id DESC LIMIT ? OFFSET ?`, auditLogTable, where)
+	if err := n.db.Select(&res.Logs, query, append(args, limit, q.Offset)...); err != nil {
+		return nil, err
+	}
+
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, auditLogTable, where)
+	if err := n.db.Get(&res.Total, countQuery, args...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteAuditLogs deletes the audit logs created before the time
+func (n *SQLDB) DeleteAuditLogs(before time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_time<?`, auditLogTable)
+	res, err := n.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveAssetCommitment inserts or updates the merkle commitment of an asset replica
+func (n *SQLDB) SaveAssetCommitment(info *types.AssetCommitment) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, node_id, root, leaves, created_time)
+		        VALUES (:hash, :node_id, :root, :leaves, NOW())
+				ON DUPLICATE KEY UPDATE root=:root, leaves=:leaves, created_time=NOW()`, assetCommitmentTable)
+	_, err := n.db.NamedExec(query, info)
+
+	return err
+}
+
+// LoadAssetCommitments loads the merkle commitments of all replicas of an asset
+func (n *SQLDB) LoadAssetCommitments(hash string) ([]*types.AssetCommitment, error) {
+	var out []*types.AssetCommitment
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=?`, assetCommitmentTable)
+	if err := n.db.Select(&out, query, hash); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadNodeAssetCommitments loads the merkle commitments of the asset replicas of a node
+func (n *SQLDB) LoadNodeAssetCommitments(nodeID string) ([]*types.AssetCommitment, error) {
+	var out []*types.AssetCommitment
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE node_id=?`, assetCommitmentTable)
+	if err := n.db.Select(&out, query, nodeID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
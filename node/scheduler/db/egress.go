@@ -0,0 +1,54 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// AddUserEgressTraffic adds the egress traffic of the user in the month
+func (n *SQLDB) AddUserEgressTraffic(userID, month string, traffic int64) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, month, traffic) VALUES (?, ?, ?) 
+			ON DUPLICATE KEY UPDATE traffic=traffic+VALUES(traffic), updated_time=NOW()`, userEgressTable)
+	_, err := n.db.Exec(query, userID, month, traffic)
+	return err
+}
+
+// LoadUserEgressTraffic loads the egress traffic of the user in the month
+func (n *SQLDB) LoadUserEgressTraffic(userID, month string) (int64, error) {
+	var traffic int64
+	query := fmt.Sprintf(`SELECT traffic FROM %s WHERE user_id=? AND month=?`, userEgressTable)
+	err := n.db.Get(&traffic, query, userID, month)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return traffic, err
+}
+
+// SaveUserEgressPlan inserts or replaces the egress plan of the user
+func (n *SQLDB) SaveUserEgressPlan(plan *types.UserEgressPlan) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, monthly_quota, limit_rate) VALUES (:user_id, :monthly_quota, :limit_rate) 
+			ON DUPLICATE KEY UPDATE monthly_quota=VALUES(monthly_quota), limit_rate=VALUES(limit_rate), updated_time=NOW()`, userEgressPlanTable)
+	_, err := n.db.NamedExec(query, plan)
+	return err
+}
+
+// LoadUserEgressPlan loads the egress plan of the user
+func (n *SQLDB) LoadUserEgressPlan(userID string) (*types.UserEgressPlan, error) {
+	plan := &types.UserEgressPlan{}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=?`, userEgressPlanTable)
+	if err := n.db.Get(plan, query, userID); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// DeleteUserEgressPlan deletes the egress plan of the user
+func (n *SQLDB) DeleteUserEgressPlan(userID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id=?`, userEgressPlanTable)
+	_, err := n.db.Exec(query, userID)
+	return err
+}
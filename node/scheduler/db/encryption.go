@@ -0,0 +1,69 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveUserPublicKey inserts or replaces the public key of the user
+func (n *SQLDB) SaveUserPublicKey(userID string, publicKey []byte) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, public_key) VALUES (?, ?) ON DUPLICATE KEY UPDATE public_key=?, updated_time=NOW()`, userPublicKeyTable)
+	_, err := n.db.Exec(query, userID, publicKey, publicKey)
+	return err
+}
+
+// LoadUserPublicKey loads the public key of the user
+func (n *SQLDB) LoadUserPublicKey(userID string) ([]byte, error) {
+	var publicKey []byte
+	query := fmt.Sprintf(`SELECT public_key FROM %s WHERE user_id=?`, userPublicKeyTable)
+	if err := n.db.Get(&publicKey, query, userID); err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+}
+
+// SaveAssetKeyEnvelopes inserts or replaces the key envelopes of the asset
+func (n *SQLDB) SaveAssetKeyEnvelopes(envelopes []*types.AssetKeyEnvelope) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveAssetKeyEnvelopes Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (hash, user_id, shared_by, envelope) VALUES (:hash, :user_id, :shared_by, :envelope)
+			ON DUPLICATE KEY UPDATE shared_by=VALUES(shared_by), envelope=VALUES(envelope), created_time=NOW()`, assetKeyEnvelopeTable)
+	for _, envelope := range envelopes {
+		if _, err = tx.NamedExec(query, envelope); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadAssetKeyEnvelope loads the key envelope of the asset for the user
+func (n *SQLDB) LoadAssetKeyEnvelope(hash, userID string) (*types.AssetKeyEnvelope, error) {
+	envelope := &types.AssetKeyEnvelope{}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=? AND user_id=?`, assetKeyEnvelopeTable)
+	if err := n.db.Get(envelope, query, hash, userID); err != nil {
+		return nil, err
+	}
+
+	return envelope, nil
+}
+
+// DeleteAssetKeyEnvelopes deletes the key envelopes of the asset
+func (n *SQLDB) DeleteAssetKeyEnvelopes(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetKeyEnvelopeTable)
+	_, err := n.db.Exec(query, hash)
+	return err
+}
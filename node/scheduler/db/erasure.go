@@ -0,0 +1,119 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// SaveAssetErasureInfo inserts or updates the erasure coding info of an asset
+func (n *SQLDB) SaveAssetErasureInfo(info *types.AssetErasureInfo) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, data_shards, parity_shards, stripe_size, shard_size, car_size) 
+		        VALUES (:hash, :data_shards, :parity_shards, :stripe_size, :shard_size, :car_size) 
+				ON DUPLICATE KEY UPDATE data_shards=:data_shards, parity_shards=:parity_shards, stripe_size=:stripe_size, 
+				shard_size=:shard_size, car_size=:car_size`, assetErasureTable)
+	_, err := n.db.NamedExec(query, info)
+
+	return err
+}
+
+// LoadAssetErasureInfo loads the erasure coding info of an asset, returns sql.ErrNoRows if the asset is not erasure coded
+func (n *SQLDB) LoadAssetErasureInfo(hash string) (*types.AssetErasureInfo, error) {
+	var info types.AssetErasureInfo
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=?", assetErasureTable)
+	if err := n.db.Get(&info, query, hash); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// DeleteAssetErasureInfo deletes the erasure coding info and the shard records of an asset
+func (n *SQLDB) DeleteAssetErasureInfo(hash string) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteAssetErasureInfo Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetErasureTable)
+	_, err = tx.Exec(query, hash)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, replicaShardTable)
+	_, err = tx.Exec(query, hash)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveReplicaShards inserts or updates the shard indexes held by nodes
+func (n *SQLDB) SaveReplicaShards(shards []*types.ReplicaShard) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveReplicaShards Rollback err:%s", err.Error())
+		}
+	}()
+
+	for _, shard := range shards {
+		query := fmt.Sprintf(
+			`INSERT INTO %s (hash, node_id, shard_index) 
+				VALUES (:hash, :node_id, :shard_index) 
+				ON DUPLICATE KEY UPDATE shard_index=:shard_index`, replicaShardTable)
+
+		_, err := tx.NamedExec(query, shard)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadReplicaShards loads the shards of an asset whose replicas are in the given statuses
+func (n *SQLDB) LoadReplicaShards(hash string, statuses []types.ReplicaStatus) ([]*types.ReplicaShard, error) {
+	sQuery := fmt.Sprintf(`SELECT a.hash, a.node_id, a.shard_index FROM %s a LEFT JOIN %s b ON a.hash = b.hash AND a.node_id = b.node_id 
+		WHERE a.hash=? AND b.status in (?)`, replicaShardTable, replicaInfoTable)
+	query, args, err := sqlx.In(sQuery, hash, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*types.ReplicaShard
+	query = n.db.Rebind(query)
+	if err := n.db.Select(&out, query, args...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LoadReplicaShard loads the shard held by the node, returns sql.ErrNoRows if the node holds a full replica
+func (n *SQLDB) LoadReplicaShard(hash, nodeID string) (*types.ReplicaShard, error) {
+	var info types.ReplicaShard
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=? AND node_id=?", replicaShardTable)
+	if err := n.db.Get(&info, query, hash, nodeID); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
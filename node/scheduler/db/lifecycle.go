@@ -0,0 +1,188 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveLifecyclePolicy inserts a lifecycle policy with its rules and returns the policy id
+func (n *SQLDB) SaveLifecyclePolicy(policy *types.LifecyclePolicy) (int64, error) {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveLifecyclePolicy Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`INSERT INTO %s (name, delete_expired) VALUES (?, ?)`, lifecyclePolicyTable)
+	result, err := tx.Exec(query, policy.Name, policy.DeleteExpired)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, rule := range policy.Rules {
+		rule.PolicyID = id
+
+		query = fmt.Sprintf(
+			`INSERT INTO %s (policy_id, after_days, max_retrievals, edge_replicas, delete_asset) 
+				VALUES (:policy_id, :after_days, :max_retrievals, :edge_replicas, :delete_asset)`, lifecycleRuleTable)
+		_, err = tx.NamedExec(query, rule)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// LoadLifecyclePolicies loads all lifecycle policies with their rules
+func (n *SQLDB) LoadLifecyclePolicies() ([]*types.LifecyclePolicy, error) {
+	var policies []*types.LifecyclePolicy
+	query := fmt.Sprintf(`SELECT * FROM %s`, lifecyclePolicyTable)
+	if err := n.db.Select(&policies, query); err != nil {
+		return nil, err
+	}
+
+	var rules []*types.LifecycleRule
+	query = fmt.Sprintf(`SELECT * FROM %s`, lifecycleRuleTable)
+	if err := n.db.Select(&rules, query); err != nil {
+		return nil, err
+	}
+
+	policyMap := make(map[int64]*types.LifecyclePolicy, len(policies))
+	for _, policy := range policies {
+		policyMap[policy.ID] = policy
+	}
+
+	for _, rule := range rules {
+		if policy, ok := policyMap[rule.PolicyID]; ok {
+			policy.Rules = append(policy.Rules, rule)
+		}
+	}
+
+	return policies, nil
+}
+
+// LifecyclePolicyExists checks if the lifecycle policy exists
+func (n *SQLDB) LifecyclePolicyExists(policyID int64) (bool, error) {
+	var total int64
+	query := fmt.Sprintf(`SELECT count(id) FROM %s WHERE id=?`, lifecyclePolicyTable)
+	if err := n.db.Get(&total, query, policyID); err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// DeleteLifecyclePolicy deletes a lifecycle policy, its rules and its bindings
+func (n *SQLDB) DeleteLifecyclePolicy(policyID int64) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteLifecyclePolicy Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=?`, lifecyclePolicyTable)
+	if _, err = tx.Exec(query, policyID); err != nil {
+		return err
+	}
+
+	for _, table := range []string{lifecycleRuleTable, assetLifecycleTable, groupLifecycleTable} {
+		query = fmt.Sprintf(`DELETE FROM %s WHERE policy_id=?`, table)
+		if _, err = tx.Exec(query, policyID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveAssetLifecyclePolicy attaches a lifecycle policy to an asset
+func (n *SQLDB) SaveAssetLifecyclePolicy(hash string, policyID int64) error {
+	query := fmt.Sprintf(`INSERT INTO %s (hash, policy_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE policy_id=?`, assetLifecycleTable)
+	_, err := n.db.Exec(query, hash, policyID, policyID)
+	return err
+}
+
+// DeleteAssetLifecyclePolicy detaches the lifecycle policy from an asset
+func (n *SQLDB) DeleteAssetLifecyclePolicy(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetLifecycleTable)
+	_, err := n.db.Exec(query, hash)
+	return err
+}
+
+// SaveGroupLifecyclePolicy attaches a lifecycle policy to a user asset group
+func (n *SQLDB) SaveGroupLifecyclePolicy(userID string, groupID int, policyID int64) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, group_id, policy_id) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE policy_id=?`, groupLifecycleTable)
+	_, err := n.db.Exec(query, userID, groupID, policyID, policyID)
+	return err
+}
+
+// DeleteGroupLifecyclePolicy detaches the lifecycle policy from a user asset group
+func (n *SQLDB) DeleteGroupLifecyclePolicy(userID string, groupID int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id=? AND group_id=?`, groupLifecycleTable)
+	_, err := n.db.Exec(query, userID, groupID)
+	return err
+}
+
+// LoadAssetLifecyclePolicies loads the lifecycle policy id of every asset with a policy,
+// the policy attached to the asset takes precedence over the policy of its group
+func (n *SQLDB) LoadAssetLifecyclePolicies() (map[string]int64, error) {
+	type binding struct {
+		Hash     string `db:"hash"`
+		PolicyID int64  `db:"policy_id"`
+	}
+
+	var groupBindings []*binding
+	query := fmt.Sprintf(`SELECT u.hash, g.policy_id FROM %s g JOIN %s u ON g.user_id=u.user_id AND g.group_id=u.group_id`,
+		groupLifecycleTable, userAssetTable)
+	if err := n.db.Select(&groupBindings, query); err != nil {
+		return nil, err
+	}
+
+	var assetBindings []*binding
+	query = fmt.Sprintf(`SELECT hash, policy_id FROM %s`, assetLifecycleTable)
+	if err := n.db.Select(&assetBindings, query); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]int64, len(groupBindings)+len(assetBindings))
+	for _, b := range groupBindings {
+		out[b.Hash] = b.PolicyID
+	}
+
+	for _, b := range assetBindings {
+		out[b.Hash] = b.PolicyID
+	}
+
+	return out, nil
+}
+
+// LoadRetrieveCountOfAsset returns the number of retrievals of the asset since the given unix time
+func (n *SQLDB) LoadRetrieveCountOfAsset(cid string, since int64) (int64, error) {
+	var count int64
+	query := fmt.Sprintf(`SELECT count(token_id) FROM %s WHERE cid=? AND created_time>=?`, retrieveEventTable)
+	if err := n.db.Get(&count, query, cid, since); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
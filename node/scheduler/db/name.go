@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveNameRecord inserts the record of the name or replaces the record with a lower sequence,
+// returns false if the record is not saved because the sequence is not greater than the saved one
+func (n *SQLDB) SaveNameRecord(record *types.NameRecord) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET value=?, sequence=?, validity=?, ttl=?, signature=?, user_id=?, updated_time=NOW() WHERE name=? AND sequence<?`, nameRecordTable)
+	result, err := n.db.Exec(query, record.Value, record.Sequence, record.Validity, record.TTL, record.Signature, record.UserID, record.Name, record.Sequence)
+	if err != nil {
+		return false, err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if rows > 0 {
+		return true, nil
+	}
+
+	var count int
+	query = fmt.Sprintf(`SELECT count(*) FROM %s WHERE name=?`, nameRecordTable)
+	if err := n.db.Get(&count, query, record.Name); err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	// the insert of the same name published at the same time fails by the primary key
+	query = fmt.Sprintf(`INSERT INTO %s (name, value, sequence, validity, ttl, public_key, signature, user_id)
+	        VALUES (:name, :value, :sequence, :validity, :ttl, :public_key, :signature, :user_id)`, nameRecordTable)
+	if _, err := n.db.NamedExec(query, record); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// LoadNameRecord loads the record of the name
+func (n *SQLDB) LoadNameRecord(name string) (*types.NameRecord, error) {
+	record := &types.NameRecord{}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE name=?`, nameRecordTable)
+	if err := n.db.Get(record, query, name); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// LoadNameRecordsOfUser loads the records of the names published by the user
+func (n *SQLDB) LoadNameRecordsOfUser(userID string) ([]*types.NameRecord, error) {
+	var out []*types.NameRecord
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=? ORDER BY updated_time DESC`, nameRecordTable)
+	if err := n.db.Select(&out, query, userID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
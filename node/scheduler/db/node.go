@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
@@ -158,14 +159,68 @@ func (n *SQLDB) LoadValidationResultInfos(nodeID string, limit, offset int) (*ty
 	return res, nil
 }
 
-// SaveEdgeUpdateConfig inserts edge update information.
+// edgeRelease is the signature and rollout stage of an edge update
+type edgeRelease struct {
+	NodeType       int            `db:"node_type"`
+	Signature      string         `db:"signature"`
+	RolloutPercent int            `db:"rollout_percent"`
+	RolloutAreas   string         `db:"rollout_areas"`
+	RolloutNodes   sql.NullString `db:"rollout_nodes"`
+}
+
+// SaveEdgeUpdateConfig inserts edge update information with its signature and rollout stage.
 func (n *SQLDB) SaveEdgeUpdateConfig(info *api.EdgeUpdateConfig) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("SaveEdgeUpdateConfig Rollback err:%s", err.Error())
+		}
+	}()
+
 	sqlString := fmt.Sprintf(`INSERT INTO %s (node_type, app_name, version, hash, download_url) VALUES (:node_type, :app_name, :version, :hash, :download_url) ON DUPLICATE KEY UPDATE app_name=:app_name, version=:version, hash=:hash, download_url=:download_url`, edgeUpdateTable)
-	_, err := n.db.NamedExec(sqlString, info)
-	return err
+	_, err = tx.NamedExec(sqlString, info)
+	if err != nil {
+		return err
+	}
+
+	release := &edgeRelease{
+		NodeType:       info.NodeType,
+		Signature:      info.Signature,
+		RolloutPercent: info.RolloutPercent,
+		RolloutAreas:   strings.Join(info.RolloutAreas, ","),
+		RolloutNodes:   sql.NullString{String: strings.Join(info.RolloutNodes, ","), Valid: true},
+	}
+	sqlString = fmt.Sprintf(`INSERT INTO %s (node_type, signature, rollout_percent, rollout_areas, rollout_nodes) VALUES (:node_type, :signature, :rollout_percent, :rollout_areas, :rollout_nodes)
+				ON DUPLICATE KEY UPDATE signature=:signature, rollout_percent=:rollout_percent, rollout_areas=:rollout_areas, rollout_nodes=:rollout_nodes`, edgeReleaseTable)
+	_, err = tx.NamedExec(sqlString, release)
+	if err != nil {
+		return err
+	}
+
+	// the patches of the previous release are replaced
+	_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE node_type=?`, edgePatchTable), info.NodeType)
+	if err != nil {
+		return err
+	}
+
+	sqlString = fmt.Sprintf(`INSERT INTO %s (node_type, from_version, download_url, hash) VALUES (:node_type, :from_version, :download_url, :hash)`, edgePatchTable)
+	for _, patch := range info.Patches {
+		patch.NodeType = info.NodeType
+		_, err = tx.NamedExec(sqlString, patch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-// LoadEdgeUpdateConfigs load edge update information.
+// LoadEdgeUpdateConfigs load edge update information, the updates without rollout stage are rolled out to all nodes.
 func (n *SQLDB) LoadEdgeUpdateConfigs() (map[int]*api.EdgeUpdateConfig, error) {
 	query := fmt.Sprintf(`SELECT * FROM %s`, edgeUpdateTable)
 
@@ -174,10 +229,40 @@ func (n *SQLDB) LoadEdgeUpdateConfigs() (map[int]*api.EdgeUpdateConfig, error) {
 		return nil, err
 	}
 
+	var releases []*edgeRelease
+	query = fmt.Sprintf(`SELECT * FROM %s`, edgeReleaseTable)
+	if err := n.db.Select(&releases, query); err != nil {
+		return nil, err
+	}
+
+	releaseOfType := make(map[int]*edgeRelease, len(releases))
+	for _, release := range releases {
+		releaseOfType[release.NodeType] = release
+	}
+
+	var patches []*api.EdgeUpdatePatch
+	query = fmt.Sprintf(`SELECT * FROM %s`, edgePatchTable)
+	if err := n.db.Select(&patches, query); err != nil {
+		return nil, err
+	}
+
 	ret := make(map[int]*api.EdgeUpdateConfig)
 	for _, info := range out {
+		info.RolloutPercent = 100
+		if release, ok := releaseOfType[info.NodeType]; ok {
+			info.Signature = release.Signature
+			info.RolloutPercent = release.RolloutPercent
+			info.RolloutAreas = splitList(release.RolloutAreas)
+			info.RolloutNodes = splitList(release.RolloutNodes.String)
+		}
 		ret[info.NodeType] = info
 	}
+
+	for _, patch := range patches {
+		if info, ok := ret[patch.NodeType]; ok {
+			info.Patches = append(info.Patches, patch)
+		}
+	}
 	return ret, nil
 }
 
@@ -185,9 +270,29 @@ func (n *SQLDB) LoadEdgeUpdateConfigs() (map[int]*api.EdgeUpdateConfig, error) {
 func (n *SQLDB) DeleteEdgeUpdateConfig(nodeType int) error {
 	deleteString := fmt.Sprintf(`DELETE FROM %s WHERE node_type=?`, edgeUpdateTable)
 	_, err := n.db.Exec(deleteString, nodeType)
+	if err != nil {
+		return err
+	}
+
+	deleteString = fmt.Sprintf(`DELETE FROM %s WHERE node_type=?`, edgeReleaseTable)
+	_, err = n.db.Exec(deleteString, nodeType)
+	if err != nil {
+		return err
+	}
+
+	deleteString = fmt.Sprintf(`DELETE FROM %s WHERE node_type=?`, edgePatchTable)
+	_, err = n.db.Exec(deleteString, nodeType)
 	return err
 }
 
+// splitList splits the comma separated list, empty string is an empty list
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
 // UpdateValidators update validators
 func (n *SQLDB) UpdateValidators(nodeIDs []string, serverID dtypes.ServerID) error {
 	tx, err := n.db.Beginx()
@@ -261,7 +366,7 @@ func (n *SQLDB) SaveNodeInfo(info *types.NodeInfo) error {
 				VALUES (:node_id, :mac_location, :cpu_cores, :memory, :node_name, :cpu_info, :available_disk_space, :titan_disk_usage,
 				:disk_type, :io_system, :system_version, :nat_type, :disk_space, :bandwidth_up, :bandwidth_down, :scheduler_sid) 
 				ON DUPLICATE KEY UPDATE node_id=:node_id, scheduler_sid=:scheduler_sid, system_version=:system_version, cpu_cores=:cpu_cores, titan_disk_usage=:titan_disk_usage,
-				memory=:memory, node_name=:node_name, disk_space=:disk_space, cpu_info=:cpu_info, available_disk_space=:available_disk_space, available_disk_space=:available_disk_space `, nodeInfoTable)
+				memory=:memory, node_name=:node_name, disk_space=:disk_space, cpu_info=:cpu_info, available_disk_space=:available_disk_space`, nodeInfoTable)
 
 	_, err := n.db.NamedExec(query, info)
 	return err
@@ -636,6 +741,72 @@ func (n *SQLDB) RemoveInvalidWorkloadResult(sIDs []string) error {
 	return err
 }
 
+// MarkWorkloadInvalid sets the status of the workload record to invalid and records the reason
+func (n *SQLDB) MarkWorkloadInvalid(record *types.WorkloadRecord, reason types.WorkloadInvalidReason) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("MarkWorkloadInvalid Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`UPDATE %s SET status=? WHERE token_id=?`, workloadRecordTable)
+	_, err = tx.Exec(query, types.WorkloadStatusInvalid, record.ID)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (token_id, node_id, client_id, reason) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE reason=?`, workloadInvalidTable)
+	_, err = tx.Exec(query, record.ID, record.NodeID, record.ClientID, reason, reason)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LoadWorkloadInvalidReason load the reason of the invalid workload
+func (n *SQLDB) LoadWorkloadInvalidReason(tokenID string) (types.WorkloadInvalidReason, error) {
+	var reason types.WorkloadInvalidReason
+	query := fmt.Sprintf(`SELECT reason FROM %s WHERE token_id=?`, workloadInvalidTable)
+	err := n.db.Get(&reason, query, tokenID)
+	return reason, err
+}
+
+// DeleteInvalidWorkloads deletes the invalid workload records and their reasons older than 7 days
+func (n *SQLDB) DeleteInvalidWorkloads() error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteInvalidWorkloads Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE status=? AND token_id IN (SELECT token_id FROM %s WHERE created_time<DATE_SUB(NOW(), INTERVAL 7 DAY))`, workloadRecordTable, workloadInvalidTable)
+	_, err = tx.Exec(query, types.WorkloadStatusInvalid)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE created_time<DATE_SUB(NOW(), INTERVAL 7 DAY)`, workloadInvalidTable)
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // LoadUnCalculatedValidationResults Load not calculated profit validation results
 func (n *SQLDB) LoadUnCalculatedValidationResults(maxTime time.Time, limit int) (*sqlx.Rows, error) {
 	sQuery := fmt.Sprintf(`SELECT * FROM %s WHERE calculated_profit=? AND end_time<? order by end_time asc LIMIT ?`, validationResultTable)
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// ReplacePartialReplicas replaces the partial replicas of the node
+func (n *SQLDB) ReplacePartialReplicas(nodeID string, infos []*types.PartialReplica) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("ReplacePartialReplicas Rollback err:%s", err.Error())
+		}
+	}()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE node_id=?`, partialReplicaTable)
+	if _, err = tx.Exec(query, nodeID); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (hash, node_id, blocks, size) VALUES (:hash, :node_id, :blocks, :size)`, partialReplicaTable)
+	for _, info := range infos {
+		if _, err = tx.NamedExec(query, info); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadPartialReplicas loads the partial replicas of the asset
+func (n *SQLDB) LoadPartialReplicas(hash string) ([]*types.PartialReplica, error) {
+	var out []*types.PartialReplica
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE hash=? ORDER BY size DESC`, partialReplicaTable)
+	if err := n.db.Select(&out, query, hash); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SavePresignedLink inserts the presigned link
+func (n *SQLDB) SavePresignedLink(link *types.PresignedLink) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, user_id, hash, expiration, max_downloads, allowed_ips, has_password, password_hash) 
+			VALUES (:id, :user_id, :hash, :expiration, :max_downloads, :allowed_ips, :has_password, :password_hash)`, presignedLinkTable)
+	_, err := n.db.NamedExec(query, link)
+	return err
+}
+
+// LoadPresignedLink loads the presigned link
+func (n *SQLDB) LoadPresignedLink(id string) (*types.PresignedLink, error) {
+	link := &types.PresignedLink{}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=?`, presignedLinkTable)
+	if err := n.db.Get(link, query, id); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// ListPresignedLinks lists the presigned links of the asset of the user
+func (n *SQLDB) ListPresignedLinks(userID, hash string) ([]*types.PresignedLink, error) {
+	var out []*types.PresignedLink
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=? AND hash=? ORDER BY created_time DESC`, presignedLinkTable)
+	if err := n.db.Select(&out, query, userID, hash); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// RevokePresignedLink revokes the presigned link of the user
+func (n *SQLDB) RevokePresignedLink(id, userID string) error {
+	query := fmt.Sprintf(`UPDATE %s SET revoked=? WHERE id=? AND user_id=?`, presignedLinkTable)
+	result, err := n.db.Exec(query, true, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// LoadRevokedPresignedLinks loads the ids of the revoked presigned links which are not expired
+func (n *SQLDB) LoadRevokedPresignedLinks() ([]string, error) {
+	var out []string
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE revoked=? AND expiration>NOW()`, presignedLinkTable)
+	if err := n.db.Select(&out, query, true); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// AddPresignedLinkDownload counts a download of the presigned link,
+// returns false if the link is revoked or out of max downloads
+func (n *SQLDB) AddPresignedLinkDownload(id string) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET downloads=downloads+1 WHERE id=? AND revoked=? AND (max_downloads=0 OR downloads<max_downloads)`, presignedLinkTable)
+	result, err := n.db.Exec(query, id, false)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// DeletePresignedLinks deletes the presigned links of the asset
+func (n *SQLDB) DeletePresignedLinks(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, presignedLinkTable)
+	_, err := n.db.Exec(query, hash)
+	return err
+}
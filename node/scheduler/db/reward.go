@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// RewardNodeInfo is the state of a node replayed through the reward models
+type RewardNodeInfo struct {
+	NodeID         string  `db:"node_id"`
+	NATType        string  `db:"nat_type"`
+	TitanDiskUsage float64 `db:"titan_disk_usage"`
+	BandwidthUp    int64   `db:"bandwidth_up"`
+	// OnlineDuration is the online minutes of the node since the first login
+	OnlineDuration int            `db:"online_duration"`
+	FirstLoginTime time.Time      `db:"first_login_time"`
+	NodeType       types.NodeType `db:"node_type"`
+}
+
+// SaveRewardModel inserts or replaces the reward model of the version
+func (n *SQLDB) SaveRewardModel(model *types.RewardModel) error {
+	buf, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	var startTime sql.NullTime
+	if !model.StartTime.IsZero() {
+		startTime = sql.NullTime{Time: model.StartTime, Valid: true}
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (version, model, start_time) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE model=?, start_time=?`, rewardModelTable)
+	_, err = n.db.Exec(query, model.Version, string(buf), startTime, string(buf), startTime)
+	return err
+}
+
+// LoadRewardModels loads all reward models ordered by version
+func (n *SQLDB) LoadRewardModels() ([]*types.RewardModel, error) {
+	var rows []string
+	query := fmt.Sprintf(`SELECT model FROM %s ORDER BY version`, rewardModelTable)
+	if err := n.db.Select(&rows, query); err != nil {
+		return nil, err
+	}
+
+	out := make([]*types.RewardModel, 0, len(rows))
+	for _, row := range rows {
+		model := &types.RewardModel{}
+		if err := json.Unmarshal([]byte(row), model); err != nil {
+			return nil, err
+		}
+		out = append(out, model)
+	}
+
+	return out, nil
+}
+
+// LoadRewardModel loads the reward model of the version
+func (n *SQLDB) LoadRewardModel(version int) (*types.RewardModel, error) {
+	var row string
+	query := fmt.Sprintf(`SELECT model FROM %s WHERE version=?`, rewardModelTable)
+	if err := n.db.Get(&row, query, version); err != nil {
+		return nil, err
+	}
+
+	model := &types.RewardModel{}
+	return model, json.Unmarshal([]byte(row), model)
+}
+
+// LoadActiveRewardModel loads the model with the latest start time not after t, sql.ErrNoRows if no model is scheduled
+func (n *SQLDB) LoadActiveRewardModel(t time.Time) (*types.RewardModel, error) {
+	var row string
+	query := fmt.Sprintf(`SELECT model FROM %s WHERE start_time IS NOT NULL AND start_time<=? ORDER BY start_time DESC LIMIT 1`, rewardModelTable)
+	if err := n.db.Get(&row, query, t); err != nil {
+		return nil, err
+	}
+
+	model := &types.RewardModel{}
+	return model, json.Unmarshal([]byte(row), model)
+}
+
+// LoadRewardNodeInfos loads the states of the nodes which the rewards are computed from
+func (n *SQLDB) LoadRewardNodeInfos() ([]*RewardNodeInfo, error) {
+	var out []*RewardNodeInfo
+	query := fmt.Sprintf(`SELECT a.node_id, a.nat_type, a.titan_disk_usage, a.bandwidth_up, a.online_duration, a.first_login_time, COALESCE(b.node_type, '0') AS node_type
+		FROM %s a LEFT JOIN %s b ON a.node_id=b.node_id`, nodeInfoTable, nodeRegisterTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// CountNodeValidations counts the passed validations of every node in the time range
+func (n *SQLDB) CountNodeValidations(start, end time.Time) (map[string]int, error) {
+	query := fmt.Sprintf(`SELECT node_id, COUNT(*) AS count FROM %s WHERE status=? AND end_time>=? AND end_time<? GROUP BY node_id`, validationResultTable)
+	return n.countOfNodes(query, types.ValidationStatusSuccess, start, end)
+}
+
+// CountNodeRetrieves counts the succeeded workloads of every node in the time range,
+// the succeeded workload records are moved to the retrieve events
+func (n *SQLDB) CountNodeRetrieves(start, end time.Time) (map[string]int, error) {
+	query := fmt.Sprintf(`SELECT node_id, COUNT(*) AS count FROM %s WHERE created_time>=? AND created_time<? GROUP BY node_id`, retrieveEventTable)
+	return n.countOfNodes(query, start.Unix(), end.Unix())
+}
+
+func (n *SQLDB) countOfNodes(query string, args ...interface{}) (map[string]int, error) {
+	var rows []struct {
+		NodeID string `db:"node_id"`
+		Count  int    `db:"count"`
+	}
+	if err := n.db.Select(&rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]int, len(rows))
+	for _, row := range rows {
+		out[row.NodeID] = row.Count
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// SaveAssetScaling inserts or updates the popularity scaling record of an asset
+func (n *SQLDB) SaveAssetScaling(info *types.AssetScaling) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (hash, base_replicas, scaled_replicas, updated_time) 
+		        VALUES (:hash, :base_replicas, :scaled_replicas, NOW()) 
+				ON DUPLICATE KEY UPDATE base_replicas=:base_replicas, scaled_replicas=:scaled_replicas, updated_time=NOW()`, assetScalingTable)
+	_, err := n.db.NamedExec(query, info)
+
+	return err
+}
+
+// LoadAssetScalings loads the popularity scaling records of all assets
+func (n *SQLDB) LoadAssetScalings() ([]*types.AssetScaling, error) {
+	var out []*types.AssetScaling
+	query := fmt.Sprintf(`SELECT * FROM %s`, assetScalingTable)
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeleteAssetScaling deletes the popularity scaling record of an asset
+func (n *SQLDB) DeleteAssetScaling(hash string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE hash=?`, assetScalingTable)
+	_, err := n.db.Exec(query, hash)
+
+	return err
+}
+
+// LoadRetrieveCounts returns the number of retrievals of every asset since the given unix time, the key is the asset cid
+func (n *SQLDB) LoadRetrieveCounts(since int64) (map[string]int64, error) {
+	type retrieveCount struct {
+		CID   string `db:"cid"`
+		Count int64  `db:"count"`
+	}
+
+	var counts []*retrieveCount
+	query := fmt.Sprintf(`SELECT cid, count(token_id) AS count FROM %s WHERE created_time>=? GROUP BY cid`, retrieveEventTable)
+	if err := n.db.Select(&counts, query, since); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		out[c.CID] = c.Count
+	}
+
+	return out, nil
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/jmoiron/sqlx"
+)
+
+// SaveSchedulerShard records the scheduler as an owner of an asset state table in the area
+func (n *SQLDB) SaveSchedulerShard(serverID dtypes.ServerID, areaID string) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (server_id, area_id, updated_time) VALUES (?, ?, NOW())
+				ON DUPLICATE KEY UPDATE area_id=?, updated_time=NOW()`, schedulerShardTable)
+	_, err := n.db.Exec(query, serverID, areaID, areaID)
+
+	return err
+}
+
+// LoadSchedulerShards loads the schedulers which own asset state tables in the area
+func (n *SQLDB) LoadSchedulerShards(areaID string) ([]dtypes.ServerID, error) {
+	var out []dtypes.ServerID
+	query := fmt.Sprintf(`SELECT server_id FROM %s WHERE area_id=?`, schedulerShardTable)
+	if err := n.db.Select(&out, query, areaID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeleteSchedulerShard deletes the scheduler whose asset states are all adopted by other schedulers
+func (n *SQLDB) DeleteSchedulerShard(serverID dtypes.ServerID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE server_id=?`, schedulerShardTable)
+	_, err := n.db.Exec(query, serverID)
+
+	return err
+}
+
+// LoadAssetStateHashes loads the hashes of the assets in the state machine table of the server
+func (n *SQLDB) LoadAssetStateHashes(serverID dtypes.ServerID) ([]string, error) {
+	var out []string
+	query := fmt.Sprintf(`SELECT hash FROM %s`, assetStateTable(serverID))
+	if err := n.db.Select(&out, query); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// MoveAssetStates moves the asset states from the state machine table of a server to another,
+// the scheduler of the moved asset records is updated in the same transaction, returns the hashes moved
+func (n *SQLDB) MoveAssetStates(hashes []string, from, to dtypes.ServerID) ([]string, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("MoveAssetStates Rollback err:%s", err.Error())
+		}
+	}()
+
+	// the states may be moved by another scheduler, only the rows still in the table are moved
+	sQuery := fmt.Sprintf(`SELECT hash FROM %s WHERE hash in (?)`, assetStateTable(from))
+	query, args, err := sqlx.In(sQuery, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var moved []string
+	if err = tx.Select(&moved, tx.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	if len(moved) == 0 {
+		return nil, nil
+	}
+
+	sQuery = fmt.Sprintf(
+		`INSERT INTO %s (hash, state, retry_count, replenish_replicas)
+				SELECT hash, state, retry_count, replenish_replicas FROM %s WHERE hash in (?)
+				ON DUPLICATE KEY UPDATE state=VALUES(state), retry_count=VALUES(retry_count), replenish_replicas=VALUES(replenish_replicas)`,
+		assetStateTable(to), assetStateTable(from))
+	query, args, err = sqlx.In(sQuery, moved)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(tx.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	sQuery = fmt.Sprintf(`DELETE FROM %s WHERE hash in (?)`, assetStateTable(from))
+	query, args, err = sqlx.In(sQuery, moved)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(tx.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	sQuery = fmt.Sprintf(`UPDATE %s SET scheduler_sid=? WHERE scheduler_sid=? AND hash in (?)`, assetRecordTable)
+	query, args, err = sqlx.In(sQuery, to, from, moved)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(tx.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return moved, tx.Commit()
+}
@@ -45,6 +45,27 @@ const (
 	replenishBackupTable  = "replenish_backup"
 	userAssetGroupTable   = "user_asset_group"
 	awsDataTable          = "aws_data"
+	assetErasureTable     = "asset_erasure"
+	replicaShardTable     = "replica_shard"
+	lifecyclePolicyTable  = "lifecycle_policy"
+	lifecycleRuleTable    = "lifecycle_rule"
+	assetLifecycleTable   = "asset_lifecycle"
+	groupLifecycleTable   = "group_lifecycle"
+	assetScalingTable     = "asset_scaling"
+	assetCommitmentTable  = "asset_commitment"
+	schedulerShardTable   = "scheduler_shard"
+	edgeReleaseTable      = "edge_update_release"
+	edgePatchTable        = "edge_update_patch"
+	workloadInvalidTable  = "workload_invalid"
+	rewardModelTable      = "reward_model"
+	partialReplicaTable   = "partial_replica"
+	nameRecordTable       = "name_record"
+	userPublicKeyTable    = "user_public_key"
+	assetKeyEnvelopeTable = "asset_key_envelope"
+	userEgressTable       = "user_egress"
+	userEgressPlanTable   = "user_egress_plan"
+	presignedLinkTable    = "presigned_link"
+	auditLogTable         = "audit_log"
 
 	// Default limits for loading table entries.
 	loadNodeInfosDefaultLimit           = 1000
@@ -56,6 +77,7 @@ const (
 	loadRetrieveDefaultLimit            = 100
 	loadReplicaDefaultLimit             = 100
 	loadUserDefaultLimit                = 100
+	loadAuditLogDefaultLimit            = 500
 )
 
 // assetStateTable returns the asset state table name for the given serverID.
@@ -99,6 +121,27 @@ func InitTables(d *SQLDB, serverID dtypes.ServerID) error {
 	tx.MustExec(fmt.Sprintf(cReplenishBackupTable, replenishBackupTable))
 	tx.MustExec(fmt.Sprintf(cUserAssetGroupTable, userAssetGroupTable))
 	tx.MustExec(fmt.Sprintf(cAWSDataTable, awsDataTable))
+	tx.MustExec(fmt.Sprintf(cAssetErasureTable, assetErasureTable))
+	tx.MustExec(fmt.Sprintf(cReplicaShardTable, replicaShardTable))
+	tx.MustExec(fmt.Sprintf(cLifecyclePolicyTable, lifecyclePolicyTable))
+	tx.MustExec(fmt.Sprintf(cLifecycleRuleTable, lifecycleRuleTable))
+	tx.MustExec(fmt.Sprintf(cAssetLifecycleTable, assetLifecycleTable))
+	tx.MustExec(fmt.Sprintf(cGroupLifecycleTable, groupLifecycleTable))
+	tx.MustExec(fmt.Sprintf(cAssetScalingTable, assetScalingTable))
+	tx.MustExec(fmt.Sprintf(cAssetCommitmentTable, assetCommitmentTable))
+	tx.MustExec(fmt.Sprintf(cSchedulerShardTable, schedulerShardTable))
+	tx.MustExec(fmt.Sprintf(cEdgeReleaseTable, edgeReleaseTable))
+	tx.MustExec(fmt.Sprintf(cEdgePatchTable, edgePatchTable))
+	tx.MustExec(fmt.Sprintf(cWorkloadInvalidTable, workloadInvalidTable))
+	tx.MustExec(fmt.Sprintf(cRewardModelTable, rewardModelTable))
+	tx.MustExec(fmt.Sprintf(cPartialReplicaTable, partialReplicaTable))
+	tx.MustExec(fmt.Sprintf(cNameRecordTable, nameRecordTable))
+	tx.MustExec(fmt.Sprintf(cUserPublicKeyTable, userPublicKeyTable))
+	tx.MustExec(fmt.Sprintf(cAssetKeyEnvelopeTable, assetKeyEnvelopeTable))
+	tx.MustExec(fmt.Sprintf(cUserEgressTable, userEgressTable))
+	tx.MustExec(fmt.Sprintf(cUserEgressPlanTable, userEgressPlanTable))
+	tx.MustExec(fmt.Sprintf(cPresignedLinkTable, presignedLinkTable))
+	tx.MustExec(fmt.Sprintf(cAuditLogTable, auditLogTable))
 
 	return tx.Commit()
 }
@@ -0,0 +1,390 @@
+package db
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/sqldb"
+)
+
+func TestSQLiteBackend(t *testing.T) {
+	client, err := sqldb.NewDB("sqlite://" + filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck // ignore error
+
+	d, err := NewSQLDB(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tables are created if not exists
+	for i := 0; i < 2; i++ {
+		if err := InitTables(d, "test-server"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &types.NameRecord{Value: "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku", Sequence: 1, Validity: time.Now().Add(time.Hour).Truncate(time.Second), UserID: "user"}
+	if err := ipns.Sign(key, record); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := d.SaveNameRecord(record); err != nil || !saved {
+		t.Fatalf("save name record %v %v", saved, err)
+	}
+
+	record.Value, record.Sequence = "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku/v2", 2
+	if err := ipns.Sign(key, record); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := d.SaveNameRecord(record); err != nil || !saved {
+		t.Fatalf("save name record %v %v", saved, err)
+	}
+
+	// the record of a sequence not greater is not saved
+	if saved, err := d.SaveNameRecord(record); err != nil || saved {
+		t.Fatalf("save name record of the same sequence %v %v", saved, err)
+	}
+
+	loaded, err := d.LoadNameRecord(record.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Value != record.Value || loaded.Sequence != 2 || !loaded.Validity.Equal(record.Validity) {
+		t.Fatalf("unexpected record %+v", loaded)
+	}
+
+	// the signature is valid after the round trip of the database
+	if err := ipns.Verify(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		group, err := d.CreateAssetGroup(&types.AssetGroup{UserID: "user", Name: "group"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if group.ID != i {
+			t.Fatalf("group id %d, expected %d", group.ID, i)
+		}
+	}
+
+	for _, name := range []string{"a%b", "a%c", "A%d", "abc"} {
+		if err := d.SaveAssetUser("hash-"+name, "user", name, "file", 1, time.Now().Add(time.Hour), "", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assets, err := d.ListUserAssetsByName("user", 0, "a%", "a%b", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0].AssetName != "a%c" {
+		t.Fatalf("unexpected assets %+v", assets)
+	}
+
+	// the same content can be saved with several names
+	if err := d.SaveUserTotalStorageSize("user", 100); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"empty", "folder/"} {
+		if err := d.SaveAssetUser("hash-empty", "user", name, "s3", 3, time.Now().Add(time.Hour), "", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count, err := d.CountAssetNamesOfUser("hash-empty", "user"); err != nil || count != 2 {
+		t.Fatalf("count asset names %d, %v", count, err)
+	}
+	if users, err := d.ListUsersForAsset("hash-empty"); err != nil || len(users) != 1 {
+		t.Fatalf("list users for asset %v, %v", users, err)
+	}
+	if err := d.DeleteAssetUserByName("hash-empty", "user", "empty", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.LoadUserAssetByName("user", "folder/", 0); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := d.LoadUserInfo("user"); err != nil || info.UsedSize != 3 {
+		t.Fatalf("used storage size of user %+v, %v", info, err)
+	}
+	if err := d.SaveAssetUser("hash-empty", "user", "empty", "s3", 3, time.Now().Add(time.Hour), "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteAssetUser("hash-empty", "user"); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := d.LoadUserInfo("user"); err != nil || info.UsedSize != 0 {
+		t.Fatalf("used storage size of user %+v, %v", info, err)
+	}
+
+	if err := d.DeleteReplicaEvents(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := d.AddUserEgressTraffic("user", "2026-10", 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	traffic, err := d.LoadUserEgressTraffic("user", "2026-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if traffic != 200 {
+		t.Fatalf("egress traffic %d, expected 200", traffic)
+	}
+
+	link := &types.PresignedLink{ID: "link", UserID: "user", Hash: "hash", Expiration: time.Now().Add(time.Hour), MaxDownloads: 2, HasPassword: true}
+	if err := d.SavePresignedLink(link); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		ok, err := d.AddPresignedLinkDownload("link")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != (i < 2) {
+			t.Fatalf("download %d counted %v", i, ok)
+		}
+	}
+
+	if err := d.RevokePresignedLink("link", "other"); err == nil {
+		t.Fatal("link is revoked by other user")
+	}
+
+	if err := d.RevokePresignedLink("link", "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := d.LoadRevokedPresignedLinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revoked) != 1 || revoked[0] != "link" {
+		t.Fatalf("unexpected revoked links %v", revoked)
+	}
+
+	for _, method := range []string{"SetUserVIP", "DeactivateNode", "SetUserVIP"} {
+		if err := d.SaveAuditLog(&types.AuditLog{Caller: "admin", Role: "admin", Method: method, Args: "[]", Success: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs, err := d.LoadAuditLogs(&types.AuditLogQuery{Method: "SetUserVIP", Start: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs.Total != 2 || len(logs.Logs) != 2 || logs.Logs[0].ID != 3 || !logs.Logs[0].Success {
+		t.Fatalf("unexpected audit logs %+v", logs)
+	}
+
+	if _, err := d.db.Exec(d.db.Rebind(fmt.Sprintf(`UPDATE %s SET created_time=? WHERE id=1`, auditLogTable)), time.Now().AddDate(0, 0, -10)); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := d.DeleteAuditLogs(time.Now().AddDate(0, 0, -7)); err != nil || deleted != 1 {
+		t.Fatalf("deleted %d audit logs, err %v", deleted, err)
+	}
+
+	for _, hash := range []string{"hash-a", "hash-b", "hash-c"} {
+		state := "Servicing"
+		if hash == "hash-c" {
+			state = "EdgesPulling"
+		}
+		if err := d.SaveAssetRecord(&types.AssetRecord{Hash: hash, CID: hash, State: state, ServerID: "test-server"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := d.LoadAssetStateCounts("test-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts["Servicing"] != 2 || counts["EdgesPulling"] != 1 {
+		t.Fatalf("unexpected asset state counts %v", counts)
+	}
+
+	policy := &types.LifecyclePolicy{Name: "policy", Rules: []*types.LifecycleRule{{AfterDays: 30, EdgeReplicas: 1}, {AfterDays: 90, Delete: true}}}
+	policyID, err := d.SaveLifecyclePolicy(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policies, err := d.LoadLifecyclePolicies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 1 || policies[0].ID != policyID || len(policies[0].Rules) != 2 {
+		t.Fatalf("unexpected lifecycle policies %+v", policies)
+	}
+
+	if err := d.SaveGroupLifecyclePolicy("user", 0, policyID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SaveAssetLifecyclePolicy("hash-abc", policyID+1); err != nil {
+		t.Fatal(err)
+	}
+
+	bindings, err := d.LoadAssetLifecyclePolicies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bindings) != 4 || bindings["hash-a%b"] != policyID || bindings["hash-abc"] != policyID+1 {
+		t.Fatalf("unexpected lifecycle bindings %v", bindings)
+	}
+
+	if err := d.DeleteLifecyclePolicy(policyID); err != nil {
+		t.Fatal(err)
+	}
+	if exist, err := d.LifecyclePolicyExists(policyID); err != nil || exist {
+		t.Fatalf("lifecycle policy exists %v, err %v", exist, err)
+	}
+
+	now := time.Now().Unix()
+	for i, created := range []int64{now - 3600, now - 60, now} {
+		event := &types.RetrieveEvent{TokenID: fmt.Sprintf("token-%d", i), NodeID: "node", ClientID: "client", CID: "cid-a", CreatedTime: created}
+		if err := d.SaveRetrieveEventInfo(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retrievals, err := d.LoadRetrieveCountOfAsset("cid-a", now-600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retrievals != 2 {
+		t.Fatalf("retrieve count %d, expected 2", retrievals)
+	}
+
+	if err := d.SaveRetrieveEventInfo(&types.RetrieveEvent{TokenID: "token-b", NodeID: "node", ClientID: "client", CID: "cid-b", CreatedTime: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	retrieveCounts, err := d.LoadRetrieveCounts(now - 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retrieveCounts) != 2 || retrieveCounts["cid-a"] != 2 || retrieveCounts["cid-b"] != 1 {
+		t.Fatalf("unexpected retrieve counts %v", retrieveCounts)
+	}
+
+	for _, serverID := range []dtypes.ServerID{"lost-server", "third-server"} {
+		if err := InitTables(d, serverID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for hash, serverID := range map[string]dtypes.ServerID{"hash-l1": "lost-server", "hash-l2": "lost-server", "hash-t": "third-server"} {
+		if err := d.SaveAssetRecord(&types.AssetRecord{Hash: hash, CID: hash, State: "Servicing", ServerID: serverID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the asset of another scheduler is not moved
+	moved, err := d.MoveAssetStates([]string{"hash-l1", "hash-t"}, "lost-server", "test-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(moved) != 1 || moved[0] != "hash-l1" {
+		t.Fatalf("unexpected moved assets %v", moved)
+	}
+
+	for hash, serverID := range map[string]dtypes.ServerID{"hash-l1": "test-server", "hash-l2": "lost-server", "hash-t": "third-server"} {
+		record, err := d.LoadAssetRecord(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.ServerID != serverID || record.State != "Servicing" {
+			t.Fatalf("asset %s of scheduler %s state %s, expected scheduler %s", hash, record.ServerID, record.State, serverID)
+		}
+	}
+
+	replicas := []*types.ReplicaInfo{
+		{Hash: "hash-a", NodeID: "node-d", Status: types.ReplicaStatusSucceeded},
+		{Hash: "hash-b", NodeID: "node-d", Status: types.ReplicaStatusFailed},
+	}
+	if err := d.SaveReplicasStatus(replicas); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := d.DeleteAssetRecordsOfNode("node-d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Hash != "hash-a" || removed[0].Cid != "hash-a" {
+		t.Fatalf("unexpected removed replicas %+v", removed)
+	}
+
+	tokenTime := time.Now()
+	workloads := []*types.WorkloadRecord{
+		{TokenPayload: types.TokenPayload{ID: "token-old", NodeID: "node-w", ClientID: "client-w", AssetCID: "cid-w", CreatedTime: tokenTime, Expiration: tokenTime.Add(time.Hour)}},
+		{TokenPayload: types.TokenPayload{ID: "token-new", NodeID: "node-w", ClientID: "client-w", AssetCID: "cid-w", CreatedTime: tokenTime, Expiration: tokenTime.Add(time.Hour)}},
+	}
+	if err := d.SaveWorkloadRecord(workloads); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range workloads {
+		if err := d.MarkWorkloadInvalid(w, types.WorkloadReasonTokenReuse); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := d.db.Exec(d.db.Rebind(fmt.Sprintf(`UPDATE %s SET created_time=? WHERE token_id=?`, workloadInvalidTable)), "2000-01-01 00:00:00", "token-old"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.DeleteInvalidWorkloads(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.LoadWorkloadRecord("token-old"); err == nil {
+		t.Fatal("expired invalid workload is not deleted")
+	}
+	if _, err := d.LoadWorkloadInvalidReason("token-old"); err == nil {
+		t.Fatal("expired invalid reason is not deleted")
+	}
+	if reason, err := d.LoadWorkloadInvalidReason("token-new"); err != nil || reason != types.WorkloadReasonTokenReuse {
+		t.Fatalf("invalid reason %s error %v", reason, err)
+	}
+
+	for nodeID, nodeType := range map[string]types.NodeType{"node-edge": types.NodeEdge, "node-candidate": types.NodeCandidate} {
+		info := &types.NodeInfo{NATType: "FullConeNAT", SchedulerID: "test-server"}
+		info.NodeID = nodeID
+		if err := d.SaveNodeInfo(info); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.SaveNodeRegisterInfos([]*types.ActivationDetail{{NodeID: nodeID, NodeType: nodeType}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.UpdateOnlineDuration([]*types.NodeSnapshot{{NodeID: "node-edge", OnlineDuration: 30, LastSeen: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	rewardInfos, err := d.LoadRewardNodeInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewardInfos) != 2 {
+		t.Fatalf("unexpected reward node infos %+v", rewardInfos)
+	}
+	for _, info := range rewardInfos {
+		if info.NodeID == "node-edge" && (info.NodeType != types.NodeEdge || info.OnlineDuration != 30 || info.FirstLoginTime.IsZero()) {
+			t.Fatalf("unexpected reward node info %+v", info)
+		}
+		if info.NodeID == "node-candidate" && info.NodeType != types.NodeCandidate {
+			t.Fatalf("unexpected reward node info %+v", info)
+		}
+	}
+}
@@ -170,7 +170,7 @@ var cUserAssetTable = `
 		expiration        DATETIME     DEFAULT CURRENT_TIMESTAMP,
 		password          VARCHAR(128) DEFAULT '' ,		
 		group_id          INT          DEFAULT 0,
-		PRIMARY KEY (hash,user_id),
+		PRIMARY KEY (hash,user_id,group_id,asset_name),
 		KEY idx_user_id (user_id),
 		KEY idx_group_id (group_id)
     ) ENGINE=InnoDB COMMENT='user asset';`
@@ -213,7 +213,8 @@ var cRetrieveEventTable = `
 		end_time        INT            DEFAULT 0,
 	    profit          DECIMAL(14, 6) DEFAULT 0,
 		PRIMARY KEY (token_id),
-		KEY idx_node_id (node_id)
+		KEY idx_node_id (node_id),
+		KEY idx_cid_created_time (cid, created_time)
 	) ENGINE=InnoDB COMMENT='asset retrieve event';`
 
 var cAssetVisitCountTable = `
@@ -251,3 +252,227 @@ var cAWSDataTable = `
 		size            FLOAT        DEFAULT 0,
 		PRIMARY KEY (bucket)
     ) ENGINE=InnoDB COMMENT='aws data';`
+
+var cAssetErasureTable = `
+    CREATE TABLE if not exists %s (
+	    hash          VARCHAR(128) NOT NULL,
+		data_shards   INT          DEFAULT 0,
+		parity_shards INT          DEFAULT 0,
+		stripe_size   INT          DEFAULT 0,
+		shard_size    BIGINT       DEFAULT 0,
+		car_size      BIGINT       DEFAULT 0,
+		PRIMARY KEY (hash)
+    ) ENGINE=InnoDB COMMENT='erasure coding info of asset';`
+
+var cReplicaShardTable = `
+    CREATE TABLE if not exists %s (
+	    hash          VARCHAR(128) NOT NULL,
+		node_id       VARCHAR(128) NOT NULL,
+		shard_index   INT          DEFAULT 0,
+		PRIMARY KEY (hash,node_id),
+		KEY idx_node_id (node_id)
+    ) ENGINE=InnoDB COMMENT='erasure coded shard held by node';`
+
+var cLifecyclePolicyTable = `
+    CREATE TABLE if not exists %s (
+		id             INT UNSIGNED AUTO_INCREMENT,
+		name           VARCHAR(64)  DEFAULT '',
+		delete_expired BOOLEAN      DEFAULT false,
+	    created_time   DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id)
+    ) ENGINE=InnoDB COMMENT='asset lifecycle policy';`
+
+var cLifecycleRuleTable = `
+    CREATE TABLE if not exists %s (
+		policy_id      INT UNSIGNED NOT NULL,
+		after_days     INT          DEFAULT 0,
+		max_retrievals INT          DEFAULT 0,
+		edge_replicas  INT          DEFAULT 0,
+		delete_asset   BOOLEAN      DEFAULT false,
+		KEY idx_policy_id (policy_id)
+    ) ENGINE=InnoDB COMMENT='asset lifecycle rule';`
+
+var cAssetLifecycleTable = `
+    CREATE TABLE if not exists %s (
+	    hash           VARCHAR(128) NOT NULL,
+		policy_id      INT UNSIGNED NOT NULL,
+		PRIMARY KEY (hash),
+		KEY idx_policy_id (policy_id)
+    ) ENGINE=InnoDB COMMENT='lifecycle policy of asset';`
+
+var cGroupLifecycleTable = `
+    CREATE TABLE if not exists %s (
+	    user_id        VARCHAR(128) NOT NULL,
+		group_id       INT          NOT NULL,
+		policy_id      INT UNSIGNED NOT NULL,
+		PRIMARY KEY (user_id,group_id),
+		KEY idx_policy_id (policy_id)
+    ) ENGINE=InnoDB COMMENT='lifecycle policy of user asset group';`
+
+var cAssetScalingTable = `
+    CREATE TABLE if not exists %s (
+	    hash            VARCHAR(128) NOT NULL,
+		base_replicas   INT          DEFAULT 0,
+		scaled_replicas INT          DEFAULT 0,
+		updated_time    DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash)
+    ) ENGINE=InnoDB COMMENT='edge replicas added by popularity scaling';`
+
+var cAssetCommitmentTable = `
+    CREATE TABLE if not exists %s (
+	    hash         VARCHAR(128) NOT NULL,
+	    node_id      VARCHAR(128) NOT NULL,
+		root         VARCHAR(64)  NOT NULL,
+		leaves       INT          DEFAULT 0,
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash,node_id),
+		KEY idx_node_id (node_id)
+    ) ENGINE=InnoDB COMMENT='merkle commitments of asset replicas';`
+
+var cSchedulerShardTable = `
+    CREATE TABLE if not exists %s (
+	    server_id    VARCHAR(128) NOT NULL,
+		area_id      VARCHAR(64)  NOT NULL,
+		updated_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (server_id),
+		KEY idx_area_id (area_id)
+    ) ENGINE=InnoDB COMMENT='schedulers which own asset state tables';`
+
+var cEdgeReleaseTable = `
+    CREATE TABLE if not exists %s (
+	    node_type       INT          NOT NULL,
+		signature       VARCHAR(128) NOT NULL,
+		rollout_percent INT          DEFAULT 100,
+		rollout_areas   VARCHAR(512) DEFAULT '',
+		rollout_nodes   TEXT,
+		PRIMARY KEY (node_type)
+    ) ENGINE=InnoDB COMMENT='signature and rollout stage of edge update';`
+
+var cEdgePatchTable = `
+    CREATE TABLE if not exists %s (
+	    node_type    INT          NOT NULL,
+		from_version VARCHAR(32)  NOT NULL,
+		download_url VARCHAR(256) NOT NULL,
+		hash         VARCHAR(128) NOT NULL,
+		PRIMARY KEY (node_type,from_version)
+    ) ENGINE=InnoDB COMMENT='binary patches from previous versions of edge update';`
+
+var cWorkloadInvalidTable = `
+    CREATE TABLE if not exists %s (
+	    token_id     VARCHAR(128) NOT NULL,
+		node_id      VARCHAR(128) NOT NULL,
+		client_id    VARCHAR(128) NOT NULL,
+		reason       INT          DEFAULT 0,
+		created_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (token_id),
+		KEY idx_node_id (node_id),
+		KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='reason of invalid workload';`
+
+var cRewardModelTable = `
+    CREATE TABLE if not exists %s (
+	    version      INT      NOT NULL,
+		model        TEXT     NOT NULL,
+		start_time   DATETIME DEFAULT NULL,
+		created_time DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (version),
+		KEY idx_start_time (start_time)
+    ) ENGINE=InnoDB COMMENT='versioned reward models';`
+
+var cPartialReplicaTable = `
+    CREATE TABLE if not exists %s (
+	    hash         VARCHAR(128) NOT NULL,
+		node_id      VARCHAR(128) NOT NULL,
+		blocks       INT          DEFAULT 0,
+		size         BIGINT       DEFAULT 0,
+		updated_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash,node_id),
+		KEY idx_node_id (node_id)
+    ) ENGINE=InnoDB COMMENT='assets partially held by the read-through cache of edges';`
+
+var cNameRecordTable = `
+    CREATE TABLE if not exists %s (
+	    name         VARCHAR(128)    NOT NULL,
+		value        VARCHAR(1024)   NOT NULL,
+		sequence     BIGINT UNSIGNED DEFAULT 0,
+		validity     DATETIME        NOT NULL,
+		ttl          BIGINT          DEFAULT 0,
+		public_key   VARBINARY(64)   NOT NULL,
+		signature    VARBINARY(128)  NOT NULL,
+		user_id      VARCHAR(128)    DEFAULT '',
+		updated_time DATETIME        DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		PRIMARY KEY (name),
+		KEY idx_user_id (user_id)
+    ) ENGINE=InnoDB COMMENT='signed records of the mutable names';`
+
+var cUserPublicKeyTable = `
+    CREATE TABLE if not exists %s (
+	    user_id      VARCHAR(128)  NOT NULL,
+		public_key   VARBINARY(32) NOT NULL,
+		updated_time DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id)
+    ) ENGINE=InnoDB COMMENT='public keys of users for the encrypted assets';`
+
+var cAssetKeyEnvelopeTable = `
+    CREATE TABLE if not exists %s (
+	    hash         VARCHAR(128)   NOT NULL,
+		user_id      VARCHAR(128)   NOT NULL,
+		shared_by    VARCHAR(128)   NOT NULL,
+		envelope     VARBINARY(256) NOT NULL,
+		created_time DATETIME       DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hash,user_id),
+		KEY idx_user_id (user_id)
+    ) ENGINE=InnoDB COMMENT='data keys of encrypted assets wrapped for users';`
+
+var cUserEgressTable = `
+    CREATE TABLE if not exists %s (
+	    user_id      VARCHAR(128) NOT NULL,
+		month        VARCHAR(8)   NOT NULL,
+		traffic      BIGINT       DEFAULT 0,
+		updated_time DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id,month)
+    ) ENGINE=InnoDB COMMENT='monthly egress traffic of users';`
+
+var cUserEgressPlanTable = `
+    CREATE TABLE if not exists %s (
+	    user_id       VARCHAR(128) NOT NULL,
+		monthly_quota BIGINT       DEFAULT 0,
+		limit_rate    BIGINT       DEFAULT 0,
+		updated_time  DATETIME     DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id)
+    ) ENGINE=InnoDB COMMENT='egress plans of users overriding the vip tiers';`
+
+var cPresignedLinkTable = `
+    CREATE TABLE if not exists %s (
+	    id            VARCHAR(128)  NOT NULL,
+		user_id       VARCHAR(128)  NOT NULL,
+		hash          VARCHAR(128)  NOT NULL,
+		expiration    DATETIME      NOT NULL,
+		max_downloads INT           DEFAULT 0,
+		downloads     INT           DEFAULT 0,
+		allowed_ips   VARCHAR(1024) DEFAULT '',
+		has_password  BOOLEAN       DEFAULT false,
+		password_hash VARCHAR(128)  DEFAULT '',
+		revoked       BOOLEAN       DEFAULT false,
+		created_time  DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_user_hash (user_id,hash),
+		KEY idx_hash (hash)
+    ) ENGINE=InnoDB COMMENT='revocable presigned urls of the user assets';`
+
+var cAuditLogTable = `
+    CREATE TABLE if not exists %s (
+	    id           INT UNSIGNED  AUTO_INCREMENT,
+		caller       VARCHAR(128)  DEFAULT '',
+		role         VARCHAR(128)  DEFAULT '',
+		remote_addr  VARCHAR(128)  DEFAULT '',
+		method       VARCHAR(128)  NOT NULL,
+		args         TEXT,
+		success      BOOLEAN       DEFAULT false,
+		result       VARCHAR(1024) DEFAULT '',
+		created_time DATETIME      DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY idx_caller (caller),
+		KEY idx_method (method),
+		KEY idx_created_time (created_time)
+    ) ENGINE=InnoDB COMMENT='audit logs of the privileged calls of the scheduler api';`
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
@@ -64,8 +65,9 @@ func (n *SQLDB) DeleteAssetUser(hash, userID string) error {
 		}
 	}()
 
+	// the asset may be saved with several names of the user
 	var size int64
-	query := fmt.Sprintf("SELECT total_size FROM %s WHERE hash=? AND user_id=?", userAssetTable)
+	query := fmt.Sprintf("SELECT COALESCE(SUM(total_size), 0) FROM %s WHERE hash=? AND user_id=?", userAssetTable)
 	err = tx.Get(&size, query, hash, userID)
 	if err != nil {
 		return err
@@ -92,10 +94,58 @@ func (n *SQLDB) DeleteAssetUser(hash, userID string) error {
 	return tx.Commit()
 }
 
+// DeleteAssetUserByName delete one name of the user asset and the size of it
+func (n *SQLDB) DeleteAssetUserByName(hash, userID, assetName string, groupID int) error {
+	tx, err := n.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("DeleteAssetUserByName Rollback err:%s", err.Error())
+		}
+	}()
+
+	var size int64
+	query := fmt.Sprintf("SELECT total_size FROM %s WHERE hash=? AND user_id=? AND group_id=? AND BINARY asset_name=?", userAssetTable)
+	err = tx.Get(&size, query, hash, userID, groupID, assetName)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE hash=? AND user_id=? AND group_id=? AND BINARY asset_name=?`, userAssetTable)
+	_, err = tx.Exec(query, hash, userID, groupID, assetName)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(
+		`UPDATE %s SET used_storage_size=used_storage_size-? WHERE user_id=?`, userInfoTable)
+	_, err = tx.Exec(query, size, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CountAssetNamesOfUser returns the number of names the user saves the asset with
+func (n *SQLDB) CountAssetNamesOfUser(hash, userID string) (int64, error) {
+	var total int64
+	query := fmt.Sprintf(`SELECT count(hash) FROM %s WHERE hash=? AND user_id=?`, userAssetTable)
+	if err := n.db.Get(&total, query, hash, userID); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 // ListUsersForAsset Get a list of users by asset
 func (n *SQLDB) ListUsersForAsset(hash string) ([]string, error) {
 	var infos []string
-	query := fmt.Sprintf("SELECT user_id FROM %s WHERE hash=?", userAssetTable)
+	query := fmt.Sprintf("SELECT DISTINCT user_id FROM %s WHERE hash=?", userAssetTable)
 
 	err := n.db.Select(&infos, query, hash)
 	if err != nil {
@@ -198,7 +248,7 @@ func (n *SQLDB) GetAssetExpiration(hash, userID string) (time.Time, error) {
 func (n *SQLDB) GetUserStorageSize(user string) (int64, error) {
 	var hashes []string
 
-	query := fmt.Sprintf("SELECT hash FROM %s WHERE user_id=? ", userAssetTable)
+	query := fmt.Sprintf("SELECT DISTINCT hash FROM %s WHERE user_id=? ", userAssetTable)
 	err := n.db.Select(&hashes, query, user)
 	if err != nil {
 		return 0, err
@@ -467,3 +517,48 @@ func (n *SQLDB) DeleteAssetGroup(userID string, gid int) error {
 
 	return tx.Commit()
 }
+
+// LoadAssetGroupByName load the group of user with the name under the parent
+func (n *SQLDB) LoadAssetGroupByName(userID, name string, parent int) (*types.AssetGroup, error) {
+	var info types.AssetGroup
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id=? AND parent=? AND BINARY name=? LIMIT 1", userAssetGroupTable)
+	if err := n.db.Get(&info, query, userID, parent, name); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// LoadUserAssetByName load the asset of user with the name in the group
+func (n *SQLDB) LoadUserAssetByName(userID, name string, groupID int) (*types.UserAssetDetail, error) {
+	var info types.UserAssetDetail
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id=? AND group_id=? AND BINARY asset_name=? LIMIT 1", userAssetTable)
+	if err := n.db.Get(&info, query, userID, groupID, name); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// ListUserAssetsByName list the assets of user in the group sorted by name,
+// the name of assets starts with prefix and is greater than startAfter
+func (n *SQLDB) ListUserAssetsByName(userID string, groupID int, prefix, startAfter string, limit int) ([]*types.UserAssetDetail, error) {
+	if limit > loadAssetRecordsDefaultLimit {
+		limit = loadAssetRecordsDefaultLimit
+	}
+
+	var infos []*types.UserAssetDetail
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=? AND group_id=? AND asset_name LIKE BINARY ? AND BINARY asset_name>? 
+	        ORDER BY BINARY asset_name LIMIT ?`, userAssetTable)
+	err := n.db.Select(&infos, query, userID, groupID, escapeLike(prefix)+"%", startAfter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// escapeLike escapes the wildcard characters of LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
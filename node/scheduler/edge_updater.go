@@ -2,45 +2,87 @@ package scheduler
 
 import (
 	"context"
+	"crypto/ed25519"
 
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"golang.org/x/xerrors"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/build"
 )
 
 // EdgeUpdateManager manages information about edge node updates.
 type EdgeUpdateManager struct {
 	db          *db.SQLDB
 	updateInfos map[int]*api.EdgeUpdateConfig
+	areaID      string
+	// releaseKey verifies the signatures of the updates, no update is accepted without it
+	releaseKey ed25519.PublicKey
 }
 
 // NewEdgeUpdateManager creates a new EdgeUpdateManager with the given SQL database connection.
-func NewEdgeUpdateManager(db *db.SQLDB) (*EdgeUpdateManager, error) {
+func NewEdgeUpdateManager(db *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc) (*EdgeUpdateManager, error) {
+	cfg, err := configFunc()
+	if err != nil {
+		return nil, err
+	}
+
 	updater := &EdgeUpdateManager{
-		db: db,
+		db:     db,
+		areaID: cfg.AreaID,
+	}
+
+	keyHex := cfg.ReleaseKey
+	if keyHex == "" {
+		keyHex = build.ReleaseKey
+	}
+
+	if keyHex == "" {
+		log.Warn("release key is not set, the edge updates are refused")
+	} else if updater.releaseKey, err = api.ParseReleaseKey(keyHex); err != nil {
+		return nil, err
 	}
 
 	return updater, nil
 }
 
 // GetEdgeUpdateConfigs  returns the map of edge node update information.
+// The updates not rolled out to the area of the scheduler are only returned to the admin.
 func (eu *EdgeUpdateManager) GetEdgeUpdateConfigs(ctx context.Context) (map[int]*api.EdgeUpdateConfig, error) {
-	if eu.updateInfos != nil {
+	if eu.updateInfos == nil {
+		appUpdateInfo, err := eu.db.LoadEdgeUpdateConfigs()
+		if err != nil {
+			log.Errorf("GetEdgeUpdateConfigs error:%s", err)
+			return nil, err
+		}
+		eu.updateInfos = appUpdateInfo
+	}
+
+	if api.HasPerm(ctx, api.RoleDefault, api.RoleAdmin) {
 		return eu.updateInfos, nil
 	}
 
-	appUpdateInfo, err := eu.db.LoadEdgeUpdateConfigs()
-	if err != nil {
-		log.Errorf("GetEdgeUpdateConfigs error:%s", err)
-		return nil, err
+	out := make(map[int]*api.EdgeUpdateConfig, len(eu.updateInfos))
+	for nodeType, info := range eu.updateInfos {
+		if info.InArea(eu.areaID) {
+			out[nodeType] = info
+		}
 	}
-	eu.updateInfos = appUpdateInfo
 
-	return eu.updateInfos, nil
+	return out, nil
 }
 
-// SetEdgeUpdateConfig sets the EdgeUpdateConfig for the given node type.
+// SetEdgeUpdateConfig sets the EdgeUpdateConfig for the given node type, the update must be signed by the release key.
 func (eu *EdgeUpdateManager) SetEdgeUpdateConfig(ctx context.Context, info *api.EdgeUpdateConfig) error {
+	if err := info.VerifySignature(eu.releaseKey); err != nil {
+		return xerrors.Errorf("verify edge update %s %s error %w", info.AppName, info.Version.String(), err)
+	}
+
+	if info.RolloutPercent < 0 || info.RolloutPercent > 100 {
+		return xerrors.Errorf("rollout percent %d is out of range [0, 100]", info.RolloutPercent)
+	}
+
 	if eu.updateInfos == nil {
 		eu.updateInfos = make(map[int]*api.EdgeUpdateConfig)
 	}
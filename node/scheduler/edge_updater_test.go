@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/sqldb"
+)
+
+func TestSetEdgeUpdateConfig(t *testing.T) {
+	client, err := sqldb.NewDB("sqlite://" + filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck // ignore error
+
+	d, err := db.NewSQLDB(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InitTables(d, "test-server"); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	release := &api.EdgeUpdateConfig{NodeType: 1, AppName: "titan-edge", Version: api.Version(0x000201), Hash: "abc", RolloutPercent: 100}
+	release.Signature = hex.EncodeToString(ed25519.Sign(priv, release.SignedMessage()))
+
+	// no update is accepted without the release key
+	eu := &EdgeUpdateManager{db: d}
+	if err := eu.SetEdgeUpdateConfig(ctx, release); err == nil {
+		t.Fatal("update is accepted without the release key")
+	}
+
+	eu.releaseKey = pub
+	if err := eu.SetEdgeUpdateConfig(ctx, release); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := *release
+	tampered.Hash = "def"
+	if err := eu.SetEdgeUpdateConfig(ctx, &tampered); err == nil {
+		t.Fatal("tampered update is accepted")
+	}
+
+	if eu.updateInfos[1].Hash != "abc" {
+		t.Fatalf("unexpected update %+v", eu.updateInfos[1])
+	}
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+// egressMonth returns the month the egress traffic at t is accounted to
+func egressMonth(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// userEgress returns the egress quota of the user and the traffic used in this month,
+// the plan of the user overrides the tier given by the vip state
+func (s *Scheduler) userEgress(userID string, enableVIP bool) (*types.UserEgress, error) {
+	egress := &types.UserEgress{UserID: userID, Month: egressMonth(time.Now())}
+
+	plan, err := s.db.LoadUserEgressPlan(userID)
+	switch err {
+	case nil:
+		egress.MonthlyQuota = plan.MonthlyQuota
+		egress.LimitRate = plan.LimitRate
+	case sql.ErrNoRows:
+		egress.MonthlyQuota = s.SchedulerCfg.UserFreeMonthlyEgress
+		egress.LimitRate = s.SchedulerCfg.UserFreeLimitRate
+		if enableVIP {
+			egress.MonthlyQuota = s.SchedulerCfg.UserVipMonthlyEgress
+			egress.LimitRate = s.SchedulerCfg.UserVipLimitRate
+		}
+	default:
+		return nil, err
+	}
+
+	egress.UsedTraffic, err = s.db.LoadUserEgressTraffic(userID, egress.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	return egress, nil
+}
+
+// GetUserEgress get the egress quota of user and the traffic used in this month
+func (s *Scheduler) GetUserEgress(ctx context.Context, userID string) (*types.UserEgress, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	userInfo, err := s.loadUserInfo(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userEgress(userID, userInfo.EnableVIP)
+}
+
+// SetUserEgressPlan sets the egress plan of user, it overrides the tier given by the vip state
+func (s *Scheduler) SetUserEgressPlan(ctx context.Context, plan *types.UserEgressPlan) error {
+	if plan == nil || len(plan.UserID) == 0 {
+		return xerrors.New("user id of plan can not be empty")
+	}
+
+	if plan.MonthlyQuota < 0 || plan.LimitRate < 0 {
+		return xerrors.Errorf("invalid plan, monthly quota %d, limit rate %d", plan.MonthlyQuota, plan.LimitRate)
+	}
+
+	return s.db.SaveUserEgressPlan(plan)
+}
+
+// RemoveUserEgressPlan removes the egress plan of user, the tier given by the vip state is used again
+func (s *Scheduler) RemoveUserEgressPlan(ctx context.Context, userID string) error {
+	return s.db.DeleteUserEgressPlan(userID)
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// publicKeySize is the size of the X25519 public key
+	publicKeySize = 32
+	// maxEnvelopeSize is the max size of the wrapped data key
+	maxEnvelopeSize = 256
+)
+
+// SetUserPublicKey sets the X25519 public key of the user, the data keys of the encrypted assets are wrapped for it
+func (s *Scheduler) SetUserPublicKey(ctx context.Context, userID string, publicKey []byte) error {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	if len(publicKey) != publicKeySize {
+		return xerrors.Errorf("invalid public key size %d", len(publicKey))
+	}
+
+	return s.db.SaveUserPublicKey(userID, publicKey)
+}
+
+// GetUserPublicKey retrieves the X25519 public key of the user
+func (s *Scheduler) GetUserPublicKey(ctx context.Context, userID string) ([]byte, error) {
+	publicKey, err := s.db.LoadUserPublicKey(userID)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("user %s has no public key", userID)
+	}
+
+	return publicKey, err
+}
+
+// SaveAssetKeyEnvelopes saves the data key of the encrypted asset wrapped for the users
+func (s *Scheduler) SaveAssetKeyEnvelopes(ctx context.Context, userID, assetCID string, envelopes []*types.AssetKeyEnvelope) error {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.db.AssetExistsOfUser(hash, userID)
+	if err != nil {
+		return err
+	}
+
+	// the asset is uploaded if any user owns it
+	uploaded := owner
+	if !owner {
+		users, err := s.db.ListUsersForAsset(hash)
+		if err != nil {
+			return err
+		}
+		uploaded = len(users) > 0
+	}
+
+	for _, envelope := range envelopes {
+		if len(envelope.UserID) == 0 {
+			return xerrors.New("user id of envelope can not be empty")
+		}
+
+		if len(envelope.Envelope) == 0 || len(envelope.Envelope) > maxEnvelopeSize {
+			return xerrors.Errorf("invalid envelope size %d", len(envelope.Envelope))
+		}
+
+		// the user can save its own key before the asset is uploaded or if it owns the asset,
+		// the owner can share the key with anyone, the users holding the key can share it with the users who have no key
+		if envelope.UserID == userID {
+			if uploaded && !owner {
+				return xerrors.Errorf("user %s does not own the uploaded asset %s", userID, assetCID)
+			}
+		} else if !owner {
+			if err := s.checkKeyShare(hash, userID, envelope.UserID); err != nil {
+				return err
+			}
+		}
+
+		envelope.Hash = hash
+		envelope.SharedBy = userID
+	}
+
+	return s.db.SaveAssetKeyEnvelopes(envelopes)
+}
+
+// checkKeyShare checks the user who does not own the asset holds the key and the recipient has no key
+func (s *Scheduler) checkKeyShare(hash, userID, recipient string) error {
+	if _, err := s.db.LoadAssetKeyEnvelope(hash, userID); err == sql.ErrNoRows {
+		return xerrors.Errorf("user %s neither owns asset %s nor holds its key", userID, hash)
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := s.db.LoadAssetKeyEnvelope(hash, recipient); err == nil {
+		return xerrors.Errorf("user %s already holds the key of asset %s", recipient, hash)
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	return nil
+}
+
+// GetAssetKeyEnvelope retrieves the data key of the encrypted asset wrapped for the user
+func (s *Scheduler) GetAssetKeyEnvelope(ctx context.Context, userID, assetCID string) (*types.AssetKeyEnvelope, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := s.db.LoadAssetKeyEnvelope(hash, userID)
+	if err == sql.ErrNoRows {
+		return nil, xerrors.Errorf("user %s holds no key of asset %s", userID, assetCID)
+	}
+
+	return envelope, err
+}
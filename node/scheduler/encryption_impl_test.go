@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/sqldb"
+)
+
+func TestSaveAssetKeyEnvelopes(t *testing.T) {
+	client, err := sqldb.NewDB("sqlite://" + filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck // ignore error
+
+	d, err := db.NewSQLDB(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InitTables(d, "test-server"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scheduler{EdgeUpdateManager: &EdgeUpdateManager{db: d}}
+	ctx := context.Background()
+	assetCID := "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	envelope := func(userID string) []*types.AssetKeyEnvelope {
+		return []*types.AssetKeyEnvelope{{UserID: userID, Envelope: []byte("wrapped key of " + userID)}}
+	}
+
+	// the key is saved before the asset is uploaded
+	if err := s.SaveAssetKeyEnvelopes(ctx, "owner", assetCID, envelope("owner")); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SaveAssetUser(hash, "owner", "asset", "file", 10, time.Now().Add(time.Hour), "", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the owner replaces its key and shares the key
+	if err := s.SaveAssetKeyEnvelopes(ctx, "owner", assetCID, append(envelope("owner"), envelope("friend")...)); err != nil {
+		t.Fatal(err)
+	}
+
+	// other users can not save their own key of the uploaded asset
+	if err := s.SaveAssetKeyEnvelopes(ctx, "other", assetCID, envelope("other")); err == nil {
+		t.Fatal("save self envelope of the asset of other user without error")
+	}
+
+	// the users holding the key share it with the users who have no key only
+	if err := s.SaveAssetKeyEnvelopes(ctx, "friend", assetCID, envelope("owner")); err == nil {
+		t.Fatal("replace the key of the owner without error")
+	}
+	if err := s.SaveAssetKeyEnvelopes(ctx, "friend", assetCID, envelope("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.GetAssetKeyEnvelope(ctx, "other", assetCID); err != nil || string(got.Envelope) != "wrapped key of other" || got.SharedBy != "friend" {
+		t.Fatalf("unexpected envelope %+v error %v", got, err)
+	}
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+)
+
+// eventBufferSize is the count of events buffered for a subscriber, the events are dropped when the buffer is full
+const eventBufferSize = 256
+
+// SubscribeEvents streams the asset, replica, node and validation events matching the filter until the context is done
+func (s *Scheduler) SubscribeEvents(ctx context.Context, filter *types.EventFilter) (<-chan *types.SchedulerEvent, error) {
+	// the node manager publishes the node itself, so the online and offline topics are told apart by the subscription
+	subOnline := s.PubSub.Sub(types.EventNodeOnline.String())
+	subOffline := s.PubSub.Sub(types.EventNodeOffline.String())
+	subEvents := s.PubSub.Sub(types.EventAssetState.String(), types.EventReplica.String(), types.EventValidationResult.String())
+
+	out := make(chan *types.SchedulerEvent, eventBufferSize)
+
+	go func() {
+		defer close(out)
+		defer s.unsubEvents(subOnline, subOffline, subEvents)
+
+		for {
+			var event *types.SchedulerEvent
+
+			select {
+			case msg := <-subOnline:
+				event = nodeStateEvent(msg, true)
+			case msg := <-subOffline:
+				event = nodeStateEvent(msg, false)
+			case msg := <-subEvents:
+				event, _ = msg.(*types.SchedulerEvent)
+			case <-ctx.Done():
+				return
+			}
+
+			if event == nil || !filter.Match(event) {
+				continue
+			}
+
+			select {
+			case out <- event:
+			default:
+				log.Warnf("event subscriber is too slow, drop %s event", event.Topic)
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// unsubEvents unsubscribes the channels, which are drained until closed so the publishers never block on them
+func (s *Scheduler) unsubEvents(subs ...chan interface{}) {
+	for _, sub := range subs {
+		go func(ch chan interface{}) {
+			for range ch {
+			}
+		}(sub)
+		s.PubSub.Unsub(sub)
+	}
+}
+
+// nodeStateEvent converts the node published on the online and offline topics to the streamed event
+func nodeStateEvent(msg interface{}, online bool) *types.SchedulerEvent {
+	n, ok := msg.(*node.Node)
+	if !ok || n == nil {
+		return nil
+	}
+
+	topic := types.EventNodeOffline
+	if online {
+		topic = types.EventNodeOnline
+	}
+
+	return &types.SchedulerEvent{
+		Topic: topic,
+		Time:  time.Now(),
+		Node:  &types.NodeStateEvent{NodeID: n.NodeID, Type: n.Type, Online: online},
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/scheduler/validation"
 	"github.com/Filecoin-Titan/titan/node/scheduler/workload"
 	"github.com/docker/go-units"
+	"github.com/filecoin-project/pubsub"
 	"github.com/quic-go/quic-go"
 
 	"go.uber.org/fx"
@@ -30,6 +31,7 @@ import (
 	"github.com/Filecoin-Titan/titan/node/common"
 	"github.com/Filecoin-Titan/titan/node/handler"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/Filecoin-Titan/titan/node/scheduler/sharding"
 	logging "github.com/ipfs/go-log/v2"
 
 	titanrsa "github.com/Filecoin-Titan/titan/node/rsa"
@@ -63,6 +65,9 @@ type Scheduler struct {
 	SetSchedulerConfigFunc dtypes.SetSchedulerConfigFunc
 	GetSchedulerConfigFunc dtypes.GetSchedulerConfigFunc
 	WorkloadManager        *workload.Manager
+	ShardingManager        *sharding.Manager
+	PubSub                 *pubsub.PubSub
+	AuditLogCleaner        *AuditLogCleaner
 
 	PrivateKey *rsa.PrivateKey
 	Transport  *quic.Transport
@@ -199,7 +204,7 @@ func (s *Scheduler) nodeConnect(ctx context.Context, opts *types.ConnectOptions,
 	cNode.DiskSpace = nodeInfo.DiskSpace
 	cNode.TitanDiskUsage = nodeInfo.TitanDiskUsage
 	cNode.DiskUsage = nodeInfo.DiskUsage
-	cNode.IncomeIncr = (cNode.CalculateMCx(s.NodeManager.TotalNetworkEdges) * 360)
+	cNode.IncomeIncr = (cNode.CalculateMCx(s.NodeManager.RewardModel(), s.NodeManager.TotalNetworkEdges) * 360)
 
 	if !alreadyConnect {
 		pStr, err := s.NodeManager.LoadNodePublicKey(nodeID)
@@ -369,7 +374,12 @@ func (s *Scheduler) SubmitUserWorkloadReport(ctx context.Context, r io.Reader) e
 		return xerrors.Errorf("decrypt error: %w", err)
 	}
 
-	return s.WorkloadManager.HandleUserWorkload(data, node)
+	clientIP, _, err := net.SplitHostPort(handler.GetRemoteAddr(ctx))
+	if err != nil {
+		log.Warnf("SubmitUserWorkloadReport split remote address error %s", err.Error())
+	}
+
+	return s.WorkloadManager.HandleUserWorkload(data, node, clientIP)
 }
 
 // SubmitNodeWorkloadReport submits report of workload for node Asset Download
@@ -412,7 +422,20 @@ func (s *Scheduler) GetRetrieveEventRecords(ctx context.Context, nodeID string,
 
 // GetWorkloadRecord retrieves workload result.
 func (s *Scheduler) GetWorkloadRecord(ctx context.Context, tokenID string) (*types.WorkloadRecord, error) {
-	return s.NodeManager.LoadWorkloadRecord(tokenID)
+	record, err := s.NodeManager.LoadWorkloadRecord(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Status == types.WorkloadStatusInvalid {
+		reason, err := s.NodeManager.LoadWorkloadInvalidReason(tokenID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		record.InvalidReason = reason
+	}
+
+	return record, nil
 }
 
 // ElectValidators elect validators
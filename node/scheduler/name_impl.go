@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/ipns"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+// maxNameTTL is the max time the resolvers cache a name record
+const maxNameTTL = 24 * time.Hour
+
+// PublishNameRecord publishes the signed record of a mutable name, the record replaces the record with a lower sequence
+func (s *Scheduler) PublishNameRecord(ctx context.Context, record *types.NameRecord) error {
+	if record == nil {
+		return xerrors.New("record can not be empty")
+	}
+
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		record.UserID = uID
+	}
+
+	if err := ipns.Verify(record); err != nil {
+		return err
+	}
+
+	// the signed validity is in seconds, the sub-seconds are rounded by some databases
+	record.Validity = record.Validity.Truncate(time.Second)
+
+	if ipns.Expired(record, time.Now()) {
+		return xerrors.Errorf("record of name %s is expired", record.Name)
+	}
+
+	if record.TTL > maxNameTTL {
+		return xerrors.Errorf("ttl %s is longer than %s", record.TTL, maxNameTTL)
+	}
+
+	old, err := s.db.LoadNameRecord(record.Name)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if old != nil {
+		if len(old.UserID) > 0 && old.UserID != record.UserID {
+			return xerrors.Errorf("name %s is published by other user", record.Name)
+		}
+
+		if record.Sequence <= old.Sequence {
+			return xerrors.Errorf("sequence %d of name %s is not greater than %d", record.Sequence, record.Name, old.Sequence)
+		}
+	}
+
+	// the sequence is checked again by the update, a record published at the same time may be saved first
+	saved, err := s.db.SaveNameRecord(record)
+	if err != nil {
+		return err
+	}
+
+	if !saved {
+		return xerrors.Errorf("sequence %d of name %s is not greater than the published one", record.Sequence, record.Name)
+	}
+
+	return nil
+}
+
+// ResolveName retrieves the record of the mutable name
+func (s *Scheduler) ResolveName(ctx context.Context, name string) (*types.NameRecord, error) {
+	record, err := s.db.LoadNameRecord(name)
+	if err != nil {
+		return nil, xerrors.Errorf("LoadNameRecord %s err:%s", name, err.Error())
+	}
+
+	if ipns.Expired(record, time.Now()) {
+		return nil, xerrors.Errorf("record of name %s is expired", name)
+	}
+
+	return record, nil
+}
+
+// ListNameRecords lists the records of the names published by the user
+func (s *Scheduler) ListNameRecords(ctx context.Context, userID string) ([]*types.NameRecord, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	return s.db.LoadNameRecordsOfUser(userID)
+}
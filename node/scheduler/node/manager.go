@@ -46,6 +46,10 @@ type Manager struct {
 	TotalNetworkEdges int // Number of edge nodes in the entire network (including those on other schedulers)
 
 	nodeIPs sync.Map
+
+	rewardLock   sync.Mutex
+	rewardModel  *types.RewardModel
+	rewardLoaded time.Time
 }
 
 // NewManager creates a new instance of the node manager
@@ -180,6 +184,7 @@ func (m *Manager) startNodeKeepaliveTimer() {
 
 		saveInfo := count%saveInfoInterval == 0
 		m.nodesKeepalive(saveInfo)
+		m.recordOnlineNodes()
 	}
 }
 
@@ -346,7 +351,7 @@ func (m *Manager) nodesKeepalive(isSave bool) {
 				node.OnlineDuration += int((saveInfoInterval * keepaliveTime) / time.Minute)
 
 				// add node mc
-				mc := node.CalculateMCx(m.TotalNetworkEdges)
+				mc := node.CalculateMCx(m.RewardModel(), m.TotalNetworkEdges)
 				// update client incomeIncr (Increase value every thirty minutes)
 				node.IncomeIncr = (mc * 360)
 
@@ -493,13 +498,32 @@ func (m *Manager) checkNodeDeactivate() {
 	}
 
 	for _, nodeID := range nodes {
-		err = m.DeleteAssetRecordsOfNode(nodeID)
+		replicas, err := m.DeleteAssetRecordsOfNode(nodeID)
 		if err != nil {
 			log.Errorf("DeleteAssetOfNode err:%s", err.Error())
+			continue
+		}
+
+		for _, replica := range replicas {
+			m.publishReplicaRemoved(nodeID, replica)
 		}
 	}
 }
 
+// publishReplicaRemoved publishes the replica removed with the deactivated node to the event subscribers
+func (m *Manager) publishReplicaRemoved(nodeID string, replica *types.NodeAssetInfo) {
+	m.notify.Pub(&types.SchedulerEvent{
+		Topic: types.EventReplica,
+		Time:  time.Now(),
+		Replica: &types.ReplicaChangedEvent{
+			Hash:   replica.Hash,
+			CID:    replica.Cid,
+			NodeID: nodeID,
+			Event:  types.ReplicaEventRemove,
+		},
+	}, types.EventReplica.String())
+}
+
 func (m *Manager) UpdateNodeDiskUsage(nodeID string, diskUsage float64) {
 	node := m.GetNode(nodeID)
 	if node == nil {
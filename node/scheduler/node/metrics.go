@@ -0,0 +1,76 @@
+package node
+
+import (
+	"context"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
+	"go.opencensus.io/tag"
+)
+
+// metricNodeTypes and metricNATTypes are the node types and nat types reported in the metrics
+var (
+	metricNodeTypes = []types.NodeType{types.NodeEdge, types.NodeCandidate, types.NodeValidator}
+	metricNATTypes  = []types.NatType{
+		types.NatTypeUnknown,
+		types.NatTypeNo,
+		types.NatTypeSymmetric,
+		types.NatTypeFullCone,
+		types.NatTypeRestricted,
+		types.NatTypePortRestricted,
+	}
+)
+
+type onlineNodesKey struct {
+	nodeType types.NodeType
+	natType  types.NatType
+}
+
+// recordOnlineNodes records the number of online nodes of every node type and nat type,
+// the candidates elected as validators of the scheduler are counted as validators
+func (m *Manager) recordOnlineNodes() {
+	validators := make(map[string]struct{})
+	if nodeIDs, err := m.LoadValidators(m.ServerID); err != nil {
+		log.Errorf("recordOnlineNodes LoadValidators err:%s", err.Error())
+	} else {
+		for _, nodeID := range nodeIDs {
+			validators[nodeID] = struct{}{}
+		}
+	}
+
+	counts := make(map[onlineNodesKey]int64)
+	count := func(key, value interface{}) bool {
+		node := value.(*Node)
+		if node == nil {
+			return true
+		}
+
+		nodeType := node.Type
+		if _, ok := validators[node.NodeID]; ok && nodeType == types.NodeCandidate {
+			nodeType = types.NodeValidator
+		}
+		counts[onlineNodesKey{nodeType: nodeType, natType: node.NATType}]++
+		return true
+	}
+
+	m.edgeNodes.Range(count)
+	m.candidateNodes.Range(count)
+
+	area := ""
+	if cfg, err := m.config(); err == nil {
+		area = cfg.AreaID
+	}
+
+	// every node type and nat type pair is recorded, so the gauge of a pair drops to 0 once its last node goes offline
+	ctx := context.Background()
+	for _, nodeType := range metricNodeTypes {
+		for _, natType := range metricNATTypes {
+			tags := []tag.Mutator{
+				tag.Upsert(metrics.NodeType, nodeType.String()),
+				tag.Upsert(metrics.NATType, natType.String()),
+				tag.Upsert(metrics.Area, area),
+			}
+			metrics.RecordWithTags(ctx, tags, metrics.OnlineNodes.M(counts[onlineNodesKey{nodeType: nodeType, natType: natType}]))
+		}
+	}
+}
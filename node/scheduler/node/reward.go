@@ -0,0 +1,57 @@
+package node
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/reward"
+)
+
+// rewardModelRefresh is the interval of reloading the active reward model
+const rewardModelRefresh = time.Minute
+
+// RewardModel returns the reward model of the current epoch,
+// the default model with the profits of the config is used if no model is scheduled
+func (m *Manager) RewardModel() *types.RewardModel {
+	m.rewardLock.Lock()
+	defer m.rewardLock.Unlock()
+
+	if m.rewardModel != nil && time.Since(m.rewardLoaded) < rewardModelRefresh {
+		return m.rewardModel
+	}
+
+	model, err := m.LoadActiveRewardModel(time.Now())
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Errorf("LoadActiveRewardModel err:%s", err.Error())
+		}
+		model = m.defaultRewardModel()
+	}
+
+	m.rewardModel = model
+	m.rewardLoaded = time.Now()
+	return model
+}
+
+// ResetRewardModel drops the cached model, so the next call reloads it
+func (m *Manager) ResetRewardModel() {
+	m.rewardLock.Lock()
+	defer m.rewardLock.Unlock()
+
+	m.rewardModel = nil
+}
+
+func (m *Manager) defaultRewardModel() *types.RewardModel {
+	model := reward.DefaultModel()
+
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return model
+	}
+
+	model.ValidationProfit = cfg.ValidationProfit
+	model.WorkloadProfit = cfg.WorkloadProfit
+	return model
+}
@@ -605,10 +605,8 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 		}
 
 		// edge
-		if cNode.Type != types.NodeCandidate {
-			if cNode.NATType != types.NatTypeNo || cNode.ExternalIP == "" {
-				continue
-			}
+		if cNode.Type != types.NodeCandidate && !cNode.Traversable() {
+			continue
 		}
 
 		token, tkPayload, err := cNode.Token(cid, uuid.NewString(), titanRsa, s.NodeManager.PrivateKey)
@@ -624,6 +622,7 @@ func (s *Scheduler) GetCandidateDownloadInfos(ctx context.Context, cid string) (
 			Address:   cNode.DownloadAddr(),
 			Tk:        token,
 			AWSBucket: aInfo.Note,
+			NatType:   cNode.NATType.String(),
 		}
 
 		sources = append(sources, source)
@@ -746,6 +745,16 @@ func nodeStatus(node *node.Node) types.NodeStatus {
 
 // VerifyTokenWithLimitCount verify token in limit count
 func (s *Scheduler) VerifyTokenWithLimitCount(ctx context.Context, token string) (*types.JWTPayload, error) {
+	return s.verifyTokenWithLimitCount(ctx, token, nil)
+}
+
+// VerifyPresignedToken verify the token of presigned url with the client ip and password of the download
+func (s *Scheduler) VerifyPresignedToken(ctx context.Context, token, clientIP, password string) (*types.JWTPayload, error) {
+	return s.verifyTokenWithLimitCount(ctx, token, &presignedDownload{clientIP: clientIP, password: password})
+}
+
+// verifyTokenWithLimitCount verify token in limit count, the download of presigned url is checked with pd
+func (s *Scheduler) verifyTokenWithLimitCount(ctx context.Context, token string, pd *presignedDownload) (*types.JWTPayload, error) {
 	jwtPayload, err := s.AuthVerify(ctx, token)
 	if err != nil {
 		return nil, &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: fmt.Sprintf("verify token error %s", err.Error())}
@@ -778,6 +787,41 @@ func (s *Scheduler) VerifyTokenWithLimitCount(ctx context.Context, token string)
 		return nil, err
 	}
 
+	egress, err := s.userEgress(payload.UserID, userInfo.EnableVIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if egress.MonthlyQuota > 0 && egress.UsedTraffic >= egress.MonthlyQuota {
+		return nil, &api.ErrWeb{Code: terrors.UserEgressOutOfQuota.Int(), Message: fmt.Sprintf("egress quota %d of month %s is exhausted", egress.MonthlyQuota, egress.Month)}
+	}
+
+	// the edge throttles the download with the limit rate and stops it at the remaining allowance,
+	// every token gets the whole remaining allowance, so the quota is a soft limit:
+	// the concurrent downloads may overrun it, the overrun is counted and the next tokens are refused
+	payload.LimitRate = egress.LimitRate
+	if egress.MonthlyQuota > 0 {
+		payload.TrafficLimit = egress.MonthlyQuota - egress.UsedTraffic
+	}
+
+	extend, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	jwtPayload.Extend = string(extend)
+
+	// the presigned url is limited by its own max downloads instead of the visit count of share link
+	if len(payload.LinkID) > 0 {
+		if pd == nil {
+			return nil, xerrors.New("presigned url must be verified with the client ip and password")
+		}
+
+		if err = s.countPresignedURLDownload(payload.LinkID, pd); err != nil {
+			return nil, err
+		}
+		return jwtPayload, nil
+	}
+
 	if userInfo.EnableVIP {
 		return jwtPayload, nil
 	}
@@ -958,7 +1002,7 @@ func (s *Scheduler) GetAssetsInBucket(ctx context.Context, nodeID string, bucket
 	}
 
 	if len(hashesBytes) == 0 {
-		return nil, fmt.Errorf("bucket %s not exist any asset", bucketID)
+		return nil, fmt.Errorf("bucket %d not exist any asset", bucketID)
 	}
 
 	assetHashes := make([]string, 0)
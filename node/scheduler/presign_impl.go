@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/xerrors"
+)
+
+// PresignAssetURL creates a revocable url to download the asset of the user
+func (s *Scheduler) PresignAssetURL(ctx context.Context, userID, assetCID string, opts *types.PresignOptions) (*types.PresignedURL, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	u := s.newUser(userID)
+	return u.PresignAsset(ctx, assetCID, opts, s, s.NodeManager)
+}
+
+// ListPresignedURLs lists the presigned urls of the asset of the user
+func (s *Scheduler) ListPresignedURLs(ctx context.Context, userID, assetCID string) ([]*types.PresignedLink, error) {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.db.ListPresignedLinks(userID, hash)
+}
+
+// RevokePresignedURL revokes the presigned url of the user
+func (s *Scheduler) RevokePresignedURL(ctx context.Context, userID, id string) error {
+	uID := handler.GetUserID(ctx)
+	if len(uID) > 0 {
+		userID = uID
+	}
+
+	err := s.db.RevokePresignedLink(id, userID)
+	if err == sql.ErrNoRows {
+		return xerrors.Errorf("user %s has no presigned url %s", userID, id)
+	}
+
+	return err
+}
+
+// GetRevokedPresignedURLs retrieves the ids of the revoked presigned urls which are not expired
+func (s *Scheduler) GetRevokedPresignedURLs(ctx context.Context) ([]string, error) {
+	return s.db.LoadRevokedPresignedLinks()
+}
+
+// presignedDownload is the client of the download with the presigned url
+type presignedDownload struct {
+	clientIP string
+	password string
+}
+
+// countPresignedURLDownload counts a download with the presigned url, the download is rejected
+// if the url is revoked, out of max downloads, or the client ip and password are not allowed
+func (s *Scheduler) countPresignedURLDownload(id string, pd *presignedDownload) error {
+	link, err := s.db.LoadPresignedLink(id)
+	if err == sql.ErrNoRows || (err == nil && link.Revoked) {
+		return &api.ErrWeb{Code: terrors.PresignedURLRevoked.Int(), Message: fmt.Sprintf("presigned url %s is revoked", id)}
+	} else if err != nil {
+		return err
+	}
+
+	if len(link.AllowedIPs) > 0 && !ipAllowed(pd.clientIP, strings.Split(link.AllowedIPs, ",")) {
+		return &api.ErrWeb{Code: terrors.PresignedURLClientNotAllowed.Int(), Message: fmt.Sprintf("client ip %s is not allowed by the presigned url", pd.clientIP)}
+	}
+
+	if link.HasPassword && bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(pd.password)) != nil {
+		return &api.ErrWeb{Code: terrors.PresignedURLPasswordIncorrect.Int(), Message: "password of the presigned url is incorrect"}
+	}
+
+	ok, err := s.db.AddPresignedLinkDownload(id)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return nil
+	}
+
+	// revoked between the load and the count
+	link, err = s.db.LoadPresignedLink(id)
+	if err == sql.ErrNoRows || (err == nil && link.Revoked) {
+		return &api.ErrWeb{Code: terrors.PresignedURLRevoked.Int(), Message: fmt.Sprintf("presigned url %s is revoked", id)}
+	} else if err != nil {
+		return err
+	}
+
+	return &api.ErrWeb{Code: terrors.PresignedURLOutOfMaxDownloads.Int(), Message: fmt.Sprintf("presigned url is out of max downloads %d", link.MaxDownloads)}
+}
+
+// ipAllowed checks the ip matches one of the allowed ips or cidrs
+func ipAllowed(ip string, allowed []string) bool {
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
+	}
+
+	for _, a := range allowed {
+		if _, ipNet, err := net.ParseCIDR(a); err == nil {
+			if ipNet.Contains(clientIP) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(a); allowedIP != nil && allowedIP.Equal(clientIP) {
+			return true
+		}
+	}
+
+	return false
+}
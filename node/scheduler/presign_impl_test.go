@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/sqldb"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCountPresignedURLDownload(t *testing.T) {
+	client, err := sqldb.NewDB("sqlite://" + filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck // ignore error
+
+	d, err := db.NewSQLDB(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InitTables(d, "test-server"); err != nil {
+		t.Fatal(err)
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link := &types.PresignedLink{
+		ID:           "link",
+		UserID:       "user",
+		Hash:         "hash",
+		Expiration:   time.Now().Add(time.Hour),
+		MaxDownloads: 2,
+		AllowedIPs:   "10.0.0.0/8,192.168.1.1",
+		HasPassword:  true,
+		PasswordHash: string(passwordHash),
+	}
+	if err := d.SavePresignedLink(link); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scheduler{EdgeUpdateManager: &EdgeUpdateManager{db: d}}
+	cases := []struct {
+		clientIP string
+		password string
+		ok       bool
+	}{
+		{"192.168.1.2", "secret", false},
+		{"10.1.2.3", "wrong", false},
+		{"10.1.2.3", "", false},
+		{"10.1.2.3", "secret", true},
+		{"192.168.1.1", "secret", true},
+		// out of max downloads
+		{"10.1.2.3", "secret", false},
+	}
+
+	for _, c := range cases {
+		err := s.countPresignedURLDownload("link", &presignedDownload{clientIP: c.clientIP, password: c.password})
+		if (err == nil) != c.ok {
+			t.Fatalf("%s with password %q: %v", c.clientIP, c.password, err)
+		}
+	}
+
+	// the rejected downloads are not counted
+	saved, err := d.LoadPresignedLink("link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Downloads != 2 {
+		t.Fatalf("downloads %d, expect 2", saved.Downloads)
+	}
+}
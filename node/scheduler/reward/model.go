@@ -0,0 +1,140 @@
+// Package reward computes the points of the nodes with the versioned reward models.
+package reward
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// DefaultModel returns the built-in model, which is used until a model is scheduled
+func DefaultModel() *types.RewardModel {
+	return &types.RewardModel{
+		Version: 1,
+		BandwidthCurve: []types.RewardCurvePoint{
+			{MiB: 0, Points: 0},
+			{MiB: 5, Points: 0.25},
+			{MiB: 50, Points: 36.25},
+			{MiB: 200, Points: 66.25},
+		},
+		NATMultipliers: map[string]float64{
+			types.NatTypeNo.String():             1.5,
+			types.NatTypeFullCone.String():       1.4,
+			types.NatTypeRestricted.String():     1.2,
+			types.NatTypePortRestricted.String(): 1.1,
+			types.NatTypeSymmetric.String():      1,
+		},
+		NetworkWeights: []types.RewardNetworkTier{
+			{MaxNodes: 2000, Weight: 1.7},
+			{MaxNodes: 5000, Weight: 1.6},
+			{MaxNodes: 10000, Weight: 1.5},
+			{MaxNodes: 15000, Weight: 1.4},
+			{MaxNodes: 25000, Weight: 1.3},
+			{MaxNodes: 35000, Weight: 1.2},
+			{MaxNodes: 50000, Weight: 1.1},
+		},
+		NetworkWeightDefault: 1,
+		Storage:              types.RewardStorageTerm{Factor: 12.5, CapGiB: 2000, Base: 0.1, MinGiB: 10},
+		OnlineIncrement:      0.00289,
+	}
+}
+
+// Validate checks the model is well formed
+func Validate(m *types.RewardModel) error {
+	if m.Version <= 0 {
+		return fmt.Errorf("invalid model version %d", m.Version)
+	}
+
+	if len(m.BandwidthCurve) == 0 {
+		return fmt.Errorf("bandwidth curve can not be empty")
+	}
+
+	for i, p := range m.BandwidthCurve {
+		if p.MiB < 0 || (i > 0 && p.MiB <= m.BandwidthCurve[i-1].MiB) {
+			return fmt.Errorf("bandwidth curve point %d is not in ascending order", i)
+		}
+	}
+
+	for i, t := range m.NetworkWeights {
+		if i > 0 && t.MaxNodes <= m.NetworkWeights[i-1].MaxNodes {
+			return fmt.Errorf("network tier %d is not in ascending order", i)
+		}
+	}
+
+	if m.Storage.MinGiB <= 0 {
+		return fmt.Errorf("storage min GiB must be positive")
+	}
+
+	return nil
+}
+
+// Input is the state of a node the points are computed from
+type Input struct {
+	BandwidthUp    int64
+	NATType        types.NatType
+	TitanDiskUsage float64
+	// NetworkSize is the count of edges in the network
+	NetworkSize int
+	// IPNodes is the count of nodes sharing the ip of the node
+	IPNodes int
+}
+
+// Income returns the points of the node in a validation
+func Income(m *types.RewardModel, in Input) float64 {
+	mb := bandwidthPoints(m, bToMB(float64(in.BandwidthUp)))
+	mn := m.NATMultipliers[in.NATType.String()]
+	mx := Weight(m, in.NetworkSize)
+	mbn := (mb * mn * mx) / float64(max(in.IPNodes, 1))
+
+	s := bToGB(in.TitanDiskUsage * m.Storage.Factor)
+	capped := min(s, m.Storage.CapGiB)
+	ms := mx * capped * (m.Storage.Base + 1/max(capped, m.Storage.MinGiB))
+
+	poa := mbn + ms
+	return math.Round(poa*1000000) / 1000000
+}
+
+// OnlineIncome returns the points of an online node per keepalive
+func OnlineIncome(m *types.RewardModel, networkSize int) float64 {
+	return m.OnlineIncrement * Weight(m, networkSize)
+}
+
+// Weight returns the weight of the network size
+func Weight(m *types.RewardModel, networkSize int) float64 {
+	for _, t := range m.NetworkWeights {
+		if networkSize <= t.MaxNodes {
+			return t.Weight
+		}
+	}
+	return m.NetworkWeightDefault
+}
+
+// bandwidthPoints interpolates the bandwidth curve
+func bandwidthPoints(m *types.RewardModel, mib float64) float64 {
+	curve := m.BandwidthCurve
+	if len(curve) == 0 {
+		return 0
+	}
+
+	if mib <= curve[0].MiB {
+		return curve[0].Points
+	}
+
+	for i := 1; i < len(curve); i++ {
+		if mib <= curve[i].MiB {
+			prev := curve[i-1]
+			return prev.Points + (curve[i].Points-prev.Points)*(mib-prev.MiB)/(curve[i].MiB-prev.MiB)
+		}
+	}
+
+	return curve[len(curve)-1].Points
+}
+
+func bToGB(b float64) float64 {
+	return b / 1024 / 1024 / 1024
+}
+
+func bToMB(b float64) float64 {
+	return b / 1024 / 1024
+}
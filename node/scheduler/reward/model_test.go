@@ -0,0 +1,61 @@
+package reward
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+// legacyIncome is the formula hard-coded before the reward models
+func legacyIncome(in Input) float64 {
+	b := bToMB(float64(in.BandwidthUp))
+	mb := 66.25
+	if b <= 5 {
+		mb = 0.05 * b
+	} else if b <= 50 {
+		mb = 0.25 + 0.8*(b-5)
+	} else if b <= 200 {
+		mb = 36.25 + 0.2*(b-50)
+	}
+
+	mn := map[types.NatType]float64{types.NatTypeNo: 1.5, types.NatTypeFullCone: 1.4, types.NatTypeRestricted: 1.2, types.NatTypePortRestricted: 1.1, types.NatTypeSymmetric: 1}[in.NATType]
+
+	mx := 1.0
+	for _, t := range []struct {
+		n int
+		w float64
+	}{{2000, 1.7}, {5000, 1.6}, {10000, 1.5}, {15000, 1.4}, {25000, 1.3}, {35000, 1.2}, {50000, 1.1}} {
+		if in.NetworkSize <= t.n {
+			mx = t.w
+			break
+		}
+	}
+
+	s := bToGB(in.TitanDiskUsage * 12.5)
+	ms := mx * math.Min(s, 2000) * (0.1 + 1/math.Max(math.Min(s, 2000), 10))
+
+	return math.Round(((mb*mn*mx)/float64(in.IPNodes)+ms)*1000000) / 1000000
+}
+
+func TestDefaultModel(t *testing.T) {
+	m := DefaultModel()
+	if err := Validate(m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bw := range []int64{0, 3 << 20, 5 << 20, 20 << 20, 50 << 20, 120 << 20, 500 << 20} {
+		for _, nat := range []types.NatType{types.NatTypeUnknown, types.NatTypeNo, types.NatTypeSymmetric, types.NatTypePortRestricted} {
+			for _, size := range []int{100, 3000, 60000} {
+				in := Input{BandwidthUp: bw, NATType: nat, TitanDiskUsage: float64(size) * (1 << 30), NetworkSize: size, IPNodes: 2}
+				if got, want := Income(m, in), legacyIncome(in); got != want {
+					t.Fatalf("income of %+v is %f, legacy income %f", in, got, want)
+				}
+			}
+		}
+	}
+
+	if got := OnlineIncome(m, 100); math.Abs(got-0.00289*1.7) > 1e-12 {
+		t.Fatalf("online income %f", got)
+	}
+}
@@ -0,0 +1,114 @@
+package reward
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+)
+
+// keepaliveInterval is the interval the online income of a node increases
+const keepaliveInterval = 5 * time.Second
+
+// Simulate replays the validations and workloads in the time range through the base and the candidate models,
+// the nodes are ordered by the absolute delta of the rewards
+func Simulate(sdb *db.SQLDB, base, candidate *types.RewardModel, start, end time.Time) (*types.RewardSimulation, error) {
+	infos, err := sdb.LoadRewardNodeInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	validations, err := sdb.CountNodeValidations(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads, err := sdb.CountNodeRetrieves(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// the network size of the models is the count of the edges
+	edges := 0
+	for _, info := range infos {
+		if info.NodeType == types.NodeEdge {
+			edges++
+		}
+	}
+
+	out := &types.RewardSimulation{
+		BaseVersion:      base.Version,
+		CandidateVersion: candidate.Version,
+		StartTime:        start,
+		EndTime:          end,
+		NetworkSize:      edges,
+		Nodes:            make([]*types.RewardNodeDelta, 0, len(infos)),
+	}
+
+	for _, info := range infos {
+		v, w := validations[info.NodeID], workloads[info.NodeID]
+		online := onlineTime(info, start, end)
+		if v == 0 && w == 0 && online == 0 {
+			continue
+		}
+
+		// the ip of the nodes is not persisted, every node is replayed as the only node of its ip
+		in := Input{
+			BandwidthUp:    info.BandwidthUp,
+			NATType:        types.NatTypeUnknown.FromString(info.NATType),
+			TitanDiskUsage: info.TitanDiskUsage,
+			NetworkSize:    edges,
+			IPNodes:        1,
+		}
+
+		d := &types.RewardNodeDelta{
+			NodeID:      info.NodeID,
+			Validations: v,
+			Workloads:   w,
+			Base:        replay(base, in, v, w, online),
+			Candidate:   replay(candidate, in, v, w, online),
+		}
+		d.Delta = d.Candidate - d.Base
+		out.Nodes = append(out.Nodes, d)
+	}
+
+	sort.Slice(out.Nodes, func(i, j int) bool {
+		return abs(out.Nodes[i].Delta) > abs(out.Nodes[j].Delta)
+	})
+
+	return out, nil
+}
+
+// replay returns the reward of the validations, the workloads and the online time of a node with the model
+func replay(m *types.RewardModel, in Input, validations, workloads int, online time.Duration) float64 {
+	keepalives := float64(online / keepaliveInterval)
+	return Income(m, in)*float64(validations) + m.WorkloadProfit*float64(workloads) + OnlineIncome(m, in.NetworkSize)*keepalives
+}
+
+// onlineTime estimates the online time of an edge in the time range,
+// the online history is not persisted, the node is assumed to be online at its average rate since the first login
+func onlineTime(info *db.RewardNodeInfo, start, end time.Time) time.Duration {
+	if info.NodeType != types.NodeEdge || info.FirstLoginTime.IsZero() {
+		return 0
+	}
+
+	if info.FirstLoginTime.After(start) {
+		start = info.FirstLoginTime
+	}
+
+	lifetime := time.Since(info.FirstLoginTime)
+	if !start.Before(end) || lifetime <= 0 {
+		return 0
+	}
+
+	rate := min(float64(time.Duration(info.OnlineDuration)*time.Minute)/float64(lifetime), 1)
+	return time.Duration(rate * float64(end.Sub(start)))
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
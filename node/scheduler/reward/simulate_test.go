@@ -0,0 +1,35 @@
+package reward
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+)
+
+func TestReplay(t *testing.T) {
+	m := DefaultModel()
+	end := time.Now()
+	start := end.Add(-10 * time.Hour)
+
+	// online half of the 20 hours since the first login
+	edge := &db.RewardNodeInfo{NodeID: "e1", NodeType: types.NodeEdge, OnlineDuration: 600, FirstLoginTime: end.Add(-20 * time.Hour)}
+	online := onlineTime(edge, start, end)
+	if math.Abs(online.Hours()-5) > 0.01 {
+		t.Fatalf("online time %s, expect 5h", online)
+	}
+
+	candidate := &db.RewardNodeInfo{NodeID: "c1", NodeType: types.NodeCandidate, OnlineDuration: 600, FirstLoginTime: end.Add(-20 * time.Hour)}
+	if online := onlineTime(candidate, start, end); online != 0 {
+		t.Fatalf("online time of candidate %s", online)
+	}
+
+	in := Input{NATType: types.NatTypeUnknown.FromString("FullConeNAT"), NetworkSize: 100, IPNodes: 1}
+	got := replay(m, in, 2, 3, time.Hour)
+	want := Income(m, in)*2 + m.WorkloadProfit*3 + OnlineIncome(m, 100)*720
+	if math.Abs(got-want) > 1e-9 {
+		t.Fatalf("replay %f, expect %f", got, want)
+	}
+}
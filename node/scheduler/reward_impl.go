@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/scheduler/reward"
+	"golang.org/x/xerrors"
+)
+
+// GetRewardModels retrieves the reward models, the first one is the model of the current epoch
+func (s *Scheduler) GetRewardModels(ctx context.Context) ([]*types.RewardModel, error) {
+	active := s.NodeManager.RewardModel()
+
+	models, err := s.NodeManager.LoadRewardModels()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*types.RewardModel{active}
+	for _, model := range models {
+		if model.Version != active.Version {
+			out = append(out, model)
+		}
+	}
+
+	return out, nil
+}
+
+// SaveRewardModel saves a reward model, the model is used from its start time
+func (s *Scheduler) SaveRewardModel(ctx context.Context, model *types.RewardModel) error {
+	if model == nil {
+		return xerrors.New("model can not be empty")
+	}
+
+	if err := reward.Validate(model); err != nil {
+		return err
+	}
+
+	// the rewards of a started epoch are reproducible only if its model is never changed
+	old, err := s.NodeManager.LoadRewardModel(model.Version)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if old != nil && !old.StartTime.IsZero() && old.StartTime.Before(time.Now()) {
+		return xerrors.Errorf("reward model %d is in use since %s", model.Version, old.StartTime.Format(time.DateTime))
+	}
+
+	if err := s.NodeManager.SaveRewardModel(model); err != nil {
+		return err
+	}
+
+	s.NodeManager.ResetRewardModel()
+	return nil
+}
+
+// SimulateRewardModel replays the history in the time range through the candidate model and the model of the base version
+func (s *Scheduler) SimulateRewardModel(ctx context.Context, candidate *types.RewardModel, baseVersion int, start, end time.Time) (*types.RewardSimulation, error) {
+	if candidate == nil {
+		return nil, xerrors.New("candidate model can not be empty")
+	}
+
+	if err := reward.Validate(candidate); err != nil {
+		return nil, err
+	}
+
+	if !start.Before(end) {
+		return nil, xerrors.Errorf("start time %s is not before end time %s", start, end)
+	}
+
+	base := s.NodeManager.RewardModel()
+	if baseVersion > 0 && baseVersion != base.Version {
+		var err error
+		base, err = s.NodeManager.LoadRewardModel(baseVersion)
+		if err != nil {
+			return nil, xerrors.Errorf("load reward model %d err: %w", baseVersion, err)
+		}
+	}
+
+	return reward.Simulate(s.NodeManager.SQLDB, base, candidate, start, end)
+}
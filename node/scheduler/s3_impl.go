@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/sigv4"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// the key of object is saved as the asset name
+	s3MaxKeyLen = 128
+	// the max number of objects returned by a list request
+	s3MaxListLimit = 1000
+	// the lifetime of the token returned by AuthS3Request, it covers the upload of a large object
+	s3TokenTTL = 2 * time.Hour
+)
+
+// bucket names follow the s3 naming rules, the length is limited by the name of asset group
+var s3BucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,30}[a-z0-9]$`)
+
+// AuthS3Request checks the signature of s3 request, the access key id is "<user-id>:<api-key-name>"
+// and the secret access key is the api key
+func (s *Scheduler) AuthS3Request(ctx context.Context, req *types.S3AuthReq) (*types.S3Identity, error) {
+	index := strings.LastIndex(req.AccessKeyID, ":")
+	if index <= 0 || index == len(req.AccessKeyID)-1 {
+		return nil, &api.ErrWeb{Code: terrors.APPKeyNotFound.Int(), Message: fmt.Sprintf("invalid access key id %s", req.AccessKeyID)}
+	}
+	userID, keyName := req.AccessKeyID[:index], req.AccessKeyID[index+1:]
+
+	keys, err := s.newUser(userID).GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := keys[keyName]
+	if !ok {
+		return nil, &api.ErrWeb{Code: terrors.APPKeyNotFound.Int(), Message: fmt.Sprintf("access key id %s not found", req.AccessKeyID)}
+	}
+
+	signature := sigv4.Sign(key.APIKey, req.Date, req.Region, req.Service, req.StringToSign)
+	if !hmac.Equal([]byte(signature), []byte(req.Signature)) {
+		return nil, &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: "signature does not match"}
+	}
+
+	payload, err := s.AuthVerify(ctx, key.APIKey)
+	if err != nil {
+		return nil, &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: err.Error()}
+	}
+
+	if payload.ID != userID {
+		return nil, &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: fmt.Sprintf("api key does not belong to user %s", userID)}
+	}
+
+	token, err := s.newS3Token(ctx, userID, time.Now().Add(s3TokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.S3Identity{UserID: userID, AccessControlList: payload.AccessControlList, Token: token}, nil
+}
+
+// newS3Token generates the token of the s3 session of user, the token can only be used by the calling candidate
+func (s *Scheduler) newS3Token(ctx context.Context, userID string, expiration time.Time) (string, error) {
+	nodeID := handler.GetNodeID(ctx)
+	if len(nodeID) == 0 {
+		return "", xerrors.New("s3 request must be authenticated by candidate")
+	}
+
+	buf, err := json.Marshal(&types.S3Session{UserID: userID, NodeID: nodeID, Expiration: expiration})
+	if err != nil {
+		return "", err
+	}
+
+	return s.AuthNew(ctx, &types.JWTPayload{Extend: string(buf)})
+}
+
+// verifyS3Token verifies the token returned by AuthS3Request and returns the user of it
+func (s *Scheduler) verifyS3Token(ctx context.Context, token string) (string, error) {
+	payload, err := s.AuthVerify(ctx, token)
+	if err != nil {
+		return "", &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: err.Error()}
+	}
+
+	session := &types.S3Session{}
+	if len(payload.Allow) > 0 || json.Unmarshal([]byte(payload.Extend), session) != nil || len(session.UserID) == 0 {
+		return "", &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: "invalid s3 token"}
+	}
+
+	if session.NodeID != handler.GetNodeID(ctx) {
+		return "", &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: "s3 token does not belong to the node"}
+	}
+
+	if session.Expiration.Before(time.Now()) {
+		return "", &api.ErrWeb{Code: terrors.VerifyTokenError.Int(), Message: "s3 token is expiration"}
+	}
+
+	return session.UserID, nil
+}
+
+// ListS3Buckets lists the buckets of user
+func (s *Scheduler) ListS3Buckets(ctx context.Context, token string) ([]*types.AssetGroup, error) {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := s.db.ListAssetGroupForUser(userID, rootGroup, userAssetGroupMaxCount, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.AssetGroups, nil
+}
+
+// CreateS3Bucket creates a bucket for user
+func (s *Scheduler) CreateS3Bucket(ctx context.Context, token, bucket string) error {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if !s3BucketNameRegexp.MatchString(bucket) {
+		return &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: fmt.Sprintf("invalid bucket name %s", bucket)}
+	}
+
+	if _, err := s.db.LoadAssetGroupByName(userID, bucket, rootGroup); err == nil {
+		return &api.ErrWeb{Code: terrors.GroupAlreadyExist.Int(), Message: fmt.Sprintf("bucket %s already exist", bucket)}
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	_, err = s.CreateAssetGroup(ctx, userID, bucket, rootGroup)
+	return err
+}
+
+// DeleteS3Bucket deletes an empty bucket of user
+func (s *Scheduler) DeleteS3Bucket(ctx context.Context, token, bucket string) error {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.loadS3Bucket(userID, bucket)
+	if err != nil {
+		return err
+	}
+
+	return s.DeleteAssetGroup(ctx, userID, group.ID)
+}
+
+// ListS3Objects lists the objects whose key starts with prefix and is greater than startAfter
+func (s *Scheduler) ListS3Objects(ctx context.Context, token, bucket, prefix, startAfter string, limit int) (*types.ListS3ObjectsRsp, error) {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.loadS3Bucket(userID, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > s3MaxListLimit {
+		limit = s3MaxListLimit
+	}
+
+	return s.newUser(userID).ListS3Objects(ctx, group.ID, prefix, startAfter, limit)
+}
+
+// GetS3Object retrieves the object with the key
+func (s *Scheduler) GetS3Object(ctx context.Context, token, bucket, key string) (*types.S3Object, error) {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.loadS3Bucket(userID, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newUser(userID).GetS3Object(ctx, group.ID, key)
+}
+
+// PutS3Object saves an object stored in the candidate, the object with the same key is replaced
+func (s *Scheduler) PutS3Object(ctx context.Context, token, bucket string, object *types.S3Object) error {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	nodeID := handler.GetNodeID(ctx)
+	if s.NodeManager.GetCandidateNode(nodeID) == nil {
+		return xerrors.Errorf("candidate node %s not found", nodeID)
+	}
+
+	if len(object.Key) == 0 || len(object.Key) > s3MaxKeyLen {
+		return &api.ErrWeb{Code: terrors.ParametersAreWrong.Int(), Message: fmt.Sprintf("the length of key must be between 1 and %d", s3MaxKeyLen)}
+	}
+
+	group, err := s.loadS3Bucket(userID, bucket)
+	if err != nil {
+		return err
+	}
+
+	expiration := time.Now().Add(time.Duration(s.SchedulerCfg.UploadAssetExpiration) * 24 * time.Hour)
+	return s.newUser(userID).PutS3Object(ctx, group.ID, object, expiration, nodeID)
+}
+
+// DeleteS3Object deletes the object with the key
+func (s *Scheduler) DeleteS3Object(ctx context.Context, token, bucket, key string) error {
+	userID, err := s.verifyS3Token(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.loadS3Bucket(userID, bucket)
+	if err != nil {
+		return err
+	}
+
+	return s.newUser(userID).DeleteS3Object(ctx, group.ID, key)
+}
+
+func (s *Scheduler) loadS3Bucket(userID, bucket string) (*types.AssetGroup, error) {
+	group, err := s.db.LoadAssetGroupByName(userID, bucket, rootGroup)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &api.ErrWeb{Code: terrors.GroupNotExist.Int(), Message: fmt.Sprintf("bucket %s not exist", bucket)}
+		}
+		return nil, err
+	}
+
+	return group, nil
+}
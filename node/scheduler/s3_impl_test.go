@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/common"
+	"github.com/Filecoin-Titan/titan/node/handler"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gbrlsnchs/jwt/v3"
+)
+
+func TestVerifyS3Token(t *testing.T) {
+	s := &Scheduler{CommonAPI: &common.CommonAPI{APISecret: jwt.NewHS256([]byte("abc_123"))}}
+	candidate := func(nodeID string) context.Context {
+		ctx := api.WithPerm(context.Background(), []auth.Permission{api.RoleCandidate})
+		return context.WithValue(ctx, handler.ID{}, nodeID)
+	}
+
+	token, err := s.newS3Token(candidate("c_1"), "user", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID, err := s.verifyS3Token(candidate("c_1"), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != "user" {
+		t.Fatalf("expected user, got %s", userID)
+	}
+
+	// the token can not be used by other candidates
+	if _, err := s.verifyS3Token(candidate("c_2"), token); err == nil {
+		t.Fatal("verify s3 token of other candidate without error")
+	}
+
+	expired, err := s.newS3Token(candidate("c_1"), "user", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.verifyS3Token(candidate("c_1"), expired); err == nil {
+		t.Fatal("verify expired s3 token without error")
+	}
+
+	// the api key of user is not an s3 token
+	apiKey, err := s.AuthNew(context.Background(), &types.JWTPayload{ID: "user", Allow: []auth.Permission{api.RoleUser}, Extend: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.verifyS3Token(candidate("c_1"), apiKey); err == nil {
+		t.Fatal("verify api key as s3 token without error")
+	}
+
+	// a bare user id is not a token
+	if _, err := s.verifyS3Token(candidate("c_1"), "user"); err == nil {
+		t.Fatal("verify user id as s3 token without error")
+	}
+}
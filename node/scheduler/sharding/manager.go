@@ -0,0 +1,339 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/client"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/etcdcli"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"github.com/Filecoin-Titan/titan/node/scheduler/assets"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/filecoin-project/go-jsonrpc"
+	logging "github.com/ipfs/go-log/v2"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+var log = logging.Logger("sharding")
+
+const (
+	// a lost scheduler is taken over after the duration, so a restarting scheduler keeps its assets
+	handoffDelay = 2 * time.Minute
+	// interval of checking the lost schedulers
+	handoffInterval = time.Minute
+	// interval of watching etcd again after the watch channel is closed
+	rewatchInterval = 10 * time.Second
+	// the count of asset states moved in a transaction
+	handoffBatchSize = 500
+)
+
+// Manager partitions the asset state machines of an area across the schedulers registered in etcd,
+// the states of a lost scheduler are adopted by the schedulers which own them on the consistent hash ring
+type Manager struct {
+	etcdcli  *etcdcli.Client
+	db       *db.SQLDB
+	assetMgr *assets.Manager
+	serverID dtypes.ServerID
+	areaID   string
+
+	lock sync.Mutex
+	// schedulers of the area registered in etcd, key is etcd key
+	members map[string]dtypes.ServerID
+	// configs of the schedulers of the area, which are used to forward the new assets to their owners
+	configs map[dtypes.ServerID]*types.SchedulerCfg
+	// the api clients of the schedulers the new assets are forwarded to
+	apis map[dtypes.ServerID]*schedulerAPI
+	// the time the scheduler is found lost
+	lostTimes map[dtypes.ServerID]time.Time
+}
+
+type schedulerAPI struct {
+	api.Scheduler
+	closer jsonrpc.ClientCloser
+}
+
+// NewManager creates a sharding manager
+func NewManager(ec *etcdcli.Client, sdb *db.SQLDB, am *assets.Manager, serverID dtypes.ServerID, configFunc dtypes.GetSchedulerConfigFunc) (*Manager, error) {
+	cfg, err := configFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		etcdcli:   ec,
+		db:        sdb,
+		assetMgr:  am,
+		serverID:  serverID,
+		areaID:    cfg.AreaID,
+		members:   make(map[string]dtypes.ServerID),
+		configs:   make(map[dtypes.ServerID]*types.SchedulerCfg),
+		apis:      make(map[dtypes.ServerID]*schedulerAPI),
+		lostTimes: make(map[dtypes.ServerID]time.Time),
+	}
+
+	return m, nil
+}
+
+// Start records the scheduler as an owner of asset states, and starts taking over the lost schedulers
+func (m *Manager) Start(ctx context.Context) {
+	if err := m.db.SaveSchedulerShard(m.serverID, m.areaID); err != nil {
+		log.Errorf("SaveSchedulerShard err:%s", err.Error())
+	}
+
+	go m.watchMembers(ctx)
+
+	ticker := time.NewTicker(handoffInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.adoptLostSchedulers()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Owner returns the scheduler which owns the asset on the ring of the live schedulers
+func (m *Manager) Owner(hash string) dtypes.ServerID {
+	return dtypes.ServerID(m.ring().Owner(hash))
+}
+
+// OwnerAPI returns the api of the scheduler which owns the asset, nil if the asset is owned by this scheduler
+func (m *Manager) OwnerAPI(hash string) (api.Scheduler, error) {
+	owner := m.Owner(hash)
+	if owner == m.serverID {
+		return nil, nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if s, ok := m.apis[owner]; ok {
+		return s, nil
+	}
+
+	cfg, ok := m.configs[owner]
+	if !ok {
+		return nil, fmt.Errorf("config of scheduler %s not found", owner)
+	}
+
+	headers := http.Header{}
+	headers.Add("Authorization", "Bearer "+cfg.AccessToken)
+	s, closer, err := client.NewScheduler(context.Background(), cfg.SchedulerURL, headers, jsonrpc.WithHTTPClient(client.NewHTTP3Client()))
+	if err != nil {
+		return nil, err
+	}
+
+	m.apis[owner] = &schedulerAPI{Scheduler: s, closer: closer}
+	return s, nil
+}
+
+func (m *Manager) ring() *Ring {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	serverIDs := []string{string(m.serverID)}
+	for _, serverID := range m.members {
+		if serverID != m.serverID {
+			serverIDs = append(serverIDs, string(serverID))
+		}
+	}
+	return NewRing(serverIDs)
+}
+
+func (m *Manager) isMember(serverID dtypes.ServerID) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, member := range m.members {
+		if member == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// watchMembers keeps the schedulers of the area up to date with etcd
+func (m *Manager) watchMembers(ctx context.Context) {
+	for {
+		if err := m.loadMembers(); err != nil {
+			log.Errorf("load schedulers err:%s", err.Error())
+		}
+
+		watchChan := m.etcdcli.WatchServers(ctx, types.NodeScheduler.String())
+		for resp := range watchChan {
+			for _, event := range resp.Events {
+				switch event.Type {
+				case mvccpb.PUT:
+					m.onMemberPut(event.Kv)
+				case mvccpb.DELETE:
+					m.onMemberDelete(event.Kv)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(rewatchInterval):
+			log.Warnf("watch schedulers closed, watch again")
+		}
+	}
+}
+
+func (m *Manager) loadMembers() error {
+	resp, err := m.etcdcli.GetServers(types.NodeScheduler.String())
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	m.members = make(map[string]dtypes.ServerID)
+	m.lock.Unlock()
+
+	for _, kv := range resp.Kvs {
+		m.onMemberPut(kv)
+	}
+	return nil
+}
+
+func (m *Manager) onMemberPut(kv *mvccpb.KeyValue) {
+	cfg := &types.SchedulerCfg{}
+	if err := etcdcli.SCUnmarshal(kv.Value, cfg); err != nil {
+		log.Errorf("unmarshal scheduler %s config err:%s", string(kv.Key), err.Error())
+		return
+	}
+
+	if cfg.AreaID != m.areaID {
+		return
+	}
+
+	serverID := serverIDFromKey(string(kv.Key))
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.members[string(kv.Key)] = serverID
+	delete(m.lostTimes, serverID)
+
+	// the client is created again with the new config
+	m.configs[serverID] = cfg
+	m.closeAPI(serverID)
+}
+
+func (m *Manager) onMemberDelete(kv *mvccpb.KeyValue) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	serverID, ok := m.members[string(kv.Key)]
+	if !ok {
+		return
+	}
+
+	log.Infof("scheduler %s lost, its assets will be taken over after %s", serverID, handoffDelay)
+	delete(m.members, string(kv.Key))
+	delete(m.configs, serverID)
+	m.closeAPI(serverID)
+	m.lostTimes[serverID] = time.Now()
+}
+
+// closeAPI closes the api client of the scheduler, the caller must hold the lock
+func (m *Manager) closeAPI(serverID dtypes.ServerID) {
+	if s, ok := m.apis[serverID]; ok {
+		s.closer()
+		delete(m.apis, serverID)
+	}
+}
+
+// lostSince returns the time the scheduler is found lost, the scheduler is recorded as lost now if it is not yet
+func (m *Manager) lostSince(serverID dtypes.ServerID) time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	lostTime, ok := m.lostTimes[serverID]
+	if !ok {
+		lostTime = time.Now()
+		m.lostTimes[serverID] = lostTime
+	}
+	return lostTime
+}
+
+// adoptLostSchedulers takes over the asset states of the schedulers which are not registered in etcd
+func (m *Manager) adoptLostSchedulers() {
+	serverIDs, err := m.db.LoadSchedulerShards(m.areaID)
+	if err != nil {
+		log.Errorf("LoadSchedulerShards err:%s", err.Error())
+		return
+	}
+
+	for _, serverID := range serverIDs {
+		if serverID == m.serverID || m.isMember(serverID) {
+			continue
+		}
+
+		if time.Since(m.lostSince(serverID)) < handoffDelay {
+			continue
+		}
+
+		if err := m.adopt(serverID); err != nil {
+			log.Errorf("adopt assets of scheduler %s err:%s", serverID, err.Error())
+		}
+	}
+}
+
+// adopt moves the asset states owned by this scheduler from the lost scheduler, and resumes their state machines
+func (m *Manager) adopt(lost dtypes.ServerID) error {
+	hashes, err := m.db.LoadAssetStateHashes(lost)
+	if err != nil {
+		return err
+	}
+
+	ring := m.ring()
+	adopted := make([]string, 0)
+	for _, hash := range hashes {
+		if ring.Owner(hash) == string(m.serverID) {
+			adopted = append(adopted, hash)
+		}
+	}
+
+	for i := 0; i < len(adopted); i += handoffBatchSize {
+		end := i + handoffBatchSize
+		if end > len(adopted) {
+			end = len(adopted)
+		}
+
+		moved, err := m.db.MoveAssetStates(adopted[i:end], lost, m.serverID)
+		if err != nil {
+			return fmt.Errorf("move asset states %w", err)
+		}
+		m.assetMgr.ResumeAssets(moved)
+	}
+
+	if len(adopted) > 0 {
+		log.Infof("adopted %d of %d assets from lost scheduler %s", len(adopted), len(hashes), lost)
+	}
+
+	// the rest are adopted by the other schedulers
+	if len(adopted) == len(hashes) {
+		m.lock.Lock()
+		delete(m.lostTimes, lost)
+		m.lock.Unlock()
+
+		return m.db.DeleteSchedulerShard(lost)
+	}
+
+	return nil
+}
+
+// serverIDFromKey returns the server id of the etcd key /<node type>/<server id>
+func serverIDFromKey(key string) dtypes.ServerID {
+	return dtypes.ServerID(strings.TrimPrefix(key, fmt.Sprintf("/%s/", types.NodeScheduler.String())))
+}
@@ -0,0 +1,70 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/lib/etcdcli"
+	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func TestMembers(t *testing.T) {
+	m := &Manager{
+		serverID:  "s1",
+		areaID:    "area",
+		members:   make(map[string]dtypes.ServerID),
+		configs:   make(map[dtypes.ServerID]*types.SchedulerCfg),
+		apis:      make(map[dtypes.ServerID]*schedulerAPI),
+		lostTimes: make(map[dtypes.ServerID]time.Time),
+	}
+
+	put := func(serverID, areaID string) *mvccpb.KeyValue {
+		value, err := etcdcli.SCMarshal(&types.SchedulerCfg{SchedulerURL: "https://" + serverID, AreaID: areaID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		kv := &mvccpb.KeyValue{Key: []byte(fmt.Sprintf("/%s/%s", types.NodeScheduler.String(), serverID)), Value: value}
+		m.onMemberPut(kv)
+		return kv
+	}
+
+	put("s1", "area")
+	s2 := put("s2", "area")
+	put("s3", "other-area")
+
+	if !m.isMember("s2") || m.isMember("s3") {
+		t.Fatalf("unexpected members %v", m.members)
+	}
+
+	// the assets are partitioned across the schedulers of the area
+	owners := make(map[dtypes.ServerID]int)
+	for i := 0; i < 100; i++ {
+		hash := fmt.Sprintf("asset-%d", i)
+		owner := m.Owner(hash)
+		owners[owner]++
+
+		if owner == m.serverID {
+			if api, err := m.OwnerAPI(hash); err != nil || api != nil {
+				t.Fatalf("asset %s owned by this scheduler is forwarded", hash)
+			}
+		}
+	}
+	if len(owners) != 2 || owners["s1"] == 0 || owners["s2"] == 0 {
+		t.Fatalf("unexpected owners %v", owners)
+	}
+
+	m.onMemberDelete(s2)
+	if m.isMember("s2") || m.configs["s2"] != nil || m.lostTimes["s2"].IsZero() {
+		t.Fatal("lost scheduler is still a member")
+	}
+
+	for i := 0; i < 100; i++ {
+		if owner := m.Owner(fmt.Sprintf("asset-%d", i)); owner != "s1" {
+			t.Fatalf("asset-%d owned by lost scheduler %s", i, owner)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the count of points of a scheduler on the ring, more points spread the assets more evenly
+const virtualNodes = 128
+
+// Ring is a consistent hash ring of schedulers, an asset is owned by the first scheduler clockwise from its hash
+type Ring struct {
+	points []uint64
+	owners map[uint64]string
+}
+
+// NewRing creates a ring of the schedulers
+func NewRing(serverIDs []string) *Ring {
+	r := &Ring{owners: make(map[uint64]string, len(serverIDs)*virtualNodes)}
+	for _, serverID := range serverIDs {
+		for i := 0; i < virtualNodes; i++ {
+			point := hashKey(serverID + "#" + strconv.Itoa(i))
+			// the smaller server id wins the collided point, so the ring is the same on every scheduler
+			if owner, ok := r.owners[point]; ok && owner < serverID {
+				continue
+			}
+			r.owners[point] = serverID
+		}
+	}
+
+	r.points = make([]uint64, 0, len(r.owners))
+	for point := range r.owners {
+		r.points = append(r.points, point)
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+
+	return r
+}
+
+// Owner returns the scheduler which owns the key, empty if the ring has no scheduler
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hashKey(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,37 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	ring := NewRing(servers)
+
+	counts := make(map[string]int)
+	owners := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("asset-%d", i)
+		owners[key] = ring.Owner(key)
+		counts[owners[key]]++
+	}
+
+	for _, server := range servers {
+		if counts[server] < 500 {
+			t.Fatalf("server %s owns %d of 3000 keys", server, counts[server])
+		}
+	}
+
+	// only the keys of the removed server move
+	shrunk := NewRing([]string{"s3", "s1"})
+	for key, owner := range owners {
+		if owner != "s2" && shrunk.Owner(key) != owner {
+			t.Fatalf("key %s moved from %s to %s", key, owner, shrunk.Owner(key))
+		}
+	}
+
+	if NewRing(nil).Owner("asset") != "" {
+		t.Fatal("empty ring owns key")
+	}
+}
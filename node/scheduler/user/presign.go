@@ -0,0 +1,104 @@
+package user
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/node"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maxPresignExpiration is the max lifetime of the presigned url
+	maxPresignExpiration = 30 * 24 * time.Hour
+	// maxAllowedIPs is the max number of the client ips or cidrs of the presigned url
+	maxAllowedIPs = 32
+)
+
+// PresignAsset creates the revocable url to download the asset with the constraints of opts
+func (u *User) PresignAsset(ctx context.Context, assetCID string, opts *types.PresignOptions, schedulerAPI api.Scheduler, nodeManager *node.Manager) (*types.PresignedURL, error) {
+	if err := checkPresignOptions(opts); err != nil {
+		return nil, err
+	}
+
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &types.PresignedLink{
+		ID:           uuid.NewString(),
+		UserID:       u.ID,
+		Hash:         hash,
+		Expiration:   opts.Expiration,
+		MaxDownloads: opts.MaxDownloads,
+		AllowedIPs:   strings.Join(opts.AllowedIPs, ","),
+		HasPassword:  len(opts.Password) > 0,
+	}
+
+	auth := &types.AuthUserUploadDownloadAsset{
+		UserID:     u.ID,
+		AssetCID:   assetCID,
+		Expiration: opts.Expiration,
+		LinkID:     link.ID,
+	}
+
+	// the password hash is kept in the link, the scheduler checks the password of the download
+	if link.HasPassword {
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash = string(passwordHash)
+	}
+
+	url, err := u.shareURL(auth, schedulerAPI, nodeManager)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.SavePresignedLink(link); err != nil {
+		return nil, err
+	}
+
+	return &types.PresignedURL{ID: link.ID, URL: url, Expiration: link.Expiration}, nil
+}
+
+// checkPresignOptions checks the expiration is in the allowed lifetime and the allowed ips are valid
+func checkPresignOptions(opts *types.PresignOptions) error {
+	if opts == nil {
+		return xerrors.New("presign options can not be empty")
+	}
+
+	lifetime := time.Until(opts.Expiration)
+	if lifetime <= 0 || lifetime > maxPresignExpiration {
+		return xerrors.Errorf("expiration must be in %s", maxPresignExpiration)
+	}
+
+	if opts.MaxDownloads < 0 {
+		return xerrors.Errorf("invalid max downloads %d", opts.MaxDownloads)
+	}
+
+	if len(opts.AllowedIPs) > maxAllowedIPs {
+		return xerrors.Errorf("allowed ips are more than %d", maxAllowedIPs)
+	}
+
+	for _, ip := range opts.AllowedIPs {
+		if net.ParseIP(ip) != nil {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(ip); err != nil {
+			return xerrors.Errorf("invalid ip or cidr %s", ip)
+		}
+	}
+
+	return nil
+}
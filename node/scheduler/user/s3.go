@@ -0,0 +1,163 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/terrors"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"golang.org/x/xerrors"
+)
+
+// s3AssetType is the asset type of objects saved by s3 gateway
+const s3AssetType = "s3"
+
+// ListS3Objects lists the objects in the group, the objects are sorted by key
+func (u *User) ListS3Objects(ctx context.Context, groupID int, prefix, startAfter string, limit int) (*types.ListS3ObjectsRsp, error) {
+	// load one more to check if the list is truncated
+	userAssets, err := u.ListUserAssetsByName(u.ID, groupID, prefix, startAfter, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.ListS3ObjectsRsp{}
+	if len(userAssets) > limit {
+		rsp.IsTruncated = true
+		userAssets = userAssets[:limit]
+	}
+
+	hashes := make([]string, 0, len(userAssets))
+	for _, userAsset := range userAssets {
+		hashes = append(hashes, userAsset.Hash)
+	}
+
+	cids, err := u.LoadAssetCIDs(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp.Objects = make([]*types.S3Object, 0, len(userAssets))
+	for _, userAsset := range userAssets {
+		rsp.Objects = append(rsp.Objects, s3ObjectFrom(userAsset, cids[userAsset.Hash]))
+	}
+
+	return rsp, nil
+}
+
+// GetS3Object retrieves the object with the key in the group
+func (u *User) GetS3Object(ctx context.Context, groupID int, key string) (*types.S3Object, error) {
+	userAsset, err := u.LoadUserAssetByName(u.ID, key, groupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("object %s not found", key)}
+		}
+		return nil, err
+	}
+
+	cids, err := u.LoadAssetCIDs([]string{userAsset.Hash})
+	if err != nil {
+		return nil, err
+	}
+
+	cid, ok := cids[userAsset.Hash]
+	if !ok {
+		return nil, &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("asset of object %s not found", key)}
+	}
+
+	return s3ObjectFrom(userAsset, cid), nil
+}
+
+// PutS3Object saves the object stored in the candidate node to the group, the object with the same key is replaced
+func (u *User) PutS3Object(ctx context.Context, groupID int, object *types.S3Object, expiration time.Time, nodeID string) error {
+	hash, err := cidutil.CIDToHash(object.CID)
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.CidToHashFiled.Int(), Message: err.Error()}
+	}
+
+	old, err := u.LoadUserAssetByName(u.ID, object.Key, groupID)
+	if err != nil && err != sql.ErrNoRows {
+		return &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if old != nil && old.Hash == hash {
+		return nil
+	}
+
+	// the same content may be saved with several keys, such as empty objects and folder markers
+	exist, err := u.AssetExistsOfUser(hash, u.ID)
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	info, err := u.GetInfo()
+	if err != nil {
+		return &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	freeSize := info.TotalSize - info.UsedSize
+	if old != nil {
+		freeSize += old.TotalSize
+	}
+
+	if freeSize < object.Size {
+		return &api.ErrWeb{Code: terrors.UserStorageSizeNotEnough.Int(), Message: terrors.UserStorageSizeNotEnough.String()}
+	}
+
+	// the replaced object is deleted after the new one is saved, so a failed put keeps the old object
+	if err := u.SaveAssetUser(hash, u.ID, object.Key, s3AssetType, object.Size, expiration, "", groupID); err != nil {
+		return &api.ErrWeb{Code: terrors.DatabaseErr.Int(), Message: err.Error()}
+	}
+
+	if old != nil {
+		if err := u.deleteS3Object(old); err != nil {
+			return xerrors.Errorf("delete replaced object %s: %w", object.Key, err)
+		}
+	}
+
+	// the asset of the content is already stored for the user
+	if exist {
+		return nil
+	}
+
+	return u.Manager.CreateUploadedAssetTask(hash, object.CID, object.CarSize, expiration, nodeID)
+}
+
+// DeleteS3Object deletes the object with the key in the group
+func (u *User) DeleteS3Object(ctx context.Context, groupID int, key string) error {
+	userAsset, err := u.LoadUserAssetByName(u.ID, key, groupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &api.ErrWeb{Code: terrors.NotFound.Int(), Message: fmt.Sprintf("object %s not found", key)}
+		}
+		return err
+	}
+
+	return u.deleteS3Object(userAsset)
+}
+
+// deleteS3Object deletes the key of object, the asset is deleted with the last key of it
+func (u *User) deleteS3Object(userAsset *types.UserAssetDetail) error {
+	count, err := u.CountAssetNamesOfUser(userAsset.Hash, u.ID)
+	if err != nil {
+		return err
+	}
+
+	if count > 1 {
+		return u.DeleteAssetUserByName(userAsset.Hash, u.ID, userAsset.AssetName, userAsset.GroupID)
+	}
+
+	return u.deleteAsset(userAsset.Hash)
+}
+
+func s3ObjectFrom(userAsset *types.UserAssetDetail, cid string) *types.S3Object {
+	return &types.S3Object{
+		Key:          userAsset.AssetName,
+		CID:          cid,
+		Size:         userAsset.TotalSize,
+		LastModified: userAsset.CreatedTime,
+	}
+}
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+	"github.com/Filecoin-Titan/titan/node/scheduler/db"
+	"github.com/Filecoin-Titan/titan/node/sqldb"
+)
+
+func TestPutS3ObjectOfSameContent(t *testing.T) {
+	client, err := sqldb.NewDB("sqlite://" + filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close() //nolint:errcheck // ignore error
+
+	d, err := db.NewSQLDB(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InitTables(d, "test-server"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SaveUserTotalStorageSize("user", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// the empty object is stored with the key "a"
+	emptyCID := "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	hash, err := cidutil.CIDToHash(emptyCID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiration := time.Now().Add(time.Hour)
+	if err := d.SaveAssetUser(hash, "user", "a", s3AssetType, 0, expiration, "", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the folder marker shares the content of the empty object, the asset is not uploaded again
+	u := &User{SQLDB: d, ID: "user"}
+	ctx := context.Background()
+	if err := u.PutS3Object(ctx, 1, &types.S3Object{Key: "folder/", CID: emptyCID}, expiration, "c_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := d.ListUserAssetsByName("user", 1, "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 || objects[0].AssetName != "a" || objects[1].AssetName != "folder/" {
+		t.Fatalf("unexpected objects %+v", objects)
+	}
+
+	// deleting one key keeps the other
+	if err := u.DeleteS3Object(ctx, 1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.LoadUserAssetByName("user", "folder/", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.LoadUserAssetByName("user", "a", 1); err == nil {
+		t.Fatal("the deleted object is loaded")
+	}
+}
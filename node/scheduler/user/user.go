@@ -249,6 +249,11 @@ func (u *User) DeleteAsset(ctx context.Context, cid string) error {
 		return err
 	}
 
+	return u.deleteAsset(hash)
+}
+
+// deleteAsset removes the asset if the user is the only owner, otherwise removes the user from the asset
+func (u *User) deleteAsset(hash string) error {
 	users, err := u.ListUsersForAsset(hash)
 	if err != nil {
 		return err
@@ -265,40 +270,51 @@ func (u *User) DeleteAsset(ctx context.Context, cid string) error {
 func (u *User) ShareAssets(ctx context.Context, assetCIDs []string, schedulerAPI api.Scheduler, nodeManager *node.Manager) (map[string]string, error) {
 	urls := make(map[string]string)
 	for _, assetCID := range assetCIDs {
-		downloadInfos, err := schedulerAPI.GetCandidateDownloadInfos(context.Background(), assetCID)
+		url, err := u.shareURL(&types.AuthUserUploadDownloadAsset{UserID: u.ID, AssetCID: assetCID}, schedulerAPI, nodeManager)
 		if err != nil {
 			return nil, err
 		}
+		urls[assetCID] = url
+	}
 
-		if len(downloadInfos) == 0 {
-			return nil, fmt.Errorf("asset %s not exist", assetCID)
-		}
+	return urls, nil
+}
 
-		tk, err := generateAccessToken(&types.AuthUserUploadDownloadAsset{UserID: u.ID, AssetCID: assetCID}, schedulerAPI.(api.Common))
-		if err != nil {
-			return nil, err
-		}
+// shareURL returns the url to download the asset from the candidate with the access token of auth
+func (u *User) shareURL(auth *types.AuthUserUploadDownloadAsset, schedulerAPI api.Scheduler, nodeManager *node.Manager) (string, error) {
+	assetCID := auth.AssetCID
+	downloadInfos, err := schedulerAPI.GetCandidateDownloadInfos(context.Background(), assetCID)
+	if err != nil {
+		return "", err
+	}
 
-		hash, err := cidutil.CIDToHash(assetCID)
-		if err != nil {
-			return nil, err
-		}
-		assetName, err := u.GetAssetName(hash, u.ID)
-		if err != nil {
-			return nil, err
-		}
+	if len(downloadInfos) == 0 {
+		return "", fmt.Errorf("asset %s not exist", assetCID)
+	}
 
-		nodeID := downloadInfos[0].NodeID
-		node := nodeManager.GetCandidateNode(nodeID)
+	tk, err := generateAccessToken(auth, schedulerAPI.(api.Common))
+	if err != nil {
+		return "", err
+	}
 
-		url := fmt.Sprintf("http://%s/ipfs/%s?token=%s&filename=%s", downloadInfos[0].Address, assetCID, tk, assetName)
-		if node != nil && len(node.ExternalURL) > 0 {
-			url = fmt.Sprintf("%s/ipfs/%s?token=%s&filename=%s", node.ExternalURL, assetCID, tk, assetName)
-		}
-		urls[assetCID] = url
+	hash, err := cidutil.CIDToHash(assetCID)
+	if err != nil {
+		return "", err
+	}
+	assetName, err := u.GetAssetName(hash, u.ID)
+	if err != nil {
+		return "", err
 	}
 
-	return urls, nil
+	nodeID := downloadInfos[0].NodeID
+	node := nodeManager.GetCandidateNode(nodeID)
+
+	url := fmt.Sprintf("http://%s/ipfs/%s?token=%s&filename=%s", downloadInfos[0].Address, assetCID, tk, assetName)
+	if node != nil && len(node.ExternalURL) > 0 {
+		url = fmt.Sprintf("%s/ipfs/%s?token=%s&filename=%s", node.ExternalURL, assetCID, tk, assetName)
+	}
+
+	return url, nil
 }
 
 // GetAssetStatus retrieves a asset status
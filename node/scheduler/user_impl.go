@@ -146,11 +146,17 @@ func (s *Scheduler) UserAssetDownloadResult(ctx context.Context, userID, cid str
 		return xerrors.Errorf("UserAssetDownloadResult node not found: %s", nodeID)
 	}
 
+	s.AssetManager.AddAssetRetrieval(cid)
+
 	err := s.db.UpdateUserInfo(userID, totalTraffic, 1)
 	if err != nil {
 		return err
 	}
 
+	if err = s.db.AddUserEgressTraffic(userID, egressMonth(time.Now()), totalTraffic); err != nil {
+		return err
+	}
+
 	return s.db.UpdateUserPeakSize(userID, peakBandwidth)
 }
 
@@ -73,6 +73,10 @@ type Manager struct {
 	close      chan struct{}
 	config     dtypes.GetSchedulerConfigFunc
 
+	// storage challenges of the current round, key is node id
+	challengeLock sync.Mutex
+	challenges    map[string][]*types.StorageChallenge
+
 	updateCh chan struct{}
 
 	nextElectionTime time.Time
@@ -102,6 +106,7 @@ func NewManager(nodeMgr *node.Manager, assetMgr *assets.Manager, configFunc dtyp
 		notify:        p,
 		resultQueue:   make(chan *api.ValidationResult),
 		leadershipMgr: lmgr,
+		challenges:    make(map[string][]*types.StorageChallenge),
 	}
 
 	return manager
@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/cidutil"
+)
+
+// minCommittedReplicas is the min count of replicas committed an asset to challenge it
+const minCommittedReplicas = 2
+
+// storageChallenges picks random assets and leaves from the commitments of the node,
+// returns nil if storage proof is disabled or the node has no commitment
+func (m *Manager) storageChallenges(nodeID string) []*types.StorageChallenge {
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return nil
+	}
+
+	if !cfg.EnableStorageProof || cfg.StorageProofAssets <= 0 || cfg.StorageProofLeaves <= 0 {
+		return nil
+	}
+
+	commitments, err := m.nodeMgr.LoadNodeAssetCommitments(nodeID)
+	if err != nil {
+		log.Errorf("%s LoadNodeAssetCommitments err:%s", nodeID, err.Error())
+		return nil
+	}
+
+	if len(commitments) == 0 {
+		return nil
+	}
+
+	sort.Slice(commitments, func(i, j int) bool { return commitments[i].Hash < commitments[j].Hash })
+
+	r := rand.New(rand.NewSource(m.seed))
+	r.Shuffle(len(commitments), func(i, j int) { commitments[i], commitments[j] = commitments[j], commitments[i] })
+
+	challenges := make([]*types.StorageChallenge, 0, cfg.StorageProofAssets)
+	for _, commitment := range commitments {
+		if len(challenges) >= cfg.StorageProofAssets {
+			break
+		}
+
+		if commitment.Leaves <= 0 {
+			continue
+		}
+
+		// the commitment of an asset with a single replica can not be cross checked
+		replicas, err := m.nodeMgr.LoadAssetCommitments(commitment.Hash)
+		if err != nil {
+			log.Errorf("%s LoadAssetCommitments err:%s", commitment.Hash, err.Error())
+			continue
+		}
+
+		if len(replicas) < minCommittedReplicas {
+			continue
+		}
+
+		cid, err := cidutil.HashToCID(commitment.Hash)
+		if err != nil {
+			log.Errorf("%s HashToCID err:%s", commitment.Hash, err.Error())
+			continue
+		}
+
+		challenge := &types.StorageChallenge{AssetCID: cid}
+		for i := 0; i < cfg.StorageProofLeaves; i++ {
+			challenge.Leaves = append(challenge.Leaves, r.Intn(commitment.Leaves))
+		}
+		challenges = append(challenges, challenge)
+	}
+
+	return challenges
+}
+
+// checkStorageProofs checks every challenged leaf is proved with the commitment of the node,
+// and the commitment is the same as the most of the other replicas
+func (m *Manager) checkStorageProofs(challenges []*types.StorageChallenge, vr *api.ValidationResult) error {
+	proofs := make(map[string]*types.StorageProofResult, len(vr.Proofs))
+	for _, proof := range vr.Proofs {
+		proofs[fmt.Sprintf("%s:%d", proof.AssetCID, proof.Leaf)] = proof
+	}
+
+	for _, challenge := range challenges {
+		hash, err := cidutil.CIDToHash(challenge.AssetCID)
+		if err != nil {
+			return err
+		}
+
+		commitments, err := m.nodeMgr.LoadAssetCommitments(hash)
+		if err != nil {
+			return err
+		}
+
+		commitment, err := majorityCommitment(commitments, vr.NodeID)
+		if err != nil {
+			return fmt.Errorf("asset %s: %w", challenge.AssetCID, err)
+		}
+
+		for _, leaf := range challenge.Leaves {
+			proof, ok := proofs[fmt.Sprintf("%s:%d", challenge.AssetCID, leaf)]
+			if !ok {
+				return fmt.Errorf("asset %s leaf %d is not proved", challenge.AssetCID, leaf)
+			}
+
+			if err := checkLeafProof(proof, commitment); err != nil {
+				return fmt.Errorf("asset %s leaf %d: %w", challenge.AssetCID, leaf, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkLeafProof checks the leaf is proved with the root and the leaf count of the commitment
+func checkLeafProof(proof *types.StorageProofResult, commitment *types.AssetCommitment) error {
+	if !proof.Verified {
+		return fmt.Errorf("proof is invalid")
+	}
+
+	if proof.Root != commitment.Root {
+		return fmt.Errorf("proved with root %s, committed root %s", proof.Root, commitment.Root)
+	}
+
+	if proof.Leaves != commitment.Leaves {
+		return fmt.Errorf("proved with %d leaves, committed %d leaves", proof.Leaves, commitment.Leaves)
+	}
+
+	if proof.Leaf < 0 || proof.Leaf >= commitment.Leaves {
+		return fmt.Errorf("out of %d committed leaves", commitment.Leaves)
+	}
+
+	return nil
+}
+
+// majorityCommitment returns the commitment of the node, the root and leaf count of which must be committed by
+// the strict majority of the replicas, so an asset with a single replica can not be validated
+func majorityCommitment(commitments []*types.AssetCommitment, nodeID string) (*types.AssetCommitment, error) {
+	if len(commitments) < minCommittedReplicas {
+		return nil, fmt.Errorf("%d commitments, at least %d replicas are required", len(commitments), minCommittedReplicas)
+	}
+
+	var own *types.AssetCommitment
+	for _, commitment := range commitments {
+		if commitment.NodeID == nodeID {
+			own = commitment
+			break
+		}
+	}
+
+	if own == nil {
+		return nil, fmt.Errorf("node %s has no commitment", nodeID)
+	}
+
+	count := 0
+	for _, commitment := range commitments {
+		if commitment.Root == own.Root && commitment.Leaves == own.Leaves {
+			count++
+		}
+	}
+
+	if count*2 <= len(commitments) {
+		return nil, fmt.Errorf("node %s committed root %s, which is committed by %d of %d replicas", nodeID, own.Root, count, len(commitments))
+	}
+
+	return own, nil
+}
@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+)
+
+func TestMajorityCommitment(t *testing.T) {
+	commitment := func(nodeID, root string, leaves int) *types.AssetCommitment {
+		return &types.AssetCommitment{NodeID: nodeID, Root: root, Leaves: leaves}
+	}
+
+	cases := []struct {
+		name        string
+		commitments []*types.AssetCommitment
+		ok          bool
+	}{
+		{name: "single replica", commitments: []*types.AssetCommitment{commitment("n1", "r1", 4)}},
+		{name: "no commitment of node", commitments: []*types.AssetCommitment{commitment("n2", "r1", 4), commitment("n3", "r1", 4)}},
+		{name: "tie", commitments: []*types.AssetCommitment{commitment("n1", "r1", 4), commitment("n2", "r2", 4)}},
+		{name: "different leaves", commitments: []*types.AssetCommitment{commitment("n1", "r1", 4), commitment("n2", "r1", 5), commitment("n3", "r1", 5)}},
+		{name: "minority", commitments: []*types.AssetCommitment{commitment("n1", "r1", 4), commitment("n2", "r2", 4), commitment("n3", "r2", 4)}},
+		{name: "majority", commitments: []*types.AssetCommitment{commitment("n1", "r1", 4), commitment("n2", "r1", 4), commitment("n3", "r2", 4)}, ok: true},
+	}
+
+	for _, c := range cases {
+		own, err := majorityCommitment(c.commitments, "n1")
+		if c.ok != (err == nil) {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if c.ok && own.NodeID != "n1" {
+			t.Errorf("%s: commitment of node %s", c.name, own.NodeID)
+		}
+	}
+}
+
+func TestCheckLeafProof(t *testing.T) {
+	commitment := &types.AssetCommitment{Root: "r1", Leaves: 4}
+
+	cases := []struct {
+		name  string
+		proof *types.StorageProofResult
+		ok    bool
+	}{
+		{name: "unverified", proof: &types.StorageProofResult{Leaf: 1, Root: "r1", Leaves: 4}},
+		{name: "other root", proof: &types.StorageProofResult{Leaf: 1, Root: "r2", Leaves: 4, Verified: true}},
+		{name: "other leaves", proof: &types.StorageProofResult{Leaf: 1, Root: "r1", Leaves: 2, Verified: true}},
+		{name: "out of leaves", proof: &types.StorageProofResult{Leaf: 4, Root: "r1", Leaves: 4, Verified: true}},
+		{name: "proved", proof: &types.StorageProofResult{Leaf: 3, Root: "r1", Leaves: 4, Verified: true}, ok: true},
+	}
+
+	for _, c := range cases {
+		if err := checkLeafProof(c.proof, commitment); c.ok != (err == nil) {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/Filecoin-Titan/titan/api"
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/cidutil"
 	"github.com/google/uuid"
+	"go.opencensus.io/tag"
 	"golang.org/x/xerrors"
 )
 
@@ -62,15 +64,9 @@ func (m *Manager) isEnabled() bool {
 	return cfg.EnableValidation
 }
 
-// get the profit of validation
+// get the profit of validation from the reward model
 func (m *Manager) getValidationProfit() float64 {
-	cfg, err := m.config()
-	if err != nil {
-		log.Errorf("get config err:%s", err.Error())
-		return 0
-	}
-
-	return cfg.ValidationProfit
+	return m.nodeMgr.RewardModel().ValidationProfit
 }
 
 // startValidate is a method of the Manager that starts a new validation round.
@@ -94,6 +90,10 @@ func (m *Manager) startValidate() error {
 
 	m.resetGroup()
 
+	m.challengeLock.Lock()
+	m.challenges = make(map[string][]*types.StorageChallenge)
+	m.challengeLock.Unlock()
+
 	vrs := m.PairValidatorsAndValidatableNodes()
 	if vrs == nil {
 		return xerrors.Errorf("PairValidatorsAndValidatableNodes err...")
@@ -178,6 +178,16 @@ func (m *Manager) getValidationDetails(vrs []*VWindow) (map[string]*api.Validate
 				TCPSrvAddr: vTCPAddr,
 			}
 
+			// the node without commitment is validated with random blocks
+			if challenges := m.storageChallenges(nodeID); len(challenges) > 0 {
+				req.Challenges = challenges
+				dbInfo.Cid = challenges[0].AssetCID
+
+				m.challengeLock.Lock()
+				m.challenges[nodeID] = challenges
+				m.challengeLock.Unlock()
+			}
+
 			bReqs[nodeID] = req
 		}
 	}
@@ -207,25 +217,34 @@ func (m *Manager) updateResultInfo(status types.ValidationStatus, vr *api.Valida
 		if status == types.ValidationStatusNodeTimeOut || status == types.ValidationStatusValidateFail {
 			node.BandwidthUp = 0
 		} else {
-			if status != types.ValidationStatusCancel {
+			// the storage proofs are too small to measure the bandwidth
+			if status != types.ValidationStatusCancel && len(vr.Proofs) == 0 {
 				node.BandwidthUp = int64(vr.Bandwidth)
 			}
 		}
-		profit = node.CalculateIncome(m.nodeMgr.TotalNetworkEdges, len(m.nodeMgr.GetNodeOfIP(node.ExternalIP)))
+		profit = node.CalculateIncome(m.nodeMgr.RewardModel(), m.nodeMgr.TotalNetworkEdges, len(m.nodeMgr.GetNodeOfIP(node.ExternalIP)))
 	}
 
 	resultInfo := &types.ValidationResultInfo{
 		RoundID:     m.curRoundID,
 		NodeID:      vr.NodeID,
 		Status:      status,
-		BlockNumber: int64(len(vr.Cids)),
+		BlockNumber: int64(len(vr.Cids) + len(vr.Proofs)),
 		Bandwidth:   vr.Bandwidth,
 		Duration:    vr.CostTime,
 		Profit:      profit,
 		TokenID:     vr.Token,
 	}
 
-	return m.nodeMgr.UpdateValidationResultInfo(resultInfo)
+	err := m.nodeMgr.UpdateValidationResultInfo(resultInfo)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordWithTags(context.Background(), []tag.Mutator{tag.Upsert(metrics.Status, status.String())}, metrics.ValidationResults.M(1))
+
+	m.notify.Pub(&types.SchedulerEvent{Topic: types.EventValidationResult, Time: time.Now(), Validation: resultInfo}, types.EventValidationResult.String())
+	return nil
 }
 
 // PushResult push validation result info to queue
@@ -268,6 +287,16 @@ func (m *Manager) handleResult(vr *api.ValidationResult) {
 		return
 	}
 
+	m.challengeLock.Lock()
+	challenges, ok := m.challenges[nodeID]
+	delete(m.challenges, nodeID)
+	m.challengeLock.Unlock()
+
+	if ok {
+		status = m.handleStorageProofs(challenges, vr)
+		return
+	}
+
 	cidCount := len(vr.Cids)
 	if cidCount < 1 {
 		status = types.ValidationStatusValidateFail
@@ -323,6 +352,26 @@ func (m *Manager) handleResult(vr *api.ValidationResult) {
 	status = types.ValidationStatusSuccess
 }
 
+// handleStorageProofs handles the validation result of the node which is challenged with storage proofs
+func (m *Manager) handleStorageProofs(challenges []*types.StorageChallenge, vr *api.ValidationResult) types.ValidationStatus {
+	vInfo, err := m.nodeMgr.LoadNodeValidationInfo(m.curRoundID, vr.NodeID)
+	if err != nil {
+		log.Errorf("LoadNodeValidationCID %s , %s, err:%s", m.curRoundID, vr.NodeID, err.Error())
+		return types.ValidationStatusLoadDBErr
+	}
+
+	if vInfo.ValidatorID != vr.Validator {
+		return types.ValidationStatusValidatorMismatch
+	}
+
+	if err = m.checkStorageProofs(challenges, vr); err != nil {
+		log.Errorf("handleResult round [%s] validator [%s] nodeID [%s] storage proofs fail: %s", m.curRoundID, vr.Validator, vr.NodeID, err.Error())
+		return types.ValidationStatusValidateFail
+	}
+
+	return types.ValidationStatusSuccess
+}
+
 func (m *Manager) getAssetBlocksFromCandidate(hash, cid string, filterNode string, cidCount int) ([]string, string, error) {
 	replicas, err := m.nodeMgr.LoadReplicasByStatus(hash, []types.ReplicaStatus{types.ReplicaStatusSucceeded})
 	if err != nil {
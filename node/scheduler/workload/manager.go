@@ -2,16 +2,20 @@ package workload
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/metrics"
 	"github.com/Filecoin-Titan/titan/node/modules/dtypes"
 	"github.com/Filecoin-Titan/titan/node/scheduler/db"
 	"github.com/Filecoin-Titan/titan/node/scheduler/leadership"
 	"github.com/Filecoin-Titan/titan/node/scheduler/node"
 	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/tag"
 	"golang.org/x/xerrors"
 )
 
@@ -35,6 +39,7 @@ type Manager struct {
 	*db.SQLDB
 
 	resultQueue chan *WorkloadResult
+	stats       *trafficStats
 }
 
 // NewManager return new node manager instance
@@ -44,6 +49,7 @@ func NewManager(sdb *db.SQLDB, configFunc dtypes.GetSchedulerConfigFunc, lmgr *l
 		leadershipMgr: lmgr,
 		SQLDB:         sdb,
 		nodeMgr:       nmgr,
+		stats:         newTrafficStats(),
 	}
 
 	go manager.startHandleWorkloadResults()
@@ -122,10 +128,21 @@ func (m *Manager) handleWorkloadResults() {
 			continue
 		}
 
+		// check workload ...
+		status, reason, cWorkload := m.checkWorkload(record)
+		tags := []tag.Mutator{tag.Upsert(metrics.Status, status.String()), tag.Upsert(metrics.Reason, reason.String())}
+		metrics.RecordWithTags(context.Background(), tags, metrics.WorkloadResults.M(1))
+
+		if status == types.WorkloadStatusInvalid {
+			log.Warnf("workload %s of node %s client %s is invalid, reason %s", record.ID, record.NodeID, record.ClientID, reason.String())
+			if err := m.MarkWorkloadInvalid(record, reason); err != nil {
+				log.Errorf("MarkWorkloadInvalid token:%s error %s", record.ID, err.Error())
+			}
+			continue
+		}
+
 		removeIDs = append(removeIDs, record.ID)
 
-		// check workload ...
-		status, cWorkload := m.checkWorkload(record)
 		if status == types.WorkloadStatusSucceeded {
 			// Retrieve Event
 			if err := m.SaveRetrieveEventInfo(&types.RetrieveEvent{
@@ -142,6 +159,8 @@ func (m *Manager) handleWorkloadResults() {
 				continue
 			}
 
+			m.stats.add(record.ClientID, record.NodeID, cWorkload.DownloadSize, time.Now())
+
 			// update node bandwidths
 			t := cWorkload.EndTime.Sub(cWorkload.StartTime)
 			if t > 1 {
@@ -161,27 +180,25 @@ func (m *Manager) handleWorkloadResults() {
 			log.Errorf("RemoveInvalidWorkloadResult %d err:%s", len(removeIDs), err.Error())
 		}
 	}
-}
 
-// get the profit of validation
-func (m *Manager) getValidationProfit() float64 {
-	cfg, err := m.config()
-	if err != nil {
-		log.Errorf("get config err:%s", err.Error())
-		return 0
+	if err = m.DeleteInvalidWorkloads(); err != nil {
+		log.Errorf("DeleteInvalidWorkloads err:%s", err.Error())
 	}
+}
 
-	return cfg.WorkloadProfit
+// get the profit of workload from the reward model
+func (m *Manager) getValidationProfit() float64 {
+	return m.nodeMgr.RewardModel().WorkloadProfit
 }
 
-func (m *Manager) checkWorkload(record *types.WorkloadRecord) (types.WorkloadStatus, *types.Workload) {
+func (m *Manager) checkWorkload(record *types.WorkloadRecord) (types.WorkloadStatus, types.WorkloadInvalidReason, *types.Workload) {
 	nWorkload := &types.Workload{}
 	if len(record.NodeWorkload) > 0 {
 		dec := gob.NewDecoder(bytes.NewBuffer(record.NodeWorkload))
 		err := dec.Decode(nWorkload)
 		if err != nil {
 			log.Errorf("decode data to *types.Workload error: %w", err)
-			return types.WorkloadStatusFailed, nil
+			return types.WorkloadStatusFailed, types.WorkloadReasonNone, nil
 		}
 	}
 
@@ -191,25 +208,38 @@ func (m *Manager) checkWorkload(record *types.WorkloadRecord) (types.WorkloadSta
 		err := dec.Decode(cWorkload)
 		if err != nil {
 			log.Errorf("decode data to *types.Workload error: %w", err)
-			return types.WorkloadStatusFailed, nil
+			return types.WorkloadStatusFailed, types.WorkloadReasonNone, nil
 		}
 	}
 
 	if len(record.ClientWorkload) == 0 && len(record.NodeWorkload) == 0 {
-		return types.WorkloadStatusInvalid, cWorkload
+		return types.WorkloadStatusInvalid, types.WorkloadReasonNoReport, cWorkload
 	}
 
 	if nWorkload.DownloadSize == 0 || nWorkload.DownloadSize != cWorkload.DownloadSize {
-		return types.WorkloadStatusFailed, cWorkload
+		return types.WorkloadStatusFailed, types.WorkloadReasonNone, cWorkload
 	}
 
-	// TODO other ...
+	cfg, err := m.config()
+	if err != nil {
+		log.Errorf("get config err:%s", err.Error())
+		return types.WorkloadStatusSucceeded, types.WorkloadReasonNone, cWorkload
+	}
 
-	return types.WorkloadStatusSucceeded, cWorkload
+	c := &workloadCheck{record: record, node: nWorkload, client: cWorkload, cfg: &cfg, stats: m.stats, now: time.Now()}
+	if node := m.nodeMgr.GetNode(record.NodeID); node != nil {
+		c.bandwidthUp = node.BandwidthUp
+	}
+
+	if reason := checkRules(c); reason != types.WorkloadReasonNone {
+		return types.WorkloadStatusInvalid, reason, cWorkload
+	}
+
+	return types.WorkloadStatusSucceeded, types.WorkloadReasonNone, cWorkload
 }
 
-// HandleUserWorkload handle user workload
-func (m *Manager) HandleUserWorkload(data []byte, node *node.Node) error {
+// HandleUserWorkload handle user workload, clientIP is the address the report is submitted from
+func (m *Manager) HandleUserWorkload(data []byte, node *node.Node, clientIP string) error {
 	reports := make([]*types.WorkloadReport, 0)
 	dec := gob.NewDecoder(bytes.NewBuffer(data))
 	err := dec.Decode(&reports)
@@ -229,6 +259,12 @@ func (m *Manager) HandleUserWorkload(data []byte, node *node.Node) error {
 			rp.ClientID = node.NodeID
 		}
 
+		// the addresses of the client are observed by the scheduler, not reported
+		rp.Workload.ClientIPs = nil
+		if clientIP != "" {
+			rp.Workload.ClientIPs = []string{clientIP}
+		}
+
 		_, err := m.handleWorkloadReport(rp.NodeID, rp, true)
 		if err != nil {
 			log.Errorf("handler user workload report error %s, token id %s", err.Error(), rp.TokenID)
@@ -320,6 +356,7 @@ func (m *Manager) mergeWorkloads(workloads []*types.Workload) *types.Workload {
 	endTime := time.Time{}
 	speedCount := int64(0)
 	accumulateSpeed := int64(0)
+	clientIPs := make([]string, 0)
 
 	for _, workload := range workloads {
 		if workload.DownloadSpeed > 0 {
@@ -335,11 +372,17 @@ func (m *Manager) mergeWorkloads(workloads []*types.Workload) *types.Workload {
 		if workload.EndTime.After(endTime) {
 			endTime = workload.EndTime
 		}
+
+		for _, ip := range workload.ClientIPs {
+			if !slices.Contains(clientIPs, ip) {
+				clientIPs = append(clientIPs, ip)
+			}
+		}
 	}
 
 	downloadSpeed := int64(0)
 	if speedCount > 0 {
 		downloadSpeed = accumulateSpeed / speedCount
 	}
-	return &types.Workload{DownloadSpeed: downloadSpeed, DownloadSize: downloadSize, StartTime: startTime, EndTime: endTime}
+	return &types.Workload{DownloadSpeed: downloadSpeed, DownloadSize: downloadSize, StartTime: startTime, EndTime: endTime, ClientIPs: clientIPs}
 }
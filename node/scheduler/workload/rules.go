@@ -0,0 +1,118 @@
+package workload
+
+import (
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+// clockSkew is the tolerance of the clocks of the client and the node
+const clockSkew = time.Minute
+
+// workloadCheck is a workload whose client and node reports have the same size, checked by the fraud rules
+type workloadCheck struct {
+	record *types.WorkloadRecord
+	node   *types.Workload
+	client *types.Workload
+	// upload bandwidth of the node, 0 if unknown
+	bandwidthUp int64
+	cfg         *config.SchedulerCfg
+	stats       *trafficStats
+	now         time.Time
+}
+
+// rule returns false if the workload is fraudulent
+type rule struct {
+	reason types.WorkloadInvalidReason
+	check  func(c *workloadCheck) bool
+}
+
+// rules are checked in order, the first failed rule is the reason of the invalid workload
+var rules = []rule{
+	{types.WorkloadReasonTimeWindow, checkTimeWindow},
+	{types.WorkloadReasonSpeed, checkSpeed},
+	{types.WorkloadReasonTokenReuse, checkTokenReuse},
+	{types.WorkloadReasonClientVolume, checkClientVolume},
+	{types.WorkloadReasonCollusion, checkCollusion},
+}
+
+// checkRules returns the reason of the first failed rule, WorkloadReasonNone if all rules pass
+func checkRules(c *workloadCheck) types.WorkloadInvalidReason {
+	for _, r := range rules {
+		if !r.check(c) {
+			return r.reason
+		}
+	}
+	return types.WorkloadReasonNone
+}
+
+// checkTimeWindow checks the reports are in the token lifetime and overlap each other
+func checkTimeWindow(c *workloadCheck) bool {
+	for _, w := range []*types.Workload{c.node, c.client} {
+		if w.EndTime.Before(w.StartTime) {
+			return false
+		}
+
+		if w.StartTime.Before(c.record.CreatedTime.Add(-clockSkew)) || w.EndTime.After(c.record.Expiration.Add(clockSkew)) {
+			return false
+		}
+	}
+
+	return !c.node.StartTime.After(c.client.EndTime.Add(clockSkew)) && !c.client.StartTime.After(c.node.EndTime.Add(clockSkew))
+}
+
+// checkSpeed checks the node does not serve faster than its upload bandwidth
+func checkSpeed(c *workloadCheck) bool {
+	if c.cfg.WorkloadSpeedTolerance <= 0 || c.bandwidthUp <= 0 {
+		return true
+	}
+
+	limit := float64(c.bandwidthUp) * c.cfg.WorkloadSpeedTolerance
+	if float64(c.node.DownloadSpeed) > limit {
+		return false
+	}
+
+	// the speed of a short download is not measurable
+	duration := c.node.EndTime.Sub(c.node.StartTime)
+	if duration < time.Second {
+		return true
+	}
+
+	return float64(c.node.DownloadSize)/duration.Seconds() <= limit
+}
+
+// checkTokenReuse checks the token is not shared by too many addresses,
+// the addresses reported by the node are merged with the ones the scheduler observed from the client reports
+func checkTokenReuse(c *workloadCheck) bool {
+	if c.cfg.WorkloadMaxTokenIPs <= 0 {
+		return true
+	}
+
+	ips := make(map[string]struct{})
+	for _, w := range []*types.Workload{c.node, c.client} {
+		for _, ip := range w.ClientIPs {
+			ips[ip] = struct{}{}
+		}
+	}
+
+	return len(ips) <= c.cfg.WorkloadMaxTokenIPs
+}
+
+// checkClientVolume checks the client does not download abnormal volume in a day
+func checkClientVolume(c *workloadCheck) bool {
+	if c.cfg.WorkloadClientVolume <= 0 {
+		return true
+	}
+
+	return c.stats.clientBytes(c.record.ClientID, c.now)+c.client.DownloadSize <= c.cfg.WorkloadClientVolume
+}
+
+// checkCollusion checks the client is not paired with the node too many times in a day
+func checkCollusion(c *workloadCheck) bool {
+	if c.cfg.WorkloadPairDailyLimit <= 0 {
+		return true
+	}
+
+	return c.stats.pairCount(c.record.ClientID, c.record.NodeID, c.now)+1 <= c.cfg.WorkloadPairDailyLimit
+}
@@ -0,0 +1,74 @@
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Filecoin-Titan/titan/api/types"
+	"github.com/Filecoin-Titan/titan/node/config"
+)
+
+func TestCheckRules(t *testing.T) {
+	now := time.Now()
+	cfg := config.DefaultSchedulerCfg()
+	cfg.WorkloadPairDailyLimit = 2
+
+	newCheck := func() *workloadCheck {
+		record := &types.WorkloadRecord{TokenPayload: types.TokenPayload{ID: "t1", NodeID: "n1", ClientID: "c1", CreatedTime: now.Add(-time.Hour), Expiration: now.Add(time.Hour)}}
+		w := &types.Workload{DownloadSize: 10 << 20, DownloadSpeed: 1 << 20, StartTime: now.Add(-10 * time.Second), EndTime: now, ClientIPs: []string{"1.1.1.1"}}
+		client := *w
+		return &workloadCheck{record: record, node: w, client: &client, bandwidthUp: 2 << 20, cfg: cfg, stats: newTrafficStats(), now: now}
+	}
+
+	if reason := checkRules(newCheck()); reason != types.WorkloadReasonNone {
+		t.Fatalf("valid workload is invalid, reason %s", reason)
+	}
+
+	c := newCheck()
+	c.client.StartTime = now.Add(10 * time.Minute)
+	c.client.EndTime = now.Add(11 * time.Minute)
+	if reason := checkRules(c); reason != types.WorkloadReasonTimeWindow {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonTimeWindow)
+	}
+
+	c = newCheck()
+	c.node.DownloadSize = 100 << 20
+	if reason := checkRules(c); reason != types.WorkloadReasonSpeed {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonSpeed)
+	}
+
+	c = newCheck()
+	c.node.ClientIPs = []string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4"}
+	if reason := checkRules(c); reason != types.WorkloadReasonTokenReuse {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonTokenReuse)
+	}
+
+	// the addresses observed from the client reports count even if the node does not report them
+	c = newCheck()
+	c.node.ClientIPs = []string{"1.1.1.1", "2.2.2.2"}
+	c.client.ClientIPs = []string{"3.3.3.3", "4.4.4.4"}
+	if reason := checkRules(c); reason != types.WorkloadReasonTokenReuse {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonTokenReuse)
+	}
+
+	c = newCheck()
+	c.stats.add("c1", "n2", cfg.WorkloadClientVolume, now)
+	if reason := checkRules(c); reason != types.WorkloadReasonClientVolume {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonClientVolume)
+	}
+
+	c = newCheck()
+	c.stats.add("c1", "n1", 1, now.Add(-2*time.Hour))
+	c.stats.add("c1", "n1", 1, now)
+	if reason := checkRules(c); reason != types.WorkloadReasonCollusion {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonCollusion)
+	}
+
+	// the stats of a day ago are expired
+	c = newCheck()
+	c.stats.add("c1", "n1", 1, now.Add(-25*time.Hour))
+	c.stats.add("c1", "n1", 1, now.Add(-24*time.Hour))
+	if reason := checkRules(c); reason != types.WorkloadReasonNone {
+		t.Fatalf("reason %s, expect %s", reason, types.WorkloadReasonNone)
+	}
+}
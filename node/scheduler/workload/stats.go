@@ -0,0 +1,87 @@
+package workload
+
+import (
+	"sync"
+	"time"
+)
+
+// statsWindow is the count of hourly buckets of the traffic stats
+const statsWindow = 24
+
+// trafficStats counts the succeeded workloads of the clients and the client-node pairs in the last day,
+// the stats are kept in memory and start over when the scheduler restarts
+type trafficStats struct {
+	lock    sync.Mutex
+	buckets [statsWindow]*trafficBucket
+}
+
+type trafficBucket struct {
+	hour        int64
+	clientBytes map[string]int64
+	pairCounts  map[string]int
+}
+
+func newTrafficStats() *trafficStats {
+	return &trafficStats{}
+}
+
+func pairKey(clientID, nodeID string) string {
+	return clientID + "/" + nodeID
+}
+
+// bucket returns the bucket of the hour, the bucket of the hour a day ago is reset
+func (s *trafficStats) bucket(t time.Time) *trafficBucket {
+	hour := t.Unix() / int64(time.Hour/time.Second)
+	b := s.buckets[hour%statsWindow]
+	if b == nil || b.hour != hour {
+		b = &trafficBucket{hour: hour, clientBytes: make(map[string]int64), pairCounts: make(map[string]int)}
+		s.buckets[hour%statsWindow] = b
+	}
+	return b
+}
+
+// add counts a succeeded workload
+func (s *trafficStats) add(clientID, nodeID string, size int64, t time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	b := s.bucket(t)
+	b.clientBytes[clientID] += size
+	b.pairCounts[pairKey(clientID, nodeID)]++
+}
+
+// clientBytes returns the bytes the client downloaded in the last day
+func (s *trafficStats) clientBytes(clientID string, now time.Time) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	size := int64(0)
+	for _, b := range s.recent(now) {
+		size += b.clientBytes[clientID]
+	}
+	return size
+}
+
+// pairCount returns the count of workloads of the client and the node in the last day
+func (s *trafficStats) pairCount(clientID, nodeID string, now time.Time) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	count := 0
+	for _, b := range s.recent(now) {
+		count += b.pairCounts[pairKey(clientID, nodeID)]
+	}
+	return count
+}
+
+// recent returns the buckets of the last day
+func (s *trafficStats) recent(now time.Time) []*trafficBucket {
+	hour := now.Unix() / int64(time.Hour/time.Second)
+	out := make([]*trafficBucket, 0, statsWindow)
+	for _, b := range s.buckets {
+		if b != nil && b.hour > hour-statsWindow && b.hour <= hour {
+			out = append(out, b)
+		}
+	}
+	return out
+}
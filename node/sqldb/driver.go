@@ -0,0 +1,298 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var returningRe = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+// dialectDriver wraps the driver of the dialect, the MySQL queries of the scheduler are translated to the dialect
+type dialectDriver struct {
+	dialect Dialect
+	driver  driver.Driver
+}
+
+// Open opens a connection of the wrapped driver
+func (d *dialectDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dialectConn{Conn: conn, dialect: d.dialect, tables: make(map[string]*tableInfo)}, nil
+}
+
+// tableInfo is the primary key and the auto increment column of a table
+type tableInfo struct {
+	keys   []string
+	serial string
+}
+
+type dialectConn struct {
+	driver.Conn
+	dialect Dialect
+
+	lock   sync.Mutex
+	tables map[string]*tableInfo
+}
+
+var (
+	_ driver.ConnPrepareContext = (*dialectConn)(nil)
+	_ driver.ConnBeginTx        = (*dialectConn)(nil)
+	_ driver.ExecerContext      = (*dialectConn)(nil)
+	_ driver.QueryerContext     = (*dialectConn)(nil)
+	_ driver.Pinger             = (*dialectConn)(nil)
+	_ driver.SessionResetter    = (*dialectConn)(nil)
+	_ driver.NamedValueChecker  = (*dialectConn)(nil)
+)
+
+func (c *dialectConn) translate(ctx context.Context, query string) (string, error) {
+	return translate(c.dialect, query, func(table string) ([]string, error) {
+		info, err := c.tableInfo(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		return info.keys, nil
+	})
+}
+
+// Prepare prepares the translated query
+func (c *dialectConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares the translated query
+func (c *dialectConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query, err := c.translate(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx starts a transaction of the wrapped connection
+func (c *dialectConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // the wrapped driver does not support BeginTx
+}
+
+// ExecContext executes the translated query, the id of the row inserted to PostgreSQL is returned by RETURNING
+func (c *dialectConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	table := ""
+	if m := insertTableRe.FindStringSubmatch(query); m != nil && c.dialect == PostgreSQL && !returningRe.MatchString(query) {
+		table = m[1]
+	}
+
+	query, err := c.translate(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if table != "" {
+		info, err := c.tableInfo(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.serial != "" {
+			return c.execReturning(ctx, fmt.Sprintf("%s RETURNING %s", strings.TrimRight(strings.TrimSpace(query), ";"), info.serial), args)
+		}
+	}
+
+	return execer.ExecContext(ctx, query, c.args(args))
+}
+
+// QueryContext queries with the translated query
+func (c *dialectConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	query, err := c.translate(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryer.QueryContext(ctx, query, c.args(args))
+}
+
+// Ping checks the wrapped connection
+func (c *dialectConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the wrapped connection before it is reused
+func (c *dialectConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue converts the arguments by the wrapped connection
+func (c *dialectConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// args converts the times to UTC for SQLite, the times are saved as text and compared with datetime('now') in UTC
+func (c *dialectConn) args(args []driver.NamedValue) []driver.NamedValue {
+	if c.dialect != SQLite {
+		return args
+	}
+
+	for i := range args {
+		if t, ok := args[i].Value.(time.Time); ok {
+			args[i].Value = t.UTC()
+		}
+	}
+	return args
+}
+
+// execReturning executes the insert and returns the id of the inserted row
+func (c *dialectConn) execReturning(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &insertResult{}
+	for _, row := range rows {
+		if id, ok := row[0].(int64); ok {
+			result.id = id
+		}
+		result.rows++
+	}
+
+	return result, nil
+}
+
+// tableInfo returns the primary key and the auto increment column of the table from the catalog of the database
+func (c *dialectConn) tableInfo(ctx context.Context, table string) (*tableInfo, error) {
+	c.lock.Lock()
+	info, ok := c.tables[table]
+	c.lock.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	info = &tableInfo{}
+	exists := false
+	switch c.dialect {
+	case SQLite:
+		rows, err := c.query(ctx, "SELECT name, pk FROM pragma_table_info(?) ORDER BY pk", []driver.NamedValue{{Ordinal: 1, Value: table}})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			exists = true
+			if pk, ok := row[1].(int64); ok && pk > 0 {
+				info.keys = append(info.keys, asString(row[0]))
+			}
+		}
+	case PostgreSQL:
+		rows, err := c.query(ctx, `SELECT column_name, COALESCE(column_default LIKE 'nextval(%', false) FROM information_schema.columns
+			WHERE table_schema=current_schema() AND table_name=$1`, []driver.NamedValue{{Ordinal: 1, Value: table}})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			exists = true
+			if serial, ok := row[1].(bool); ok && serial {
+				info.serial = asString(row[0])
+			}
+		}
+
+		rows, err = c.query(ctx, `SELECT a.attname FROM pg_index i JOIN pg_attribute a ON a.attrelid=i.indrelid AND a.attnum=ANY(i.indkey)
+			WHERE i.indrelid=to_regclass($1) AND i.indisprimary`, []driver.NamedValue{{Ordinal: 1, Value: table}})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			info.keys = append(info.keys, asString(row[0]))
+		}
+	}
+
+	// the table may be created later
+	if exists {
+		c.lock.Lock()
+		c.tables[table] = info
+		c.lock.Unlock()
+	}
+
+	return info, nil
+}
+
+// query runs the query on the wrapped connection without translation and reads all rows
+func (c *dialectConn) query(ctx context.Context, query string, args []driver.NamedValue) ([][]driver.Value, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, fmt.Errorf("driver of %s does not support query", c.dialect)
+	}
+
+	rows, err := queryer.QueryContext(ctx, query, c.args(args))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck // ignore error
+
+	out := make([][]driver.Value, 0)
+	for {
+		row := make([]driver.Value, len(rows.Columns()))
+		if err := rows.Next(row); err != nil {
+			if err == io.EOF {
+				return out, nil
+			}
+			return nil, err
+		}
+		out = append(out, row)
+	}
+}
+
+func asString(v driver.Value) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// insertResult is the result of the insert with RETURNING
+type insertResult struct {
+	id   int64
+	rows int64
+}
+
+func (r *insertResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r *insertResult) RowsAffected() (int64, error) {
+	return r.rows, nil
+}
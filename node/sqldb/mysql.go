@@ -5,12 +5,10 @@ import (
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
 )
 
-// NewDB creates a new database connection using the given MySQL connection string.
-// The function returns a sqlx.DB pointer or an error if the connection failed.
-func NewDB(path string) (*sqlx.DB, error) {
+// newMySQLDB creates a new database connection using the given MySQL connection string.
+func newMySQLDB(path string) (*sqlx.DB, error) {
 	path = fmt.Sprintf("%s?parseTime=true&loc=Local", path)
 
 	client, err := sqlx.Open("mysql", path)
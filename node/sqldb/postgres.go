@@ -0,0 +1,29 @@
+package sqldb
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// postgresDriverName is the driver translating the MySQL queries to PostgreSQL
+const postgresDriverName = "titan-postgres"
+
+func init() {
+	sql.Register(postgresDriverName, &dialectDriver{dialect: PostgreSQL, driver: &pq.Driver{}})
+}
+
+// newPostgresDB creates a new database connection with the PostgreSQL connection url
+func newPostgresDB(url string) (*sqlx.DB, error) {
+	client, err := sqlx.Open(postgresDriverName, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = client.Ping(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
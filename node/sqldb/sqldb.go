@@ -0,0 +1,44 @@
+package sqldb
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect is the SQL dialect of the database backend
+type Dialect string
+
+const (
+	MySQL      Dialect = "mysql"
+	SQLite     Dialect = "sqlite"
+	PostgreSQL Dialect = "postgres"
+)
+
+// ParseAddress returns the dialect and the data source name of the database address,
+// sqlite://<path> opens an embedded SQLite database, postgres://... or postgresql://... connects to PostgreSQL,
+// other addresses are MySQL data source names, e.g. user:password@tcp(127.0.0.1:3306)/titan
+func ParseAddress(address string) (Dialect, string) {
+	switch {
+	case strings.HasPrefix(address, "sqlite://"):
+		return SQLite, strings.TrimPrefix(address, "sqlite://")
+	case strings.HasPrefix(address, "postgres://"), strings.HasPrefix(address, "postgresql://"):
+		return PostgreSQL, address
+	default:
+		return MySQL, address
+	}
+}
+
+// NewDB creates a new database connection with the database address, the dialect is selected by the address.
+// The function returns a sqlx.DB pointer or an error if the connection failed.
+func NewDB(address string) (*sqlx.DB, error) {
+	dialect, dsn := ParseAddress(address)
+	switch dialect {
+	case SQLite:
+		return newSQLiteDB(dsn)
+	case PostgreSQL:
+		return newPostgresDB(dsn)
+	default:
+		return newMySQLDB(dsn)
+	}
+}
@@ -0,0 +1,41 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the driver translating the MySQL queries to SQLite
+const sqliteDriverName = "titan-sqlite3"
+
+func init() {
+	sql.Register(sqliteDriverName, &dialectDriver{dialect: SQLite, driver: &sqlite3.SQLiteDriver{}})
+}
+
+// newSQLiteDB opens the embedded SQLite database of the file, the file is created if it does not exist
+func newSQLiteDB(path string) (*sqlx.DB, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	// SQLite allows one writer, the transactions take the write lock when they begin and wait for each other,
+	// the like of the queries is case sensitive as the MySQL like binary
+	dsn := fmt.Sprintf("file:%s?_loc=auto&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate&_cslike=true", path)
+	client, err := sqlx.Open(sqliteDriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = client.Ping(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,169 @@
+package sqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)^\s*CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)\s*\(`)
+	indexRe       = regexp.MustCompile(`(?is)^(UNIQUE\s+)?KEY\s+(\w+)\s*\((.*)\)$`)
+	autoIncRe     = regexp.MustCompile(`(?i)\bINT\s+UNSIGNED\s+AUTO_INCREMENT\b`)
+	unsignedRe    = regexp.MustCompile(`(?i)\s+UNSIGNED\b`)
+	onUpdateRe    = regexp.MustCompile(`(?i)\s+ON\s+UPDATE\s+CURRENT_TIMESTAMP\b`)
+	datetimeRe    = regexp.MustCompile(`(?i)\bDATETIME\b`)
+	tinyintRe     = regexp.MustCompile(`(?i)\bTINYINT\b`)
+	intWidthRe    = regexp.MustCompile(`(?i)\b(TINYINT|SMALLINT|MEDIUMINT|INT|INTEGER|BIGINT)\s*\(\d+\)`)
+	mediumintRe   = regexp.MustCompile(`(?i)\bMEDIUMINT\b`)
+	binaryTypeRe  = regexp.MustCompile(`(?i)\b(?:BLOB|VARBINARY\(\d+\))`)
+
+	insertTableRe = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+(\w+)`)
+	onDuplicateRe = regexp.MustCompile(`(?is)\bON\s+DUPLICATE\s+KEY\s+UPDATE\b`)
+	valuesRe      = regexp.MustCompile(`(?i)\bVALUES\((\w+)\)`)
+	dateSubRe     = regexp.MustCompile(`(?i)\bDATE_SUB\(\s*NOW\(\)\s*,\s*INTERVAL\s+(\d+)\s+(\w+)\s*\)`)
+	nowRe         = regexp.MustCompile(`(?i)\bNOW\(\)`)
+	likeBinaryRe  = regexp.MustCompile(`(?i)\bLIKE\s+BINARY\s+(\?|:\w+)`)
+	binaryRe      = regexp.MustCompile(`(?i)\bBINARY\s+(\w+(?:\.\w+)?)`)
+)
+
+// translate rewrites the MySQL query to the dialect,
+// keys returns the primary key of the table which is the conflict target of ON DUPLICATE KEY UPDATE
+func translate(dialect Dialect, query string, keys func(table string) ([]string, error)) (string, error) {
+	if dialect == MySQL {
+		return query, nil
+	}
+
+	if createTableRe.MatchString(query) {
+		return translateCreateTable(dialect, query)
+	}
+
+	if loc := onDuplicateRe.FindStringIndex(query); loc != nil {
+		m := insertTableRe.FindStringSubmatch(query)
+		if m == nil {
+			return "", fmt.Errorf("can not find the table of query %s", query)
+		}
+
+		cols, err := keys(m[1])
+		if err != nil {
+			return "", err
+		}
+
+		if len(cols) == 0 {
+			return "", fmt.Errorf("table %s has no primary key", m[1])
+		}
+
+		update := valuesRe.ReplaceAllString(query[loc[1]:], "excluded.$1")
+		query = fmt.Sprintf("%sON CONFLICT (%s) DO UPDATE SET%s", query[:loc[0]], strings.Join(cols, ", "), update)
+	}
+
+	switch dialect {
+	case SQLite:
+		query = dateSubRe.ReplaceAllString(query, "datetime('now', '-$1 $2')")
+		query = nowRe.ReplaceAllString(query, "datetime('now')")
+		query = likeBinaryRe.ReplaceAllString(query, `LIKE $1 ESCAPE '\'`)
+		query = binaryRe.ReplaceAllString(query, "$1")
+	case PostgreSQL:
+		query = dateSubRe.ReplaceAllString(query, "(NOW() - INTERVAL '$1 $2')")
+		query = likeBinaryRe.ReplaceAllString(query, "LIKE $1")
+		query = binaryRe.ReplaceAllString(query, `$1 COLLATE "C"`)
+		query = numberPlaceholders(query)
+	}
+
+	return query, nil
+}
+
+// translateCreateTable rewrites the column types and moves the keys of the table to CREATE INDEX statements
+func translateCreateTable(dialect Dialect, query string) (string, error) {
+	m := createTableRe.FindStringSubmatchIndex(query)
+	table := query[m[2]:m[3]]
+
+	end := strings.LastIndex(query, ")")
+	if end < m[1] {
+		return "", fmt.Errorf("can not parse the definition of table %s", table)
+	}
+
+	columns := make([]string, 0)
+	indexes := make([]string, 0)
+	for _, def := range splitDefinitions(query[m[1]:end]) {
+		if im := indexRe.FindStringSubmatch(def); im != nil {
+			index := "INDEX"
+			if im[1] != "" {
+				index = "UNIQUE INDEX"
+			}
+			indexes = append(indexes, fmt.Sprintf("CREATE %s IF NOT EXISTS %s_%s ON %s (%s)", index, table, im[2], table, im[3]))
+			continue
+		}
+
+		def = onUpdateRe.ReplaceAllString(def, "")
+		// the display width of the integer types is MySQL only, PostgreSQL rejects it
+		def = intWidthRe.ReplaceAllString(def, "$1")
+		if dialect == PostgreSQL {
+			def = autoIncRe.ReplaceAllString(def, "SERIAL")
+			def = datetimeRe.ReplaceAllString(def, "TIMESTAMPTZ")
+			def = tinyintRe.ReplaceAllString(def, "SMALLINT")
+			def = mediumintRe.ReplaceAllString(def, "INTEGER")
+			def = binaryTypeRe.ReplaceAllString(def, "BYTEA")
+		} else {
+			// an INTEGER column which is the primary key is the rowid of SQLite
+			def = autoIncRe.ReplaceAllString(def, "INTEGER")
+		}
+		columns = append(columns, unsignedRe.ReplaceAllString(def, ""))
+	}
+
+	stmts := append([]string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n)", table, strings.Join(columns, ",\n\t"))}, indexes...)
+	return strings.Join(stmts, ";\n") + ";", nil
+}
+
+// splitDefinitions splits the definitions of the table by the commas out of the parentheses
+func splitDefinitions(body string) []string {
+	defs := make([]string, 0)
+	depth, start := 0, 0
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				defs = append(defs, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	defs = append(defs, body[start:])
+
+	out := make([]string, 0, len(defs))
+	for _, def := range defs {
+		if def = strings.TrimSpace(def); def != "" {
+			out = append(out, def)
+		}
+	}
+
+	return out
+}
+
+// numberPlaceholders rewrites the ? placeholders to $1, $2... of PostgreSQL, the quoted strings are not changed
+func numberPlaceholders(query string) string {
+	var b strings.Builder
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
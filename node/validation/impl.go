@@ -1,11 +1,14 @@
 package validation
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"net"
 	"time"
 
 	"github.com/Filecoin-Titan/titan/api"
+	"github.com/Filecoin-Titan/titan/api/types"
 	"github.com/Filecoin-Titan/titan/node/device"
 	"github.com/ipfs/go-libipfs/blocks"
 	logging "github.com/ipfs/go-log/v2"
@@ -23,6 +26,8 @@ type Validation struct {
 
 type Checker interface {
 	GetAssetForValidation(ctx context.Context, randomSeed int64) (Asset, error)
+	// GetStorageProofs answers the challenges with the blocks and their merkle proofs
+	GetStorageProofs(ctx context.Context, challenges []*types.StorageChallenge) ([]*types.StorageProof, error)
 }
 
 type Asset interface {
@@ -43,6 +48,13 @@ func (v *Validation) ExecuteValidation(ctx context.Context, req *api.ValidateReq
 	}
 
 	go func() {
+		if len(req.Challenges) > 0 {
+			if err = v.sendProofs(conn, req, v.device.GetBandwidthUp()); err != nil {
+				log.Errorf("send proofs error %s", err.Error())
+			}
+			return
+		}
+
 		if err = v.sendBlocks(conn, req, v.device.GetBandwidthUp()); err != nil {
 			log.Errorf("send blocks error %s", err.Error())
 		}
@@ -112,3 +124,44 @@ func (v *Validation) sendBlocks(conn *net.TCPConn, req *api.ValidateReq, speedRa
 		}
 	}
 }
+
+// sendProofs answers the storage challenges with the proofs over a TCP connection
+func (v *Validation) sendProofs(conn *net.TCPConn, req *api.ValidateReq, speedRate int64) error {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Errorf("close tcp error: %s", err.Error())
+		}
+	}()
+
+	limiter := rate.NewLimiter(rate.Limit(speedRate), int(speedRate))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(req.Duration)*time.Second)
+	defer cancel()
+
+	nodeID, err := v.device.GetNodeID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := sendNodeID(conn, nodeID, limiter); err != nil {
+		return err
+	}
+
+	proofs, err := v.checker.GetStorageProofs(ctx, req.Challenges)
+	if err != nil {
+		return xerrors.Errorf("get storage proofs error %w", err)
+	}
+
+	for _, proof := range proofs {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(proof); err != nil {
+			return err
+		}
+
+		if err := sendData(conn, buf.Bytes(), api.TCPMsgTypeProof, limiter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}